@@ -53,6 +53,56 @@ func (c *Client) GetInterfaces(ctx context.Context) ([]*rpc.Interface, error) {
 	return resp.Interfaces, nil
 }
 
+func (c *Client) GetOSPFInstance(ctx context.Context) (*rpc.OSPFInstance, error) {
+	resp, err := c.rpcClient.GetOSPFInstance(ctx, &rpc.GetOSPFInstanceRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Instance, nil
+}
+
+func (c *Client) GetOSPFInterfaces(ctx context.Context) ([]*rpc.OSPFInterface, error) {
+	resp, err := c.rpcClient.GetOSPFInterfaces(ctx, &rpc.GetOSPFInterfacesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Interfaces, nil
+}
+
+func (c *Client) GetOSPFNeighbors(ctx context.Context) ([]*rpc.OSPFNeighbor, error) {
+	resp, err := c.rpcClient.GetOSPFNeighbors(ctx, &rpc.GetOSPFNeighborsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Neighbors, nil
+}
+
+func (c *Client) GetOSPFRoutes(ctx context.Context) ([]*rpc.OSPFRoute, error) {
+	resp, err := c.rpcClient.GetOSPFRoutes(ctx, &rpc.GetOSPFRoutesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Routes, nil
+}
+
+func (c *Client) GetOSPFAreas(ctx context.Context) ([]*rpc.OSPFArea, error) {
+	resp, err := c.rpcClient.GetOSPFAreas(ctx, &rpc.GetOSPFAreasRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Areas, nil
+}
+
+func (c *Client) SetOSPFMaxMetric(ctx context.Context, enabled bool) error {
+	_, err := c.rpcClient.SetOSPFMaxMetric(ctx, &rpc.SetOSPFMaxMetricRequest{Enabled: enabled})
+	return err
+}
+
 func (c *Client) GetServices(ctx context.Context) ([]config.ServiceID, error) {
 	resp, err := c.rpcClient.GetServices(ctx, &rpc.GetServicesRequest{})
 	if err != nil {
@@ -7,6 +7,8 @@ import (
 
 	"github.com/davidbalbert/chatter/chatterd/services"
 	"github.com/davidbalbert/chatter/config"
+	"github.com/davidbalbert/chatter/net/netmon"
+	"github.com/davidbalbert/chatter/ospf"
 	"github.com/davidbalbert/chatter/rpc"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
@@ -68,36 +70,26 @@ func (s *Server) GetServices(ctx context.Context) ([]config.ServiceID, error) {
 }
 
 func (s *Server) GetInterfaces(ctx context.Context) ([]*rpc.Interface, error) {
-	netifs, err := net.Interfaces()
+	service, err := s.serviceManager.Get(config.ServiceInterfaceMonitor)
 	if err != nil {
 		return nil, err
 	}
 
-	ifaces := make([]*rpc.Interface, len(netifs))
-
-	for i, netif := range netifs {
-		addrs, err := netif.Addrs()
-		if err != nil {
-			return nil, err
-		}
-
-		prefixes := make([]*rpc.Prefix, len(addrs))
-		for j, addr := range addrs {
-			ipnet, ok := addr.(*net.IPNet)
-			if !ok {
-				return nil, fmt.Errorf("failed to convert address to net.IPNet: %v", addr)
-			}
+	interfaceMonitor, ok := service.(*netmon.Monitor)
+	if !ok {
+		return nil, fmt.Errorf("expected *netmon.Monitor but got %T", service)
+	}
 
-			ones, _ := ipnet.Mask.Size()
+	snapshot := interfaceMonitor.Snapshot()
 
-			ip := ipnet.IP.To4()
-			if ip == nil {
-				ip = ipnet.IP
-			}
+	ifaces := make([]*rpc.Interface, len(snapshot))
 
+	for i, netif := range snapshot {
+		prefixes := make([]*rpc.Prefix, len(netif.Prefixes))
+		for j, prefix := range netif.Prefixes {
 			prefixes[j] = &rpc.Prefix{
-				Addr:      ip,
-				PrefixLen: int32(ones),
+				Addr:      prefix.Addr().AsSlice(),
+				PrefixLen: int32(prefix.Bits()),
 			}
 		}
 
@@ -113,3 +105,278 @@ func (s *Server) GetInterfaces(ctx context.Context) ([]*rpc.Interface, error) {
 
 	return ifaces, nil
 }
+
+func (s *Server) ospfInstance() (*ospf.Instance, error) {
+	service, err := s.serviceManager.Get(config.ServiceOSPF)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, ok := service.(*ospf.Instance)
+	if !ok {
+		return nil, fmt.Errorf("expected *ospf.Instance but got %T", service)
+	}
+
+	return instance, nil
+}
+
+func (s *Server) GetOSPFInstance(ctx context.Context) (*rpc.OSPFInstance, error) {
+	instance, err := s.ospfInstance()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := instance.InstanceSnapshot()
+	gr := snapshot.GracefulRestart
+	mm := snapshot.MaxMetric
+
+	history := make([]*rpc.OSPFSPFRun, len(snapshot.SPF.History))
+	for i, run := range snapshot.SPF.History {
+		triggers := make([]*rpc.OSPFSPFTrigger, len(run.Triggers))
+		for j, t := range run.Triggers {
+			triggers[j] = &rpc.OSPFSPFTrigger{
+				AreaId:            uint32(t.AreaID),
+				Type:              t.Type.String(),
+				Id:                t.ID.AsSlice(),
+				AdvertisingRouter: uint32(t.AdvertisingRouter),
+				Flushed:           t.Flushed,
+			}
+		}
+
+		history[i] = &rpc.OSPFSPFRun{
+			StartUnixNano: run.Start.UnixNano(),
+			Duration:      int64(run.Duration),
+			Triggers:      triggers,
+			TriggerCount:  uint32(run.TriggerCount),
+		}
+	}
+
+	return &rpc.OSPFInstance{
+		RouterId: uint32(snapshot.RouterID),
+		GracefulRestart: &rpc.OSPFGracefulRestart{
+			Enabled:           gr.Enabled,
+			GracePeriod:       gr.GracePeriod,
+			Helper:            gr.Helper,
+			StrictLsaChecking: gr.StrictLSAChecking,
+			Restarting:        gr.Restarting,
+			GraceRemaining:    int64(gr.GraceRemaining),
+			Reason:            gr.Reason.String(),
+		},
+		MaxMetric: &rpc.OSPFMaxMetric{
+			Administrative:   mm.Administrative,
+			OnStartup:        mm.OnStartup,
+			Advertising:      mm.Advertising,
+			OnRequest:        mm.OnRequest,
+			StartupRemaining: int64(mm.StartupRemaining),
+		},
+		Spf: &rpc.OSPFSPF{
+			Throttle:  throttleToRPC(snapshot.SPF.Throttle),
+			Hold:      int64(snapshot.SPF.Hold),
+			Scheduled: snapshot.SPF.Scheduled,
+			Runs:      snapshot.SPF.Runs,
+			History:   history,
+		},
+		LsaThrottle: throttleToRPC(snapshot.LSAThrottle),
+	}, nil
+}
+
+func throttleToRPC(c config.OSPFThrottleConfig) *rpc.OSPFThrottle {
+	return &rpc.OSPFThrottle{
+		InitialDelay: int64(c.InitialDelay),
+		Hold:         int64(c.Hold),
+		MaxWait:      int64(c.MaxWait),
+	}
+}
+
+func (s *Server) GetOSPFInterfaces(ctx context.Context) ([]*rpc.OSPFInterface, error) {
+	instance, err := s.ospfInstance()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := instance.InterfaceSnapshots()
+
+	ifaces := make([]*rpc.OSPFInterface, len(snapshots))
+
+	for i, iface := range snapshots {
+		ifaces[i] = &rpc.OSPFInterface{
+			Name: iface.Name,
+			Addr: &rpc.Prefix{
+				Addr:      iface.Prefix.Addr().AsSlice(),
+				PrefixLen: int32(iface.Prefix.Bits()),
+			},
+			AreaId:        uint32(iface.AreaID),
+			Type:          iface.Type.String(),
+			State:         iface.State.String(),
+			Cost:          uint32(iface.Cost),
+			Priority:      uint32(iface.RouterPriority),
+			HelloInterval: uint32(iface.HelloInterval),
+			DeadInterval:  iface.RouterDeadInterval,
+			DesignatedRouter: &rpc.OSPFRouter{
+				RouterId: uint32(iface.DR.ID),
+				Addr:     iface.DR.Addr.AsSlice(),
+			},
+			BackupDesignatedRouter: &rpc.OSPFRouter{
+				RouterId: uint32(iface.BDR.ID),
+				Addr:     iface.BDR.Addr.AsSlice(),
+			},
+			Neighbors:         uint32(iface.Neighbors),
+			AdjacentNeighbors: uint32(iface.AdjacentNeighbors),
+			Stats: &rpc.OSPFInterfaceStats{
+				HellosSent:              iface.Stats.HellosSent,
+				HellosReceived:          iface.Stats.HellosReceived,
+				NetworkMaskMismatches:   iface.Stats.NetworkMaskMismatches,
+				HelloIntervalMismatches: iface.Stats.HelloIntervalMismatches,
+				DeadIntervalMismatches:  iface.Stats.DeadIntervalMismatches,
+				OptionsMismatches:       iface.Stats.OptionsMismatches,
+				AuthTypeMismatches:      iface.Stats.AuthTypeMismatches,
+				AuthKeyMismatches:       iface.Stats.AuthKeyMismatches,
+				AuthDigestMismatches:    iface.Stats.AuthDigestMismatches,
+				AuthSequenceErrors:      iface.Stats.AuthSequenceErrors,
+			},
+			AuthType: iface.AuthType.String(),
+			Passive:  iface.Passive,
+		}
+
+		if iface.AuthType == ospf.AuthTypeCryptographic {
+			ifaces[i].AuthKeyId = uint32(iface.AuthKeyID)
+			ifaces[i].AuthAlgorithm = iface.AuthAlgorithm.String()
+		}
+
+		if iface.Type == ospf.InterfaceVirtualLink {
+			ifaces[i].TransitAreaId = uint32(iface.TransitAreaID)
+			ifaces[i].VirtualLinkEndpoint = uint32(iface.Endpoint)
+			ifaces[i].RemoteAddr = iface.RemoteAddr.AsSlice()
+		}
+	}
+
+	return ifaces, nil
+}
+
+func (s *Server) GetOSPFNeighbors(ctx context.Context) ([]*rpc.OSPFNeighbor, error) {
+	instance, err := s.ospfInstance()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := instance.NeighborSnapshots()
+
+	neighbors := make([]*rpc.OSPFNeighbor, len(snapshots))
+
+	for i, n := range snapshots {
+		history := make([]*rpc.OSPFNeighborTransition, len(n.History))
+		for j, t := range n.History {
+			history[j] = &rpc.OSPFNeighborTransition{
+				TimeUnixNano: t.Time.UnixNano(),
+				Event:        t.Event.String(),
+				From:         t.From.String(),
+				To:           t.To.String(),
+			}
+		}
+
+		neighbors[i] = &rpc.OSPFNeighbor{
+			Interface: n.Interface,
+			InterfaceAddr: &rpc.Prefix{
+				Addr:      n.InterfacePrefix.Addr().AsSlice(),
+				PrefixLen: int32(n.InterfacePrefix.Bits()),
+			},
+			RouterId:               uint32(n.ID),
+			Addr:                   n.Addr.AsSlice(),
+			State:                  n.State.String(),
+			Priority:               uint32(n.Priority),
+			DesignatedRouter:       n.DesignatedRouter.AsSlice(),
+			BackupDesignatedRouter: n.BackupDesignatedRouter.AsSlice(),
+			DeadTime:               int64(n.DeadTime),
+			History:                history,
+			RetransmissionListLen:  uint32(n.RetransmissionListLen),
+			Stats: &rpc.OSPFNeighborStats{
+				Retransmissions: n.Stats.Retransmissions,
+				DuplicateLsas:   n.Stats.DuplicateLSAs,
+			},
+			RestartHelper:  n.RestartHelper,
+			GraceRemaining: int64(n.GraceRemaining),
+		}
+	}
+
+	return neighbors, nil
+}
+
+func (s *Server) GetOSPFRoutes(ctx context.Context) ([]*rpc.OSPFRoute, error) {
+	instance, err := s.ospfInstance()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := instance.RouteSnapshots()
+
+	routes := make([]*rpc.OSPFRoute, len(snapshots))
+
+	for i, r := range snapshots {
+		nextHops := make([]*rpc.OSPFNextHop, len(r.NextHops))
+		for j, nh := range r.NextHops {
+			nextHops[j] = &rpc.OSPFNextHop{
+				Interface: nh.Interface,
+				InterfaceAddr: &rpc.Prefix{
+					Addr:      nh.InterfacePrefix.Addr().AsSlice(),
+					PrefixLen: int32(nh.InterfacePrefix.Bits()),
+				},
+				Addr: nh.Addr.AsSlice(),
+			}
+		}
+
+		routes[i] = &rpc.OSPFRoute{
+			Prefix: &rpc.Prefix{
+				Addr:      r.Prefix.Addr().AsSlice(),
+				PrefixLen: int32(r.Prefix.Bits()),
+			},
+			PathType:          r.PathType.String(),
+			Cost:              r.Cost,
+			AreaId:            uint32(r.AreaID),
+			NextHops:          nextHops,
+			Type2Cost:         r.Type2Cost,
+			AdvertisingRouter: uint32(r.AdvertisingRouter),
+			Discard:           r.Discard,
+		}
+	}
+
+	return routes, nil
+}
+
+func (s *Server) GetOSPFAreas(ctx context.Context) ([]*rpc.OSPFArea, error) {
+	instance, err := s.ospfInstance()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := instance.AreaSnapshots()
+
+	areas := make([]*rpc.OSPFArea, len(snapshots))
+
+	for i, a := range snapshots {
+		areas[i] = &rpc.OSPFArea{
+			AreaId:      uint32(a.ID),
+			Type:        a.Type.String(),
+			NoSummary:   a.NoSummary,
+			DefaultCost: a.DefaultCost,
+			Lsas:        uint32(a.LSAs),
+		}
+
+		if a.Type == ospf.AreaNSSA {
+			areas[i].TranslatorRole = a.TranslatorRole.String()
+			areas[i].TranslatorState = a.TranslatorState.String()
+		}
+	}
+
+	return areas, nil
+}
+
+func (s *Server) SetOSPFMaxMetric(ctx context.Context, enabled bool) error {
+	instance, err := s.ospfInstance()
+	if err != nil {
+		return err
+	}
+
+	instance.SetMaxMetric(enabled)
+
+	return nil
+}
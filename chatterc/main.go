@@ -89,6 +89,7 @@ func main() {
 	})
 
 	registerInterfaceCommands(ctx, cli, client)
+	registerOSPFCommands(ctx, cli, client)
 
 	cli.Run(os.Stdin)
 }
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/davidbalbert/chatter/api"
+	"github.com/davidbalbert/chatter/rpc"
+)
+
+// routerIDString formats a router or area ID in dotted decimal.
+func routerIDString(id uint32) string {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], id)
+
+	return netip.AddrFrom4(b).String()
+}
+
+// addrString formats an address from the API. Unset addresses are
+// printed as "-".
+func addrString(b []byte) string {
+	addr, ok := netip.AddrFromSlice(b)
+	if !ok || addr.IsUnspecified() {
+		return "-"
+	}
+
+	return addr.String()
+}
+
+func prefixString(p *rpc.Prefix) string {
+	addr, ok := netip.AddrFromSlice(p.GetAddr())
+	if !ok {
+		return "-"
+	}
+
+	return netip.PrefixFrom(addr, int(p.GetPrefixLen())).String()
+}
+
+// ospfRouterString formats a DR or BDR as "router-id (address)".
+func ospfRouterString(r *rpc.OSPFRouter) string {
+	if r.GetRouterId() == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%s (%s)", routerIDString(r.GetRouterId()), addrString(r.GetAddr()))
+}
+
+// nextHopString formats a route's next hop as "address (interface)", or
+// "directly connected (interface)".
+func nextHopString(nh *rpc.OSPFNextHop) string {
+	addr := addrString(nh.GetAddr())
+	if addr == "-" {
+		addr = "directly connected"
+	}
+
+	return fmt.Sprintf("%s (%s)", addr, nh.Interface)
+}
+
+// throttleString formats an SPF or LSA throttle's delays.
+func throttleString(t *rpc.OSPFThrottle) string {
+	return fmt.Sprintf("initial delay %s, hold %s, max wait %s", time.Duration(t.GetInitialDelay()), time.Duration(t.GetHold()), time.Duration(t.GetMaxWait()))
+}
+
+// spfTriggerString formats an LSA change that triggered a routing table
+// calculation as "type ID advertising-router (area)".
+func spfTriggerString(t *rpc.OSPFSPFTrigger) string {
+	s := fmt.Sprintf("%s %s %s (area %s)", t.Type, addrString(t.Id), routerIDString(t.AdvertisingRouter), routerIDString(t.AreaId))
+	if t.Flushed {
+		s += ", flushed"
+	}
+
+	return s
+}
+
+func registerOSPFCommands(ctx context.Context, cli *CLI, client *api.Client) {
+	cli.MustDocument("show ip", "IP information")
+
+	cli.MustRegister("show ip ospf", "OSPF information", func(w io.Writer) error {
+		instance, err := client.GetOSPFInstance(ctx)
+		if err != nil {
+			return err
+		}
+
+		gr := instance.GetGracefulRestart()
+
+		fmt.Fprintf(w, "OSPF router ID %s\n", routerIDString(instance.RouterId))
+
+		if gr.GetEnabled() {
+			fmt.Fprintf(w, "    Graceful restart enabled, grace period %ds\n", gr.GetGracePeriod())
+		} else {
+			fmt.Fprintf(w, "    Graceful restart disabled\n")
+		}
+
+		if gr.GetHelper() {
+			strict := "disabled"
+			if gr.GetStrictLsaChecking() {
+				strict = "enabled"
+			}
+
+			fmt.Fprintf(w, "    Graceful restart helper enabled, strict LSA checking %s\n", strict)
+		} else {
+			fmt.Fprintf(w, "    Graceful restart helper disabled\n")
+		}
+
+		if gr.GetRestarting() {
+			fmt.Fprintf(w, "    Restarting gracefully (%s), grace period ends in %s\n", gr.GetReason(), time.Duration(gr.GetGraceRemaining()).Round(time.Second))
+		}
+
+		mm := instance.GetMaxMetric()
+
+		if mm.GetOnStartup() > 0 {
+			fmt.Fprintf(w, "    Max-metric on startup for %ds\n", mm.GetOnStartup())
+		}
+
+		if mm.GetAdvertising() {
+			var reasons []string
+			if mm.GetOnRequest() {
+				reasons = append(reasons, "on request")
+			}
+
+			if mm.GetStartupRemaining() > 0 {
+				reasons = append(reasons, fmt.Sprintf("on startup, ends in %s", time.Duration(mm.GetStartupRemaining()).Round(time.Second)))
+			}
+
+			fmt.Fprintf(w, "    Advertising max-metric in router-LSAs (%s)\n", strings.Join(reasons, ", "))
+		}
+
+		spf := instance.GetSpf()
+
+		fmt.Fprintf(w, "    SPF throttle %s, current hold %s\n", throttleString(spf.GetThrottle()), time.Duration(spf.GetHold()))
+		fmt.Fprintf(w, "    LSA throttle %s\n", throttleString(instance.GetLsaThrottle()))
+
+		if n := len(spf.GetHistory()); n > 0 {
+			last := spf.GetHistory()[n-1]
+			ago := time.Since(time.Unix(0, last.StartUnixNano)).Round(time.Second)
+
+			fmt.Fprintf(w, "    SPF calculation run %d times, last %s ago, took %s\n", spf.GetRuns(), ago, time.Duration(last.Duration))
+		} else {
+			fmt.Fprintf(w, "    SPF calculation run %d times\n", spf.GetRuns())
+		}
+
+		if spf.GetScheduled() {
+			fmt.Fprintf(w, "    SPF calculation scheduled\n")
+		}
+
+		return nil
+	})
+
+	cli.MustRegister("show ip ospf spf", "Recent OSPF routing table calculations and the LSA changes that triggered them", func(w io.Writer) error {
+		instance, err := client.GetOSPFInstance(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Newest first.
+		history := instance.GetSpf().GetHistory()
+		runs := make([]*rpc.OSPFSPFRun, len(history))
+		for i, run := range history {
+			runs[len(history)-1-i] = run
+		}
+
+		table, err := tabulate(runs, []string{"Start", "Duration", "Changes", "Changed LSAs"}, false, func(r *rpc.OSPFSPFRun) ([]string, error) {
+			var changes []string
+			for _, t := range r.Triggers {
+				changes = append(changes, spfTriggerString(t))
+			}
+
+			if more := int(r.TriggerCount) - len(r.Triggers); more > 0 {
+				changes = append(changes, fmt.Sprintf("and %d more", more))
+			}
+
+			if len(changes) == 0 {
+				changes = []string{"-"}
+			}
+
+			return []string{
+				time.Unix(0, r.StartUnixNano).Format("2006-01-02 15:04:05.000"),
+				time.Duration(r.Duration).String(),
+				fmt.Sprintf("%d", r.TriggerCount),
+				strings.Join(changes, "\n"),
+			}, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range table {
+			fmt.Fprintf(w, "%s\n", row)
+		}
+
+		return nil
+	})
+
+	cli.MustDocument("ospf", "Change OSPF state")
+	cli.MustDocument("ospf max-metric", "Advertise the maximum metric")
+
+	cli.MustRegister("ospf max-metric router-lsa", "Advertise the maximum metric on transit links, so other routers avoid us", func(w io.Writer) error {
+		return client.SetOSPFMaxMetric(ctx, true)
+	})
+
+	cli.MustDocument("no", "Negate a command")
+	cli.MustDocument("no ospf", "Change OSPF state")
+	cli.MustDocument("no ospf max-metric", "Stop advertising the maximum metric")
+
+	cli.MustRegister("no ospf max-metric router-lsa", "Stop advertising the maximum metric, including on startup", func(w io.Writer) error {
+		return client.SetOSPFMaxMetric(ctx, false)
+	})
+
+	cli.MustRegister("show ip ospf area", "OSPF areas", func(w io.Writer) error {
+		areas, err := client.GetOSPFAreas(ctx)
+		if err != nil {
+			return err
+		}
+
+		table, err := tabulate(areas, []string{"Area", "Type", "Default Cost", "LSAs", "NSSA Translator"}, false, func(a *rpc.OSPFArea) ([]string, error) {
+			areaType := a.Type
+			if a.NoSummary {
+				areaType += ", no-summary"
+			}
+
+			defaultCost := "-"
+			if a.Type != "normal" {
+				defaultCost = fmt.Sprintf("%d", a.DefaultCost)
+			}
+
+			translator := "-"
+			if a.TranslatorRole != "" {
+				translator = fmt.Sprintf("%s (%s)", a.TranslatorRole, a.TranslatorState)
+			}
+
+			return []string{
+				routerIDString(a.AreaId),
+				areaType,
+				defaultCost,
+				fmt.Sprintf("%d", a.Lsas),
+				translator,
+			}, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range table {
+			fmt.Fprintf(w, "%s\n", row)
+		}
+
+		return nil
+	})
+
+	cli.MustRegister("show ip ospf interface", "OSPF interface status", func(w io.Writer) error {
+		ifaces, err := client.GetOSPFInterfaces(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i, iface := range ifaces {
+			if i > 0 {
+				fmt.Fprintf(w, "\n")
+			}
+
+			stats := iface.GetStats()
+
+			fmt.Fprintf(w, "%s %s, area %s\n", iface.Name, prefixString(iface.Addr), routerIDString(iface.AreaId))
+			fmt.Fprintf(w, "    Type %s, state %s, cost %d, priority %d\n", iface.Type, iface.State, iface.Cost, iface.Priority)
+			if iface.Passive {
+				fmt.Fprintf(w, "    Passive, no Hellos are sent or received\n")
+			}
+			if iface.VirtualLinkEndpoint != 0 {
+				fmt.Fprintf(w, "    Virtual link to %s through area %s, remote address %s\n", routerIDString(iface.VirtualLinkEndpoint), routerIDString(iface.TransitAreaId), addrString(iface.RemoteAddr))
+			}
+			fmt.Fprintf(w, "    DR %s, BDR %s\n", ospfRouterString(iface.DesignatedRouter), ospfRouterString(iface.BackupDesignatedRouter))
+			fmt.Fprintf(w, "    Hello interval %ds, dead interval %ds\n", iface.HelloInterval, iface.DeadInterval)
+			fmt.Fprintf(w, "    Neighbors %d, adjacent %d\n", iface.Neighbors, iface.AdjacentNeighbors)
+			fmt.Fprintf(w, "    Hellos sent %d, received %d\n", stats.GetHellosSent(), stats.GetHellosReceived())
+			fmt.Fprintf(w, "    Hello mismatches: network mask %d, hello interval %d, dead interval %d, options %d\n",
+				stats.GetNetworkMaskMismatches(), stats.GetHelloIntervalMismatches(), stats.GetDeadIntervalMismatches(), stats.GetOptionsMismatches())
+			if iface.AuthAlgorithm != "" {
+				fmt.Fprintf(w, "    Authentication %s, sending with key %d (%s)\n", iface.AuthType, iface.AuthKeyId, iface.AuthAlgorithm)
+			} else {
+				fmt.Fprintf(w, "    Authentication %s\n", iface.AuthType)
+			}
+			fmt.Fprintf(w, "    Authentication failures: type mismatch %d, key mismatch %d, digest mismatch %d, sequence number %d\n",
+				stats.GetAuthTypeMismatches(), stats.GetAuthKeyMismatches(), stats.GetAuthDigestMismatches(), stats.GetAuthSequenceErrors())
+		}
+
+		return nil
+	})
+
+	cli.MustRegister("show ip ospf neighbor", "OSPF neighbors", func(w io.Writer) error {
+		neighbors, err := client.GetOSPFNeighbors(ctx)
+		if err != nil {
+			return err
+		}
+
+		table, err := tabulate(neighbors, []string{"Neighbor ID", "Pri", "State", "Dead Time", "Address", "Interface"}, false, func(n *rpc.OSPFNeighbor) ([]string, error) {
+			return []string{
+				routerIDString(n.RouterId),
+				fmt.Sprintf("%d", n.Priority),
+				n.State,
+				time.Duration(n.DeadTime).Round(time.Second).String(),
+				addrString(n.Addr),
+				fmt.Sprintf("%s:%s", n.Interface, addrString(n.InterfaceAddr.GetAddr())),
+			}, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range table {
+			fmt.Fprintf(w, "%s\n", row)
+		}
+
+		return nil
+	})
+
+	cli.MustRegister("show ip ospf neighbor A.B.C.D", "OSPF neighbor details and state changes", func(w io.Writer, id netip.Addr) error {
+		neighbors, err := client.GetOSPFNeighbors(ctx)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, n := range neighbors {
+			if routerIDString(n.RouterId) != id.String() {
+				continue
+			}
+
+			found = true
+
+			fmt.Fprintf(w, "Neighbor %s, interface address %s\n", routerIDString(n.RouterId), addrString(n.Addr))
+			fmt.Fprintf(w, "    Via interface %s %s\n", n.Interface, prefixString(n.InterfaceAddr))
+			fmt.Fprintf(w, "    Priority %d, state %s\n", n.Priority, n.State)
+			fmt.Fprintf(w, "    DR %s, BDR %s\n", addrString(n.DesignatedRouter), addrString(n.BackupDesignatedRouter))
+			fmt.Fprintf(w, "    Dead timer due in %s\n", time.Duration(n.DeadTime).Round(time.Second))
+			if n.RestartHelper {
+				fmt.Fprintf(w, "    Restarting gracefully with our help, grace period ends in %s\n", time.Duration(n.GraceRemaining).Round(time.Second))
+			}
+			fmt.Fprintf(w, "    Retransmission list %d, retransmissions %d, duplicate LSAs %d\n", n.RetransmissionListLen, n.GetStats().GetRetransmissions(), n.GetStats().GetDuplicateLsas())
+			fmt.Fprintf(w, "    State changes:\n")
+
+			for _, t := range n.History {
+				ts := time.Unix(0, t.TimeUnixNano).Format(time.DateTime)
+				fmt.Fprintf(w, "        %s  %-8s -> %-8s  %s\n", ts, t.From, t.To, t.Event)
+			}
+		}
+
+		if !found {
+			fmt.Fprintf(w, "No neighbor with router ID %s\n", id)
+		}
+
+		return nil
+	})
+
+	cli.MustRegister("show ip ospf route", "OSPF routing table", func(w io.Writer) error {
+		routes, err := client.GetOSPFRoutes(ctx)
+		if err != nil {
+			return err
+		}
+
+		table, err := tabulate(routes, []string{"Prefix", "Type", "Cost", "Area", "Adv Router", "Next Hops"}, false, func(r *rpc.OSPFRoute) ([]string, error) {
+			nextHops := make([]string, len(r.NextHops))
+			for i, nh := range r.NextHops {
+				nextHops[i] = nextHopString(nh)
+			}
+
+			if r.Discard {
+				nextHops = []string{"discard"}
+			}
+
+			// Type 2 external routes are compared by their external
+			// metric first, so show it along with the cost to the ASBR.
+			cost := fmt.Sprintf("%d", r.Cost)
+			if r.PathType == "E2" {
+				cost = fmt.Sprintf("%d/%d", r.Type2Cost, r.Cost)
+			}
+
+			advertisingRouter := "-"
+			if r.AdvertisingRouter != 0 {
+				advertisingRouter = routerIDString(r.AdvertisingRouter)
+			}
+
+			return []string{
+				prefixString(r.Prefix),
+				r.PathType,
+				cost,
+				routerIDString(r.AreaId),
+				advertisingRouter,
+				strings.Join(nextHops, ", "),
+			}, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range table {
+			fmt.Fprintf(w, "%s\n", row)
+		}
+
+		return nil
+	})
+}
@@ -12,6 +12,16 @@ type Runner interface {
 	Run(ctx context.Context) error
 }
 
+// A Restartable service hands state over to its replacement when it's
+// restarted because the config changed. PrepareRestart is called on the
+// running service before it's stopped, and whatever it returns is passed
+// to Restart on the new service before it's run.
+type Restartable interface {
+	Runner
+	PrepareRestart(ctx context.Context) any
+	Restart(state any)
+}
+
 type BuilderFunc func(m *ServiceManager, conf any) (Runner, error)
 
 var builders = make(map[config.ServiceType]BuilderFunc)
@@ -99,6 +109,36 @@ func (s *ServiceManager) Run(ctx context.Context) error {
 			case conf := <-confCh:
 				st := <-s.st
 
+				// This is a defensive copy. Is there a better way?
+				bootstraps := conf.Copy().Bootstraps()
+
+				restarting := make(map[string]bool)
+				for _, b := range bootstraps {
+					restarting[b.ID.Name] = true
+				}
+
+				// Services that are being restarted, rather than stopped for
+				// good, can prepare their replacements. That can take a while,
+				// so we do it without holding st, so that Get and
+				// RunningServices don't have to wait, and services that call
+				// them while preparing don't deadlock. Only this goroutine
+				// changes the controllers, so they're the same afterwards.
+				restartable := make(map[string]Restartable)
+				for name, controller := range st.controllers {
+					if r, ok := controller.service.(Restartable); ok && restarting[name] {
+						restartable[name] = r
+					}
+				}
+
+				s.st <- st
+
+				restartStates := make(map[string]any)
+				for name, r := range restartable {
+					restartStates[name] = r.PrepareRestart(ctx)
+				}
+
+				st = <-s.st
+
 				for _, controller := range st.controllers {
 					controller.Stop()
 				}
@@ -108,9 +148,8 @@ func (s *ServiceManager) Run(ctx context.Context) error {
 					delete(st.controllers, name)
 				}
 
-				// This is a defensive copy. Is there a better way?
-				for _, b := range conf.Copy().Bootstraps() {
-					err := s.start(ctx, g, st, b)
+				for _, b := range bootstraps {
+					err := s.start(ctx, g, st, b, restartStates)
 					if err != nil {
 						return err
 					}
@@ -124,7 +163,7 @@ func (s *ServiceManager) Run(ctx context.Context) error {
 	return g.Wait()
 }
 
-func (s *ServiceManager) start(ctx context.Context, g *errgroup.Group, st state, b config.Bootstrap) error {
+func (s *ServiceManager) start(ctx context.Context, g *errgroup.Group, st state, b config.Bootstrap, restartStates map[string]any) error {
 	_, ok := st.controllers[b.ID.Name]
 	if ok {
 		return fmt.Errorf("service already running: %s", b.ID.Name)
@@ -140,6 +179,12 @@ func (s *ServiceManager) start(ctx context.Context, g *errgroup.Group, st state,
 		return err
 	}
 
+	if r, ok := service.(Restartable); ok {
+		if state, ok := restartStates[b.ID.Name]; ok {
+			r.Restart(state)
+		}
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 
 	done := make(chan struct{})
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+)
+
+type testService struct{}
+
+func (s *testService) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// A slowRestartService takes until release is closed to prepare its
+// replacement.
+type slowRestartService struct {
+	testService
+	preparing chan struct{}
+	release   chan struct{}
+}
+
+func (s *slowRestartService) PrepareRestart(ctx context.Context) any {
+	close(s.preparing)
+	<-s.release
+	return nil
+}
+
+func (s *slowRestartService) Restart(state any) {}
+
+// useTestBuilders replaces the registered builders for the duration of the
+// test.
+func useTestBuilders(t *testing.T, fns map[config.ServiceType]BuilderFunc) {
+	t.Helper()
+
+	saved := builders
+	builders = fns
+	t.Cleanup(func() { builders = saved })
+}
+
+func newTestConfigManager(t *testing.T) *config.ConfigManager {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "chatterd.yaml")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := config.NewConfigManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cm
+}
+
+func TestGetDuringPrepareRestart(t *testing.T) {
+	preparing := make(chan struct{})
+	release := make(chan struct{})
+
+	var first *slowRestartService
+
+	useTestBuilders(t, map[config.ServiceType]BuilderFunc{
+		config.ServiceTypeInterfaceMonitor: func(m *ServiceManager, conf any) (Runner, error) {
+			return &testService{}, nil
+		},
+		config.ServiceTypeAPIServer: func(m *ServiceManager, conf any) (Runner, error) {
+			if first == nil {
+				first = &slowRestartService{preparing: preparing, release: release}
+				return first, nil
+			}
+
+			return &testService{}, nil
+		},
+	})
+
+	cm := newTestConfigManager(t)
+	m := NewServiceManager(cm)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- m.Run(ctx) }()
+
+	var releaseOnce sync.Once
+	releaseRestart := func() { releaseOnce.Do(func() { close(release) }) }
+
+	defer func() {
+		releaseRestart()
+		cancel()
+		if err := <-errc; err != nil {
+			t.Errorf("Run: %v", err)
+		}
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := m.Get(config.ServiceAPIServer); err == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for services to start: %v", err)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	conf, _ := cm.LastChange()
+	if err := cm.UpdateConfig(conf.Copy()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-preparing:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for PrepareRestart")
+	}
+
+	// While the API server is preparing to restart, it's still running.
+	got := make(chan any)
+	go func() {
+		service, _ := m.Get(config.ServiceAPIServer)
+		got <- service
+		m.RunningServices()
+		close(got)
+	}()
+
+	select {
+	case service := <-got:
+		if service != first {
+			t.Errorf("expected the running API server, got %v", service)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Get blocked during PrepareRestart")
+	}
+
+	select {
+	case <-got:
+	case <-time.After(time.Second):
+		t.Fatal("RunningServices blocked during PrepareRestart")
+	}
+
+	releaseRestart()
+
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		if service, _ := m.Get(config.ServiceAPIServer); service != nil && service != any(first) {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the API server to restart")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
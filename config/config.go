@@ -109,7 +109,10 @@ type Bootstrap struct {
 func (c *Config) Bootstraps() []Bootstrap {
 	g := newGraph()
 
-	g.addNode(ServiceAPIServer)
+	// The API server reports interface state from the interface monitor,
+	// so the monitor always runs.
+	g.addNode(ServiceInterfaceMonitor)
+	g.addNode(ServiceAPIServer, ServiceInterfaceMonitor)
 
 	for s, conf := range c.protocolConfigs {
 		if conf.shouldRun() {
@@ -7,6 +7,7 @@ import (
 	"net/netip"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/davidbalbert/chatter/chatterd/common"
 )
@@ -30,9 +31,50 @@ type OSPFConfig struct {
 	Cost               uint16
 	HelloInterval      uint16
 	RouterDeadInterval uint32
+	PassiveDefault     bool // interfaces are passive unless configured otherwise
+	GracefulRestart    OSPFGracefulRestartConfig
+	MaxMetric          OSPFMaxMetricConfig
+	SPFThrottle        OSPFThrottleConfig // routing table calculations
+	LSAThrottle        OSPFThrottleConfig // originating new instances of each of our LSAs
 	Areas              map[common.AreaID]OSPFAreaConfig
 }
 
+// OSPFGracefulRestartConfig configures graceful restart, described in RFC
+// 3623. When Enabled is true, we ask our neighbors to keep their adjacencies
+// with us while we restart, and we keep our routes. When Helper is true, we
+// do the same for neighbors that restart. With StrictLSAChecking, we stop
+// helping a neighbor if the topology changes while it's restarting.
+type OSPFGracefulRestartConfig struct {
+	Enabled           bool
+	GracePeriod       uint32 // seconds
+	Helper            bool
+	StrictLSAChecking bool
+}
+
+// OSPFMaxMetricConfig configures stub router advertisement, described in RFC
+// 6987. While it's in effect, our router-LSAs give every link other than
+// stub links the maximum cost, so that other routers avoid forwarding
+// through us. With Administrative, it's in effect until it's turned off
+// through the API. With OnStartup, it's in effect for that many seconds
+// after we start.
+type OSPFMaxMetricConfig struct {
+	Administrative bool
+	OnStartup      uint32 // seconds, or 0
+}
+
+// OSPFThrottleConfig spaces out something that happens when the link state
+// database changes with exponential backoff. After a quiet period, it waits
+// InitialDelay. While changes keep coming, it happens at most once every
+// hold time, which starts at Hold and doubles each time, up to MaxWait. The
+// hold time only starts over once there have been no changes for twice
+// MaxWait, so changes that come just slower than the hold time still back
+// off.
+type OSPFThrottleConfig struct {
+	InitialDelay time.Duration
+	Hold         time.Duration
+	MaxWait      time.Duration
+}
+
 func (c *OSPFConfig) shouldRun() bool {
 	for _, area := range c.Areas {
 		if len(area.Interfaces) > 0 {
@@ -53,6 +95,11 @@ func (c *OSPFConfig) copy() protocolConfig {
 		Cost:               c.Cost,
 		HelloInterval:      c.HelloInterval,
 		RouterDeadInterval: c.RouterDeadInterval,
+		PassiveDefault:     c.PassiveDefault,
+		GracefulRestart:    c.GracefulRestart,
+		MaxMetric:          c.MaxMetric,
+		SPFThrottle:        c.SPFThrottle,
+		LSAThrottle:        c.LSAThrottle,
 		Areas:              make(map[common.AreaID]OSPFAreaConfig),
 	}
 
@@ -79,7 +126,36 @@ type OSPFAreaConfig struct {
 	Cost               uint16
 	HelloInterval      uint16
 	RouterDeadInterval uint32
+	Stub               bool
+	NSSA               bool
+	NoSummary          bool   // totally stubby: only a default summary is sent into the area
+	DefaultCost        uint32 // cost of the default summary sent into a stub area or NSSA
+	DefaultOriginate   bool   // in an NSSA, send a default Type-7 LSA into the area
+	TranslatorRole     NSSATranslatorRole
+	Authentication     *OSPFAuthConfig // nil if not configured, in which case there's no authentication
+	AddressRanges      map[netip.Prefix]OSPFAddressRangeConfig
 	Interfaces         map[string]OSPFInterfaceConfig
+	VirtualLinks       map[common.RouterID]OSPFVirtualLinkConfig // endpoints reached through this transit area
+}
+
+// NSSATranslatorRole controls whether an NSSA border router translates
+// Type-7 LSAs into AS-external-LSAs. See RFC 3101.
+type NSSATranslatorRole int
+
+const (
+	NSSATranslatorCandidate NSSATranslatorRole = iota // translate if elected
+	NSSATranslatorAlways                              // always translate
+)
+
+func (r NSSATranslatorRole) String() string {
+	switch r {
+	case NSSATranslatorCandidate:
+		return "candidate"
+	case NSSATranslatorAlways:
+		return "always"
+	default:
+		return fmt.Sprintf("NSSATranslatorRole(%d)", int(r))
+	}
 }
 
 func (c *OSPFAreaConfig) copy() OSPFAreaConfig {
@@ -87,21 +163,199 @@ func (c *OSPFAreaConfig) copy() OSPFAreaConfig {
 		Cost:               c.Cost,
 		HelloInterval:      c.HelloInterval,
 		RouterDeadInterval: c.RouterDeadInterval,
+		Stub:               c.Stub,
+		NSSA:               c.NSSA,
+		NoSummary:          c.NoSummary,
+		DefaultCost:        c.DefaultCost,
+		DefaultOriginate:   c.DefaultOriginate,
+		TranslatorRole:     c.TranslatorRole,
+		Authentication:     c.Authentication.copy(),
+		AddressRanges:      make(map[netip.Prefix]OSPFAddressRangeConfig),
 		Interfaces:         make(map[string]OSPFInterfaceConfig),
+		VirtualLinks:       make(map[common.RouterID]OSPFVirtualLinkConfig),
+	}
+
+	for k, v := range c.AddressRanges {
+		newConfig.AddressRanges[k] = v
 	}
 
 	for k, v := range c.Interfaces {
-		newConfig.Interfaces[k] = v
+		newConfig.Interfaces[k] = v.copy()
+	}
+
+	for k, v := range c.VirtualLinks {
+		v.Authentication = v.Authentication.copy()
+		newConfig.VirtualLinks[k] = v
 	}
 
 	return newConfig
 }
 
+// OSPFAddressRangeConfig configures an area's address range. An area border
+// router advertises a single summary for the range's networks, or nothing
+// at all if Advertise is false.
+type OSPFAddressRangeConfig struct {
+	Advertise bool
+}
+
+// OSPFVirtualLinkConfig configures a virtual link to an area border router
+// through a transit area. Virtual links belong to the backbone, and their
+// cost and endpoint addresses come from the transit area's shortest-path
+// tree.
+type OSPFVirtualLinkConfig struct {
+	HelloInterval      uint16
+	RouterDeadInterval uint32
+	Authentication     *OSPFAuthConfig // defaults to the backbone's
+}
+
 type OSPFInterfaceConfig struct {
 	AreaID             common.AreaID
 	Cost               uint16
 	HelloInterval      uint16
 	RouterDeadInterval uint32
+	RouterPriority     uint8
+	Network            OSPFNetworkType
+	PollInterval       uint32                            // on NBMA networks, how often Hellos are sent to neighbors that are down
+	Neighbors          map[netip.Addr]OSPFNeighborConfig // on NBMA networks, which don't support multicast
+	Authentication     *OSPFAuthConfig                   // defaults to the area's
+
+	// Passive interfaces have their prefix advertised, but don't send or
+	// receive packets, so they have no neighbors.
+	Passive    bool
+	passiveSet bool // false if Passive comes from passive-default
+}
+
+func (c *OSPFInterfaceConfig) copy() OSPFInterfaceConfig {
+	newConfig := *c
+	newConfig.Authentication = c.Authentication.copy()
+	newConfig.Neighbors = make(map[netip.Addr]OSPFNeighborConfig)
+
+	for k, v := range c.Neighbors {
+		newConfig.Neighbors[k] = v
+	}
+
+	return newConfig
+}
+
+// OSPFNetworkType is the kind of network an OSPF interface is attached to.
+// If it isn't configured, it's picked based on the network interface's
+// flags.
+type OSPFNetworkType int
+
+const (
+	OSPFNetworkAuto OSPFNetworkType = iota
+	OSPFNetworkBroadcast
+	OSPFNetworkPointToPoint
+	OSPFNetworkNBMA
+	OSPFNetworkPointToMultipoint
+)
+
+func (t OSPFNetworkType) String() string {
+	switch t {
+	case OSPFNetworkAuto:
+		return "auto"
+	case OSPFNetworkBroadcast:
+		return "broadcast"
+	case OSPFNetworkPointToPoint:
+		return "point-to-point"
+	case OSPFNetworkNBMA:
+		return "nbma"
+	case OSPFNetworkPointToMultipoint:
+		return "point-to-multipoint"
+	default:
+		return fmt.Sprintf("OSPFNetworkType(%d)", int(t))
+	}
+}
+
+// OSPFNeighborConfig configures a neighbor on an NBMA network. Priority is
+// used until we hear the neighbor's Hellos. Neighbors with a priority of 0
+// are ineligible to become DR, and only DRs and BDRs start talking to them.
+type OSPFNeighborConfig struct {
+	Priority uint8
+}
+
+// OSPFAuthConfig configures how OSPF packets are authenticated. See RFC
+// 2328, appendix D, and RFC 5709.
+type OSPFAuthConfig struct {
+	Type OSPFAuthType
+	Key  string                      // for simple password authentication, at most 8 bytes
+	Keys map[uint8]OSPFAuthKeyConfig // for cryptographic authentication, by key ID
+}
+
+func (c *OSPFAuthConfig) copy() *OSPFAuthConfig {
+	if c == nil {
+		return nil
+	}
+
+	newConfig := *c
+	newConfig.Keys = make(map[uint8]OSPFAuthKeyConfig)
+
+	for k, v := range c.Keys {
+		newConfig.Keys[k] = v
+	}
+
+	return &newConfig
+}
+
+type OSPFAuthType int
+
+const (
+	OSPFAuthNull OSPFAuthType = iota
+	OSPFAuthSimple
+	OSPFAuthCryptographic
+)
+
+func (t OSPFAuthType) String() string {
+	switch t {
+	case OSPFAuthNull:
+		return "null"
+	case OSPFAuthSimple:
+		return "simple"
+	case OSPFAuthCryptographic:
+		return "cryptographic"
+	default:
+		return fmt.Sprintf("OSPFAuthType(%d)", int(t))
+	}
+}
+
+// OSPFAuthKeyConfig configures a key for cryptographic authentication. A key
+// is used to send packets between SendStart and SendStop, and to accept
+// them between AcceptStart and AcceptStop. Zero times are unbounded. Having
+// more than one key lets keys be rolled over without dropping packets.
+type OSPFAuthKeyConfig struct {
+	Algorithm   OSPFAuthAlgorithm
+	Secret      string
+	SendStart   time.Time
+	SendStop    time.Time
+	AcceptStart time.Time
+	AcceptStop  time.Time
+}
+
+type OSPFAuthAlgorithm int
+
+const (
+	OSPFAuthMD5 OSPFAuthAlgorithm = iota
+	OSPFAuthHMACSHA1
+	OSPFAuthHMACSHA256
+	OSPFAuthHMACSHA384
+	OSPFAuthHMACSHA512
+)
+
+func (a OSPFAuthAlgorithm) String() string {
+	switch a {
+	case OSPFAuthMD5:
+		return "md5"
+	case OSPFAuthHMACSHA1:
+		return "hmac-sha-1"
+	case OSPFAuthHMACSHA256:
+		return "hmac-sha-256"
+	case OSPFAuthHMACSHA384:
+		return "hmac-sha-384"
+	case OSPFAuthHMACSHA512:
+		return "hmac-sha-512"
+	default:
+		return fmt.Sprintf("OSPFAuthAlgorithm(%d)", int(a))
+	}
 }
 
 func parseOSPFConfig(data map[string]interface{}) (*OSPFConfig, error) {
@@ -110,7 +364,24 @@ func parseOSPFConfig(data map[string]interface{}) (*OSPFConfig, error) {
 		Cost:               1,
 		HelloInterval:      10,
 		RouterDeadInterval: 40,
-		Areas:              make(map[common.AreaID]OSPFAreaConfig),
+		GracefulRestart: OSPFGracefulRestartConfig{
+			GracePeriod:       120,
+			Helper:            true,
+			StrictLSAChecking: true,
+		},
+		SPFThrottle: OSPFThrottleConfig{
+			InitialDelay: 200 * time.Millisecond,
+			Hold:         1 * time.Second,
+			MaxWait:      10 * time.Second,
+		},
+		// By default, LSAs are originated immediately, but at most once
+		// every MinLSInterval (5 seconds). See RFC 2328, section 12.4.
+		LSAThrottle: OSPFThrottleConfig{
+			InitialDelay: 0,
+			Hold:         5 * time.Second,
+			MaxWait:      5 * time.Second,
+		},
+		Areas: make(map[common.AreaID]OSPFAreaConfig),
 	}
 
 	for k, v := range data {
@@ -173,6 +444,59 @@ func parseOSPFConfig(data map[string]interface{}) (*OSPFConfig, error) {
 			}
 
 			c.RouterDeadInterval = uint32(v)
+		} else if k == "passive-default" {
+			v, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("ospf: passive-default must be a boolean")
+			}
+
+			c.PassiveDefault = v
+		} else if k == "graceful-restart" {
+			gr, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("ospf: graceful-restart must be a map")
+			}
+
+			err := c.GracefulRestart.parse(gr)
+			if err != nil {
+				return nil, err
+			}
+		} else if k == "max-metric router-lsa" {
+			mm, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("ospf: max-metric router-lsa must be a map")
+			}
+
+			err := c.MaxMetric.parse(mm)
+			if err != nil {
+				return nil, err
+			}
+		} else if k == "spf-throttle" {
+			tc, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("ospf: spf-throttle must be a map")
+			}
+
+			err := c.SPFThrottle.parse("spf-throttle", tc)
+			if err != nil {
+				return nil, err
+			}
+		} else if k == "lsa-throttle" {
+			tc, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("ospf: lsa-throttle must be a map")
+			}
+
+			err := c.LSAThrottle.parse("lsa-throttle", tc)
+			if err != nil {
+				return nil, err
+			}
+
+			// Neighbors discard instances of an LSA that arrive less than
+			// MinLSArrival (1 second) apart. See RFC 2328, section 13.
+			if c.LSAThrottle.Hold < 1*time.Second {
+				return nil, fmt.Errorf("ospf lsa-throttle: hold must be at least 1000: %d", c.LSAThrottle.Hold.Milliseconds())
+			}
 		} else if strings.HasPrefix(k, "area ") {
 			name := strings.TrimPrefix(k, "area ")
 
@@ -202,14 +526,168 @@ func parseOSPFConfig(data map[string]interface{}) (*OSPFConfig, error) {
 		c.Areas[k] = ac
 	}
 
-	_, ok := c.Areas[common.AreaID(0)]
+	backbone, ok := c.Areas[common.AreaID(0)]
 	if !ok {
 		return nil, fmt.Errorf("ospf: backbone area must be configured")
 	}
 
+	// Virtual links belong to the backbone, so they use its authentication
+	// unless they have their own.
+	for _, ac := range c.Areas {
+		for k, vc := range ac.VirtualLinks {
+			if vc.Authentication == nil {
+				vc.Authentication = backbone.Authentication.copy()
+				ac.VirtualLinks[k] = vc
+			}
+		}
+	}
+
+	if len(backbone.VirtualLinks) > 0 {
+		return nil, fmt.Errorf("ospf: backbone area can't be a transit area")
+	}
+
+	endpoints := make(map[common.RouterID]common.AreaID)
+	for areaID, ac := range c.Areas {
+		for id := range ac.VirtualLinks {
+			if id == c.RouterID {
+				return nil, fmt.Errorf("ospf area %s: virtual link to our own router-id", areaID)
+			}
+
+			if other, ok := endpoints[id]; ok {
+				return nil, fmt.Errorf("ospf: virtual link to %s configured in areas %s and %s", id, other, areaID)
+			}
+
+			endpoints[id] = areaID
+		}
+	}
+
+	if backbone.Stub {
+		return nil, fmt.Errorf("ospf: backbone area can't be a stub area")
+	}
+
+	if backbone.NSSA {
+		return nil, fmt.Errorf("ospf: backbone area can't be an NSSA")
+	}
+
 	return c, nil
 }
 
+func (gc *OSPFGracefulRestartConfig) parse(data map[string]interface{}) error {
+	for k, v := range data {
+		if k == "enabled" {
+			v, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("ospf graceful-restart: enabled must be a boolean")
+			}
+
+			gc.Enabled = v
+		} else if k == "grace-period" {
+			v, ok := v.(int)
+			if !ok {
+				return fmt.Errorf("ospf graceful-restart: grace-period must be an integer")
+			}
+
+			// The grace period can't be longer than LSRefreshTime (30
+			// minutes). See RFC 3623, appendix B.
+			if v < 1 {
+				return fmt.Errorf("ospf graceful-restart: grace-period too small: %d", v)
+			} else if v > 1800 {
+				return fmt.Errorf("ospf graceful-restart: grace-period too big: %d", v)
+			}
+
+			gc.GracePeriod = uint32(v)
+		} else if k == "helper" {
+			v, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("ospf graceful-restart: helper must be a boolean")
+			}
+
+			gc.Helper = v
+		} else if k == "strict-lsa-checking" {
+			v, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("ospf graceful-restart: strict-lsa-checking must be a boolean")
+			}
+
+			gc.StrictLSAChecking = v
+		} else {
+			return fmt.Errorf("ospf graceful-restart: unknown key: %s", k)
+		}
+	}
+
+	return nil
+}
+
+func (mc *OSPFMaxMetricConfig) parse(data map[string]interface{}) error {
+	for k, v := range data {
+		if k == "administrative" {
+			v, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("ospf max-metric router-lsa: administrative must be a boolean")
+			}
+
+			mc.Administrative = v
+		} else if k == "on-startup" {
+			v, ok := v.(int)
+			if !ok {
+				return fmt.Errorf("ospf max-metric router-lsa: on-startup must be an integer")
+			}
+
+			if v < 5 {
+				return fmt.Errorf("ospf max-metric router-lsa: on-startup too small: %d", v)
+			} else if v > 86400 {
+				return fmt.Errorf("ospf max-metric router-lsa: on-startup too big: %d", v)
+			}
+
+			mc.OnStartup = uint32(v)
+		} else {
+			return fmt.Errorf("ospf max-metric router-lsa: unknown key: %s", k)
+		}
+	}
+
+	return nil
+}
+
+// parse parses a throttle's delays, which are in milliseconds.
+func (tc *OSPFThrottleConfig) parse(name string, data map[string]interface{}) error {
+	for k, v := range data {
+		var d *time.Duration
+
+		if k == "initial-delay" {
+			d = &tc.InitialDelay
+		} else if k == "hold" {
+			d = &tc.Hold
+		} else if k == "max-wait" {
+			d = &tc.MaxWait
+		} else {
+			return fmt.Errorf("ospf %s: unknown key: %s", name, k)
+		}
+
+		ms, ok := v.(int)
+		if !ok {
+			return fmt.Errorf("ospf %s: %s must be an integer", name, k)
+		}
+
+		if ms < 0 {
+			return fmt.Errorf("ospf %s: %s must not be negative: %d", name, k, ms)
+		} else if ms > 600000 {
+			return fmt.Errorf("ospf %s: %s too big: %d", name, k, ms)
+		}
+
+		*d = time.Duration(ms) * time.Millisecond
+	}
+
+	if tc.Hold > tc.MaxWait {
+		return fmt.Errorf("ospf %s: hold can't be longer than max-wait", name)
+	}
+
+	if tc.InitialDelay > tc.MaxWait {
+		return fmt.Errorf("ospf %s: initial-delay can't be longer than max-wait", name)
+	}
+
+	return nil
+}
+
 func (ac *OSPFAreaConfig) setDefaults(c *OSPFConfig) {
 	if ac.HelloInterval == 0 {
 		ac.HelloInterval = c.HelloInterval
@@ -224,9 +702,28 @@ func (ac *OSPFAreaConfig) setDefaults(c *OSPFConfig) {
 	}
 
 	for k, ic := range ac.Interfaces {
+		if !ic.passiveSet {
+			ic.Passive = c.PassiveDefault
+		}
+
 		ic.setDefaults(ac)
 		ac.Interfaces[k] = ic
 	}
+
+	for k, vc := range ac.VirtualLinks {
+		vc.setDefaults(ac)
+		ac.VirtualLinks[k] = vc
+	}
+}
+
+func (vc *OSPFVirtualLinkConfig) setDefaults(ac *OSPFAreaConfig) {
+	if vc.HelloInterval == 0 {
+		vc.HelloInterval = ac.HelloInterval
+	}
+
+	if vc.RouterDeadInterval == 0 {
+		vc.RouterDeadInterval = ac.RouterDeadInterval
+	}
 }
 
 func (ic *OSPFInterfaceConfig) setDefaults(ac *OSPFAreaConfig) {
@@ -241,6 +738,10 @@ func (ic *OSPFInterfaceConfig) setDefaults(ac *OSPFAreaConfig) {
 	if ic.RouterDeadInterval == 0 {
 		ic.RouterDeadInterval = ac.RouterDeadInterval
 	}
+
+	if ic.Authentication == nil {
+		ic.Authentication = ac.Authentication.copy()
+	}
 }
 
 func parseAreaConfig(areaID string, data map[string]interface{}) (*OSPFAreaConfig, error) {
@@ -248,11 +749,25 @@ func parseAreaConfig(areaID string, data map[string]interface{}) (*OSPFAreaConfi
 		Cost:               0,
 		HelloInterval:      0,
 		RouterDeadInterval: 0,
+		DefaultCost:        1,
+		AddressRanges:      make(map[netip.Prefix]OSPFAddressRangeConfig),
 		Interfaces:         make(map[string]OSPFInterfaceConfig),
+		VirtualLinks:       make(map[common.RouterID]OSPFVirtualLinkConfig),
 	}
 
+	stubOnly := ""
+	nssaOnly := ""
+
+	auth, err := parseAuthConfig(fmt.Sprintf("ospf area %s", areaID), data)
+	if err != nil {
+		return nil, err
+	}
+	ac.Authentication = auth
+
 	for k, v := range data {
-		if k == "cost" {
+		if isAuthKey(k) {
+			// Parsed by parseAuthConfig.
+		} else if k == "cost" {
 			v, ok := v.(int)
 			if !ok {
 				return nil, fmt.Errorf("ospf area %s: cost must be an integer", areaID)
@@ -291,6 +806,65 @@ func parseAreaConfig(areaID string, data map[string]interface{}) (*OSPFAreaConfi
 			}
 
 			ac.RouterDeadInterval = uint32(v)
+		} else if k == "stub" {
+			v, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("ospf area %s: stub must be a boolean", areaID)
+			}
+
+			ac.Stub = v
+		} else if k == "nssa" {
+			v, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("ospf area %s: nssa must be a boolean", areaID)
+			}
+
+			ac.NSSA = v
+		} else if k == "translator-role" {
+			switch v {
+			case "candidate":
+				ac.TranslatorRole = NSSATranslatorCandidate
+			case "always":
+				ac.TranslatorRole = NSSATranslatorAlways
+			default:
+				return nil, fmt.Errorf("ospf area %s: translator-role must be candidate or always", areaID)
+			}
+
+			nssaOnly = k
+		} else if k == "default-originate" {
+			v, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("ospf area %s: default-originate must be a boolean", areaID)
+			}
+
+			ac.DefaultOriginate = v
+			if v {
+				nssaOnly = k
+			}
+		} else if k == "no-summary" {
+			v, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("ospf area %s: no-summary must be a boolean", areaID)
+			}
+
+			ac.NoSummary = v
+			if v {
+				stubOnly = k
+			}
+		} else if k == "default-cost" {
+			v, ok := v.(int)
+			if !ok {
+				return nil, fmt.Errorf("ospf area %s: default-cost must be an integer", areaID)
+			}
+
+			if v < 0 {
+				return nil, fmt.Errorf("ospf area %s: default-cost too small: %d", areaID, v)
+			} else if v >= 0xffffff {
+				return nil, fmt.Errorf("ospf area %s: default-cost too big: %d", areaID, v)
+			}
+
+			ac.DefaultCost = uint32(v)
+			stubOnly = k
 		} else if strings.HasPrefix(k, "interface ") {
 			interfaceName := strings.TrimPrefix(k, "interface ")
 
@@ -305,14 +879,147 @@ func parseAreaConfig(areaID string, data map[string]interface{}) (*OSPFAreaConfi
 			}
 
 			ac.Interfaces[interfaceName] = *ic
+		} else if strings.HasPrefix(k, "range ") {
+			name := strings.TrimPrefix(k, "range ")
+
+			prefix, err := netip.ParsePrefix(name)
+			if err != nil || !prefix.Addr().Is4() {
+				return nil, fmt.Errorf("ospf area %s: invalid range: %s", areaID, name)
+			}
+
+			if prefix != prefix.Masked() {
+				return nil, fmt.Errorf("ospf area %s: range %s has host bits set", areaID, name)
+			}
+
+			var r map[string]interface{}
+			if v != nil {
+				var ok bool
+				r, ok = v.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("ospf area %s: range %s must be a map", areaID, name)
+				}
+			}
+
+			rc, err := parseAddressRangeConfig(areaID, name, r)
+			if err != nil {
+				return nil, err
+			}
+
+			ac.AddressRanges[prefix] = *rc
+		} else if strings.HasPrefix(k, "virtual-link ") {
+			name := strings.TrimPrefix(k, "virtual-link ")
+
+			id, err := parseID(name)
+			if err != nil {
+				return nil, fmt.Errorf("ospf area %s: invalid virtual-link: %s", areaID, err)
+			}
+
+			var vl map[string]interface{}
+			if v != nil {
+				var ok bool
+				vl, ok = v.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("ospf area %s: virtual-link %s must be a map", areaID, name)
+				}
+			}
+
+			vc, err := parseVirtualLinkConfig(areaID, name, vl)
+			if err != nil {
+				return nil, err
+			}
+
+			ac.VirtualLinks[common.RouterID(id)] = *vc
 		} else {
 			return nil, fmt.Errorf("ospf area %s: unknown key: %s", areaID, k)
 		}
 	}
 
+	if ac.Stub && ac.NSSA {
+		return nil, fmt.Errorf("ospf area %s: can't be both a stub area and an NSSA", areaID)
+	}
+
+	if stubOnly != "" && !ac.Stub && !ac.NSSA {
+		return nil, fmt.Errorf("ospf area %s: %s is only allowed in stub areas and NSSAs", areaID, stubOnly)
+	}
+
+	if nssaOnly != "" && !ac.NSSA {
+		return nil, fmt.Errorf("ospf area %s: %s is only allowed in NSSAs", areaID, nssaOnly)
+	}
+
+	if len(ac.VirtualLinks) > 0 && (ac.Stub || ac.NSSA) {
+		return nil, fmt.Errorf("ospf area %s: virtual links can't be configured through stub areas or NSSAs", areaID)
+	}
+
 	return &ac, nil
 }
 
+func parseAddressRangeConfig(areaName, name string, data map[string]interface{}) (*OSPFAddressRangeConfig, error) {
+	rc := OSPFAddressRangeConfig{
+		Advertise: true,
+	}
+
+	for k, v := range data {
+		if k == "not-advertise" {
+			v, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("ospf area %s range %s: not-advertise must be a boolean", areaName, name)
+			}
+
+			rc.Advertise = !v
+		} else {
+			return nil, fmt.Errorf("ospf area %s range %s: unknown key: %s", areaName, name, k)
+		}
+	}
+
+	return &rc, nil
+}
+
+func parseVirtualLinkConfig(areaName, name string, data map[string]interface{}) (*OSPFVirtualLinkConfig, error) {
+	var vc OSPFVirtualLinkConfig
+
+	auth, err := parseAuthConfig(fmt.Sprintf("ospf area %s virtual-link %s", areaName, name), data)
+	if err != nil {
+		return nil, err
+	}
+	vc.Authentication = auth
+
+	for k, v := range data {
+		if isAuthKey(k) {
+			// Parsed by parseAuthConfig.
+		} else if k == "hello-interval" {
+			v, ok := v.(int)
+			if !ok {
+				return nil, fmt.Errorf("ospf area %s virtual-link %s: hello-interval must be an integer", areaName, name)
+			}
+
+			if v < 1 {
+				return nil, fmt.Errorf("ospf area %s virtual-link %s: hello-interval too small: %d", areaName, name, v)
+			} else if v > math.MaxUint16 {
+				return nil, fmt.Errorf("ospf area %s virtual-link %s: hello-interval too big: %d", areaName, name, v)
+			}
+
+			vc.HelloInterval = uint16(v)
+		} else if k == "dead-interval" {
+			v, ok := v.(int)
+			if !ok {
+				return nil, fmt.Errorf("ospf area %s virtual-link %s: dead-interval must be an integer", areaName, name)
+			}
+
+			if v < 1 {
+				return nil, fmt.Errorf("ospf area %s virtual-link %s: dead-interval too small: %d", areaName, name, v)
+			} else if v > math.MaxUint32 {
+				return nil, fmt.Errorf("ospf area %s virtual-link %s: dead-interval too big: %d", areaName, name, v)
+			}
+
+			vc.RouterDeadInterval = uint32(v)
+		} else {
+			return nil, fmt.Errorf("ospf area %s virtual-link %s: unknown key: %s", areaName, name, k)
+		}
+	}
+
+	return &vc, nil
+}
+
 func parseInterfaceConfig(areaName, name string, data map[string]interface{}) (*OSPFInterfaceConfig, error) {
 	id, err := parseID(areaName)
 	if err != nil {
@@ -320,12 +1027,25 @@ func parseInterfaceConfig(areaName, name string, data map[string]interface{}) (*
 	}
 
 	ic := OSPFInterfaceConfig{
-		AreaID: common.AreaID(id),
-		Cost:   0,
+		AreaID:         common.AreaID(id),
+		Cost:           0,
+		RouterPriority: 1,
+		PollInterval:   120,
+		Neighbors:      make(map[netip.Addr]OSPFNeighborConfig),
 	}
 
+	nbmaOnly := ""
+
+	auth, err := parseAuthConfig(fmt.Sprintf("ospf area %s interface %s", areaName, name), data)
+	if err != nil {
+		return nil, err
+	}
+	ic.Authentication = auth
+
 	for k, v := range data {
-		if k == "cost" {
+		if isAuthKey(k) {
+			// Parsed by parseAuthConfig.
+		} else if k == "cost" {
 			v, ok := v.(int)
 			if !ok {
 				return nil, fmt.Errorf("ospf area %s interface %s: cost must be an integer", areaName, name)
@@ -364,10 +1084,295 @@ func parseInterfaceConfig(areaName, name string, data map[string]interface{}) (*
 			}
 
 			ic.RouterDeadInterval = uint32(v)
+		} else if k == "priority" {
+			v, ok := v.(int)
+			if !ok {
+				return nil, fmt.Errorf("ospf area %s interface %s: priority must be an integer", areaName, name)
+			}
+
+			if v < 0 {
+				return nil, fmt.Errorf("ospf area %s interface %s: priority too small: %d", areaName, name, v)
+			} else if v > math.MaxUint8 {
+				return nil, fmt.Errorf("ospf area %s interface %s: priority too big: %d", areaName, name, v)
+			}
+
+			ic.RouterPriority = uint8(v)
+		} else if k == "passive" {
+			v, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("ospf area %s interface %s: passive must be a boolean", areaName, name)
+			}
+
+			ic.Passive = v
+			ic.passiveSet = true
+		} else if k == "network" {
+			switch v {
+			case "broadcast":
+				ic.Network = OSPFNetworkBroadcast
+			case "point-to-point":
+				ic.Network = OSPFNetworkPointToPoint
+			case "nbma":
+				ic.Network = OSPFNetworkNBMA
+			case "point-to-multipoint":
+				ic.Network = OSPFNetworkPointToMultipoint
+			default:
+				return nil, fmt.Errorf("ospf area %s interface %s: network must be broadcast, point-to-point, nbma or point-to-multipoint", areaName, name)
+			}
+		} else if k == "poll-interval" {
+			v, ok := v.(int)
+			if !ok {
+				return nil, fmt.Errorf("ospf area %s interface %s: poll-interval must be an integer", areaName, name)
+			}
+
+			if v < 1 {
+				return nil, fmt.Errorf("ospf area %s interface %s: poll-interval too small: %d", areaName, name, v)
+			} else if v > math.MaxUint32 {
+				return nil, fmt.Errorf("ospf area %s interface %s: poll-interval too big: %d", areaName, name, v)
+			}
+
+			ic.PollInterval = uint32(v)
+			nbmaOnly = k
+		} else if strings.HasPrefix(k, "neighbor ") {
+			addrName := strings.TrimPrefix(k, "neighbor ")
+
+			addr, err := netip.ParseAddr(addrName)
+			if err != nil || !addr.Is4() {
+				return nil, fmt.Errorf("ospf area %s interface %s: invalid neighbor: %s", areaName, name, addrName)
+			}
+
+			var nbr map[string]interface{}
+			if v != nil {
+				var ok bool
+				nbr, ok = v.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("ospf area %s interface %s: neighbor %s must be a map", areaName, name, addrName)
+				}
+			}
+
+			nc, err := parseNeighborConfig(areaName, name, addrName, nbr)
+			if err != nil {
+				return nil, err
+			}
+
+			ic.Neighbors[addr] = *nc
+			nbmaOnly = "neighbor"
 		} else {
 			return nil, fmt.Errorf("ospf area %s interface %s: unknown key: %s", areaName, name, k)
 		}
 	}
 
+	// Neighbors have to be configured on NBMA networks, so configuring
+	// them implies one.
+	if len(ic.Neighbors) > 0 && ic.Network == OSPFNetworkAuto {
+		ic.Network = OSPFNetworkNBMA
+	}
+
+	if nbmaOnly != "" && ic.Network != OSPFNetworkNBMA {
+		return nil, fmt.Errorf("ospf area %s interface %s: %s is only allowed on nbma networks", areaName, name, nbmaOnly)
+	}
+
 	return &ic, nil
 }
+
+func parseNeighborConfig(areaName, ifaceName, name string, data map[string]interface{}) (*OSPFNeighborConfig, error) {
+	nc := OSPFNeighborConfig{
+		Priority: 1,
+	}
+
+	for k, v := range data {
+		if k == "priority" {
+			v, ok := v.(int)
+			if !ok {
+				return nil, fmt.Errorf("ospf area %s interface %s neighbor %s: priority must be an integer", areaName, ifaceName, name)
+			}
+
+			if v < 0 {
+				return nil, fmt.Errorf("ospf area %s interface %s neighbor %s: priority too small: %d", areaName, ifaceName, name, v)
+			} else if v > math.MaxUint8 {
+				return nil, fmt.Errorf("ospf area %s interface %s neighbor %s: priority too big: %d", areaName, ifaceName, name, v)
+			}
+
+			nc.Priority = uint8(v)
+		} else {
+			return nil, fmt.Errorf("ospf area %s interface %s neighbor %s: unknown key: %s", areaName, ifaceName, name, k)
+		}
+	}
+
+	return &nc, nil
+}
+
+func isAuthKey(k string) bool {
+	return k == "authentication" || k == "authentication-key" || strings.HasPrefix(k, "key ")
+}
+
+// parseAuthConfig parses the authentication keys in data, which configures
+// an area, interface or virtual link described by where. It returns nil if
+// there's no authentication configured.
+func parseAuthConfig(where string, data map[string]interface{}) (*OSPFAuthConfig, error) {
+	var auth *OSPFAuthConfig
+	simpleOnly := ""
+	cryptoOnly := ""
+
+	for k, v := range data {
+		if !isAuthKey(k) {
+			continue
+		}
+
+		if auth == nil {
+			auth = &OSPFAuthConfig{Keys: make(map[uint8]OSPFAuthKeyConfig)}
+		}
+
+		if k == "authentication" {
+			switch v {
+			case "null", nil:
+				// An unquoted null is decoded as nil.
+				auth.Type = OSPFAuthNull
+			case "simple":
+				auth.Type = OSPFAuthSimple
+			case "cryptographic":
+				auth.Type = OSPFAuthCryptographic
+			default:
+				return nil, fmt.Errorf("%s: authentication must be null, simple or cryptographic", where)
+			}
+		} else if k == "authentication-key" {
+			v, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: authentication-key must be a string", where)
+			}
+
+			if len(v) > 8 {
+				return nil, fmt.Errorf("%s: authentication-key longer than 8 bytes", where)
+			}
+
+			auth.Key = v
+			simpleOnly = k
+		} else {
+			name := strings.TrimPrefix(k, "key ")
+
+			id, err := strconv.ParseUint(name, 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("%s: key id must be between 0 and 255: %s", where, name)
+			}
+
+			key, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: key %s must be a map", where, name)
+			}
+
+			kc, err := parseAuthKeyConfig(fmt.Sprintf("%s key %s", where, name), key)
+			if err != nil {
+				return nil, err
+			}
+
+			auth.Keys[uint8(id)] = *kc
+			cryptoOnly = "key"
+		}
+	}
+
+	if auth == nil {
+		return nil, nil
+	}
+
+	if simpleOnly != "" && auth.Type != OSPFAuthSimple {
+		return nil, fmt.Errorf("%s: %s is only allowed with simple authentication", where, simpleOnly)
+	}
+
+	if cryptoOnly != "" && auth.Type != OSPFAuthCryptographic {
+		return nil, fmt.Errorf("%s: %s is only allowed with cryptographic authentication", where, cryptoOnly)
+	}
+
+	if auth.Type == OSPFAuthSimple && simpleOnly == "" {
+		return nil, fmt.Errorf("%s: simple authentication requires an authentication-key", where)
+	}
+
+	if auth.Type == OSPFAuthCryptographic && len(auth.Keys) == 0 {
+		return nil, fmt.Errorf("%s: cryptographic authentication requires at least one key", where)
+	}
+
+	return auth, nil
+}
+
+func parseAuthKeyConfig(where string, data map[string]interface{}) (*OSPFAuthKeyConfig, error) {
+	var kc OSPFAuthKeyConfig
+
+	for k, v := range data {
+		if k == "algorithm" {
+			switch v {
+			case "md5":
+				kc.Algorithm = OSPFAuthMD5
+			case "hmac-sha-1":
+				kc.Algorithm = OSPFAuthHMACSHA1
+			case "hmac-sha-256":
+				kc.Algorithm = OSPFAuthHMACSHA256
+			case "hmac-sha-384":
+				kc.Algorithm = OSPFAuthHMACSHA384
+			case "hmac-sha-512":
+				kc.Algorithm = OSPFAuthHMACSHA512
+			default:
+				return nil, fmt.Errorf("%s: algorithm must be md5, hmac-sha-1, hmac-sha-256, hmac-sha-384 or hmac-sha-512", where)
+			}
+		} else if k == "secret" {
+			v, ok := v.(string)
+			if !ok || v == "" {
+				return nil, fmt.Errorf("%s: secret must be a non-empty string", where)
+			}
+
+			kc.Secret = v
+		} else if k == "send-start" || k == "send-stop" || k == "accept-start" || k == "accept-stop" {
+			t, err := parseTime(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s %v", where, k, err)
+			}
+
+			switch k {
+			case "send-start":
+				kc.SendStart = t
+			case "send-stop":
+				kc.SendStop = t
+			case "accept-start":
+				kc.AcceptStart = t
+			case "accept-stop":
+				kc.AcceptStop = t
+			}
+		} else {
+			return nil, fmt.Errorf("%s: unknown key: %s", where, k)
+		}
+	}
+
+	if kc.Secret == "" {
+		return nil, fmt.Errorf("%s: secret is required", where)
+	}
+
+	// MD5 keys are padded to 16 bytes. See RFC 2328, appendix D.3.
+	if kc.Algorithm == OSPFAuthMD5 && len(kc.Secret) > 16 {
+		return nil, fmt.Errorf("%s: md5 secret longer than 16 bytes", where)
+	}
+
+	if !kc.SendStop.IsZero() && kc.SendStop.Before(kc.SendStart) {
+		return nil, fmt.Errorf("%s: send-stop is before send-start", where)
+	}
+
+	if !kc.AcceptStop.IsZero() && kc.AcceptStop.Before(kc.AcceptStart) {
+		return nil, fmt.Errorf("%s: accept-stop is before accept-start", where)
+	}
+
+	return &kc, nil
+}
+
+// parseTime parses an RFC 3339 timestamp, which YAML might have already
+// decoded.
+func parseTime(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("must be an RFC 3339 timestamp")
+		}
+
+		return t, nil
+	default:
+		return time.Time{}, fmt.Errorf("must be an RFC 3339 timestamp")
+	}
+}
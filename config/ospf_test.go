@@ -0,0 +1,778 @@
+package config
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+)
+
+func parseOSPF(s string) (*OSPFConfig, error) {
+	c, err := parseConfig(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.protocolConfigs[ServiceOSPF].(*OSPFConfig), nil
+}
+
+func TestParseOSPFConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		yaml  string
+		check func(t *testing.T, c *OSPFConfig)
+	}{
+		{
+			name: "priority",
+			yaml: `
+ospf:
+  area 0:
+    interface eth0: {}
+    interface eth1:
+      priority: 0
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				ifaces := c.Areas[0].Interfaces
+
+				if p := ifaces["eth0"].RouterPriority; p != 1 {
+					t.Errorf("expected eth0 to have the default priority, got %d", p)
+				}
+
+				if p := ifaces["eth1"].RouterPriority; p != 0 {
+					t.Errorf("expected eth1 to have priority 0, got %d", p)
+				}
+			},
+		},
+		{
+			name: "ranges",
+			yaml: `
+ospf:
+  area 0: {}
+  area 1:
+    range 10.1.0.0/16:
+    range 10.2.0.0/16:
+      not-advertise: true
+    range 10.3.0.0/16:
+      not-advertise: false
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				ranges := c.Areas[1].AddressRanges
+
+				expected := map[string]bool{"10.1.0.0/16": true, "10.2.0.0/16": false, "10.3.0.0/16": true}
+				if len(ranges) != len(expected) {
+					t.Fatalf("expected %d ranges, got %v", len(expected), ranges)
+				}
+
+				for prefix, advertise := range expected {
+					rc, ok := ranges[netip.MustParsePrefix(prefix)]
+					if !ok || rc.Advertise != advertise {
+						t.Errorf("expected %s to have Advertise %v, got %+v", prefix, advertise, rc)
+					}
+				}
+			},
+		},
+		{
+			name: "stub areas",
+			yaml: `
+ospf:
+  area 0: {}
+  area 1:
+    stub: true
+    no-summary: true
+    default-cost: 5
+  area 2:
+    stub: true
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				if a := c.Areas[0]; a.Stub || a.NoSummary {
+					t.Errorf("unexpected backbone: %+v", a)
+				}
+
+				if a := c.Areas[1]; !a.Stub || !a.NoSummary || a.DefaultCost != 5 {
+					t.Errorf("unexpected totally stubby area: %+v", a)
+				}
+
+				if a := c.Areas[2]; !a.Stub || a.NoSummary || a.DefaultCost != 1 {
+					t.Errorf("unexpected stub area: %+v", a)
+				}
+			},
+		},
+		{
+			name: "NSSAs",
+			yaml: `
+ospf:
+  area 0: {}
+  area 1:
+    nssa: true
+    no-summary: true
+    default-cost: 5
+    translator-role: always
+    default-originate: true
+  area 2:
+    nssa: true
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				if a := c.Areas[1]; a.Stub || !a.NSSA || !a.NoSummary || a.DefaultCost != 5 || a.TranslatorRole != NSSATranslatorAlways || !a.DefaultOriginate {
+					t.Errorf("unexpected NSSA: %+v", a)
+				}
+
+				if a := c.Areas[2]; !a.NSSA || a.TranslatorRole != NSSATranslatorCandidate || a.DefaultOriginate || a.DefaultCost != 1 {
+					t.Errorf("unexpected NSSA: %+v", a)
+				}
+			},
+		},
+		{
+			name: "virtual links",
+			yaml: `
+ospf:
+  hello-interval: 5
+  area 0: {}
+  area 1:
+    dead-interval: 60
+    virtual-link 2.2.2.2:
+    virtual-link 3.3.3.3:
+      hello-interval: 1
+      dead-interval: 4
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				vls := c.Areas[1].VirtualLinks
+
+				if len(vls) != 2 {
+					t.Fatalf("expected 2 virtual links, got %v", vls)
+				}
+
+				if vc := vls[common.RouterID(0x02020202)]; vc.HelloInterval != 5 || vc.RouterDeadInterval != 60 {
+					t.Errorf("expected 2.2.2.2 to inherit its intervals, got %+v", vc)
+				}
+
+				if vc := vls[common.RouterID(0x03030303)]; vc.HelloInterval != 1 || vc.RouterDeadInterval != 4 {
+					t.Errorf("unexpected intervals for 3.3.3.3: %+v", vc)
+				}
+			},
+		},
+		{
+			name: "network types",
+			yaml: `
+ospf:
+  area 0:
+    interface eth0:
+      network: point-to-point
+    interface eth1:
+      network: point-to-multipoint
+    interface eth2:
+      poll-interval: 60
+      neighbor 10.0.0.2:
+      neighbor 10.0.0.3:
+        priority: 0
+    interface eth3:
+      network: nbma
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				ifaces := c.Areas[0].Interfaces
+
+				if ifaces["eth0"].Network != OSPFNetworkPointToPoint {
+					t.Errorf("expected eth0 to be point-to-point, got %s", ifaces["eth0"].Network)
+				}
+
+				if ifaces["eth1"].Network != OSPFNetworkPointToMultipoint {
+					t.Errorf("expected eth1 to be point-to-multipoint, got %s", ifaces["eth1"].Network)
+				}
+
+				// Neighbors imply NBMA.
+				ic := ifaces["eth2"]
+				if ic.Network != OSPFNetworkNBMA || ic.PollInterval != 60 {
+					t.Errorf("unexpected eth2: %+v", ic)
+				}
+
+				expected := map[netip.Addr]OSPFNeighborConfig{
+					netip.MustParseAddr("10.0.0.2"): {Priority: 1},
+					netip.MustParseAddr("10.0.0.3"): {Priority: 0},
+				}
+
+				if len(ic.Neighbors) != len(expected) {
+					t.Fatalf("expected %d neighbors, got %v", len(expected), ic.Neighbors)
+				}
+
+				for addr, nc := range expected {
+					if ic.Neighbors[addr] != nc {
+						t.Errorf("expected neighbor %s to be %+v, got %+v", addr, nc, ic.Neighbors[addr])
+					}
+				}
+
+				if ic := ifaces["eth3"]; ic.Network != OSPFNetworkNBMA || ic.PollInterval != 120 {
+					t.Errorf("unexpected eth3: %+v", ic)
+				}
+			},
+		},
+		{
+			name: "cryptographic authentication",
+			yaml: `
+ospf:
+  area 0:
+    authentication: cryptographic
+    key 1:
+      secret: old
+      send-stop: 2024-01-02T00:00:00Z
+      accept-stop: "2024-01-03T00:00:00Z"
+    key 2:
+      algorithm: hmac-sha-256
+      secret: new
+      send-start: "2024-01-01T12:00:00+01:00"
+      accept-start: 2024-01-01T00:00:00Z
+    interface eth0: {}
+    interface eth1:
+      authentication: "null"
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				auth := c.Areas[0].Authentication
+				if auth == nil || auth.Type != OSPFAuthCryptographic || len(auth.Keys) != 2 {
+					t.Fatalf("unexpected authentication: %+v", auth)
+				}
+
+				expected := map[uint8]OSPFAuthKeyConfig{
+					1: {
+						Algorithm:  OSPFAuthMD5,
+						Secret:     "old",
+						SendStop:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+						AcceptStop: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+					},
+					2: {
+						Algorithm:   OSPFAuthHMACSHA256,
+						Secret:      "new",
+						SendStart:   time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+						AcceptStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+					},
+				}
+
+				for id, kc := range expected {
+					got := auth.Keys[id]
+					if got.Algorithm != kc.Algorithm || got.Secret != kc.Secret ||
+						!got.SendStart.Equal(kc.SendStart) || !got.SendStop.Equal(kc.SendStop) ||
+						!got.AcceptStart.Equal(kc.AcceptStart) || !got.AcceptStop.Equal(kc.AcceptStop) {
+						t.Errorf("expected key %d to be %+v, got %+v", id, kc, got)
+					}
+				}
+
+				ifaces := c.Areas[0].Interfaces
+				if a := ifaces["eth0"].Authentication; a == nil || a.Type != OSPFAuthCryptographic || len(a.Keys) != 2 {
+					t.Errorf("expected eth0 to inherit the area's authentication, got %+v", a)
+				}
+
+				if a := ifaces["eth1"].Authentication; a == nil || a.Type != OSPFAuthNull {
+					t.Errorf("expected eth1 to have null authentication, got %+v", a)
+				}
+			},
+		},
+		{
+			name: "virtual link authentication",
+			yaml: `
+ospf:
+  area 0:
+    authentication: simple
+    authentication-key: secret
+  area 1:
+    virtual-link 2.2.2.2:
+    virtual-link 3.3.3.3:
+      authentication: simple
+      authentication-key: other
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				vls := c.Areas[1].VirtualLinks
+
+				// Virtual links belong to the backbone, so they use its
+				// authentication, not their transit area's.
+				a := vls[common.RouterID(0x02020202)].Authentication
+				if a == nil || a.Type != OSPFAuthSimple || a.Key != "secret" {
+					t.Errorf("expected 2.2.2.2 to inherit the backbone's authentication, got %+v", a)
+				}
+
+				if a == c.Areas[0].Authentication {
+					t.Errorf("expected 2.2.2.2 to have its own copy of the backbone's authentication")
+				}
+
+				if a := vls[common.RouterID(0x03030303)].Authentication; a == nil || a.Key != "other" {
+					t.Errorf("expected 3.3.3.3 to have its own authentication, got %+v", a)
+				}
+			},
+		},
+		{
+			name: "passive interfaces",
+			yaml: `
+ospf:
+  area 0:
+    interface eth0: {}
+    interface eth1:
+      passive: true
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				ifaces := c.Areas[0].Interfaces
+
+				if ifaces["eth0"].Passive || !ifaces["eth1"].Passive {
+					t.Errorf("expected only eth1 to be passive")
+				}
+			},
+		},
+		{
+			name: "passive-default",
+			yaml: `
+ospf:
+  passive-default: true
+  area 0:
+    interface eth0: {}
+    interface eth1:
+      passive: false
+    interface eth2:
+      passive: true
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				ifaces := c.Areas[0].Interfaces
+
+				// An interface's own passive setting overrides
+				// passive-default.
+				for name, passive := range map[string]bool{"eth0": true, "eth1": false, "eth2": true} {
+					if ifaces[name].Passive != passive {
+						t.Errorf("expected %s to have Passive %v", name, passive)
+					}
+				}
+			},
+		},
+		{
+			name: "graceful restart defaults",
+			yaml: `ospf: {area 0: {}}`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				if c.GracefulRestart != (OSPFGracefulRestartConfig{GracePeriod: 120, Helper: true, StrictLSAChecking: true}) {
+					t.Errorf("unexpected graceful-restart: %+v", c.GracefulRestart)
+				}
+			},
+		},
+		{
+			name: "graceful restart",
+			yaml: `
+ospf:
+  graceful-restart:
+    enabled: true
+    grace-period: 60
+    helper: false
+    strict-lsa-checking: false
+  area 0: {}
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				if c.GracefulRestart != (OSPFGracefulRestartConfig{Enabled: true, GracePeriod: 60}) {
+					t.Errorf("unexpected graceful-restart: %+v", c.GracefulRestart)
+				}
+			},
+		},
+		{
+			name: "max-metric",
+			yaml: `
+ospf:
+  max-metric router-lsa:
+    administrative: true
+    on-startup: 300
+  area 0: {}
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				if c.MaxMetric != (OSPFMaxMetricConfig{Administrative: true, OnStartup: 300}) {
+					t.Errorf("unexpected max-metric: %+v", c.MaxMetric)
+				}
+			},
+		},
+		{
+			name: "throttle defaults",
+			yaml: `ospf: {area 0: {}}`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				if c.SPFThrottle != (OSPFThrottleConfig{InitialDelay: 200 * time.Millisecond, Hold: 1 * time.Second, MaxWait: 10 * time.Second}) {
+					t.Errorf("unexpected spf-throttle: %+v", c.SPFThrottle)
+				}
+
+				if c.LSAThrottle != (OSPFThrottleConfig{InitialDelay: 0, Hold: 5 * time.Second, MaxWait: 5 * time.Second}) {
+					t.Errorf("unexpected lsa-throttle: %+v", c.LSAThrottle)
+				}
+			},
+		},
+		{
+			name: "throttles in milliseconds",
+			yaml: `
+ospf:
+  spf-throttle:
+    initial-delay: 50
+    hold: 500
+    max-wait: 5000
+  lsa-throttle:
+    initial-delay: 10
+    hold: 1000
+    max-wait: 8000
+  area 0: {}
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				if c.SPFThrottle != (OSPFThrottleConfig{InitialDelay: 50 * time.Millisecond, Hold: 500 * time.Millisecond, MaxWait: 5 * time.Second}) {
+					t.Errorf("unexpected spf-throttle: %+v", c.SPFThrottle)
+				}
+
+				if c.LSAThrottle != (OSPFThrottleConfig{InitialDelay: 10 * time.Millisecond, Hold: 1 * time.Second, MaxWait: 8 * time.Second}) {
+					t.Errorf("unexpected lsa-throttle: %+v", c.LSAThrottle)
+				}
+			},
+		},
+		{
+			name: "unquoted null authentication",
+			yaml: `
+ospf:
+  area 0:
+    authentication: simple
+    authentication-key: secret
+    interface eth0:
+      authentication: null
+  area 1:
+    virtual-link 2.2.2.2:
+      authentication: null
+`,
+			check: func(t *testing.T, c *OSPFConfig) {
+				if a := c.Areas[0].Interfaces["eth0"].Authentication; a == nil || a.Type != OSPFAuthNull {
+					t.Errorf("expected eth0 to have null authentication, got %+v", a)
+				}
+
+				if a := c.Areas[1].VirtualLinks[common.RouterID(0x02020202)].Authentication; a == nil || a.Type != OSPFAuthNull {
+					t.Errorf("expected 2.2.2.2 to have null authentication, got %+v", a)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseOSPF(tt.yaml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestParseOSPFConfigErrors(t *testing.T) {
+	tests := []struct {
+		yaml string
+		err  string
+	}{
+		// Interfaces
+		{`ospf: {area 0: {interface eth0: {priority: high}}}`, "ospf area 0 interface eth0: priority must be an integer"},
+		{`ospf: {area 0: {interface eth0: {priority: -1}}}`, "ospf area 0 interface eth0: priority too small: -1"},
+		{`ospf: {area 0: {interface eth0: {priority: 256}}}`, "ospf area 0 interface eth0: priority too big: 256"},
+		{`ospf: {area 0: {interface eth0: {network: ethernet}}}`, "ospf area 0 interface eth0: network must be broadcast, point-to-point, nbma or point-to-multipoint"},
+		{`ospf: {area 0: {interface eth0: {network: broadcast, poll-interval: 60}}}`, "ospf area 0 interface eth0: poll-interval is only allowed on nbma networks"},
+		{`ospf: {area 0: {interface eth0: {poll-interval: 60}}}`, "ospf area 0 interface eth0: poll-interval is only allowed on nbma networks"},
+		{`ospf: {area 0: {interface eth0: {poll-interval: 0}}}`, "ospf area 0 interface eth0: poll-interval too small: 0"},
+		{`ospf: {area 0: {interface eth0: {network: point-to-point, neighbor 10.0.0.2: null}}}`, "ospf area 0 interface eth0: neighbor is only allowed on nbma networks"},
+		{`ospf: {area 0: {interface eth0: {neighbor 10.0.0: null}}}`, "ospf area 0 interface eth0: invalid neighbor: 10.0.0"},
+		{`ospf: {area 0: {interface eth0: {neighbor 10.0.0.2: 1}}}`, "ospf area 0 interface eth0: neighbor 10.0.0.2 must be a map"},
+		{`ospf: {area 0: {interface eth0: {neighbor 10.0.0.2: {priority: 256}}}}`, "ospf area 0 interface eth0 neighbor 10.0.0.2: priority too big: 256"},
+		{`ospf: {area 0: {interface eth0: {neighbor 10.0.0.2: {cost: 1}}}}`, "ospf area 0 interface eth0 neighbor 10.0.0.2: unknown key: cost"},
+		{`ospf: {passive-default: 1, area 0: {}}`, "ospf: passive-default must be a boolean"},
+		{`ospf: {area 0: {interface eth0: {passive: 1}}}`, "ospf area 0 interface eth0: passive must be a boolean"},
+
+		// Ranges
+		{`ospf: {area 0: {range 10.0.0/8: null}}`, "ospf area 0: invalid range: 10.0.0/8"},
+		{`ospf: {area 0: {range 2001:db8::/32: null}}`, "ospf area 0: invalid range: 2001:db8::/32"},
+		{`ospf: {area 0: {range 10.0.0.1/8: null}}`, "ospf area 0: range 10.0.0.1/8 has host bits set"},
+		{`ospf: {area 0: {range 10.0.0.0/8: true}}`, "ospf area 0: range 10.0.0.0/8 must be a map"},
+		{`ospf: {area 0: {range 10.0.0.0/8: {not-advertise: 1}}}`, "ospf area 0 range 10.0.0.0/8: not-advertise must be a boolean"},
+		{`ospf: {area 0: {range 10.0.0.0/8: {cost: 1}}}`, "ospf area 0 range 10.0.0.0/8: unknown key: cost"},
+
+		// Stub areas and NSSAs
+		{`ospf: {area 0: {stub: true}}`, "ospf: backbone area can't be a stub area"},
+		{`ospf: {area 0: {}, area 1: {stub: yes}}`, "ospf area 1: stub must be a boolean"},
+		{`ospf: {area 0: {}, area 1: {no-summary: true}}`, "ospf area 1: no-summary is only allowed in stub areas and NSSAs"},
+		{`ospf: {area 0: {}, area 1: {default-cost: 5}}`, "ospf area 1: default-cost is only allowed in stub areas and NSSAs"},
+		{`ospf: {area 0: {}, area 1: {stub: true, default-cost: 16777215}}`, "ospf area 1: default-cost too big: 16777215"},
+		{`ospf: {area 0: {}, area 1: {stub: true, default-cost: -1}}`, "ospf area 1: default-cost too small: -1"},
+		{`ospf: {area 0: {nssa: true}}`, "ospf: backbone area can't be an NSSA"},
+		{`ospf: {area 0: {}, area 1: {stub: true, nssa: true}}`, "ospf area 1: can't be both a stub area and an NSSA"},
+		{`ospf: {area 0: {}, area 1: {nssa: 1}}`, "ospf area 1: nssa must be a boolean"},
+		{`ospf: {area 0: {}, area 1: {stub: true, translator-role: always}}`, "ospf area 1: translator-role is only allowed in NSSAs"},
+		{`ospf: {area 0: {}, area 1: {default-originate: true}}`, "ospf area 1: default-originate is only allowed in NSSAs"},
+		{`ospf: {area 0: {}, area 1: {nssa: true, translator-role: never}}`, "ospf area 1: translator-role must be candidate or always"},
+
+		// Virtual links
+		{`ospf: {area 1: {virtual-link 2.2.2.2: null}}`, "ospf: backbone area must be configured"},
+		{`ospf: {area 0: {virtual-link 2.2.2.2: null}}`, "ospf: backbone area can't be a transit area"},
+		{`ospf: {router-id: 1.1.1.1, area 0: {}, area 1: {virtual-link 1.1.1.1: null}}`, "ospf area 0.0.0.1: virtual link to our own router-id"},
+		{`ospf: {area 0: {}, area 1: {stub: true, virtual-link 2.2.2.2: null}}`, "ospf area 1: virtual links can't be configured through stub areas or NSSAs"},
+		{`ospf: {area 0: {}, area 1: {nssa: true, virtual-link 2.2.2.2: null}}`, "ospf area 1: virtual links can't be configured through stub areas or NSSAs"},
+		{`ospf: {area 0: {}, area 1: {virtual-link foo: null}}`, "ospf area 1: invalid virtual-link: must be an IPv4 address or an unsigned 32 bit integer"},
+		{`ospf: {area 0: {}, area 1: {virtual-link 2.2.2.2: []}}`, "ospf area 1: virtual-link 2.2.2.2 must be a map"},
+		{`ospf: {area 0: {}, area 1: {virtual-link 2.2.2.2: {hello-interval: 0}}}`, "ospf area 1 virtual-link 2.2.2.2: hello-interval too small: 0"},
+		{`ospf: {area 0: {}, area 1: {virtual-link 2.2.2.2: {dead-interval: x}}}`, "ospf area 1 virtual-link 2.2.2.2: dead-interval must be an integer"},
+		{`ospf: {area 0: {}, area 1: {virtual-link 2.2.2.2: {cost: 1}}}`, "ospf area 1 virtual-link 2.2.2.2: unknown key: cost"},
+
+		// Authentication
+		{`ospf: {area 0: {authentication: md5}}`, "ospf area 0: authentication must be null, simple or cryptographic"},
+		{`ospf: {area 0: {authentication: simple}}`, "ospf area 0: simple authentication requires an authentication-key"},
+		{`ospf: {area 0: {authentication: cryptographic}}`, "ospf area 0: cryptographic authentication requires at least one key"},
+		{`ospf: {area 0: {authentication: simple, authentication-key: 123456789}}`, "ospf area 0: authentication-key must be a string"},
+		{`ospf: {area 0: {authentication: simple, authentication-key: "123456789"}}`, "ospf area 0: authentication-key longer than 8 bytes"},
+		{`ospf: {area 0: {authentication: cryptographic, authentication-key: foo, key 1: {secret: foo}}}`, "ospf area 0: authentication-key is only allowed with simple authentication"},
+		{`ospf: {area 0: {authentication: simple, authentication-key: foo, key 1: {secret: foo}}}`, "ospf area 0: key is only allowed with cryptographic authentication"},
+		{`ospf: {area 0: {interface eth0: {key 1: {secret: foo}}}}`, "ospf area 0 interface eth0: key is only allowed with cryptographic authentication"},
+		{`ospf: {area 0: {}, area 1: {virtual-link 2.2.2.2: {authentication: simple}}}`, "ospf area 1 virtual-link 2.2.2.2: simple authentication requires an authentication-key"},
+		{`ospf: {area 0: {authentication: cryptographic, key 256: {secret: foo}}}`, "ospf area 0: key id must be between 0 and 255: 256"},
+		{`ospf: {area 0: {authentication: cryptographic, key 1: foo}}`, "ospf area 0: key 1 must be a map"},
+		{`ospf: {area 0: {authentication: cryptographic, key 1: {algorithm: sha-1, secret: foo}}}`, "ospf area 0 key 1: algorithm must be md5, hmac-sha-1, hmac-sha-256, hmac-sha-384 or hmac-sha-512"},
+		{`ospf: {area 0: {authentication: cryptographic, key 1: {secret: ""}}}`, "ospf area 0 key 1: secret must be a non-empty string"},
+		{`ospf: {area 0: {authentication: cryptographic, key 1: {algorithm: md5}}}`, "ospf area 0 key 1: secret is required"},
+		{`ospf: {area 0: {authentication: cryptographic, key 1: {secret: "12345678901234567"}}}`, "ospf area 0 key 1: md5 secret longer than 16 bytes"},
+		{`ospf: {area 0: {authentication: cryptographic, key 1: {secret: foo, send-start: tomorrow}}}`, "ospf area 0 key 1: send-start must be an RFC 3339 timestamp"},
+		{`ospf: {area 0: {authentication: cryptographic, key 1: {secret: foo, accept-stop: 1704067200}}}`, "ospf area 0 key 1: accept-stop must be an RFC 3339 timestamp"},
+		{`ospf: {area 0: {authentication: cryptographic, key 1: {secret: foo, send-start: "2024-01-02T00:00:00Z", send-stop: "2024-01-01T00:00:00Z"}}}`, "ospf area 0 key 1: send-stop is before send-start"},
+		{`ospf: {area 0: {authentication: cryptographic, key 1: {secret: foo, accept-start: "2024-01-02T00:00:00Z", accept-stop: "2024-01-01T00:00:00Z"}}}`, "ospf area 0 key 1: accept-stop is before accept-start"},
+		{`ospf: {area 0: {authentication: cryptographic, key 1: {secret: foo, lifetime: 10}}}`, "ospf area 0 key 1: unknown key: lifetime"},
+
+		// Graceful restart
+		{`ospf: {graceful-restart: true, area 0: {}}`, "ospf: graceful-restart must be a map"},
+		{`ospf: {graceful-restart: {enabled: 1}, area 0: {}}`, "ospf graceful-restart: enabled must be a boolean"},
+		{`ospf: {graceful-restart: {grace-period: 0}, area 0: {}}`, "ospf graceful-restart: grace-period too small: 0"},
+		{`ospf: {graceful-restart: {grace-period: 1801}, area 0: {}}`, "ospf graceful-restart: grace-period too big: 1801"},
+		{`ospf: {graceful-restart: {helper: 1}, area 0: {}}`, "ospf graceful-restart: helper must be a boolean"},
+		{`ospf: {graceful-restart: {strict-lsa-checking: 1}, area 0: {}}`, "ospf graceful-restart: strict-lsa-checking must be a boolean"},
+		{`ospf: {graceful-restart: {restart-time: 1}, area 0: {}}`, "ospf graceful-restart: unknown key: restart-time"},
+
+		// Max-metric
+		{`ospf: {max-metric router-lsa: true, area 0: {}}`, "ospf: max-metric router-lsa must be a map"},
+		{`ospf: {max-metric router-lsa: {administrative: 1}, area 0: {}}`, "ospf max-metric router-lsa: administrative must be a boolean"},
+		{`ospf: {max-metric router-lsa: {on-startup: 4}, area 0: {}}`, "ospf max-metric router-lsa: on-startup too small: 4"},
+		{`ospf: {max-metric router-lsa: {on-startup: 86401}, area 0: {}}`, "ospf max-metric router-lsa: on-startup too big: 86401"},
+		{`ospf: {max-metric router-lsa: {on-shutdown: 10}, area 0: {}}`, "ospf max-metric router-lsa: unknown key: on-shutdown"},
+
+		// Throttles
+		{`ospf: {spf-throttle: 100, area 0: {}}`, "ospf: spf-throttle must be a map"},
+		{`ospf: {lsa-throttle: 100, area 0: {}}`, "ospf: lsa-throttle must be a map"},
+		{`ospf: {spf-throttle: {hold: 1s}, area 0: {}}`, "ospf spf-throttle: hold must be an integer"},
+		{`ospf: {spf-throttle: {initial-delay: -1}, area 0: {}}`, "ospf spf-throttle: initial-delay must not be negative: -1"},
+		{`ospf: {spf-throttle: {max-wait: 600001}, area 0: {}}`, "ospf spf-throttle: max-wait too big: 600001"},
+		{`ospf: {spf-throttle: {hold: 20000}, area 0: {}}`, "ospf spf-throttle: hold can't be longer than max-wait"},
+		{`ospf: {spf-throttle: {initial-delay: 2000, max-wait: 1000, hold: 500}, area 0: {}}`, "ospf spf-throttle: initial-delay can't be longer than max-wait"},
+		{`ospf: {spf-throttle: {delay: 1}, area 0: {}}`, "ospf spf-throttle: unknown key: delay"},
+		{`ospf: {lsa-throttle: {hold: 999}, area 0: {}}`, "ospf lsa-throttle: hold must be at least 1000: 999"},
+		{`ospf: {lsa-throttle: {max-wait: 1000}, area 0: {}}`, "ospf lsa-throttle: hold can't be longer than max-wait"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseOSPF(tt.yaml)
+		if err == nil {
+			t.Errorf("%s: expected error %q", tt.yaml, tt.err)
+		} else if err.Error() != tt.err {
+			t.Errorf("%s: expected error %q, got %q", tt.yaml, tt.err, err)
+		}
+	}
+}
+
+func TestParseOSPFConfigDuplicateVirtualLink(t *testing.T) {
+	_, err := parseOSPF(`ospf: {area 0: {}, area 1: {virtual-link 2.2.2.2: null}, area 2: {virtual-link 2.2.2.2: null}}`)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// Areas are parsed in map order, so either one can be reported first.
+	a := "ospf: virtual link to 2.2.2.2 configured in areas 0.0.0.1 and 0.0.0.2"
+	b := "ospf: virtual link to 2.2.2.2 configured in areas 0.0.0.2 and 0.0.0.1"
+	if err.Error() != a && err.Error() != b {
+		t.Fatalf("expected %q, got %q", a, err)
+	}
+}
+
+// TestOSPFConfigCopy checks that changing a copy leaves the original alone,
+// and that the copy keeps the original's settings.
+func TestOSPFConfigCopy(t *testing.T) {
+	tests := []struct {
+		name   string
+		yaml   string
+		modify func(c *OSPFConfig)
+		check  func(t *testing.T, c *OSPFConfig)
+	}{
+		{
+			name: "ranges",
+			yaml: `
+ospf:
+  area 0:
+    range 10.1.0.0/16:
+`,
+			modify: func(c *OSPFConfig) {
+				c.Areas[0].AddressRanges[netip.MustParsePrefix("10.1.0.0/16")] = OSPFAddressRangeConfig{Advertise: false}
+			},
+			check: func(t *testing.T, c *OSPFConfig) {
+				if !c.Areas[0].AddressRanges[netip.MustParsePrefix("10.1.0.0/16")].Advertise {
+					t.Errorf("expected address ranges to be copied")
+				}
+			},
+		},
+		{
+			name: "stub areas",
+			yaml: `
+ospf:
+  area 0: {}
+  area 1:
+    stub: true
+    no-summary: true
+    default-cost: 5
+`,
+			modify: func(c *OSPFConfig) {},
+			check: func(t *testing.T, c *OSPFConfig) {
+				if a := c.copy().(*OSPFConfig).Areas[1]; !a.Stub || !a.NoSummary || a.DefaultCost != 5 {
+					t.Errorf("expected stub settings to be copied, got %+v", a)
+				}
+			},
+		},
+		{
+			name: "NSSAs",
+			yaml: `
+ospf:
+  area 0: {}
+  area 1:
+    nssa: true
+    translator-role: always
+    default-originate: true
+`,
+			modify: func(c *OSPFConfig) {},
+			check: func(t *testing.T, c *OSPFConfig) {
+				if a := c.copy().(*OSPFConfig).Areas[1]; !a.NSSA || a.TranslatorRole != NSSATranslatorAlways || !a.DefaultOriginate {
+					t.Errorf("expected NSSA settings to be copied, got %+v", a)
+				}
+			},
+		},
+		{
+			name: "virtual links",
+			yaml: `
+ospf:
+  area 0: {}
+  area 1:
+    virtual-link 2.2.2.2:
+`,
+			modify: func(c *OSPFConfig) {
+				c.Areas[1].VirtualLinks[common.RouterID(0x03030303)] = OSPFVirtualLinkConfig{}
+			},
+			check: func(t *testing.T, c *OSPFConfig) {
+				if len(c.Areas[1].VirtualLinks) != 1 {
+					t.Errorf("expected virtual links to be copied")
+				}
+			},
+		},
+		{
+			name: "neighbors",
+			yaml: `
+ospf:
+  area 0:
+    interface eth0:
+      neighbor 10.0.0.2:
+`,
+			modify: func(c *OSPFConfig) {
+				c.Areas[0].Interfaces["eth0"].Neighbors[netip.MustParseAddr("10.0.0.2")] = OSPFNeighborConfig{Priority: 5}
+			},
+			check: func(t *testing.T, c *OSPFConfig) {
+				if c.Areas[0].Interfaces["eth0"].Neighbors[netip.MustParseAddr("10.0.0.2")].Priority != 1 {
+					t.Errorf("expected neighbors to be copied")
+				}
+			},
+		},
+		{
+			name: "authentication",
+			yaml: `
+ospf:
+  area 0:
+    authentication: cryptographic
+    key 1:
+      secret: foo
+    interface eth0: {}
+  area 1:
+    virtual-link 2.2.2.2:
+`,
+			modify: func(c *OSPFConfig) {
+				c.Areas[0].Authentication.Keys[1] = OSPFAuthKeyConfig{Secret: "bar"}
+				c.Areas[0].Interfaces["eth0"].Authentication.Keys[1] = OSPFAuthKeyConfig{Secret: "bar"}
+				c.Areas[1].VirtualLinks[common.RouterID(0x02020202)].Authentication.Keys[1] = OSPFAuthKeyConfig{Secret: "bar"}
+			},
+			check: func(t *testing.T, c *OSPFConfig) {
+				if c.Areas[0].Authentication.Keys[1].Secret != "foo" {
+					t.Errorf("expected area authentication to be copied")
+				}
+
+				if c.Areas[0].Interfaces["eth0"].Authentication.Keys[1].Secret != "foo" {
+					t.Errorf("expected interface authentication to be copied")
+				}
+
+				if c.Areas[1].VirtualLinks[common.RouterID(0x02020202)].Authentication.Keys[1].Secret != "foo" {
+					t.Errorf("expected virtual link authentication to be copied")
+				}
+			},
+		},
+		{
+			name: "passive",
+			yaml: `
+ospf:
+  passive-default: true
+  area 0:
+    interface eth0:
+      passive: false
+`,
+			modify: func(c *OSPFConfig) {},
+			check: func(t *testing.T, c *OSPFConfig) {
+				copied := c.copy().(*OSPFConfig)
+
+				if !copied.PassiveDefault {
+					t.Errorf("expected passive-default to be copied")
+				}
+
+				// Without passiveSet, passive-default would override the
+				// interface's own setting.
+				if ic := copied.Areas[0].Interfaces["eth0"]; ic.Passive || !ic.passiveSet {
+					t.Errorf("expected passive and passiveSet to be copied, got %+v", ic)
+				}
+			},
+		},
+		{
+			name:   "graceful restart",
+			yaml:   `ospf: {graceful-restart: {enabled: true, grace-period: 60}, area 0: {}}`,
+			modify: func(c *OSPFConfig) {},
+			check: func(t *testing.T, c *OSPFConfig) {
+				if gr := c.copy().(*OSPFConfig).GracefulRestart; gr != c.GracefulRestart {
+					t.Errorf("expected graceful-restart to be copied, got %+v", gr)
+				}
+			},
+		},
+		{
+			name:   "max-metric",
+			yaml:   `ospf: {max-metric router-lsa: {administrative: true, on-startup: 60}, area 0: {}}`,
+			modify: func(c *OSPFConfig) {},
+			check: func(t *testing.T, c *OSPFConfig) {
+				if mm := c.copy().(*OSPFConfig).MaxMetric; mm != c.MaxMetric {
+					t.Errorf("expected max-metric to be copied, got %+v", mm)
+				}
+			},
+		},
+		{
+			name:   "throttles",
+			yaml:   `ospf: {spf-throttle: {hold: 2000}, lsa-throttle: {hold: 2000}, area 0: {}}`,
+			modify: func(c *OSPFConfig) {},
+			check: func(t *testing.T, c *OSPFConfig) {
+				copied := c.copy().(*OSPFConfig)
+
+				if copied.SPFThrottle != c.SPFThrottle || copied.LSAThrottle != c.LSAThrottle {
+					t.Errorf("expected throttles to be copied, got %+v and %+v", copied.SPFThrottle, copied.LSAThrottle)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseOSPF(tt.yaml)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.modify(c.copy().(*OSPFConfig))
+			tt.check(t, c)
+		})
+	}
+}
@@ -2,27 +2,433 @@ package netmon
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"time"
 
 	"github.com/davidbalbert/chatter/chatterd/services"
 	"github.com/davidbalbert/chatter/sync"
+	"go4.org/netipx"
 )
 
+// How long to wait after the first event in a burst before notifying
+// listeners.
+const batchInterval = 200 * time.Millisecond
+
 type platformMonitor interface {
 	run(context.Context, *Monitor) error
 }
 
+// A snapshot of a network interface's state.
+type Interface struct {
+	Index        int
+	Name         string
+	MTU          int
+	HardwareAddr net.HardwareAddr
+	Flags        net.Flags
+	Prefixes     []netip.Prefix // sorted
+}
+
+func (i Interface) IsUp() bool {
+	return i.Flags&net.FlagUp != 0
+}
+
+func (i Interface) IsLoopback() bool {
+	return i.Flags&net.FlagLoopback != 0
+}
+
+func (i Interface) PrefixesV4() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, p := range i.Prefixes {
+		if p.Addr().Is4() {
+			prefixes = append(prefixes, p)
+		}
+	}
+
+	return prefixes
+}
+
+func (i Interface) hasPrefix(prefix netip.Prefix) bool {
+	for _, p := range i.Prefixes {
+		if p == prefix {
+			return true
+		}
+	}
+
+	return false
+}
+
+// All interfaces at a single point in time, sorted by index.
+type Snapshot []Interface
+
+func (s Snapshot) Get(name string) (Interface, bool) {
+	for _, iface := range s {
+		if iface.Name == name {
+			return iface, true
+		}
+	}
+
+	return Interface{}, false
+}
+
+type EventType int
+
+const (
+	EventLinkAdded EventType = iota
+	EventLinkRemoved
+	EventLinkUp
+	EventLinkDown
+	EventAddrAdded
+	EventAddrRemoved
+	EventMTUChanged
+	EventFlagsChanged
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventLinkAdded:
+		return "LinkAdded"
+	case EventLinkRemoved:
+		return "LinkRemoved"
+	case EventLinkUp:
+		return "LinkUp"
+	case EventLinkDown:
+		return "LinkDown"
+	case EventAddrAdded:
+		return "AddrAdded"
+	case EventAddrRemoved:
+		return "AddrRemoved"
+	case EventMTUChanged:
+		return "MTUChanged"
+	case EventFlagsChanged:
+		return "FlagsChanged"
+	default:
+		return "Unknown"
+	}
+}
+
+type Event struct {
+	Type EventType
+
+	// The state of the interface after the change. For EventLinkRemoved,
+	// this is the last known state of the interface.
+	Interface Interface
+
+	// The address that was added or removed. Only valid for EventAddrAdded
+	// and EventAddrRemoved.
+	Prefix netip.Prefix
+}
+
+func (e Event) String() string {
+	if e.Type == EventAddrAdded || e.Type == EventAddrRemoved {
+		return fmt.Sprintf("%s %s %s", e.Interface.Name, e.Type, e.Prefix)
+	}
+
+	return fmt.Sprintf("%s %s", e.Interface.Name, e.Type)
+}
+
+type state struct {
+	interfaces map[int]Interface
+}
+
+// Monitor keeps a database of the system's network interfaces, and publishes
+// a typed Event for every change. The platformMonitor tells the Monitor when
+// something might have changed, and the Monitor figures out what.
 type Monitor struct {
-	*sync.SimpleNotifier
-	p platformMonitor
+	p      platformMonitor
+	st     chan state
+	events *sync.QueuedNotifier[Event]
+
+	// Returns the current state of all interfaces. Replaceable for testing.
+	netifs func() ([]Interface, error)
 }
 
 func New(serviceManager *services.ServiceManager, conf any) (services.Runner, error) {
-	return &Monitor{
-		SimpleNotifier: sync.NewSimpleNotifier(),
-		p:              newPlatformMonitor(),
-	}, nil
+	return newMonitor(newPlatformMonitor(), systemInterfaces)
+}
+
+func newMonitor(p platformMonitor, netifs func() ([]Interface, error)) (*Monitor, error) {
+	st := make(chan state, 1)
+	st <- state{interfaces: make(map[int]Interface)}
+
+	m := &Monitor{
+		p:      p,
+		st:     st,
+		events: sync.NewQueuedNotifier[Event](),
+		netifs: netifs,
+	}
+
+	err := m.refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
 }
 
 func (m *Monitor) Run(ctx context.Context) error {
 	return m.p.run(ctx, m)
 }
+
+// Snapshot returns the current state of all interfaces.
+func (m *Monitor) Snapshot() Snapshot {
+	st := <-m.st
+	defer func() {
+		m.st <- st
+	}()
+
+	return st.snapshot()
+}
+
+// Subscribe returns the current state of all interfaces along with a
+// Subscription that will receive every change made after the snapshot was
+// taken. Callers must Close the subscription when they're done with it.
+func (m *Monitor) Subscribe() (Snapshot, *Subscription) {
+	st := <-m.st
+	defer func() {
+		m.st <- st
+	}()
+
+	return st.snapshot(), &Subscription{m: m, t: m.events.Register()}
+}
+
+type Subscription struct {
+	m *Monitor
+	t sync.Token
+}
+
+// Next blocks until the next event is available, ctx is canceled, or the
+// subscription is closed.
+func (s *Subscription) Next(ctx context.Context) (Event, error) {
+	e, ok := s.m.events.AwaitChange(ctx, s.t)
+	if ctx.Err() != nil {
+		return Event{}, ctx.Err()
+	} else if !ok {
+		return Event{}, fmt.Errorf("subscription closed")
+	}
+
+	return e, nil
+}
+
+// Close stops the subscription. Calls to Next, including ones that are
+// blocked, return an error.
+func (s *Subscription) Close() {
+	s.m.events.Unregister(s.t)
+}
+
+// refresh re-reads the state of all interfaces and publishes an event for
+// each difference from the last known state.
+func (m *Monitor) refresh() error {
+	netifs, err := m.netifs()
+	if err != nil {
+		return err
+	}
+
+	st := <-m.st
+	defer func() {
+		m.st <- st
+	}()
+
+	interfaces := make(map[int]Interface, len(netifs))
+	for _, netif := range netifs {
+		interfaces[netif.Index] = netif
+	}
+
+	for _, e := range diff(st.interfaces, interfaces) {
+		m.events.NotifyChange(e)
+	}
+
+	st.interfaces = interfaces
+
+	return nil
+}
+
+// notify is called by platform monitors when interfaces may have changed.
+func (m *Monitor) notify() {
+	err := m.refresh()
+	if err != nil {
+		fmt.Printf("interface monitor: %v\n", err)
+	}
+}
+
+func (st state) snapshot() Snapshot {
+	s := make(Snapshot, 0, len(st.interfaces))
+	for _, iface := range st.interfaces {
+		s = append(s, iface)
+	}
+
+	sort.Slice(s, func(i, j int) bool {
+		return s[i].Index < s[j].Index
+	})
+
+	return s
+}
+
+func sortedIndices(interfaces ...map[int]Interface) []int {
+	seen := make(map[int]bool)
+	var indices []int
+
+	for _, m := range interfaces {
+		for index := range m {
+			if !seen[index] {
+				seen[index] = true
+				indices = append(indices, index)
+			}
+		}
+	}
+
+	sort.Ints(indices)
+
+	return indices
+}
+
+func diff(old, new map[int]Interface) []Event {
+	var events []Event
+
+	for _, index := range sortedIndices(old, new) {
+		o, inOld := old[index]
+		n, inNew := new[index]
+
+		// An index that's been reused by a different interface is a
+		// removal followed by an addition.
+		if inOld && inNew && o.Name != n.Name {
+			events = append(events, Event{Type: EventLinkRemoved, Interface: o})
+			events = append(events, Event{Type: EventLinkAdded, Interface: n})
+			continue
+		}
+
+		if !inNew {
+			events = append(events, Event{Type: EventLinkRemoved, Interface: o})
+			continue
+		}
+
+		if !inOld {
+			events = append(events, Event{Type: EventLinkAdded, Interface: n})
+			continue
+		}
+
+		if !o.IsUp() && n.IsUp() {
+			events = append(events, Event{Type: EventLinkUp, Interface: n})
+		} else if o.IsUp() && !n.IsUp() {
+			events = append(events, Event{Type: EventLinkDown, Interface: n})
+		}
+
+		if o.Flags&^net.FlagUp != n.Flags&^net.FlagUp {
+			events = append(events, Event{Type: EventFlagsChanged, Interface: n})
+		}
+
+		if o.MTU != n.MTU {
+			events = append(events, Event{Type: EventMTUChanged, Interface: n})
+		}
+
+		for _, p := range o.Prefixes {
+			if !n.hasPrefix(p) {
+				events = append(events, Event{Type: EventAddrRemoved, Interface: n, Prefix: p})
+			}
+		}
+
+		for _, p := range n.Prefixes {
+			if !o.hasPrefix(p) {
+				events = append(events, Event{Type: EventAddrAdded, Interface: n, Prefix: p})
+			}
+		}
+	}
+
+	return events
+}
+
+func systemInterfaces() ([]Interface, error) {
+	netifs, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interfaces: %w", err)
+	}
+
+	interfaces := make([]Interface, len(netifs))
+
+	for i, netif := range netifs {
+		addrs, err := netif.Addrs()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get addresses for interface %s: %w", netif.Name, err)
+		}
+
+		var prefixes []netip.Prefix
+		for _, addr := range addrs {
+			prefix, ok := prefixFromSTDNetAddr(addr)
+			if ok {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+
+		sort.Slice(prefixes, func(i, j int) bool {
+			return prefixes[i].Addr().Less(prefixes[j].Addr())
+		})
+
+		interfaces[i] = Interface{
+			Index:        netif.Index,
+			Name:         netif.Name,
+			MTU:          netif.MTU,
+			HardwareAddr: netif.HardwareAddr,
+			Flags:        netif.Flags,
+			Prefixes:     prefixes,
+		}
+	}
+
+	return interfaces, nil
+}
+
+// net.Interface.Addrs() returns []net.Addr which is really
+// []*net.IPNet.
+func prefixFromSTDNetAddr(addr net.Addr) (netip.Prefix, bool) {
+	ipnet, ok := addr.(*net.IPNet)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+
+	prefix, ok := netipx.FromStdIPNet(ipnet)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+
+	return prefix, true
+}
+
+// batchEvents calls notify once for every burst of values received on events.
+// After receiving an event, we wait for batchInterval for other events to
+// accumulate before calling notify. If there's a pending notification when
+// ctx is canceled, notify is called before returning.
+func batchEvents(ctx context.Context, events <-chan struct{}, notify func()) {
+	var (
+		notifyTimer *time.Timer
+		notifyCh    <-chan time.Time
+		pending     bool
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if notifyTimer != nil && !notifyTimer.Stop() {
+				<-notifyTimer.C
+			}
+
+			if pending {
+				notify()
+			}
+
+			return
+		case <-events:
+			pending = true
+
+			if notifyTimer == nil {
+				notifyTimer = time.NewTimer(batchInterval)
+				notifyCh = notifyTimer.C
+			}
+		case <-notifyCh:
+			notify()
+			pending = false
+			notifyCh = nil
+			notifyTimer = nil
+		}
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"io"
 	"os/exec"
 	"strings"
-	"time"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -37,7 +36,7 @@ func (m *macosMonitor) run(ctx context.Context, interfaceMonitor *Monitor) error
 		return fmt.Errorf("failed to get scutil stdout pipe: %w", err)
 	}
 
-	events := make(chan struct{})
+	events := make(chan struct{}, 1)
 
 	g.Go(func() error {
 		defer stdin.Close()
@@ -83,42 +82,8 @@ func (m *macosMonitor) run(ctx context.Context, interfaceMonitor *Monitor) error
 	})
 
 	g.Go(func() error {
-		// We want to batch events together, so we wait after
-		// receiving an event for other events to accumulate before
-		// notifying listeners.
-
-		var (
-			notifyTimer *time.Timer
-			notifyCh    <-chan time.Time
-			pending     bool
-		)
-
-		for {
-			select {
-			case <-ctx.Done():
-				if notifyTimer != nil && !notifyTimer.Stop() {
-					<-notifyTimer.C
-				}
-
-				if pending {
-					interfaceMonitor.NotifyChange()
-				}
-
-				return nil
-			case <-events:
-				pending = true
-
-				if notifyTimer == nil {
-					notifyTimer = time.NewTimer(200 * time.Millisecond)
-					notifyCh = notifyTimer.C
-				}
-			case <-notifyCh:
-				interfaceMonitor.NotifyChange()
-				pending = false
-				notifyCh = nil
-				notifyTimer = nil
-			}
-		}
+		batchEvents(ctx, events, interfaceMonitor.notify)
+		return nil
 	})
 
 	g.Go(func() error {
@@ -0,0 +1,148 @@
+package netmon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sys/unix"
+)
+
+// A source of netlink messages. In production this is a NETLINK_ROUTE socket,
+// but tests can substitute a fake so they don't need a real socket.
+type netlinkSource interface {
+	receive() ([]syscall.NetlinkMessage, error)
+	close() error
+}
+
+type linuxMonitor struct {
+	newSource func() (netlinkSource, error)
+}
+
+func newPlatformMonitor() platformMonitor {
+	return &linuxMonitor{
+		newSource: newNetlinkSocket,
+	}
+}
+
+func (m *linuxMonitor) run(ctx context.Context, interfaceMonitor *Monitor) error {
+	src, err := m.newSource()
+	if err != nil {
+		return err
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	events := make(chan struct{}, 1)
+
+	// Anything that changed between the Monitor's initial refresh and
+	// subscribing to netlink notifications would otherwise be missed.
+	events <- struct{}{}
+
+	g.Go(func() error {
+		// Closing the source unblocks receive().
+		<-ctx.Done()
+		return src.close()
+	})
+
+	g.Go(func() error {
+		for {
+			msgs, err := src.receive()
+			if ctx.Err() != nil {
+				return nil
+			} else if errors.Is(err, unix.ENOBUFS) {
+				// The kernel dropped messages because we weren't reading
+				// fast enough. We don't know what we missed, so treat it
+				// as a change.
+				msgs = nil
+			} else if err != nil {
+				return fmt.Errorf("failed to read from netlink socket: %w", err)
+			}
+
+			changed := err != nil
+			for _, msg := range msgs {
+				if isInterfaceChange(msg) {
+					changed = true
+				}
+			}
+
+			if changed {
+				select {
+				case events <- struct{}{}:
+				default:
+				}
+			}
+		}
+	})
+
+	g.Go(func() error {
+		batchEvents(ctx, events, interfaceMonitor.notify)
+		return nil
+	})
+
+	return g.Wait()
+}
+
+func isInterfaceChange(msg syscall.NetlinkMessage) bool {
+	switch msg.Header.Type {
+	case unix.RTM_NEWLINK, unix.RTM_DELLINK, unix.RTM_NEWADDR, unix.RTM_DELADDR:
+		return true
+	default:
+		return false
+	}
+}
+
+type netlinkSocket struct {
+	f   *os.File
+	buf []byte
+}
+
+func newNetlinkSocket() (netlinkSource, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, unix.NETLINK_ROUTE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create netlink socket: %w", err)
+	}
+
+	err = unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK})
+	if err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to bind netlink socket: %w", err)
+	}
+
+	groups := []int{unix.RTNLGRP_LINK, unix.RTNLGRP_IPV4_IFADDR, unix.RTNLGRP_IPV6_IFADDR}
+	for _, group := range groups {
+		err := unix.SetsockoptInt(fd, unix.SOL_NETLINK, unix.NETLINK_ADD_MEMBERSHIP, group)
+		if err != nil {
+			unix.Close(fd)
+			return nil, fmt.Errorf("failed to join netlink group %d: %w", group, err)
+		}
+	}
+
+	// Wrapping the non-blocking fd in an *os.File registers it with the
+	// runtime poller, which lets close() interrupt a blocked read.
+	return &netlinkSocket{
+		f:   os.NewFile(uintptr(fd), "netlink"),
+		buf: make([]byte, 64*1024),
+	}, nil
+}
+
+func (s *netlinkSocket) receive() ([]syscall.NetlinkMessage, error) {
+	n, err := s.f.Read(s.buf)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs, err := syscall.ParseNetlinkMessage(s.buf[:n])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse netlink message: %w", err)
+	}
+
+	return msgs, nil
+}
+
+func (s *netlinkSocket) close() error {
+	return s.f.Close()
+}
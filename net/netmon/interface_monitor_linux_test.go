@@ -0,0 +1,176 @@
+package netmon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+type fakeNetlinkSource struct {
+	msgs   chan []syscall.NetlinkMessage
+	errs   chan error
+	closed chan struct{}
+}
+
+func newFakeNetlinkSource() *fakeNetlinkSource {
+	return &fakeNetlinkSource{
+		msgs:   make(chan []syscall.NetlinkMessage),
+		errs:   make(chan error),
+		closed: make(chan struct{}),
+	}
+}
+
+func (s *fakeNetlinkSource) receive() ([]syscall.NetlinkMessage, error) {
+	select {
+	case msgs := <-s.msgs:
+		return msgs, nil
+	case err := <-s.errs:
+		return nil, err
+	case <-s.closed:
+		return nil, os.ErrClosed
+	}
+}
+
+func (s *fakeNetlinkSource) close() error {
+	close(s.closed)
+	return nil
+}
+
+func (s *fakeNetlinkSource) send(types ...uint16) {
+	msgs := make([]syscall.NetlinkMessage, len(types))
+	for i, t := range types {
+		msgs[i] = syscall.NetlinkMessage{Header: syscall.NlMsghdr{Type: t}}
+	}
+
+	s.msgs <- msgs
+}
+
+func startFakeMonitor(t *testing.T, ctx context.Context) (*Monitor, *fakeNetlinkSource, *fakeInterfaces, chan error) {
+	src := newFakeNetlinkSource()
+	netifs := newFakeInterfaces(eth0)
+
+	p := &linuxMonitor{
+		newSource: func() (netlinkSource, error) {
+			return src, nil
+		},
+	}
+
+	m, err := newMonitor(p, netifs.get)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Run(ctx)
+	}()
+
+	// Wait for the refresh that happens after subscribing to netlink.
+	time.Sleep(2 * batchInterval)
+
+	return m, src, netifs, done
+}
+
+func TestLinuxMonitorPublishesInterfaceChanges(t *testing.T) {
+	types := []uint16{unix.RTM_NEWLINK, unix.RTM_DELLINK, unix.RTM_NEWADDR, unix.RTM_DELADDR}
+
+	for _, typ := range types {
+		ctx, cancel := context.WithCancel(context.Background())
+		m, src, netifs, done := startFakeMonitor(t, ctx)
+
+		_, sub := m.Subscribe()
+
+		changed := eth0
+		changed.MTU = 9000
+		netifs.set(changed)
+
+		src.send(typ)
+
+		nextCtx, nextCancel := context.WithTimeout(ctx, 2*time.Second)
+		e, err := sub.Next(nextCtx)
+		nextCancel()
+		if err != nil {
+			t.Fatalf("%d: %v", typ, err)
+		}
+
+		if e.Type != EventMTUChanged || e.Interface.MTU != 9000 {
+			t.Fatalf("%d: unexpected event: %v", typ, e)
+		}
+
+		sub.Close()
+		cancel()
+
+		if err := <-done; err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	}
+}
+
+func TestLinuxMonitorBatchesEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, src, netifs, _ := startFakeMonitor(t, ctx)
+
+	calls := netifs.callCount()
+
+	src.send(unix.RTM_NEWLINK)
+	src.send(unix.RTM_NEWADDR, unix.RTM_NEWADDR)
+	src.send(unix.RTM_DELADDR)
+
+	time.Sleep(2 * batchInterval)
+
+	if n := netifs.callCount() - calls; n != 1 {
+		t.Fatalf("expected one refresh, got %d", n)
+	}
+}
+
+func TestLinuxMonitorIgnoresOtherMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, src, netifs, _ := startFakeMonitor(t, ctx)
+
+	calls := netifs.callCount()
+
+	src.send(unix.RTM_NEWROUTE, unix.RTM_DELROUTE, unix.RTM_NEWNEIGH)
+
+	time.Sleep(2 * batchInterval)
+
+	if netifs.callCount() != calls {
+		t.Fatalf("expected no refresh")
+	}
+}
+
+func TestLinuxMonitorRefreshesOnOverrun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, src, netifs, _ := startFakeMonitor(t, ctx)
+
+	calls := netifs.callCount()
+
+	src.errs <- os.NewSyscallError("read", unix.ENOBUFS)
+
+	time.Sleep(2 * batchInterval)
+
+	if n := netifs.callCount() - calls; n != 1 {
+		t.Fatalf("expected one refresh, got %d", n)
+	}
+}
+
+func TestLinuxMonitorReturnsReadErrors(t *testing.T) {
+	_, src, _, done := startFakeMonitor(t, context.Background())
+
+	readErr := errors.New("boom")
+	src.errs <- readErr
+
+	if err := <-done; !errors.Is(err, readErr) {
+		t.Fatalf("expected %v, got %v", readErr, err)
+	}
+}
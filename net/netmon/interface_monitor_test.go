@@ -0,0 +1,275 @@
+package netmon
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"reflect"
+	gosync "sync"
+	"testing"
+	"time"
+)
+
+var (
+	lo0 = Interface{
+		Index:    1,
+		Name:     "lo0",
+		MTU:      16384,
+		Flags:    net.FlagUp | net.FlagLoopback,
+		Prefixes: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/8")},
+	}
+
+	eth0 = Interface{
+		Index:    2,
+		Name:     "eth0",
+		MTU:      1500,
+		Flags:    net.FlagUp | net.FlagBroadcast | net.FlagMulticast,
+		Prefixes: []netip.Prefix{netip.MustParsePrefix("10.0.0.1/24")},
+	}
+)
+
+type fakeInterfaces struct {
+	mu         gosync.Mutex
+	interfaces []Interface
+	calls      int
+}
+
+func newFakeInterfaces(interfaces ...Interface) *fakeInterfaces {
+	return &fakeInterfaces{interfaces: interfaces}
+}
+
+func (f *fakeInterfaces) get() ([]Interface, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	return f.interfaces, nil
+}
+
+func (f *fakeInterfaces) set(interfaces ...Interface) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.interfaces = interfaces
+}
+
+func (f *fakeInterfaces) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls
+}
+
+type nopPlatformMonitor struct{}
+
+func (nopPlatformMonitor) run(ctx context.Context, m *Monitor) error {
+	<-ctx.Done()
+	return nil
+}
+
+func eventTypes(events []Event) []EventType {
+	types := make([]EventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+
+	return types
+}
+
+func TestDiffAddAndRemove(t *testing.T) {
+	events := diff(map[int]Interface{1: lo0}, map[int]Interface{2: eth0})
+
+	expected := []EventType{EventLinkRemoved, EventLinkAdded}
+	if !reflect.DeepEqual(eventTypes(events), expected) {
+		t.Fatalf("expected %v, got %v", expected, eventTypes(events))
+	}
+
+	if events[0].Interface.Name != "lo0" || events[1].Interface.Name != "eth0" {
+		t.Fatalf("unexpected events: %v", events)
+	}
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	events := diff(map[int]Interface{1: lo0, 2: eth0}, map[int]Interface{1: lo0, 2: eth0})
+
+	if len(events) != 0 {
+		t.Fatalf("expected no events, got %v", events)
+	}
+}
+
+func TestDiffUpDown(t *testing.T) {
+	down := eth0
+	down.Flags &^= net.FlagUp
+
+	events := diff(map[int]Interface{2: eth0}, map[int]Interface{2: down})
+	if !reflect.DeepEqual(eventTypes(events), []EventType{EventLinkDown}) {
+		t.Fatalf("unexpected events: %v", events)
+	}
+
+	events = diff(map[int]Interface{2: down}, map[int]Interface{2: eth0})
+	if !reflect.DeepEqual(eventTypes(events), []EventType{EventLinkUp}) {
+		t.Fatalf("unexpected events: %v", events)
+	}
+}
+
+func TestDiffFlagsAndMTU(t *testing.T) {
+	changed := eth0
+	changed.Flags |= net.FlagLoopback
+	changed.MTU = 9000
+
+	events := diff(map[int]Interface{2: eth0}, map[int]Interface{2: changed})
+
+	expected := []EventType{EventFlagsChanged, EventMTUChanged}
+	if !reflect.DeepEqual(eventTypes(events), expected) {
+		t.Fatalf("expected %v, got %v", expected, eventTypes(events))
+	}
+
+	if events[1].Interface.MTU != 9000 {
+		t.Fatalf("expected new MTU in event, got %d", events[1].Interface.MTU)
+	}
+}
+
+func TestDiffAddresses(t *testing.T) {
+	changed := eth0
+	changed.Prefixes = []netip.Prefix{
+		netip.MustParsePrefix("10.0.1.1/24"),
+		netip.MustParsePrefix("fe80::1/64"),
+	}
+
+	events := diff(map[int]Interface{2: eth0}, map[int]Interface{2: changed})
+
+	expected := []Event{
+		{Type: EventAddrRemoved, Interface: changed, Prefix: netip.MustParsePrefix("10.0.0.1/24")},
+		{Type: EventAddrAdded, Interface: changed, Prefix: netip.MustParsePrefix("10.0.1.1/24")},
+		{Type: EventAddrAdded, Interface: changed, Prefix: netip.MustParsePrefix("fe80::1/64")},
+	}
+
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected %v, got %v", expected, events)
+	}
+}
+
+func TestDiffIndexReused(t *testing.T) {
+	renamed := eth0
+	renamed.Name = "eth1"
+
+	events := diff(map[int]Interface{2: eth0}, map[int]Interface{2: renamed})
+
+	expected := []EventType{EventLinkRemoved, EventLinkAdded}
+	if !reflect.DeepEqual(eventTypes(events), expected) {
+		t.Fatalf("expected %v, got %v", expected, eventTypes(events))
+	}
+}
+
+func TestMonitorSnapshot(t *testing.T) {
+	m, err := newMonitor(nopPlatformMonitor{}, newFakeInterfaces(eth0, lo0).get)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := m.Snapshot()
+	if !reflect.DeepEqual(snapshot, Snapshot{lo0, eth0}) {
+		t.Fatalf("unexpected snapshot: %v", snapshot)
+	}
+
+	iface, ok := snapshot.Get("eth0")
+	if !ok || iface.Index != 2 {
+		t.Fatalf("expected to find eth0, got %v", iface)
+	}
+
+	if _, ok := snapshot.Get("eth1"); ok {
+		t.Fatalf("expected not to find eth1")
+	}
+}
+
+func TestMonitorSubscribe(t *testing.T) {
+	netifs := newFakeInterfaces(lo0)
+
+	m, err := newMonitor(nopPlatformMonitor{}, netifs.get)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, sub := m.Subscribe()
+	defer sub.Close()
+
+	if !reflect.DeepEqual(snapshot, Snapshot{lo0}) {
+		t.Fatalf("unexpected snapshot: %v", snapshot)
+	}
+
+	netifs.set(lo0, eth0)
+	m.notify()
+
+	netifs.set(eth0)
+	m.notify()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	e, err := sub.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Type != EventLinkAdded || e.Interface.Name != "eth0" {
+		t.Fatalf("unexpected event: %v", e)
+	}
+
+	e, err = sub.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Type != EventLinkRemoved || e.Interface.Name != "lo0" {
+		t.Fatalf("unexpected event: %v", e)
+	}
+}
+
+func TestSubscriptionNextCanceled(t *testing.T) {
+	m, err := newMonitor(nopPlatformMonitor{}, newFakeInterfaces(lo0).get)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, sub := m.Subscribe()
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = sub.Next(ctx)
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSubscriptionCloseUnblocksNext(t *testing.T) {
+	m, err := newMonitor(nopPlatformMonitor{}, newFakeInterfaces(lo0).get)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, sub := m.Subscribe()
+
+	done := make(chan error)
+	go func() {
+		_, err := sub.Next(context.Background())
+		done <- err
+	}()
+
+	// Give Next time to block.
+	time.Sleep(50 * time.Millisecond)
+	sub.Close()
+
+	select {
+	case err := <-done:
+		if err == nil || err.Error() != "subscription closed" {
+			t.Fatalf("expected subscription closed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Next didn't return after Close")
+	}
+
+	// Closing twice is harmless.
+	sub.Close()
+}
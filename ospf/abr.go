@@ -0,0 +1,275 @@
+package ospf
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// An activeRange is an address range containing at least one of its
+// area's intra-area routes. Its cost is the largest cost of those routes.
+// See RFC 2328, section 12.4.3.
+type activeRange struct {
+	AddressRange
+	areaID common.AreaID
+	cost   uint32
+}
+
+// activeRanges returns the active address ranges of every area.
+func (inst *Instance) activeRanges() []activeRange {
+	var ranges []activeRange
+
+	for _, area := range inst.sortedAreas() {
+		for _, ar := range area.AddressRanges {
+			active := false
+			var cost uint32
+
+			for prefix, r := range area.routes {
+				if !ar.Prefix.Contains(prefix.Addr()) || prefix.Bits() < ar.Prefix.Bits() {
+					continue
+				}
+
+				active = true
+				if r.cost > cost {
+					cost = r.cost
+				}
+			}
+
+			if active {
+				ranges = append(ranges, activeRange{AddressRange: ar, areaID: area.ID, cost: cost})
+			}
+		}
+	}
+
+	return ranges
+}
+
+// addDiscardRoutes adds a route with no next hops for each active address
+// range, so that traffic to parts of the range that aren't reachable is
+// dropped instead of following a less specific route. Only area border
+// routers summarize ranges.
+func (inst *Instance) addDiscardRoutes() {
+	if !inst.isABR() {
+		return
+	}
+
+	for _, ar := range inst.activeRanges() {
+		if _, ok := inst.rib[ar.Prefix]; ok {
+			continue
+		}
+
+		inst.rib[ar.Prefix] = &route{
+			prefix:   ar.Prefix,
+			pathType: PathIntraArea,
+			cost:     ar.cost,
+			areaID:   ar.areaID,
+			discard:  true,
+		}
+	}
+}
+
+// nextHopsIn reports whether any of nextHops is through an interface in
+// the area.
+func nextHopsIn(nextHops []nextHop, areaID common.AreaID) bool {
+	for _, nh := range nextHops {
+		if nh.iface.AreaID == areaID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// summaryHeader returns the header for a summary-LSA we originate into the
+// area.
+func (inst *Instance) summaryHeader(area *Area, id netip.Addr) lsaHeader {
+	return lsaHeader{
+		options:           area.options(),
+		id:                id,
+		advertisingRouter: inst.RouterID,
+	}
+}
+
+// linkStateIDs assigns a Link State ID to each of prefixes, as described
+// in RFC 2328, appendix E. A network's ID is usually its address, but when
+// networks share an address, the least specific one gets it, and the
+// others get their address with the host bits set. Networks that can't be
+// given an unused ID are left out.
+func linkStateIDs(prefixes []netip.Prefix) map[netip.Prefix]netip.Addr {
+	sorted := slices.Clone(prefixes)
+	sort.Slice(sorted, func(a, b int) bool {
+		if sorted[a].Bits() != sorted[b].Bits() {
+			return sorted[a].Bits() < sorted[b].Bits()
+		}
+
+		return sorted[a].Addr().Less(sorted[b].Addr())
+	})
+
+	ids := make(map[netip.Prefix]netip.Addr)
+	used := make(map[netip.Addr]bool)
+
+	for _, prefix := range sorted {
+		id := prefix.Addr()
+		if used[id] {
+			id = lastAddr(prefix)
+		}
+
+		if used[id] {
+			fmt.Printf("ospf: no Link State ID available for %s\n", prefix)
+			continue
+		}
+
+		used[id] = true
+		ids[prefix] = id
+	}
+
+	return ids
+}
+
+// summariesFor returns the summary-LSAs and ASBR-summary-LSAs we originate
+// into the area as an area border router, as described in RFC 2328, section
+// 12.4.3. Stub areas get a default summary instead of ASBR-summary-LSAs,
+// and totally stubby areas get only the default summary. NSSAs get no
+// ASBR-summary-LSAs, and a default summary only if they're totally stubby.
+func (inst *Instance) summariesFor(area *Area, ranges []activeRange) []LSA {
+	costs := make(map[netip.Prefix]uint32)
+
+	if area.isStub() || (area.NSSA && area.NoSummary) {
+		costs[netip.PrefixFrom(netip.IPv4Unspecified(), 0)] = area.StubDefaultCost
+
+		if area.NoSummary {
+			return inst.networkSummaries(area, costs)
+		}
+	}
+
+	for prefix, r := range inst.rib {
+		if r.pathType > PathInterArea || r.discard || r.areaID == area.ID || r.cost >= lsInfinity {
+			continue
+		}
+
+		if nextHopsIn(r.nextHops, area.ID) {
+			continue
+		}
+
+		// Intra-area routes covered by one of their area's ranges are
+		// summarized by the range.
+		if r.pathType == PathIntraArea && inst.Areas[r.areaID].rangeFor(prefix) != nil {
+			continue
+		}
+
+		costs[prefix] = r.cost
+	}
+
+	for _, ar := range ranges {
+		if !ar.Advertise || ar.areaID == area.ID || ar.cost >= lsInfinity {
+			continue
+		}
+
+		costs[ar.Prefix] = ar.cost
+	}
+
+	lsas := inst.networkSummaries(area, costs)
+
+	if !area.ExternalRoutingCapability {
+		return lsas
+	}
+
+	for _, id := range inst.asbrs() {
+		r := inst.asbrRoute(id)
+		if r.areaID == area.ID || r.cost >= lsInfinity || nextHopsIn(r.nextHops, area.ID) {
+			continue
+		}
+
+		lsas = append(lsas, newASBRSummaryLSA(inst.summaryHeader(area, addrFromRouterID(id)), r.cost))
+	}
+
+	return lsas
+}
+
+// networkSummaries returns a summary-LSA for each network in costs, keyed
+// by prefix, advertised with its cost.
+func (inst *Instance) networkSummaries(area *Area, costs map[netip.Prefix]uint32) []LSA {
+	var lsas []LSA
+
+	for prefix, id := range linkStateIDs(maps.Keys(costs)) {
+		lsas = append(lsas, newSummaryLSA(inst.summaryHeader(area, id), prefixMask(prefix.Bits()), costs[prefix]))
+	}
+
+	return lsas
+}
+
+// asbrs returns the AS boundary routers we have routes to, other than
+// those in NSSAs, whose routes leave the NSSA as translated
+// AS-external-LSAs.
+func (inst *Instance) asbrs() []common.RouterID {
+	seen := make(map[common.RouterID]bool)
+
+	var ids []common.RouterID
+	for _, area := range inst.sortedAreas() {
+		if area.NSSA {
+			continue
+		}
+
+		for id, r := range area.borderRouters {
+			if r.asbr && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
+
+func isSummaryType(t lsType) bool {
+	return t == lsTypeSummary || t == lsTypeASBRSummary
+}
+
+// originateSummaries brings our summary-LSAs in line with the routing
+// table. Summaries we no longer want, including all of them if we've
+// stopped being an area border router, are flushed.
+func (inst *Instance) originateSummaries() {
+	desired := make(map[originationKey]LSA)
+
+	if inst.isABR() {
+		ranges := inst.activeRanges()
+
+		for _, area := range inst.sortedAreas() {
+			for _, lsa := range inst.summariesFor(area, ranges) {
+				desired[newOriginationKey(area.ID, lsa.Key())] = lsa
+			}
+		}
+	}
+
+	inst.reconcileOriginations(desired, isSummaryType)
+}
+
+// reconcileOriginations originates each LSA in desired, and flushes the
+// LSAs we've originated with types matching owned that aren't in desired.
+func (inst *Instance) reconcileOriginations(desired map[originationKey]LSA, owned func(lsType) bool) {
+	for key, lsa := range desired {
+		lsa := lsa
+		inst.scheduleLSA(key.areaID, key.lsdbKey, func() LSA {
+			return lsa
+		})
+	}
+
+	for key := range inst.originations {
+		if _, ok := desired[key]; ok || !owned(key.Type) {
+			continue
+		}
+
+		if existing, ok := inst.lookupLSA(key.areaID, key.lsdbKey); !ok || existing.Age() == maxAge {
+			delete(inst.originations, key)
+			continue
+		}
+
+		inst.scheduleLSA(key.areaID, key.lsdbKey, func() LSA {
+			return nil
+		})
+	}
+}
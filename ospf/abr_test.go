@@ -0,0 +1,278 @@
+package ospf
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+)
+
+// summaryMetrics returns the metric of each summary-LSA we originated into
+// the area that isn't being flushed, keyed by prefix, or router ID for
+// ASBR-summary-LSAs.
+func summaryMetrics(inst *Instance, areaID common.AreaID) map[string]uint32 {
+	metrics := make(map[string]uint32)
+
+	for _, lsa := range inst.Areas[areaID].lsdb {
+		if lsa.AdvertisingRouter() != inst.RouterID || lsa.Age() == maxAge {
+			continue
+		}
+
+		switch s := lsa.LSA.(type) {
+		case *summaryLSA:
+			metrics[s.Prefix().String()] = s.Metric()
+		case *asbrSummaryLSA:
+			metrics[s.ID().String()] = s.Metric()
+		}
+	}
+
+	return metrics
+}
+
+// summaryIDs returns the Link State ID of each summary-LSA we originated
+// into the area that isn't being flushed, keyed by prefix.
+func summaryIDs(inst *Instance, areaID common.AreaID) map[string]string {
+	ids := make(map[string]string)
+
+	for _, lsa := range inst.Areas[areaID].lsdb {
+		if s, ok := lsa.LSA.(*summaryLSA); ok && s.AdvertisingRouter() == inst.RouterID && lsa.Age() != maxAge {
+			ids[s.Prefix().String()] = s.ID().String()
+		}
+	}
+
+	return ids
+}
+
+// allowOriginations lets every origination happen immediately, rather
+// than waiting out MinLSInterval.
+func allowOriginations(inst *Instance) {
+	for _, o := range inst.originations {
+		if o.timer != nil {
+			o.timer.Stop()
+			o.timer = nil
+		}
+
+		o.last = time.Time{}
+	}
+}
+
+func TestSummaryOrigination(t *testing.T) {
+	inst := newTestABR(t)
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.Areas[1].AddressRanges = []AddressRange{{Prefix: netip.MustParsePrefix("172.16.0.0/16"), Advertise: true}}
+
+	inst.runSPF()
+
+	// Into the backbone: area 1's networks, with 172.16.1.0/24 and
+	// 172.16.2.0/24 aggregated into a range whose cost is the larger of
+	// theirs, and the ASBR.
+	expected := map[string]uint32{
+		"10.1.0.0/24":    10,
+		"172.16.0.0/16":  15,
+		"192.168.0.0/24": 11,
+		"3.3.3.3":        10,
+	}
+
+	if got := summaryMetrics(inst, 0); !reflect.DeepEqual(got, expected) {
+		t.Errorf("area 0: expected %v, got %v", expected, got)
+	}
+
+	// Into area 1: only the backbone's network.
+	expected = map[string]uint32{"10.0.0.0/24": 10}
+
+	if got := summaryMetrics(inst, 1); !reflect.DeepEqual(got, expected) {
+		t.Errorf("area 1: expected %v, got %v", expected, got)
+	}
+
+	discard, ok := inst.rib[netip.MustParsePrefix("172.16.0.0/16")]
+	if !ok || !discard.discard || len(discard.nextHops) != 0 {
+		t.Errorf("expected a discard route for the range, got %+v", discard)
+	}
+
+	// A range that isn't advertised hides its networks entirely.
+	inst.Areas[1].AddressRanges[0].Advertise = false
+	allowOriginations(inst)
+	inst.runSPF()
+
+	expected = map[string]uint32{
+		"10.1.0.0/24":    10,
+		"192.168.0.0/24": 11,
+		"3.3.3.3":        10,
+	}
+
+	if got := summaryMetrics(inst, 0); !reflect.DeepEqual(got, expected) {
+		t.Errorf("area 0 with not-advertise: expected %v, got %v", expected, got)
+	}
+
+	// Without area 1, we're no longer an area border router, and our
+	// summaries are flushed.
+	for _, iface := range inst.Interfaces {
+		if iface.AreaID == 1 {
+			iface.State = iDown
+		}
+	}
+
+	allowOriginations(inst)
+	inst.runSPF()
+
+	if got := summaryMetrics(inst, 0); len(got) != 0 {
+		t.Errorf("expected summaries to be flushed, got %v", got)
+	}
+
+	if _, ok := inst.rib[netip.MustParsePrefix("172.16.0.0/16")]; ok {
+		t.Errorf("expected no discard route when not an area border router")
+	}
+}
+
+func TestInterAreaRouteForActiveRange(t *testing.T) {
+	inst := newTestABR(t)
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.Areas[1].AddressRanges = []AddressRange{{Prefix: netip.MustParsePrefix("172.16.0.0/16"), Advertise: true}}
+
+	// Another ABR on the backbone advertises our range back to us.
+	inst.installLSA(0, testRouterLSAWithLinks("1.1.1.1", routerFlagB,
+		testLink(linkTransit, "10.0.0.1", "10.0.0.1", 10),
+	))
+
+	for _, lsa := range []LSA{
+		testRouterLSAWithLinks("2.2.2.2", routerFlagB,
+			testLink(linkTransit, "10.0.0.1", "10.0.0.2", 10),
+		),
+		testNetworkLSA("10.0.0.1", "1.1.1.1", 24, "1.1.1.1", "2.2.2.2"),
+		testSummaryLSA("2.2.2.2", "172.16.0.0/16", 1),
+		testSummaryLSA("2.2.2.2", "172.20.0.0/16", 1),
+	} {
+		inst.installLSA(0, lsa)
+	}
+
+	inst.runSPF()
+
+	if r := inst.rib[netip.MustParsePrefix("172.16.0.0/16")]; r == nil || !r.discard {
+		t.Errorf("expected our discard route to be kept, got %+v", r)
+	}
+
+	expectRIBRoute(t, inst, "172.20.0.0/16", PathInterArea, 11, 0, "10.0.0.2")
+}
+
+func TestSummaryLinkStateIDs(t *testing.T) {
+	inst := newTestABR(t)
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.installLSA(1, withSequenceNumber(testRouterLSAWithLinks("3.3.3.3", routerFlagE,
+		testLink(linkTransit, "10.1.0.1", "10.1.0.3", 10),
+		testLink(linkStub, "172.16.0.0", "255.255.255.0", 1),
+	), initialSequenceNumber+1))
+
+	inst.runSPF()
+
+	if id := summaryIDs(inst, 0)["172.16.0.0/24"]; id != "172.16.0.0" {
+		t.Fatalf("expected 172.16.0.0/24 to have ID 172.16.0.0, got %q", id)
+	}
+
+	// A less specific network with the same address takes over the ID, and
+	// the more specific one gets its host bits set. See RFC 2328, appendix
+	// E.
+	inst.installLSA(1, withSequenceNumber(testRouterLSAWithLinks("3.3.3.3", routerFlagE,
+		testLink(linkTransit, "10.1.0.1", "10.1.0.3", 10),
+		testLink(linkStub, "172.16.0.0", "255.255.0.0", 2),
+		testLink(linkStub, "172.16.0.0", "255.255.255.0", 1),
+	), initialSequenceNumber+2))
+
+	allowOriginations(inst)
+	inst.runSPF()
+
+	expected := map[string]string{
+		"10.1.0.0/24":   "10.1.0.0",
+		"172.16.0.0/16": "172.16.0.0",
+		"172.16.0.0/24": "172.16.0.255",
+	}
+
+	if got := summaryIDs(inst, 0); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	metrics := summaryMetrics(inst, 0)
+	if metrics["172.16.0.0/16"] != 12 || metrics["172.16.0.0/24"] != 11 {
+		t.Errorf("expected metrics 12 and 11, got %v", metrics)
+	}
+}
+
+func TestLinkStateIDs(t *testing.T) {
+	prefixes := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/24"),
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("10.0.0.0/16"),
+		netip.MustParsePrefix("10.0.0.0/32"),
+		netip.MustParsePrefix("192.168.0.0/24"),
+	}
+
+	expected := map[netip.Prefix]netip.Addr{
+		netip.MustParsePrefix("10.0.0.0/8"):     netip.MustParseAddr("10.0.0.0"),
+		netip.MustParsePrefix("10.0.0.0/16"):    netip.MustParseAddr("10.0.255.255"),
+		netip.MustParsePrefix("10.0.0.0/24"):    netip.MustParseAddr("10.0.0.255"),
+		netip.MustParsePrefix("192.168.0.0/24"): netip.MustParseAddr("192.168.0.0"),
+	}
+
+	// 10.0.0.0/32 has no host bits to set, so it's left out.
+	if got := linkStateIDs(prefixes); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestStubAreaSummaries(t *testing.T) {
+	inst := newTestABR(t)
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	// Area 1 is a stub area. 2.2.2.2, an AS boundary router, is on the
+	// backbone.
+	inst.Areas[1].ExternalRoutingCapability = false
+	inst.Areas[1].StubDefaultCost = 5
+
+	inst.installLSA(0, testRouterLSAWithLinks("1.1.1.1", routerFlagB,
+		testLink(linkTransit, "10.0.0.1", "10.0.0.1", 10),
+	))
+
+	for _, lsa := range []LSA{
+		testRouterLSAWithLinks("2.2.2.2", routerFlagE,
+			testLink(linkTransit, "10.0.0.1", "10.0.0.2", 10),
+		),
+		testNetworkLSA("10.0.0.1", "1.1.1.1", 24, "1.1.1.1", "2.2.2.2"),
+	} {
+		inst.installLSA(0, lsa)
+	}
+
+	inst.runSPF()
+
+	// A default summary replaces the ASBR-summary-LSA for 2.2.2.2.
+	expected := map[string]uint32{
+		"0.0.0.0/0":   5,
+		"10.0.0.0/24": 10,
+	}
+
+	if got := summaryMetrics(inst, 1); !reflect.DeepEqual(got, expected) {
+		t.Errorf("stub area: expected %v, got %v", expected, got)
+	}
+
+	if got := summaryMetrics(inst, 0); got["0.0.0.0/0"] != 0 || got["10.1.0.0/24"] != 10 {
+		t.Errorf("backbone: expected area 1's summaries and no default, got %v", got)
+	}
+
+	// A totally stubby area gets only the default.
+	inst.Areas[1].NoSummary = true
+	allowOriginations(inst)
+	inst.runSPF()
+
+	expected = map[string]uint32{"0.0.0.0/0": 5}
+
+	if got := summaryMetrics(inst, 1); !reflect.DeepEqual(got, expected) {
+		t.Errorf("totally stubby area: expected %v, got %v", expected, got)
+	}
+}
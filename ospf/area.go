@@ -1,7 +1,10 @@
 package ospf
 
 import (
+	"fmt"
 	"net/netip"
+	"sort"
+	"time"
 
 	"github.com/davidbalbert/chatter/chatterd/common"
 	"github.com/davidbalbert/chatter/config"
@@ -17,15 +20,147 @@ type Area struct {
 	AddressRanges []AddressRange
 	// Interfaces are stored in Instance.Interfaces
 
-	// TODO: LSDB
-	// TODO: ShortestPathTree
-	TransitCapability         bool // calculated when ShortestPathTree is calculated
+	lsdb                      lsdb
+	spt                       map[vertexID]*vertex // the shortest-path tree
+	routes                    routingTable         // intra-area routes
+	borderRouters             map[common.RouterID]*routerRoute
+	TransitCapability         bool // calculated when spt is calculated
 	ExternalRoutingCapability bool
-	// TODO: StubDefaultCost
+	StubDefaultCost           uint32 // if we're an ABR, the cost of the default summary we send into a stub area or NSSA
+	NoSummary                 bool   // in a stub area or NSSA, send only the default summary
+
+	// NSSAs (RFC 3101)
+	NSSA                     bool
+	DefaultOriginate         bool // if we're an ABR, send a default Type-7 LSA into the NSSA
+	TranslatorRole           config.NSSATranslatorRole
+	TranslatorState          NSSATranslatorState
+	translatorStabilityTimer *time.Timer // while running, we keep translating after losing the election
 }
 
 func newArea(areaID common.AreaID, conf config.OSPFAreaConfig) *Area {
+	var ranges []AddressRange
+	for prefix, rc := range conf.AddressRanges {
+		ranges = append(ranges, AddressRange{Prefix: prefix, Advertise: rc.Advertise})
+	}
+
+	sort.Slice(ranges, func(a, b int) bool {
+		return comparePrefixes(ranges[a].Prefix, ranges[b].Prefix) < 0
+	})
+
 	return &Area{
-		ID: areaID,
+		ID:                        areaID,
+		AddressRanges:             ranges,
+		lsdb:                      newLSDB(),
+		spt:                       make(map[vertexID]*vertex),
+		routes:                    make(routingTable),
+		borderRouters:             make(map[common.RouterID]*routerRoute),
+		ExternalRoutingCapability: !conf.Stub && !conf.NSSA,
+		StubDefaultCost:           conf.DefaultCost,
+		NoSummary:                 conf.NoSummary,
+		NSSA:                      conf.NSSA,
+		DefaultOriginate:          conf.DefaultOriginate,
+		TranslatorRole:            conf.TranslatorRole,
+	}
+}
+
+// options returns the options we set in Hellos and LSAs in the area.
+func (a *Area) options() uint8 {
+	var options uint8
+
+	if a.ExternalRoutingCapability {
+		options |= optE
+	}
+
+	return options
+}
+
+// isStub reports whether the area is a stub area. AS-external-LSAs aren't
+// flooded into stub areas, and the area's routers reach external
+// destinations using a default route originated by the area border routers.
+// See RFC 2328, section 3.6.
+func (a *Area) isStub() bool {
+	return !a.ExternalRoutingCapability && !a.NSSA
+}
+
+// AreaType is the kind of area, which determines how external routes are
+// carried into it.
+type AreaType int
+
+const (
+	AreaNormal AreaType = iota
+	AreaStub
+	AreaNSSA
+)
+
+func (t AreaType) String() string {
+	switch t {
+	case AreaNormal:
+		return "normal"
+	case AreaStub:
+		return "stub"
+	case AreaNSSA:
+		return "NSSA"
+	default:
+		return fmt.Sprintf("AreaType(%d)", int(t))
 	}
 }
+
+func (a *Area) areaType() AreaType {
+	switch {
+	case a.NSSA:
+		return AreaNSSA
+	case a.isStub():
+		return AreaStub
+	default:
+		return AreaNormal
+	}
+}
+
+// rangeFor returns the address range containing prefix, or nil if there
+// isn't one.
+func (a *Area) rangeFor(prefix netip.Prefix) *AddressRange {
+	for i := range a.AddressRanges {
+		r := &a.AddressRanges[i]
+		if r.Prefix.Bits() <= prefix.Bits() && r.Prefix.Contains(prefix.Addr()) {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// An AreaSnapshot is a copy of an area's state, for reporting.
+type AreaSnapshot struct {
+	ID              common.AreaID
+	Type            AreaType
+	NoSummary       bool
+	DefaultCost     uint32
+	LSAs            int
+	TranslatorRole  config.NSSATranslatorRole
+	TranslatorState NSSATranslatorState
+}
+
+func (a *Area) snapshot() AreaSnapshot {
+	return AreaSnapshot{
+		ID:              a.ID,
+		Type:            a.areaType(),
+		NoSummary:       a.NoSummary,
+		DefaultCost:     a.StubDefaultCost,
+		LSAs:            len(a.lsdb),
+		TranslatorRole:  a.TranslatorRole,
+		TranslatorState: a.TranslatorState,
+	}
+}
+
+// AreaSnapshots returns the state of every area, sorted by ID.
+func (i *Instance) AreaSnapshots() []AreaSnapshot {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var areas []AreaSnapshot
+	for _, area := range i.sortedAreas() {
+		areas = append(areas, area.snapshot())
+	}
+
+	return areas
+}
@@ -0,0 +1,287 @@
+package ospf
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/netip"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+)
+
+// apad is appended to packets in place of the digest when calculating
+// HMAC-SHA digests. See RFC 5709, section 3.3.
+const apad = 0x878fe1f3
+
+// configureAuth sets up the interface's authentication from conf, which is
+// nil if there's no authentication.
+func (i *Interface) configureAuth(conf *config.OSPFAuthConfig) {
+	i.AuType = AuthTypeNull
+	i.AuthenticationKey = 0
+	i.authKeys = nil
+
+	if conf == nil {
+		return
+	}
+
+	switch conf.Type {
+	case config.OSPFAuthSimple:
+		var key [8]byte
+		copy(key[:], conf.Key)
+
+		i.AuType = AuthTypeSimple
+		i.AuthenticationKey = binary.BigEndian.Uint64(key[:])
+	case config.OSPFAuthCryptographic:
+		i.AuType = AuthTypeCryptographic
+		i.authKeys = conf.Keys
+	}
+}
+
+// authTrailerLen returns the number of bytes that might be appended to
+// packets sent out of the interface for authentication.
+func (i *Interface) authTrailerLen() int {
+	n := 0
+	for _, key := range i.authKeys {
+		if l := digestLen(key.Algorithm); l > n {
+			n = l
+		}
+	}
+
+	return n
+}
+
+// packetOverhead returns the number of bytes in a packet sent out of the
+// interface that aren't part of the OSPF packet's body.
+func (i *Interface) packetOverhead() int {
+	return ipHeaderLen + packetHeaderLen + i.authTrailerLen()
+}
+
+func digestLen(a config.OSPFAuthAlgorithm) int {
+	switch a {
+	case config.OSPFAuthHMACSHA1:
+		return sha1.Size
+	case config.OSPFAuthHMACSHA256:
+		return sha256.Size
+	case config.OSPFAuthHMACSHA384:
+		return sha512.Size384
+	case config.OSPFAuthHMACSHA512:
+		return sha512.Size
+	default:
+		return md5.Size
+	}
+}
+
+func hashFor(a config.OSPFAuthAlgorithm) func() hash.Hash {
+	switch a {
+	case config.OSPFAuthHMACSHA1:
+		return sha1.New
+	case config.OSPFAuthHMACSHA256:
+		return sha256.New
+	case config.OSPFAuthHMACSHA384:
+		return sha512.New384
+	case config.OSPFAuthHMACSHA512:
+		return sha512.New
+	default:
+		return md5.New
+	}
+}
+
+// authDigest returns the digest that's appended to data, an OSPF packet
+// with its authentication field filled in. MD5 digests are described in
+// RFC 2328, appendix D.4.3, and HMAC-SHA digests in RFC 5709, section 3.3.
+func authDigest(key config.OSPFAuthKeyConfig, data []byte) []byte {
+	l := digestLen(key.Algorithm)
+	newHash := hashFor(key.Algorithm)
+
+	if key.Algorithm == config.OSPFAuthMD5 {
+		secret := make([]byte, l)
+		copy(secret, key.Secret)
+
+		h := newHash()
+		h.Write(data)
+		h.Write(secret)
+
+		return h.Sum(nil)
+	}
+
+	// Keys longer than the digest are hashed, and shorter ones are padded
+	// with zeros, which HMAC does for us.
+	secret := []byte(key.Secret)
+	if len(secret) > l {
+		h := newHash()
+		h.Write(secret)
+		secret = h.Sum(nil)
+	}
+
+	pad := make([]byte, l)
+	for j := 0; j < l; j += 4 {
+		binary.BigEndian.PutUint32(pad[j:], apad)
+	}
+
+	mac := hmac.New(newHash, secret)
+	mac.Write(data)
+	mac.Write(pad)
+
+	return mac.Sum(nil)
+}
+
+func keyActive(start, stop, now time.Time) bool {
+	return !now.Before(start) && (stop.IsZero() || now.Before(stop))
+}
+
+// selectKey returns the ID of the active key with the most recent start
+// time, where lifetime returns each key's start and stop times. If every
+// key has expired, it returns the one that expired last, so we don't
+// fall back to sending or accepting unauthenticated packets. See RFC 2328,
+// appendix D.3.
+func selectKey(keys map[uint8]config.OSPFAuthKeyConfig, now time.Time, lifetime func(config.OSPFAuthKeyConfig) (time.Time, time.Time)) (uint8, bool) {
+	var (
+		best, last         uint8
+		found, expired     bool
+		bestStart, lastEnd time.Time
+	)
+
+	for id, key := range keys {
+		start, stop := lifetime(key)
+
+		if keyActive(start, stop, now) {
+			if !found || start.After(bestStart) || (start.Equal(bestStart) && id > best) {
+				best, bestStart, found = id, start, true
+			}
+		} else if !stop.IsZero() && !now.Before(stop) {
+			if !expired || stop.After(lastEnd) || (stop.Equal(lastEnd) && id > last) {
+				last, lastEnd, expired = id, stop, true
+			}
+		}
+	}
+
+	if found {
+		return best, true
+	}
+
+	return last, expired
+}
+
+func sendLifetime(key config.OSPFAuthKeyConfig) (time.Time, time.Time) {
+	return key.SendStart, key.SendStop
+}
+
+func acceptLifetime(key config.OSPFAuthKeyConfig) (time.Time, time.Time) {
+	return key.AcceptStart, key.AcceptStop
+}
+
+// acceptsKey reports whether packets authenticated with the key id are
+// accepted. During a rollover, more than one key can be accepted.
+func (i *Interface) acceptsKey(id uint8, now time.Time) bool {
+	key, ok := i.authKeys[id]
+	if !ok {
+		return false
+	}
+
+	if keyActive(key.AcceptStart, key.AcceptStop, now) {
+		return true
+	}
+
+	selected, ok := selectKey(i.authKeys, now, acceptLifetime)
+	return ok && selected == id
+}
+
+// nextCryptoSequenceNumber returns the sequence number for the next packet
+// sent with cryptographic authentication. Sequence numbers come from the
+// clock so that they keep increasing across restarts, and they never
+// decrease, even if the clock goes backwards.
+func (i *Interface) nextCryptoSequenceNumber() uint32 {
+	if now := uint32(time.Now().Unix()); now > i.cryptoSequenceNumber {
+		i.cryptoSequenceNumber = now
+	}
+
+	return i.cryptoSequenceNumber
+}
+
+func cryptoAuthData(keyID uint8, authLen int, seq uint32) uint64 {
+	return uint64(keyID)<<40 | uint64(authLen)<<32 | uint64(seq)
+}
+
+// setAuthField fills in the authentication type and field of h, as
+// described in RFC 2328, appendix D.4. With cryptographic authentication,
+// it returns the key that the packet's digest is calculated with.
+func (i *Interface) setAuthField(h *PacketHeader) (*config.OSPFAuthKeyConfig, error) {
+	h.authType = uint16(i.AuType)
+	h.authData = 0
+
+	switch i.AuType {
+	case AuthTypeSimple:
+		h.authData = i.AuthenticationKey
+	case AuthTypeCryptographic:
+		id, ok := selectKey(i.authKeys, time.Now(), sendLifetime)
+		if !ok {
+			return nil, fmt.Errorf("no authentication key to send with")
+		}
+
+		key := i.authKeys[id]
+		h.authData = cryptoAuthData(id, digestLen(key.Algorithm), i.nextCryptoSequenceNumber())
+
+		return &key, nil
+	}
+
+	return nil, nil
+}
+
+// authenticate checks a received packet's authentication, as described in
+// RFC 2328, appendix D.4. data is the whole packet, including any digest
+// after the OSPF packet. With cryptographic authentication, it returns the
+// packet's sequence number.
+func (i *Interface) authenticate(h *PacketHeader, data []byte, src netip.Addr) (uint32, bool) {
+	drop := func(counter *uint64, format string, args ...interface{}) (uint32, bool) {
+		*counter++
+		fmt.Printf("ospf: %s %s: dropping %s from %s (%s): %s\n", i.name, i.Prefix, h.t, h.routerID, src, fmt.Sprintf(format, args...))
+		return 0, false
+	}
+
+	if h.authType != uint16(i.AuType) {
+		return drop(&i.Stats.AuthTypeMismatches, "authentication type mismatch: got %s, expected %s", AuthType(h.authType), i.AuType)
+	}
+
+	switch i.AuType {
+	case AuthTypeSimple:
+		if h.authData != i.AuthenticationKey {
+			return drop(&i.Stats.AuthKeyMismatches, "wrong password")
+		}
+	case AuthTypeCryptographic:
+		keyID := uint8(h.authData >> 40)
+		authLen := int(uint8(h.authData >> 32))
+		seq := uint32(h.authData)
+
+		if !i.acceptsKey(keyID, time.Now()) {
+			return drop(&i.Stats.AuthKeyMismatches, "unknown or inactive key %d", keyID)
+		}
+
+		key := i.authKeys[keyID]
+		if authLen != digestLen(key.Algorithm) {
+			return drop(&i.Stats.AuthKeyMismatches, "key %d: digest length %d, expected %d", keyID, authLen, digestLen(key.Algorithm))
+		}
+
+		length := int(h.length)
+		if len(data) < length+authLen {
+			return drop(&i.Stats.AuthDigestMismatches, "truncated digest")
+		}
+
+		if !hmac.Equal(data[length:length+authLen], authDigest(key, data[:length])) {
+			return drop(&i.Stats.AuthDigestMismatches, "key %d: digest mismatch", keyID)
+		}
+
+		if n, ok := i.Neighbors[h.routerID]; ok && seq < n.cryptoSequenceNumber {
+			return drop(&i.Stats.AuthSequenceErrors, "sequence number %d is less than %d", seq, n.cryptoSequenceNumber)
+		}
+
+		return seq, true
+	}
+
+	return 0, true
+}
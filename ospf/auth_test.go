@@ -0,0 +1,307 @@
+package ospf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+)
+
+func testCryptoAuth(keys map[uint8]config.OSPFAuthKeyConfig) *config.OSPFAuthConfig {
+	return &config.OSPFAuthConfig{Type: config.OSPFAuthCryptographic, Keys: keys}
+}
+
+func testKey(algorithm config.OSPFAuthAlgorithm, secret string) config.OSPFAuthKeyConfig {
+	return config.OSPFAuthKeyConfig{Algorithm: algorithm, Secret: secret}
+}
+
+// newTestAuthInterfaces returns a broadcast interface on router 1.1.1.1,
+// and another on 2.2.2.2 that sends packets to it.
+func newTestAuthInterfaces(t *testing.T, recvAuth, sendAuth *config.OSPFAuthConfig) (recv, send *Interface) {
+	t.Helper()
+
+	recv = newTestBroadcastInterface(t)
+	recv.configureAuth(recvAuth)
+
+	send = newTestBroadcastInterface(t)
+	send.instance.RouterID = mustParseRouterID("2.2.2.2")
+	send.Prefix = netip.MustParsePrefix("10.0.0.2/24")
+	send.configureAuth(sendAuth)
+
+	return recv, send
+}
+
+// sendTestPacket sends p out of send, and returns the packet as it arrives
+// on recv's network.
+func sendTestPacket(t *testing.T, recv, send *Interface, p Packet) *receivedPacket {
+	t.Helper()
+
+	network := newMemNetwork()
+	send.transport.close()
+	send.transport = network.attach(send.Prefix.Addr(), send.ifindex)
+	rx := network.attach(recv.Prefix.Addr(), recv.ifindex)
+
+	if err := send.sendPacket(p, recv.Prefix.Addr()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case rp := <-rx.packets:
+		return rp
+	default:
+		t.Fatal("expected a packet")
+		return nil
+	}
+}
+
+func TestAuthenticateCryptographic(t *testing.T) {
+	algorithms := []config.OSPFAuthAlgorithm{
+		config.OSPFAuthMD5,
+		config.OSPFAuthHMACSHA1,
+		config.OSPFAuthHMACSHA256,
+		config.OSPFAuthHMACSHA384,
+		config.OSPFAuthHMACSHA512,
+	}
+
+	for _, algorithm := range algorithms {
+		t.Run(algorithm.String(), func(t *testing.T) {
+			auth := testCryptoAuth(map[uint8]config.OSPFAuthKeyConfig{7: testKey(algorithm, "secret")})
+			recv, send := newTestAuthInterfaces(t, auth, auth)
+
+			recv.instance.mu.Lock()
+			defer recv.instance.mu.Unlock()
+
+			rp := sendTestPacket(t, recv, send, testHello("2.2.2.2"))
+
+			h, err := ParsePacket(rp.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(rp.data) != int(h.Header().length)+digestLen(algorithm) {
+				t.Fatalf("expected a %d byte digest after the packet, got %d bytes", digestLen(algorithm), len(rp.data)-int(h.Header().length))
+			}
+
+			if id := uint8(h.Header().authData >> 40); id != 7 {
+				t.Errorf("expected key ID 7, got %d", id)
+			}
+
+			recv.handlePacket(rp)
+
+			n, ok := recv.Neighbors[mustParseRouterID("2.2.2.2")]
+			if !ok {
+				t.Fatalf("expected the Hello to be accepted, stats %+v", recv.Stats)
+			}
+
+			if n.cryptoSequenceNumber != uint32(h.Header().authData) {
+				t.Errorf("expected the neighbor's sequence number to be recorded")
+			}
+
+			// Any change to the packet is detected.
+			tampered := *rp
+			tampered.data = append([]byte(nil), rp.data...)
+			tampered.data[packetHeaderLen+4]++
+			recv.handlePacket(&tampered)
+
+			if recv.Stats.AuthDigestMismatches != 1 {
+				t.Errorf("expected a digest mismatch, got %+v", recv.Stats)
+			}
+		})
+	}
+}
+
+func TestAuthenticateFailures(t *testing.T) {
+	simple := &config.OSPFAuthConfig{Type: config.OSPFAuthSimple, Key: "hunter2"}
+	key1 := testCryptoAuth(map[uint8]config.OSPFAuthKeyConfig{1: testKey(config.OSPFAuthHMACSHA256, "one")})
+	key2 := testCryptoAuth(map[uint8]config.OSPFAuthKeyConfig{2: testKey(config.OSPFAuthHMACSHA256, "one")})
+	wrongSecret := testCryptoAuth(map[uint8]config.OSPFAuthKeyConfig{1: testKey(config.OSPFAuthHMACSHA256, "two")})
+	wrongAlgorithm := testCryptoAuth(map[uint8]config.OSPFAuthKeyConfig{1: testKey(config.OSPFAuthHMACSHA1, "one")})
+
+	tests := []struct {
+		name       string
+		recv, send *config.OSPFAuthConfig
+		count      func(s InterfaceStats) uint64
+	}{
+		{"type", nil, simple, func(s InterfaceStats) uint64 { return s.AuthTypeMismatches }},
+		{"password", simple, &config.OSPFAuthConfig{Type: config.OSPFAuthSimple, Key: "hunter3"}, func(s InterfaceStats) uint64 { return s.AuthKeyMismatches }},
+		{"key ID", key1, key2, func(s InterfaceStats) uint64 { return s.AuthKeyMismatches }},
+		{"digest length", key1, wrongAlgorithm, func(s InterfaceStats) uint64 { return s.AuthKeyMismatches }},
+		{"secret", key1, wrongSecret, func(s InterfaceStats) uint64 { return s.AuthDigestMismatches }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recv, send := newTestAuthInterfaces(t, test.recv, test.send)
+
+			recv.instance.mu.Lock()
+			defer recv.instance.mu.Unlock()
+
+			recv.handlePacket(sendTestPacket(t, recv, send, testHello("2.2.2.2")))
+
+			if len(recv.Neighbors) != 0 {
+				t.Errorf("expected the Hello to be dropped")
+			}
+
+			if test.count(recv.Stats) != 1 {
+				t.Errorf("expected the failure to be counted, got %+v", recv.Stats)
+			}
+		})
+	}
+}
+
+func TestAuthenticateSimple(t *testing.T) {
+	auth := &config.OSPFAuthConfig{Type: config.OSPFAuthSimple, Key: "hunter2"}
+	recv, send := newTestAuthInterfaces(t, auth, auth)
+
+	recv.instance.mu.Lock()
+	defer recv.instance.mu.Unlock()
+
+	rp := sendTestPacket(t, recv, send, testHello("2.2.2.2"))
+
+	if string(rp.data[16:23]) != "hunter2" || rp.data[23] != 0 {
+		t.Errorf("expected the password, padded with zeros, in the authentication field, got %q", rp.data[16:24])
+	}
+
+	recv.handlePacket(rp)
+
+	if len(recv.Neighbors) != 1 {
+		t.Errorf("expected the Hello to be accepted, stats %+v", recv.Stats)
+	}
+}
+
+func TestAuthenticateSequenceNumbers(t *testing.T) {
+	auth := testCryptoAuth(map[uint8]config.OSPFAuthKeyConfig{1: testKey(config.OSPFAuthMD5, "secret")})
+	recv, send := newTestAuthInterfaces(t, auth, auth)
+
+	recv.instance.mu.Lock()
+	defer recv.instance.mu.Unlock()
+
+	rp := sendTestPacket(t, recv, send, testHello("2.2.2.2"))
+	recv.handlePacket(rp)
+
+	// Replaying the same packet is allowed, because sequence numbers only
+	// have to be non-decreasing.
+	recv.handlePacket(rp)
+
+	if recv.Stats.AuthSequenceErrors != 0 || recv.Stats.HellosReceived != 2 {
+		t.Fatalf("expected both Hellos to be accepted, got %+v", recv.Stats)
+	}
+
+	n := recv.Neighbors[mustParseRouterID("2.2.2.2")]
+	n.cryptoSequenceNumber++
+
+	recv.handlePacket(rp)
+
+	if recv.Stats.AuthSequenceErrors != 1 {
+		t.Errorf("expected a sequence number error, got %+v", recv.Stats)
+	}
+}
+
+func TestHMACSHADigest(t *testing.T) {
+	data := []byte("an OSPF packet")
+	key := testKey(config.OSPFAuthHMACSHA256, "secret")
+
+	// RFC 5709, section 3.3: HMAC of the packet followed by Apad.
+	pad := make([]byte, sha256.Size)
+	for i := 0; i < len(pad); i += 4 {
+		binary.BigEndian.PutUint32(pad[i:], 0x878fe1f3)
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(data)
+	mac.Write(pad)
+
+	if !hmac.Equal(authDigest(key, data), mac.Sum(nil)) {
+		t.Errorf("unexpected digest")
+	}
+
+	// Keys longer than the hash are hashed first.
+	long := testKey(config.OSPFAuthHMACSHA256, "a secret that's longer than thirty two bytes")
+	sum := sha256.Sum256([]byte(long.Secret))
+
+	if !hmac.Equal(authDigest(long, data), authDigest(testKey(config.OSPFAuthHMACSHA256, string(sum[:])), data)) {
+		t.Errorf("expected long keys to be hashed")
+	}
+}
+
+func TestKeyRollover(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	old := testKey(config.OSPFAuthHMACSHA256, "old")
+	old.SendStop = now.Add(-time.Hour)
+	old.AcceptStop = now.Add(time.Hour)
+
+	next := testKey(config.OSPFAuthHMACSHA256, "new")
+	next.SendStart = now.Add(-2 * time.Hour)
+	next.AcceptStart = now.Add(-2 * time.Hour)
+
+	iface := newTestBroadcastInterface(t)
+	iface.configureAuth(testCryptoAuth(map[uint8]config.OSPFAuthKeyConfig{1: old, 2: next}))
+
+	// While both keys are valid, we send with the newest one, and accept
+	// either.
+	if id, ok := selectKey(iface.authKeys, now.Add(-90*time.Minute), sendLifetime); !ok || id != 2 {
+		t.Errorf("expected to send with key 2, got %d", id)
+	}
+
+	if !iface.acceptsKey(1, now) || !iface.acceptsKey(2, now) {
+		t.Errorf("expected both keys to be accepted during the rollover")
+	}
+
+	if iface.acceptsKey(1, now.Add(2*time.Hour)) {
+		t.Errorf("expected key 1 to no longer be accepted")
+	}
+
+	if iface.acceptsKey(3, now) {
+		t.Errorf("expected unknown keys to be rejected")
+	}
+
+	// If every key expires, we keep using the last one rather than
+	// sending unauthenticated packets.
+	last := testKey(config.OSPFAuthHMACSHA256, "last")
+	last.SendStop = now
+	iface.configureAuth(testCryptoAuth(map[uint8]config.OSPFAuthKeyConfig{1: old, 3: last}))
+
+	if id, ok := selectKey(iface.authKeys, now.Add(time.Hour), sendLifetime); !ok || id != 3 {
+		t.Errorf("expected to keep sending with key 3, got %d", id)
+	}
+
+	// Keys that haven't started can't be used.
+	future := testKey(config.OSPFAuthHMACSHA256, "future")
+	future.SendStart = now.Add(time.Hour)
+	iface.configureAuth(testCryptoAuth(map[uint8]config.OSPFAuthKeyConfig{4: future}))
+
+	if _, ok := selectKey(iface.authKeys, now, sendLifetime); ok {
+		t.Errorf("expected no key to send with")
+	}
+}
+
+func TestAuthBetweenInstances(t *testing.T) {
+	network := newMemNetwork()
+
+	conf := testInterfaceConfig()
+	conf.Authentication = testCryptoAuth(map[uint8]config.OSPFAuthKeyConfig{1: testKey(config.OSPFAuthHMACSHA512, "secret")})
+	confs := map[string]config.OSPFInterfaceConfig{"eth0": conf}
+
+	r1 := newTestInstance(t, "1.1.1.1", network, confs)
+	r2 := newTestInstance(t, "2.2.2.2", network, confs)
+
+	i1 := startTestInterface(t, r1, testNetif("eth0", 1, "10.0.0.1/24"))
+	startTestInterface(t, r2, testNetif("eth0", 2, "10.0.0.2/24"))
+
+	waitFor(t, r1, 20*time.Second, "r1 to be adjacent to r2", func() bool {
+		return neighborStateOf(i1, "2.2.2.2") == nFull
+	})
+
+	r1.mu.Lock()
+	defer r1.mu.Unlock()
+
+	s := i1.Stats
+	if s.AuthTypeMismatches+s.AuthKeyMismatches+s.AuthDigestMismatches+s.AuthSequenceErrors != 0 {
+		t.Errorf("expected no authentication failures, got %+v", s)
+	}
+}
@@ -0,0 +1,169 @@
+package ospf
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// A drCandidate is a router that's eligible to become DR or BDR, along with
+// the DR and BDR it's declared in its Hellos.
+type drCandidate struct {
+	Router
+	priority uint8
+	dr       netip.Addr
+	bdr      netip.Addr
+}
+
+func (c *drCandidate) declaresDR() bool {
+	return c.dr == c.Addr
+}
+
+func (c *drCandidate) declaresBDR() bool {
+	return c.bdr == c.Addr
+}
+
+// better reports whether c should be preferred over other: the highest
+// priority wins, and router ID breaks ties.
+func (c *drCandidate) better(other *drCandidate) bool {
+	if c.priority != other.priority {
+		return c.priority > other.priority
+	}
+
+	return c.ID > other.ID
+}
+
+// calculateDR runs steps 2 and 3 of the election in RFC 2328, section 9.4. All
+// candidates must have a non-zero priority.
+func calculateDR(candidates []drCandidate) (dr, bdr Router) {
+	// Step 2: elect the BDR from the routers that aren't declaring
+	// themselves DR. Routers declaring themselves BDR take precedence.
+	var best *drCandidate
+	declared := false
+
+	for i := range candidates {
+		c := &candidates[i]
+		if c.declaresDR() {
+			continue
+		}
+
+		if c.declaresBDR() && !declared {
+			best = c
+			declared = true
+		} else if c.declaresBDR() == declared && (best == nil || c.better(best)) {
+			best = c
+		}
+	}
+
+	if best != nil {
+		bdr = best.Router
+	}
+
+	// Step 3: elect the DR from the routers declaring themselves DR. If
+	// there aren't any, the BDR becomes DR.
+	best = nil
+	for i := range candidates {
+		c := &candidates[i]
+		if c.declaresDR() && (best == nil || c.better(best)) {
+			best = c
+		}
+	}
+
+	if best != nil {
+		dr = best.Router
+	} else {
+		dr = bdr
+	}
+
+	return dr, bdr
+}
+
+func (i *Interface) self() Router {
+	return Router{ID: i.instance.RouterID, Addr: i.Prefix.Addr()}
+}
+
+// drCandidates returns this router and its neighbors in state 2-Way or
+// greater that are eligible to become DR.
+func (i *Interface) drCandidates() []drCandidate {
+	var candidates []drCandidate
+
+	if i.RouterPriority > 0 {
+		dr := i.DR.Addr
+		if i.reclaimDR {
+			dr = i.Prefix.Addr()
+		}
+
+		candidates = append(candidates, drCandidate{
+			Router:   i.self(),
+			priority: i.RouterPriority,
+			dr:       dr,
+			bdr:      i.BDR.Addr,
+		})
+	}
+
+	for _, n := range i.Neighbors {
+		if n.state < n2Way || n.Priority == 0 {
+			continue
+		}
+
+		candidates = append(candidates, drCandidate{
+			Router:   Router{ID: n.ID, Addr: n.Addr},
+			priority: n.Priority,
+			dr:       n.DesignatedRouter,
+			bdr:      n.BackupDesignatedRouter,
+		})
+	}
+
+	return candidates
+}
+
+// electDR calculates the DR and BDR for the interface's network and sets
+// the interface's state accordingly, as described in RFC 2328, section 9.4.
+func (i *Interface) electDR() {
+	self := i.self()
+	oldDR, oldBDR := i.DR, i.BDR
+
+	dr, bdr := calculateDR(i.drCandidates())
+	i.reclaimDR = false
+
+	// Step 4: if we've become or stopped being DR or BDR, run the election
+	// again, this time declaring our new role.
+	if (dr == self) != (oldDR == self) || (bdr == self) != (oldBDR == self) {
+		i.DR, i.BDR = dr, bdr
+		dr, bdr = calculateDR(i.drCandidates())
+	}
+
+	i.DR, i.BDR = dr, bdr
+
+	wasDR := i.State == iDR || i.State == iBackup
+
+	// Step 5
+	if i.DR == self {
+		i.setState(iDR)
+	} else if i.BDR == self {
+		i.setState(iBackup)
+	} else {
+		i.setState(iDROther)
+	}
+
+	// Step 6: on NBMA networks, the DR and BDR start talking to the
+	// configured neighbors that aren't eligible to become DR.
+	if i.Type == InterfaceNBMA && !wasDR && (i.State == iDR || i.State == iBackup) {
+		i.startStaticNeighbors(func(n *Neighbor) bool {
+			return n.Priority == 0
+		})
+	}
+
+	if i.DR != oldDR || i.BDR != oldBDR {
+		fmt.Printf("ospf: %s %s: DR %s, BDR %s\n", i.name, i.Prefix, i.DR.ID, i.BDR.ID)
+
+		// Step 7
+		for _, n := range i.Neighbors {
+			if n.state >= n2Way {
+				n.handleEvent(neAdjOK)
+			}
+		}
+
+		// Our transit link names the DR.
+		i.instance.scheduleRouterLSAs()
+	}
+}
@@ -0,0 +1,191 @@
+package ospf
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+)
+
+// testCandidate returns a candidate with router ID n.n.n.n and address
+// 10.0.0.n, declaring routers dr and bdr (0 for none).
+func testCandidate(n int, priority uint8, dr, bdr int) drCandidate {
+	addr := func(n int) netip.Addr {
+		if n == 0 {
+			return netip.IPv4Unspecified()
+		}
+
+		return netip.AddrFrom4([4]byte{10, 0, 0, byte(n)})
+	}
+
+	return drCandidate{
+		Router:   testRouter(n),
+		priority: priority,
+		dr:       addr(dr),
+		bdr:      addr(bdr),
+	}
+}
+
+func testRouter(n int) Router {
+	return Router{
+		ID:   mustParseRouterID(fmt.Sprintf("%d.%d.%d.%d", n, n, n, n)),
+		Addr: netip.AddrFrom4([4]byte{10, 0, 0, byte(n)}),
+	}
+}
+
+func TestCalculateDR(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []drCandidate
+		dr, bdr    Router
+	}{
+		{
+			name: "no candidates",
+		},
+		{
+			name:       "no declarations",
+			candidates: []drCandidate{testCandidate(1, 1, 0, 0), testCandidate(2, 1, 0, 0)},
+			dr:         testRouter(2),
+			bdr:        testRouter(2),
+		},
+		{
+			name:       "priority beats router ID",
+			candidates: []drCandidate{testCandidate(1, 5, 0, 0), testCandidate(2, 1, 0, 0)},
+			dr:         testRouter(1),
+			bdr:        testRouter(1),
+		},
+		{
+			name:       "existing DR and BDR are kept",
+			candidates: []drCandidate{testCandidate(1, 1, 1, 2), testCandidate(2, 1, 1, 2), testCandidate(3, 10, 1, 2)},
+			dr:         testRouter(1),
+			bdr:        testRouter(2),
+		},
+		{
+			name:       "declared BDR preferred over higher priority",
+			candidates: []drCandidate{testCandidate(1, 1, 0, 1), testCandidate(2, 10, 0, 1)},
+			dr:         testRouter(1),
+			bdr:        testRouter(1),
+		},
+		{
+			name:       "BDR excludes routers declaring DR",
+			candidates: []drCandidate{testCandidate(1, 1, 1, 0), testCandidate(2, 1, 1, 0), testCandidate(3, 1, 1, 0)},
+			dr:         testRouter(1),
+			bdr:        testRouter(3),
+		},
+		{
+			name:       "highest declared DR wins",
+			candidates: []drCandidate{testCandidate(1, 1, 1, 0), testCandidate(2, 2, 2, 0)},
+			dr:         testRouter(2),
+			bdr:        Router{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dr, bdr := calculateDR(test.candidates)
+
+			if dr != test.dr {
+				t.Errorf("expected DR %v, got %v", test.dr, dr)
+			}
+
+			if bdr != test.bdr {
+				t.Errorf("expected BDR %v, got %v", test.bdr, bdr)
+			}
+		})
+	}
+}
+
+func TestElectDRBecomesDR(t *testing.T) {
+	iface := newTestBroadcastInterface(t)
+	iface.State = iWaiting
+
+	// A lower priority neighbor with no opinions yet.
+	h := testHello("2.2.2.2")
+	iface.handleHello(h, netip.MustParseAddr("10.0.0.2"))
+	h.neighbors = append(h.neighbors, iface.instance.RouterID)
+	h.routerPriority = 0
+	iface.handleHello(h, netip.MustParseAddr("10.0.0.2"))
+
+	iface.handleEvent(ieWaitTimer)
+
+	if iface.State != iDR {
+		t.Fatalf("expected DR, got %s", iface.State)
+	}
+
+	if iface.DR != iface.self() || iface.BDR.IsValid() {
+		t.Errorf("expected to be DR with no BDR, got %v, %v", iface.DR, iface.BDR)
+	}
+
+	// As DR, we're adjacent to everyone.
+	n := iface.Neighbors[mustParseRouterID("2.2.2.2")]
+	if n.state != nExStart {
+		t.Errorf("expected neighbor in ExStart, got %s", n.state)
+	}
+}
+
+func TestBackupSeenEndsWaiting(t *testing.T) {
+	iface := newTestBroadcastInterface(t)
+	iface.State = iWaiting
+
+	h := testHello("2.2.2.2")
+	h.designatedRouter = netip.MustParseAddr("10.0.0.2")
+	h.neighbors = append(h.neighbors, iface.instance.RouterID)
+
+	// The neighbor is declaring itself DR with no BDR, so there's no need
+	// to wait any longer.
+	iface.handleHello(h, netip.MustParseAddr("10.0.0.2"))
+
+	if iface.State != iBackup {
+		t.Fatalf("expected Backup, got %s", iface.State)
+	}
+
+	if iface.DR.ID != mustParseRouterID("2.2.2.2") || iface.BDR != iface.self() {
+		t.Errorf("unexpected DR and BDR: %v, %v", iface.DR, iface.BDR)
+	}
+}
+
+func TestDRElection(t *testing.T) {
+	network := newMemNetwork()
+
+	routers := []struct {
+		id       string
+		addr     string
+		priority uint8
+	}{
+		{"1.1.1.1", "10.0.0.1/24", 1},
+		{"2.2.2.2", "10.0.0.2/24", 10},
+		{"3.3.3.3", "10.0.0.3/24", 1},
+		{"4.4.4.4", "10.0.0.4/24", 0},
+	}
+
+	var instances []*Instance
+	var ifaces []*Interface
+
+	for i, r := range routers {
+		conf := testInterfaceConfig()
+		conf.RouterPriority = r.priority
+
+		inst := newTestInstance(t, r.id, network, map[string]config.OSPFInterfaceConfig{"eth0": conf})
+
+		netif := testNetif("eth0", i+1, r.addr)
+		netif.Flags |= net.FlagBroadcast
+
+		instances = append(instances, inst)
+		ifaces = append(ifaces, startTestInterface(t, inst, netif))
+	}
+
+	// The wait timer is RouterDeadInterval, so give it time to expire.
+	expected := []interfaceState{iDROther, iDR, iBackup, iDROther}
+
+	for i, inst := range instances {
+		iface := ifaces[i]
+		waitFor(t, inst, 10*time.Second, fmt.Sprintf("%s to become %s", routers[i].id, expected[i]), func() bool {
+			return iface.State == expected[i] &&
+				iface.DR.ID == mustParseRouterID("2.2.2.2") &&
+				iface.BDR.ID == mustParseRouterID("3.3.3.3")
+		})
+	}
+}
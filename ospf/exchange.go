@@ -0,0 +1,513 @@
+package ospf
+
+import (
+	"fmt"
+	"net/netip"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// ipHeaderLen is the length of the IPv4 header on the packets we send,
+// which have no IP options.
+const ipHeaderLen = 20
+
+// maxDDHeaders returns the number of LSA headers that fit in a Database
+// Description packet sent out of the interface.
+func (i *Interface) maxDDHeaders() int {
+	return atLeastOne((i.mtu - i.packetOverhead() - ddLen) / lsaHeaderLen)
+}
+
+// maxLSRequests returns the number of requests that fit in a Link State
+// Request packet sent out of the interface.
+func (i *Interface) maxLSRequests() int {
+	return atLeastOne((i.mtu - i.packetOverhead()) / lsReqItemLen)
+}
+
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+
+	return n
+}
+
+// maxLSUpdLen returns the maximum length of the LSAs in a Link State Update
+// sent out of the interface.
+func (i *Interface) maxLSUpdLen() int {
+	return i.mtu - i.packetOverhead() - lsUpdLen
+}
+
+// ddInterfaceMTU is the value of the Interface MTU field in our Database
+// Description packets. It's zero on virtual links.
+func (i *Interface) ddInterfaceMTU() uint16 {
+	if i.isVirtualLink() || i.mtu > 0xffff {
+		return 0
+	}
+
+	return uint16(i.mtu)
+}
+
+// lsdbFor returns the database that LSAs of type t are stored in.
+func (i *Interface) lsdbFor(t lsType) lsdb {
+	return i.instance.lsdbIn(i.scopeFor(t), t)
+}
+
+func (i *Interface) lookupLSA(key lsdbKey) (*installedLSA, bool) {
+	return i.instance.lookupLSAIn(i.scopeFor(key.Type), key)
+}
+
+// installLSA adds lsa to the database, replacing any older instance.
+func (i *Interface) installLSA(lsa LSA) {
+	i.instance.installLSAIn(i.scopeFor(lsa.Type()), lsa)
+}
+
+func isKnownLSType(t lsType) bool {
+	return (t >= lsTypeRouter && t <= lsTypeASExternal) || t == lsTypeNSSA || isOpaque(t)
+}
+
+// floodsExternal reports whether AS-external-LSAs are flooded over the
+// interface.
+func (i *Interface) floodsExternal() bool {
+	return i.area().ExternalRoutingCapability && !i.isVirtualLink()
+}
+
+// acceptsLSType reports whether LSAs of type t can be exchanged over the
+// interface. AS-scoped LSAs aren't flooded into stub areas, NSSAs or over
+// virtual links, and Type-7 LSAs only exist in NSSAs.
+func (i *Interface) acceptsLSType(t lsType) bool {
+	switch {
+	case !isKnownLSType(t):
+		return false
+	case isASScoped(t):
+		return i.floodsExternal()
+	case t == lsTypeNSSA:
+		return i.area().NSSA
+	default:
+		return true
+	}
+}
+
+// neighborFor returns the neighbor that sent a packet. On broadcast, NBMA
+// and Point-to-MultiPoint networks, neighbors are identified by their
+// address. On point-to-point networks and virtual links, they're identified
+// by their router ID.
+func (i *Interface) neighborFor(h *PacketHeader, src netip.Addr) (*Neighbor, bool) {
+	n, ok := i.Neighbors[h.routerID]
+	if !ok {
+		return nil, false
+	}
+
+	if !i.isPTP() && !i.isVirtualLink() && n.Addr != src {
+		return nil, false
+	}
+
+	return n, true
+}
+
+// addr returns the address that packets for the neighbor are sent to. On
+// point-to-point networks, that's AllSPFRouters, because the neighbor's
+// address might not be on our subnet.
+func (n *Neighbor) addr() netip.Addr {
+	if n.iface.isPTP() {
+		return AllSPFRouters
+	}
+
+	return n.Addr
+}
+
+// sendPacket sends p to the neighbor.
+func (n *Neighbor) sendPacket(p Packet) {
+	err := n.iface.sendPacket(p, n.addr())
+	if err != nil {
+		fmt.Printf("ospf: %s %s: failed to send packet to %s: %v\n", n.iface.name, n.iface.Prefix, n.ID, err)
+	}
+}
+
+func (n *Neighbor) restartRxmtTimer() {
+	d := time.Duration(n.iface.RxmtInterval) * time.Second
+
+	n.rxmtDeadline = time.Now().Add(d)
+	n.RxmtTimer.Reset(d)
+}
+
+// armRxmtTimer starts the retransmission timer unless it's already running.
+func (n *Neighbor) armRxmtTimer() {
+	if time.Now().Before(n.rxmtDeadline) {
+		return
+	}
+
+	n.restartRxmtTimer()
+}
+
+// handleRxmtTimer retransmits whatever the neighbor hasn't acknowledged.
+func (n *Neighbor) handleRxmtTimer() {
+	retransmitted := false
+
+	if n.state == nExStart || (n.state == nExchange && n.Master) {
+		if n.lastSentDD != nil {
+			n.sendPacket(n.lastSentDD)
+			retransmitted = true
+		}
+	}
+
+	if (n.state == nExchange || n.state == nLoading) && len(n.lsReqPending) > 0 {
+		n.sendLSRequest()
+		retransmitted = true
+	}
+
+	if n.state >= nExchange && len(n.RetransmissionList) > 0 {
+		n.retransmitLSAs()
+		retransmitted = true
+	}
+
+	if retransmitted {
+		n.restartRxmtTimer()
+	} else {
+		n.rxmtDeadline = time.Time{}
+	}
+}
+
+// sendInitialDD starts master/slave negotiation by sending an empty Database
+// Description packet with the I, M and MS bits set. It's retransmitted until
+// negotiation is done.
+func (n *Neighbor) sendInitialDD() {
+	n.lastSentDD = &DD{
+		interfaceMTU:   n.iface.ddInterfaceMTU(),
+		options:        n.iface.ddOptions(),
+		flags:          ddFlagI | ddFlagM | ddFlagMS,
+		sequenceNumber: n.DDSequenceNumber,
+	}
+
+	n.sendPacket(n.lastSentDD)
+	n.restartRxmtTimer()
+}
+
+// sendDD sends the next Database Description packet in the exchange,
+// describing as many LSAs from the Database summary list as will fit.
+func (n *Neighbor) sendDD() {
+	count := len(n.DatabaseSummaryList)
+	if count > n.iface.maxDDHeaders() {
+		count = n.iface.maxDDHeaders()
+	}
+
+	dd := &DD{
+		interfaceMTU:   n.iface.ddInterfaceMTU(),
+		options:        n.iface.ddOptions(),
+		sequenceNumber: n.DDSequenceNumber,
+		lsaHeaders:     n.DatabaseSummaryList[:count],
+	}
+
+	n.DatabaseSummaryList = n.DatabaseSummaryList[count:]
+
+	if len(n.DatabaseSummaryList) > 0 {
+		dd.flags |= ddFlagM
+	}
+
+	if n.Master {
+		dd.flags |= ddFlagMS
+	}
+
+	n.lastSentDD = dd
+	n.sendPacket(dd)
+
+	// Only the master retransmits. The slave just responds.
+	if n.Master {
+		n.restartRxmtTimer()
+	}
+}
+
+// buildDatabaseSummaryList lists the contents of the link state database in
+// the Database summary list when the neighbor enters Exchange. MaxAge LSAs
+// go on the retransmission list instead. Opaque LSAs are only listed for
+// neighbors that support them.
+func (n *Neighbor) buildDatabaseSummaryList() {
+	i := n.iface
+
+	add := func(db lsdb) {
+		for _, lsa := range db {
+			if !n.exchangesLSType(lsa.Type()) {
+				continue
+			}
+
+			h := headerOf(lsa)
+
+			if h.Age() >= maxAge {
+				n.RetransmissionList = append(n.RetransmissionList, h)
+			} else {
+				n.DatabaseSummaryList = append(n.DatabaseSummaryList, h)
+			}
+		}
+	}
+
+	add(i.area().lsdb)
+	add(i.lsdb)
+
+	if i.floodsExternal() {
+		add(i.instance.lsdb)
+	}
+
+	if len(n.RetransmissionList) > 0 {
+		n.armRxmtTimer()
+	}
+}
+
+func isDuplicateDD(dd, last *DD) bool {
+	return last != nil &&
+		dd.flags == last.flags &&
+		dd.options == last.options &&
+		dd.sequenceNumber == last.sequenceNumber
+}
+
+// handleDD processes a received Database Description packet, as described
+// in RFC 2328, section 10.6.
+func (i *Interface) handleDD(dd *DD, src netip.Addr) {
+	n, ok := i.neighborFor(&dd.PacketHeader, src)
+	if !ok {
+		return
+	}
+
+	if !i.isVirtualLink() && int(dd.interfaceMTU) > i.mtu {
+		fmt.Printf("ospf: %s %s: dropping DD from %s: MTU mismatch: got %d, expected at most %d\n", i.name, i.Prefix, n.ID, dd.interfaceMTU, i.mtu)
+		return
+	}
+
+	switch n.state {
+	case nDown, nAttempt, n2Way:
+		return
+	case nInit:
+		n.handleEvent(ne2WayReceived)
+		if n.state != nExStart {
+			return
+		}
+
+		fallthrough
+	case nExStart:
+		if dd.flags == ddFlagI|ddFlagM|ddFlagMS && len(dd.lsaHeaders) == 0 && n.ID > i.instance.RouterID {
+			n.Master = false
+			n.DDSequenceNumber = dd.sequenceNumber
+		} else if dd.flags&(ddFlagI|ddFlagMS) == 0 && dd.sequenceNumber == n.DDSequenceNumber && n.ID < i.instance.RouterID {
+			n.Master = true
+		} else {
+			return
+		}
+
+		n.Options = dd.options
+		n.handleEvent(neNegotiationDone)
+		n.acceptDD(dd)
+	case nExchange:
+		if isDuplicateDD(dd, n.LastReceivedDD) {
+			if !n.Master {
+				n.sendPacket(n.lastSentDD)
+			}
+			return
+		}
+
+		if (dd.flags&ddFlagMS != 0) != !n.Master {
+			n.seqNumberMismatch("master/slave bit mismatch")
+			return
+		}
+
+		if dd.flags&ddFlagI != 0 {
+			n.seqNumberMismatch("unexpected init bit")
+			return
+		}
+
+		if dd.options != n.LastReceivedDD.options {
+			n.seqNumberMismatch("options changed")
+			return
+		}
+
+		if (n.Master && dd.sequenceNumber != n.DDSequenceNumber) || (!n.Master && dd.sequenceNumber != n.DDSequenceNumber+1) {
+			n.seqNumberMismatch(fmt.Sprintf("unexpected sequence number %d", dd.sequenceNumber))
+			return
+		}
+
+		n.acceptDD(dd)
+	case nLoading, nFull:
+		if isDuplicateDD(dd, n.LastReceivedDD) {
+			if !n.Master {
+				n.sendPacket(n.lastSentDD)
+			}
+			return
+		}
+
+		n.seqNumberMismatch("unexpected DD after exchange")
+	}
+}
+
+func (n *Neighbor) seqNumberMismatch(reason string) {
+	fmt.Printf("ospf: %s %s: neighbor %s: sequence number mismatch: %s\n", n.iface.name, n.iface.Prefix, n.ID, reason)
+	n.handleEvent(neSeqNumberMismatch)
+}
+
+// acceptDD processes a DD that's next in sequence.
+func (n *Neighbor) acceptDD(dd *DD) {
+	i := n.iface
+
+	n.LastReceivedDD = dd
+
+	for _, h := range dd.lsaHeaders {
+		if !i.acceptsLSType(h.Type()) {
+			n.seqNumberMismatch(fmt.Sprintf("unexpected LS type %d", h.Type()))
+			return
+		}
+
+		lsa, ok := i.lookupLSA(h.Key())
+		if !ok || h.Compare(lsa) > 0 {
+			n.LinkStateRequestList = append(n.LinkStateRequestList, h)
+		}
+	}
+
+	if n.Master {
+		n.DDSequenceNumber++
+
+		// The DD we just received acknowledges our last one.
+		sentAll := n.lastSentDD.flags&ddFlagM == 0 && n.lastSentDD.flags&ddFlagI == 0
+		if sentAll && dd.flags&ddFlagM == 0 {
+			n.lastSentDD = nil
+			n.handleEvent(neExchangeDone)
+		} else {
+			n.sendDD()
+		}
+	} else {
+		n.DDSequenceNumber = dd.sequenceNumber
+		n.sendDD()
+
+		if dd.flags&ddFlagM == 0 && n.lastSentDD.flags&ddFlagM == 0 {
+			n.handleEvent(neExchangeDone)
+		}
+	}
+
+	if n.state == nExchange || n.state == nLoading {
+		if len(n.lsReqPending) == 0 && len(n.LinkStateRequestList) > 0 {
+			n.sendLSRequest()
+		}
+	}
+}
+
+// sendLSRequest requests the LSAs at the front of the Link state request
+// list. The request is retransmitted until they've all been received.
+func (n *Neighbor) sendLSRequest() {
+	count := len(n.LinkStateRequestList)
+	if count > n.iface.maxLSRequests() {
+		count = n.iface.maxLSRequests()
+	}
+
+	req := &LSReq{}
+	n.lsReqPending = make(map[lsdbKey]bool)
+
+	for _, h := range n.LinkStateRequestList[:count] {
+		req.requests = append(req.requests, h.Key())
+		n.lsReqPending[h.Key()] = true
+	}
+
+	n.sendPacket(req)
+	n.restartRxmtTimer()
+}
+
+// onRequestList reports whether an instance of the LSA identified by key is
+// on the Link state request list.
+func (n *Neighbor) onRequestList(key lsdbKey) bool {
+	return slices.IndexFunc(n.LinkStateRequestList, func(h *lsaHeader) bool {
+		return h.Key() == key
+	}) != -1
+}
+
+// requestReceived removes lsa from the Link state request list, as long as
+// it's at least as recent as the instance we asked for.
+func (n *Neighbor) requestReceived(lsa LSA) {
+	idx := slices.IndexFunc(n.LinkStateRequestList, func(h *lsaHeader) bool {
+		return h.Key() == lsa.Key()
+	})
+
+	if idx == -1 || lsa.Compare(n.LinkStateRequestList[idx]) < 0 {
+		return
+	}
+
+	n.LinkStateRequestList = slices.Delete(n.LinkStateRequestList, idx, idx+1)
+	delete(n.lsReqPending, lsa.Key())
+}
+
+// checkRequests sends the next Link State Request once the previous one has
+// been answered, and finishes loading when there's nothing left to request.
+func (n *Neighbor) checkRequests() {
+	if n.state != nExchange && n.state != nLoading {
+		return
+	}
+
+	if len(n.lsReqPending) > 0 {
+		return
+	}
+
+	if len(n.LinkStateRequestList) > 0 {
+		n.sendLSRequest()
+	} else if n.state == nLoading {
+		n.handleEvent(neLoadingDone)
+	}
+}
+
+// handleLSReq processes a received Link State Request, as described in RFC
+// 2328, section 10.7.
+func (i *Interface) handleLSReq(req *LSReq, src netip.Addr) {
+	n, ok := i.neighborFor(&req.PacketHeader, src)
+	if !ok || n.state < nExchange {
+		return
+	}
+
+	var lsas []LSA
+
+	for _, key := range req.requests {
+		lsa, ok := i.lookupLSA(key)
+		if !ok {
+			fmt.Printf("ospf: %s %s: neighbor %s requested unknown LSA %v\n", i.name, i.Prefix, n.ID, key)
+			n.handleEvent(neBadLSReq)
+			return
+		}
+
+		lsas = append(lsas, i.transmitCopy(lsa))
+	}
+
+	i.sendLSUpdates(lsas, n.addr())
+}
+
+// sendLSUpdates sends lsas to dst in as many Link State Update packets as
+// it takes.
+func (i *Interface) sendLSUpdates(lsas []LSA, dst netip.Addr) {
+	maxLen := i.maxLSUpdLen()
+
+	for len(lsas) > 0 {
+		upd := &LSUpd{}
+		length := 0
+
+		for len(lsas) > 0 {
+			l := len(lsas[0].Bytes())
+			if len(upd.lsas) > 0 && length+l > maxLen {
+				break
+			}
+
+			upd.lsas = append(upd.lsas, lsas[0])
+			length += l
+			lsas = lsas[1:]
+		}
+
+		err := i.sendPacket(upd, dst)
+		if err != nil {
+			fmt.Printf("ospf: %s %s: failed to send %s to %s: %v\n", i.name, i.Prefix, pLSUpd, dst, err)
+		}
+	}
+}
+
+// transmitCopy returns a copy of lsa to send out the interface, with its
+// age incremented by InfTransDelay.
+func (i *Interface) transmitCopy(lsa LSA) LSA {
+	c, _ := ParseLSA(lsa.Bytes())
+
+	age := int(lsa.Age()) + i.InfTransDelay
+	if age > maxAge {
+		age = maxAge
+	}
+	c.SetAge(uint16(age))
+
+	return c
+}
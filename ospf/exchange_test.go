@@ -0,0 +1,216 @@
+package ospf
+
+import (
+	"fmt"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+)
+
+// newTestAdjacency returns a point-to-point interface with a neighbor with
+// router ID id that's in ExStart, and a transport that receives the packets
+// we send to the neighbor.
+func newTestAdjacency(t *testing.T, id string) (*Interface, *Neighbor, *memTransport) {
+	t.Helper()
+
+	iface := newTestBroadcastInterface(t)
+	iface.Type = InterfacePointToPoint
+	iface.State = iPointToPoint
+
+	peer := iface.transport.(*memTransport).network.attach(netip.MustParseAddr("10.0.0.2"), 1)
+	peer.joinGroup(AllSPFRouters)
+	t.Cleanup(func() { peer.close() })
+
+	h := testHello(id)
+	h.neighbors = append(h.neighbors, iface.instance.RouterID)
+	iface.handleHello(h, netip.MustParseAddr("10.0.0.2"))
+
+	n := iface.Neighbors[mustParseRouterID(id)]
+	assertNeighborState(t, n, nExStart)
+
+	dd := receiveTestPacket[*DD](t, peer)
+	if dd.flags != ddFlagI|ddFlagM|ddFlagMS || dd.sequenceNumber != n.DDSequenceNumber {
+		t.Fatalf("expected initial DD with sequence number %d, got %s, %d", n.DDSequenceNumber, dd.flags, dd.sequenceNumber)
+	}
+
+	return iface, n, peer
+}
+
+// receiveTestPacket returns the next packet received by peer, which must be
+// a P.
+func receiveTestPacket[P Packet](t *testing.T, peer *memTransport) P {
+	t.Helper()
+
+	select {
+	case rp := <-peer.packets:
+		p, err := ParsePacket(rp.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pp, ok := p.(P)
+		if !ok {
+			t.Fatalf("expected %T, got %T", pp, p)
+		}
+
+		return pp
+	case <-time.After(time.Second):
+		var p P
+		t.Fatalf("timed out waiting for %T", p)
+		return p
+	}
+}
+
+func testDD(from string, flags ddFlags, seq uint32, headers ...*lsaHeader) *DD {
+	return &DD{
+		PacketHeader:   PacketHeader{routerID: mustParseRouterID(from)},
+		interfaceMTU:   1500,
+		options:        optE,
+		flags:          flags,
+		sequenceNumber: seq,
+		lsaHeaders:     headers,
+	}
+}
+
+func TestDDNegotiationSlave(t *testing.T) {
+	iface, n, peer := newTestAdjacency(t, "2.2.2.2")
+	src := netip.MustParseAddr("10.0.0.2")
+
+	// The neighbor has a higher router ID, so it's master.
+	iface.handleDD(testDD("2.2.2.2", ddFlagI|ddFlagM|ddFlagMS, 1000), src)
+	assertNeighborState(t, n, nExchange)
+
+	if n.Master || n.DDSequenceNumber != 1000 {
+		t.Fatalf("expected to be slave with sequence number 1000, got %v, %d", n.Master, n.DDSequenceNumber)
+	}
+
+	dd := receiveTestPacket[*DD](t, peer)
+	if dd.flags != 0 || dd.sequenceNumber != 1000 {
+		t.Errorf("expected DD with no flags and sequence number 1000, got %s, %d", dd.flags, dd.sequenceNumber)
+	}
+
+	// Duplicates are answered by resending our last DD.
+	iface.handleDD(testDD("2.2.2.2", ddFlagI|ddFlagM|ddFlagMS, 1000), src)
+	assertNeighborState(t, n, nExchange)
+	receiveTestPacket[*DD](t, peer)
+
+	// Skipping a sequence number is an error.
+	iface.handleDD(testDD("2.2.2.2", ddFlagMS, 1002), src)
+	assertNeighborState(t, n, nExStart)
+}
+
+func TestDDNegotiationMaster(t *testing.T) {
+	iface, n, peer := newTestAdjacency(t, "0.0.0.9")
+	src := netip.MustParseAddr("10.0.0.2")
+
+	lsa := testRouterLSA("0.0.0.9", initialSequenceNumber)
+	seq := n.DDSequenceNumber
+
+	// The neighbor has a lower router ID, so it agrees to be slave.
+	iface.handleDD(testDD("0.0.0.9", ddFlagM, seq, &lsa.lsaHeader), src)
+	assertNeighborState(t, n, nExchange)
+
+	if !n.Master || n.DDSequenceNumber != seq+1 {
+		t.Fatalf("expected to be master with sequence number %d, got %v, %d", seq+1, n.Master, n.DDSequenceNumber)
+	}
+
+	dd := receiveTestPacket[*DD](t, peer)
+	if dd.flags != ddFlagMS || dd.sequenceNumber != seq+1 {
+		t.Errorf("expected DD with MS and sequence number %d, got %s, %d", seq+1, dd.flags, dd.sequenceNumber)
+	}
+
+	req := receiveTestPacket[*LSReq](t, peer)
+	if len(req.requests) != 1 || req.requests[0] != lsa.Key() {
+		t.Errorf("expected request for %v, got %v", lsa.Key(), req.requests)
+	}
+
+	iface.handleDD(testDD("0.0.0.9", 0, seq+1), src)
+	assertNeighborState(t, n, nLoading)
+
+	iface.handleLSUpd(&LSUpd{PacketHeader: PacketHeader{routerID: n.ID}, lsas: []LSA{lsa}}, src)
+	assertNeighborState(t, n, nFull)
+
+	if _, ok := iface.lookupLSA(lsa.Key()); !ok {
+		t.Errorf("expected %v to be installed", lsa.Key())
+	}
+}
+
+func TestDDMTUMismatch(t *testing.T) {
+	iface, n, _ := newTestAdjacency(t, "2.2.2.2")
+
+	dd := testDD("2.2.2.2", ddFlagI|ddFlagM|ddFlagMS, 1000)
+	dd.interfaceMTU = 9000
+
+	iface.handleDD(dd, netip.MustParseAddr("10.0.0.2"))
+	assertNeighborState(t, n, nExStart)
+}
+
+func TestBadLSReq(t *testing.T) {
+	iface, n, peer := newTestAdjacency(t, "2.2.2.2")
+	src := netip.MustParseAddr("10.0.0.2")
+
+	lsa := testRouterLSA("1.1.1.1", initialSequenceNumber)
+	iface.installLSA(lsa)
+
+	iface.handleDD(testDD("2.2.2.2", ddFlagI|ddFlagM|ddFlagMS, 1000), src)
+	assertNeighborState(t, n, nExchange)
+	receiveTestPacket[*DD](t, peer)
+
+	iface.handleLSReq(&LSReq{PacketHeader: PacketHeader{routerID: n.ID}, requests: []lsdbKey{lsa.Key()}}, src)
+	upd := receiveTestPacket[*LSUpd](t, peer)
+	if len(upd.lsas) != 1 || upd.lsas[0].Key() != lsa.Key() {
+		t.Errorf("expected update containing %v, got %v", lsa.Key(), upd.lsas)
+	}
+
+	unknown := testRouterLSA("3.3.3.3", initialSequenceNumber)
+	iface.handleLSReq(&LSReq{PacketHeader: PacketHeader{routerID: n.ID}, requests: []lsdbKey{unknown.Key()}}, src)
+	assertNeighborState(t, n, nExStart)
+}
+
+func TestDatabaseExchange(t *testing.T) {
+	network := newMemNetwork()
+
+	r1 := newTestInstance(t, "1.1.1.1", network, map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig()})
+	r2 := newTestInstance(t, "2.2.2.2", network, map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig()})
+
+	// Enough LSAs that describing and requesting them takes more than one
+	// packet.
+	for i := 0; i < 300; i++ {
+		id := fmt.Sprintf("10.%d.%d.0", i/256, i%256)
+		lsa := newTestLSA(lsTypeSummary, id, "1.1.1.1", initialSequenceNumber, make([]byte, 8))
+		r1.Areas[0].lsdb[lsa.Key()] = &installedLSA{LSA: lsa, installedAt: time.Now()}
+	}
+
+	external := newTestLSA(lsTypeASExternal, "0.0.0.0", "2.2.2.2", initialSequenceNumber, make([]byte, 16))
+	r2.lsdb[external.Key()] = &installedLSA{LSA: external, installedAt: time.Now()}
+
+	startTestInterface(t, r1, testNetif("eth0", 1, "10.0.0.1/24"))
+	startTestInterface(t, r2, testNetif("eth0", 2, "10.0.0.2/24"))
+
+	for _, inst := range []*Instance{r1, r2} {
+		waitFor(t, inst, 10*time.Second, fmt.Sprintf("%s to reach Full", inst.RouterID), func() bool {
+			for _, iface := range inst.Interfaces {
+				for _, n := range iface.Neighbors {
+					return n.state == nFull
+				}
+			}
+
+			return false
+		})
+	}
+
+	r1.mu.Lock()
+	defer r1.mu.Unlock()
+	r2.mu.Lock()
+	defer r2.mu.Unlock()
+
+	if len(r2.Areas[0].lsdb) != len(r1.Areas[0].lsdb) {
+		t.Errorf("expected %d LSAs in r2's area database, got %d", len(r1.Areas[0].lsdb), len(r2.Areas[0].lsdb))
+	}
+
+	if _, ok := r1.lsdb[external.Key()]; !ok {
+		t.Errorf("expected r1 to have %v", external.Key())
+	}
+}
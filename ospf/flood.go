@@ -0,0 +1,393 @@
+package ospf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// ackDelay is how long acknowledgments are held so that they can be sent
+// together. It must be shorter than RxmtInterval.
+const ackDelay = 1 * time.Second
+
+// NeighborStats counts flooding activity for a neighbor.
+type NeighborStats struct {
+	Retransmissions uint64 // LSAs retransmitted to the neighbor
+	DuplicateLSAs   uint64 // LSAs received that matched our database copy
+}
+
+// handleLSUpd processes a received Link State Update, as described in RFC
+// 2328, section 13.
+func (i *Interface) handleLSUpd(upd *LSUpd, src netip.Addr) {
+	n, ok := i.neighborFor(&upd.PacketHeader, src)
+	if !ok || n.state < nExchange {
+		return
+	}
+
+	var directAcks []*lsaHeader
+
+	for _, lsa := range upd.lsas {
+		// (1), (2) and (3)
+		if !lsa.IsChecksumValid() {
+			fmt.Printf("ospf: %s %s: dropping LSA %v from %s: bad checksum\n", i.name, i.Prefix, lsa.Key(), n.ID)
+			continue
+		}
+
+		if !i.acceptsLSType(lsa.Type()) {
+			continue
+		}
+
+		installed, ok := i.lookupLSA(lsa.Key())
+		scope := i.scopeFor(lsa.Type())
+
+		// (4)
+		if !ok && lsa.Age() == maxAge && !i.instance.hasExchangingNeighbors() {
+			directAcks = append(directAcks, headerOf(lsa))
+			continue
+		}
+
+		// (5)
+		if !ok || lsa.Compare(installed) > 0 {
+			if ok && time.Since(installed.installedAt) < minLSArrival*time.Second {
+				continue
+			}
+
+			if ok {
+				i.instance.removeFromRetransmissionLists(scope, lsa.Key())
+			}
+
+			floodedBack := i.instance.floodLSA(lsa, scope, n)
+			i.installLSA(lsa)
+
+			if !floodedBack && (i.State != iBackup || n.ID == i.DR.ID) {
+				i.delayAck(lsa)
+			}
+
+			n.requestReceived(lsa)
+
+			if i.instance.isSelfOriginated(lsa) {
+				i.instance.handleSelfOriginated(scope, lsa)
+			}
+
+			continue
+		}
+
+		// (6)
+		if n.onRequestList(lsa.Key()) {
+			n.handleEvent(neBadLSReq)
+			return
+		}
+
+		// (7)
+		if lsa.Compare(installed) == 0 {
+			n.Stats.DuplicateLSAs++
+
+			if n.removeFromRetransmissionList(lsa) {
+				// An implied acknowledgment.
+				if i.State == iBackup && n.ID == i.DR.ID {
+					i.delayAck(lsa)
+				}
+			} else {
+				directAcks = append(directAcks, headerOf(lsa))
+			}
+
+			continue
+		}
+
+		// (8) Our copy is more recent.
+		if installed.Age() == maxAge && installed.SequenceNumber() == maxSequenceNumber {
+			continue
+		}
+
+		if time.Since(installed.installedAt) >= minLSArrival*time.Second {
+			i.sendLSUpdates([]LSA{i.transmitCopy(installed)}, n.addr())
+		}
+	}
+
+	if len(directAcks) > 0 {
+		n.sendPacket(&LSAck{lsaHeaders: directAcks})
+	}
+
+	n.checkRequests()
+}
+
+// headerOf returns a copy of lsa's header, with its current age.
+func headerOf(lsa LSA) *lsaHeader {
+	h, _ := parseLSAHeader(lsa.Bytes())
+
+	h.age = lsa.Age()
+	binary.BigEndian.PutUint16(h.bytes[0:2], h.age)
+
+	return h
+}
+
+// hasExchangingNeighbors reports whether any neighbor is in Exchange or
+// Loading.
+func (inst *Instance) hasExchangingNeighbors() bool {
+	for _, iface := range inst.Interfaces {
+		for _, n := range iface.Neighbors {
+			if n.state == nExchange || n.state == nLoading {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// floodingScope returns the interfaces that an LSA of type t in scope s is
+// flooded out of. AS-external-LSAs and AS-scoped opaque LSAs are flooded
+// throughout the AS, except into stub areas, NSSAs and over virtual links.
+// Link-local opaque LSAs stay on their interface, and everything else,
+// including Type-7 LSAs, stays in its area.
+func (inst *Instance) floodingScope(t lsType, s lsaScope) []*Interface {
+	if t == lsTypeOpaqueLink {
+		return []*Interface{s.iface}
+	}
+
+	var ifaces []*Interface
+
+	for _, iface := range inst.Interfaces {
+		if isASScoped(t) && iface.floodsExternal() {
+			ifaces = append(ifaces, iface)
+		} else if !isASScoped(t) && iface.AreaID == s.areaID {
+			ifaces = append(ifaces, iface)
+		}
+	}
+
+	return ifaces
+}
+
+// floodLSA floods lsa out of the interfaces in its flooding scope, as
+// described in RFC 2328, section 13.3. from is the neighbor that sent us
+// lsa, or nil if we originated it. It reports whether lsa was flooded back
+// out of the interface it was received on.
+func (inst *Instance) floodLSA(lsa LSA, s lsaScope, from *Neighbor) bool {
+	floodedBack := false
+
+	for _, iface := range inst.floodingScope(lsa.Type(), s) {
+		if iface.floodLSA(lsa, from) && from != nil && iface == from.iface {
+			floodedBack = true
+		}
+	}
+
+	return floodedBack
+}
+
+// floodLSA floods lsa out of the interface, reporting whether it was sent.
+func (i *Interface) floodLSA(lsa LSA, from *Neighbor) bool {
+	added := false
+
+	// (1)
+	for _, n := range i.Neighbors {
+		if n.state < nExchange || !n.exchangesLSType(lsa.Type()) {
+			continue
+		}
+
+		if n.state == nExchange || n.state == nLoading {
+			idx := slices.IndexFunc(n.LinkStateRequestList, func(h *lsaHeader) bool {
+				return h.Key() == lsa.Key()
+			})
+
+			if idx != -1 {
+				cmp := lsa.Compare(n.LinkStateRequestList[idx])
+				if cmp < 0 {
+					continue
+				}
+
+				n.LinkStateRequestList = slices.Delete(n.LinkStateRequestList, idx, idx+1)
+				delete(n.lsReqPending, lsa.Key())
+				n.checkRequests()
+
+				if cmp == 0 {
+					continue
+				}
+			}
+		}
+
+		if n == from {
+			continue
+		}
+
+		n.addToRetransmissionList(lsa)
+		n.armRxmtTimer()
+		added = true
+	}
+
+	// (2)
+	if !added {
+		return false
+	}
+
+	// (3) and (4)
+	if from != nil && from.iface == i {
+		if from.ID == i.DR.ID || from.ID == i.BDR.ID || i.State == iBackup {
+			return false
+		}
+	}
+
+	// (5)
+	c := i.transmitCopy(lsa)
+	for _, dst := range i.floodDestinations() {
+		i.sendLSUpdates([]LSA{c}, dst)
+	}
+
+	return true
+}
+
+// floodDestinations returns the addresses that Link State Updates and
+// delayed acknowledgments are sent to, as described in RFC 2328, section
+// 13.3. On broadcast networks, only the DR and BDR listen to AllDRouters.
+// On NBMA and Point-to-MultiPoint networks and virtual links, packets are
+// sent to each adjacent neighbor.
+func (i *Interface) floodDestinations() []netip.Addr {
+	switch {
+	case i.isPTP():
+		return []netip.Addr{AllSPFRouters}
+	case i.Type == InterfaceBroadcast:
+		if i.State == iDR || i.State == iBackup {
+			return []netip.Addr{AllSPFRouters}
+		}
+
+		return []netip.Addr{AllDRouters}
+	default:
+		var dsts []netip.Addr
+		for _, n := range i.Neighbors {
+			if n.state >= nExchange {
+				dsts = append(dsts, n.Addr)
+			}
+		}
+
+		return dsts
+	}
+}
+
+func (n *Neighbor) retransmissionIndex(key lsdbKey) int {
+	return slices.IndexFunc(n.RetransmissionList, func(h *lsaHeader) bool {
+		return h.Key() == key
+	})
+}
+
+// addToRetransmissionList adds lsa to the retransmission list, replacing
+// any other instance of it.
+func (n *Neighbor) addToRetransmissionList(lsa LSA) {
+	if idx := n.retransmissionIndex(lsa.Key()); idx != -1 {
+		n.RetransmissionList[idx] = headerOf(lsa)
+	} else {
+		n.RetransmissionList = append(n.RetransmissionList, headerOf(lsa))
+	}
+}
+
+// removeFromRetransmissionList removes lsa from the retransmission list if
+// the same instance is on it, reporting whether it was.
+func (n *Neighbor) removeFromRetransmissionList(lsa LSAMetadata) bool {
+	idx := n.retransmissionIndex(lsa.Key())
+	if idx == -1 || lsa.Compare(n.RetransmissionList[idx]) != 0 {
+		return false
+	}
+
+	n.RetransmissionList = slices.Delete(n.RetransmissionList, idx, idx+1)
+	return true
+}
+
+// removeFromRetransmissionLists removes every instance of the LSA
+// identified by key in scope s from the retransmission lists of neighbors
+// in its flooding scope. Our router-LSAs have the same key in every area,
+// so neighbors in other areas are left alone.
+func (inst *Instance) removeFromRetransmissionLists(s lsaScope, key lsdbKey) {
+	for _, iface := range inst.floodingScope(key.Type, s) {
+		for _, n := range iface.Neighbors {
+			if idx := n.retransmissionIndex(key); idx != -1 {
+				n.RetransmissionList = slices.Delete(n.RetransmissionList, idx, idx+1)
+			}
+		}
+	}
+}
+
+// retransmitLSAs resends the LSAs on the retransmission list directly to
+// the neighbor, as many as fit in a single Link State Update.
+func (n *Neighbor) retransmitLSAs() {
+	i := n.iface
+
+	var lsas []LSA
+	length := 0
+
+	for _, h := range n.RetransmissionList {
+		lsa, ok := i.lookupLSA(h.Key())
+		if !ok || lsa.Compare(h) != 0 {
+			continue
+		}
+
+		l := len(lsa.Bytes())
+		if len(lsas) > 0 && length+l > i.maxLSUpdLen() {
+			break
+		}
+
+		lsas = append(lsas, i.transmitCopy(lsa))
+		length += l
+	}
+
+	if len(lsas) == 0 {
+		return
+	}
+
+	n.Stats.Retransmissions += uint64(len(lsas))
+	i.sendLSUpdates(lsas, n.addr())
+}
+
+// delayAck queues an acknowledgment for lsa, to be sent out of the
+// interface along with any others when the ack timer fires.
+func (i *Interface) delayAck(lsa LSA) {
+	if len(i.delayedAcks) == 0 {
+		i.AckTimer.Reset(ackDelay)
+	}
+
+	i.delayedAcks = append(i.delayedAcks, headerOf(lsa))
+}
+
+// sendDelayedAcks sends the queued acknowledgments, as many per packet as
+// fit.
+func (i *Interface) sendDelayedAcks() {
+	maxHeaders := atLeastOne((i.mtu - i.packetOverhead()) / lsaHeaderLen)
+
+	for len(i.delayedAcks) > 0 {
+		count := len(i.delayedAcks)
+		if count > maxHeaders {
+			count = maxHeaders
+		}
+
+		ack := &LSAck{lsaHeaders: i.delayedAcks[:count]}
+		i.delayedAcks = i.delayedAcks[count:]
+
+		for _, dst := range i.floodDestinations() {
+			err := i.sendPacket(ack, dst)
+			if err != nil {
+				fmt.Printf("ospf: %s %s: failed to send %s to %s: %v\n", i.name, i.Prefix, pLSAck, dst, err)
+			}
+		}
+	}
+
+	i.delayedAcks = nil
+}
+
+// handleLSAck processes a received Link State Acknowledgment, as described
+// in RFC 2328, section 13.7.
+func (i *Interface) handleLSAck(ack *LSAck, src netip.Addr) {
+	n, ok := i.neighborFor(&ack.PacketHeader, src)
+	if !ok || n.state < nExchange {
+		return
+	}
+
+	for _, h := range ack.lsaHeaders {
+		if n.removeFromRetransmissionList(h) {
+			continue
+		}
+
+		if idx := n.retransmissionIndex(h.Key()); idx != -1 {
+			fmt.Printf("ospf: %s %s: questionable acknowledgment of %v from %s\n", i.name, i.Prefix, h.Key(), n.ID)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package ospf
+
+import (
+	"fmt"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+)
+
+// newTestFloodingInterface returns a broadcast interface on which we're DR,
+// with fully adjacent neighbors 2.2.2.2 at 10.0.0.2 and 3.3.3.3 at
+// 10.0.0.3. The returned transports receive the neighbors' packets.
+func newTestFloodingInterface(t *testing.T) (*Interface, []*Neighbor, []*memTransport) {
+	t.Helper()
+
+	iface := newTestBroadcastInterface(t)
+	iface.State = iDR
+	iface.DR = iface.self()
+
+	var neighbors []*Neighbor
+	var peers []*memTransport
+
+	for _, n := range []int{2, 3} {
+		addr := netip.AddrFrom4([4]byte{10, 0, 0, byte(n)})
+
+		peer := iface.transport.(*memTransport).network.attach(addr, 1)
+		peer.joinGroup(AllSPFRouters)
+		t.Cleanup(func() { peer.close() })
+
+		nbr := newNeighbor(iface, mustParseRouterID(fmt.Sprintf("%d.%d.%d.%d", n, n, n, n)), addr)
+		nbr.state = nFull
+		iface.Neighbors[nbr.ID] = nbr
+
+		neighbors = append(neighbors, nbr)
+		peers = append(peers, peer)
+	}
+
+	return iface, neighbors, peers
+}
+
+func testLSUpd(from *Neighbor, lsas ...LSA) *LSUpd {
+	return &LSUpd{PacketHeader: PacketHeader{routerID: from.ID}, lsas: lsas}
+}
+
+func TestFloodNewerLSA(t *testing.T) {
+	iface, nbrs, peers := newTestFloodingInterface(t)
+
+	lsa := testRouterLSA("2.2.2.2", initialSequenceNumber)
+	iface.handleLSUpd(testLSUpd(nbrs[0], lsa), nbrs[0].Addr)
+
+	if _, ok := iface.lookupLSA(lsa.Key()); !ok {
+		t.Fatalf("expected %v to be installed", lsa.Key())
+	}
+
+	// It's flooded to 3.3.3.3, but not back to the neighbor it came from.
+	if len(nbrs[0].RetransmissionList) != 0 {
+		t.Errorf("expected nothing to retransmit to %s, got %d", nbrs[0].ID, len(nbrs[0].RetransmissionList))
+	}
+
+	if len(nbrs[1].RetransmissionList) != 1 {
+		t.Fatalf("expected 1 LSA to retransmit to %s, got %d", nbrs[1].ID, len(nbrs[1].RetransmissionList))
+	}
+
+	upd := receiveTestPacket[*LSUpd](t, peers[1])
+	if len(upd.lsas) != 1 || upd.lsas[0].Key() != lsa.Key() || upd.lsas[0].Age() != lsa.Age()+1 {
+		t.Errorf("expected %v with age %d, got %v", lsa.Key(), lsa.Age()+1, upd.lsas)
+	}
+
+	// Flooding it back out the receiving interface is an implied
+	// acknowledgment, so there's nothing to delay.
+	if len(iface.delayedAcks) != 0 {
+		t.Errorf("expected no delayed acks, got %d", len(iface.delayedAcks))
+	}
+
+	iface.handleLSAck(&LSAck{PacketHeader: PacketHeader{routerID: nbrs[1].ID}, lsaHeaders: []*lsaHeader{headerOf(upd.lsas[0])}}, nbrs[1].Addr)
+
+	if len(nbrs[1].RetransmissionList) != 0 {
+		t.Errorf("expected ack to clear the retransmission list")
+	}
+}
+
+func TestFloodDuplicateLSA(t *testing.T) {
+	iface, nbrs, peers := newTestFloodingInterface(t)
+
+	lsa := testRouterLSA("2.2.2.2", initialSequenceNumber)
+	iface.handleLSUpd(testLSUpd(nbrs[0], lsa), nbrs[0].Addr)
+	receiveTestPacket[*LSUpd](t, peers[0])
+	receiveTestPacket[*LSUpd](t, peers[1])
+
+	// 3.3.3.3 sending it back to us is an implied acknowledgment.
+	iface.handleLSUpd(testLSUpd(nbrs[1], lsa), nbrs[1].Addr)
+
+	if len(nbrs[1].RetransmissionList) != 0 {
+		t.Errorf("expected implied ack to clear the retransmission list")
+	}
+
+	// 2.2.2.2 sending it again gets a direct acknowledgment.
+	iface.handleLSUpd(testLSUpd(nbrs[0], lsa), nbrs[0].Addr)
+
+	ack := receiveTestPacket[*LSAck](t, peers[0])
+	if len(ack.lsaHeaders) != 1 || ack.lsaHeaders[0].Key() != lsa.Key() {
+		t.Errorf("expected ack for %v, got %v", lsa.Key(), ack.lsaHeaders)
+	}
+
+	if nbrs[0].Stats.DuplicateLSAs != 1 || nbrs[1].Stats.DuplicateLSAs != 1 {
+		t.Errorf("expected 1 duplicate from each neighbor, got %d, %d", nbrs[0].Stats.DuplicateLSAs, nbrs[1].Stats.DuplicateLSAs)
+	}
+}
+
+func TestFloodMinLSArrival(t *testing.T) {
+	iface, nbrs, _ := newTestFloodingInterface(t)
+
+	lsa := testRouterLSA("2.2.2.2", initialSequenceNumber)
+	iface.handleLSUpd(testLSUpd(nbrs[0], lsa), nbrs[0].Addr)
+
+	newer := testRouterLSA("2.2.2.2", initialSequenceNumber+1)
+	iface.handleLSUpd(testLSUpd(nbrs[0], newer), nbrs[0].Addr)
+
+	installed, _ := iface.lookupLSA(lsa.Key())
+	if installed.SequenceNumber() != initialSequenceNumber {
+		t.Errorf("expected newer instance to be discarded, got sequence number %d", installed.SequenceNumber())
+	}
+
+	iface.lsdbFor(lsTypeRouter)[lsa.Key()].installedAt = time.Now().Add(-minLSArrival * time.Second)
+	iface.handleLSUpd(testLSUpd(nbrs[0], newer), nbrs[0].Addr)
+
+	installed, _ = iface.lookupLSA(lsa.Key())
+	if installed.SequenceNumber() != initialSequenceNumber+1 {
+		t.Errorf("expected newer instance to be installed, got sequence number %d", installed.SequenceNumber())
+	}
+}
+
+func TestFloodOlderLSA(t *testing.T) {
+	iface, nbrs, peers := newTestFloodingInterface(t)
+
+	lsa := testRouterLSA("2.2.2.2", initialSequenceNumber+1)
+	iface.installLSA(lsa)
+	iface.lsdbFor(lsTypeRouter)[lsa.Key()].installedAt = time.Now().Add(-minLSArrival * time.Second)
+
+	older := testRouterLSA("2.2.2.2", initialSequenceNumber)
+	iface.handleLSUpd(testLSUpd(nbrs[0], older), nbrs[0].Addr)
+
+	// We send our copy back to the neighbor.
+	upd := receiveTestPacket[*LSUpd](t, peers[0])
+	if len(upd.lsas) != 1 || upd.lsas[0].SequenceNumber() != initialSequenceNumber+1 {
+		t.Errorf("expected our copy of %v, got %v", lsa.Key(), upd.lsas)
+	}
+}
+
+func TestRetransmission(t *testing.T) {
+	iface, nbrs, peers := newTestFloodingInterface(t)
+	iface.RxmtInterval = 1
+
+	inst := iface.instance
+	go nbrs[1].run()
+
+	lsa := testRouterLSA("2.2.2.2", initialSequenceNumber)
+
+	inst.mu.Lock()
+	iface.handleLSUpd(testLSUpd(nbrs[0], lsa), nbrs[0].Addr)
+	inst.mu.Unlock()
+
+	receiveTestPacket[*LSUpd](t, peers[1])
+
+	// Without an acknowledgment, 3.3.3.3 gets the LSA again, sent directly.
+	select {
+	case rp := <-peers[1].packets:
+		if rp.dst != nbrs[1].Addr {
+			t.Errorf("expected retransmission to %s, got %s", nbrs[1].Addr, rp.dst)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for retransmission")
+	}
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if nbrs[1].Stats.Retransmissions == 0 {
+		t.Errorf("expected retransmissions to be counted")
+	}
+}
+
+func TestFloodingBetweenInstances(t *testing.T) {
+	network := newMemNetwork()
+
+	r1 := newTestInstance(t, "1.1.1.1", network, map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig()})
+	r2 := newTestInstance(t, "2.2.2.2", network, map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig()})
+
+	iface := startTestInterface(t, r1, testNetif("eth0", 1, "10.0.0.1/24"))
+	startTestInterface(t, r2, testNetif("eth0", 2, "10.0.0.2/24"))
+
+	for _, inst := range []*Instance{r1, r2} {
+		waitFor(t, inst, 10*time.Second, fmt.Sprintf("%s to reach Full", inst.RouterID), func() bool {
+			for _, iface := range inst.Interfaces {
+				for _, n := range iface.Neighbors {
+					return n.state == nFull
+				}
+			}
+
+			return false
+		})
+	}
+
+	lsa := testRouterLSA("1.1.1.1", initialSequenceNumber)
+
+	r1.mu.Lock()
+	iface.installLSA(lsa)
+	r1.floodLSA(lsa, areaScope(0), nil)
+	r1.mu.Unlock()
+
+	waitFor(t, r2, 5*time.Second, "r2 to install the LSA", func() bool {
+		_, ok := r2.Areas[0].lsdb[lsa.Key()]
+		return ok
+	})
+
+	// r2 acknowledges it, emptying the retransmission list.
+	waitFor(t, r1, 5*time.Second, "r2 to acknowledge the LSA", func() bool {
+		for _, n := range iface.Neighbors {
+			return len(n.RetransmissionList) == 0
+		}
+
+		return false
+	})
+}
+
+func TestNewerLSALeavesOtherAreasRetransmissionLists(t *testing.T) {
+	iface, nbrs, _ := newTestFloodingInterface(t)
+	inst := iface.instance
+
+	conf := testInterfaceConfig()
+	conf.AreaID = 1
+	inst.Areas[1] = newArea(1, config.OSPFAreaConfig{})
+
+	netif := testNetif("eth1", 2, "10.0.1.1/24")
+	eth1, err := newInterface(inst, conf, netif, netif.Prefixes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { eth1.transport.close() })
+
+	eth1.State = iDR
+	inst.Interfaces[interfaceID{name: netif.Name, prefix: netif.Prefixes[0]}] = eth1
+
+	area1Nbr := newNeighbor(eth1, mustParseRouterID("5.5.5.5"), netip.MustParseAddr("10.0.1.5"))
+	area1Nbr.state = nFull
+	eth1.Neighbors[area1Nbr.ID] = area1Nbr
+
+	// 4.4.4.4 is another area border router, so its router-LSAs in both
+	// areas have the same key.
+	lsa := testRouterLSA("4.4.4.4", initialSequenceNumber)
+	iface.installLSA(lsa)
+	eth1.installLSA(lsa)
+	iface.lsdbFor(lsTypeRouter)[lsa.Key()].installedAt = time.Now().Add(-minLSArrival * time.Second)
+
+	area1Nbr.addToRetransmissionList(lsa)
+
+	newer := testRouterLSA("4.4.4.4", initialSequenceNumber+1)
+	iface.handleLSUpd(testLSUpd(nbrs[0], newer), nbrs[0].Addr)
+
+	if installed, _ := iface.lookupLSA(lsa.Key()); installed.SequenceNumber() != initialSequenceNumber+1 {
+		t.Fatalf("expected newer instance to be installed in area 0, got sequence number %d", installed.SequenceNumber())
+	}
+
+	if area1Nbr.retransmissionIndex(lsa.Key()) == -1 {
+		t.Errorf("expected the area 1 instance to stay on 5.5.5.5's retransmission list")
+	}
+}
@@ -0,0 +1,260 @@
+package ospf
+
+import (
+	"fmt"
+	"net/netip"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Options field bits (RFC 2328, Appendix A.2).
+const (
+	optE  uint8 = 0x02 // AS-external-LSAs are flooded into the area
+	optMC uint8 = 0x04 // multicast
+	optNP uint8 = 0x08 // N-bit in Hellos, P-bit in Type-7 LSAs (RFC 3101)
+	optEA uint8 = 0x10 // external attributes
+	optDC uint8 = 0x20 // demand circuits
+	optO  uint8 = 0x40 // opaque LSAs (RFC 5250)
+)
+
+// InterfaceStats counts the Hellos sent and received on an interface, along
+// with the received Hellos that were dropped because their parameters didn't
+// match ours, and the received packets that failed authentication.
+type InterfaceStats struct {
+	HellosSent     uint64
+	HellosReceived uint64
+
+	NetworkMaskMismatches   uint64
+	HelloIntervalMismatches uint64
+	DeadIntervalMismatches  uint64
+	OptionsMismatches       uint64
+
+	AuthTypeMismatches   uint64
+	AuthKeyMismatches    uint64 // wrong password, or unknown key
+	AuthDigestMismatches uint64
+	AuthSequenceErrors   uint64 // cryptographic sequence number went backwards
+}
+
+func (i *Interface) area() *Area {
+	return i.instance.Areas[i.AreaID]
+}
+
+// options returns the options we set in Hellos and Database Description
+// packets sent over the interface. In an NSSA, they include the N-bit. See
+// RFC 3101.
+func (i *Interface) options() uint8 {
+	options := i.area().options()
+
+	if i.area().NSSA {
+		options |= optNP
+	}
+
+	return options
+}
+
+// ddOptions returns the options we set in Database Description packets,
+// which also include the O-bit, because we support opaque LSAs. See RFC
+// 5250, section 3.
+func (i *Interface) ddOptions() uint8 {
+	return i.options() | optO
+}
+
+// sendHello sends a Hello out of the interface, as described in RFC 2328,
+// section 9.5.
+func (i *Interface) sendHello() {
+	if i.State == iDown || i.State == iLoopback {
+		return
+	}
+
+	// Neighbors on NBMA networks that are down are polled less often.
+	poll := false
+	if i.Type == InterfaceNBMA && time.Since(i.lastPoll) >= time.Duration(i.PollInterval)*time.Second {
+		poll = true
+		i.lastPoll = time.Now()
+	}
+
+	hello := i.buildHello()
+	for _, dst := range i.helloDestinations(poll) {
+		i.sendHelloTo(hello, dst)
+	}
+}
+
+// sendHelloTo sends hello to dst.
+func (i *Interface) sendHelloTo(hello *Hello, dst netip.Addr) {
+	err := i.sendPacket(hello, dst)
+	if err != nil {
+		fmt.Printf("ospf: %s %s: failed to send hello to %s: %v\n", i.name, i.Prefix, dst, err)
+		return
+	}
+
+	i.Stats.HellosSent++
+}
+
+func (i *Interface) buildHello() *Hello {
+	hello := &Hello{
+		helloInterval:          i.HelloInterval,
+		options:                i.options(),
+		routerPriority:         i.RouterPriority,
+		routerDeadInterval:     i.RouterDeadInterval,
+		designatedRouter:       i.DR.Addr,
+		backupDesignatedRouter: i.BDR.Addr,
+	}
+
+	// Point-to-point networks and virtual links don't have a network mask.
+	if i.isPTP() || i.isVirtualLink() {
+		hello.networkMask = netip.IPv4Unspecified()
+	} else {
+		hello.networkMask = prefixMask(i.Prefix.Bits())
+	}
+
+	for id, n := range i.Neighbors {
+		if n.state >= nInit {
+			hello.neighbors = append(hello.neighbors, id)
+		}
+	}
+
+	slices.Sort(hello.neighbors)
+
+	return hello
+}
+
+// helloDestinations returns the addresses that Hellos are sent to. On NBMA
+// networks there's no multicast, so they're sent to each neighbor in turn,
+// including the ones in state Down if poll is true. On virtual links,
+// they're sent to the other end's address.
+func (i *Interface) helloDestinations(poll bool) []netip.Addr {
+	if i.isVirtualLink() {
+		if !i.vlink.remoteAddr.IsValid() {
+			return nil
+		}
+
+		return []netip.Addr{i.vlink.remoteAddr}
+	}
+
+	if i.Type != InterfaceNBMA {
+		return []netip.Addr{AllSPFRouters}
+	}
+
+	return i.nbmaHelloDestinations(poll)
+}
+
+// handleHello processes a received Hello, as described in RFC 2328, section
+// 10.5.
+func (i *Interface) handleHello(hello *Hello, src netip.Addr) {
+	i.Stats.HellosReceived++
+
+	from := hello.routerID
+
+	if !i.isPTP() && !i.isVirtualLink() && hello.networkMask != prefixMask(i.Prefix.Bits()) {
+		i.Stats.NetworkMaskMismatches++
+		fmt.Printf("ospf: %s %s: dropping hello from %s (%s): network mask mismatch: %s\n", i.name, i.Prefix, from, src, hello.networkMask)
+		return
+	}
+
+	if hello.helloInterval != i.HelloInterval {
+		i.Stats.HelloIntervalMismatches++
+		fmt.Printf("ospf: %s %s: dropping hello from %s (%s): hello interval mismatch: got %d, expected %d\n", i.name, i.Prefix, from, src, hello.helloInterval, i.HelloInterval)
+		return
+	}
+
+	if hello.routerDeadInterval != i.RouterDeadInterval {
+		i.Stats.DeadIntervalMismatches++
+		fmt.Printf("ospf: %s %s: dropping hello from %s (%s): dead interval mismatch: got %d, expected %d\n", i.name, i.Prefix, from, src, hello.routerDeadInterval, i.RouterDeadInterval)
+		return
+	}
+
+	if hello.options&optE != i.options()&optE {
+		i.Stats.OptionsMismatches++
+		fmt.Printf("ospf: %s %s: dropping hello from %s (%s): E-bit mismatch\n", i.name, i.Prefix, from, src)
+		return
+	}
+
+	if hello.options&optNP != i.options()&optNP {
+		i.Stats.OptionsMismatches++
+		fmt.Printf("ospf: %s %s: dropping hello from %s (%s): N-bit mismatch\n", i.name, i.Prefix, from, src)
+		return
+	}
+
+	n, ok := i.Neighbors[from]
+	if !ok && i.Type == InterfaceNBMA {
+		n = i.adoptStaticNeighbor(from, src)
+		ok = n != nil
+	}
+
+	if !ok {
+		n = newNeighbor(i, from, src)
+		i.Neighbors[from] = n
+		go n.run()
+	}
+
+	oldPriority := n.Priority
+	declaredDR := n.DesignatedRouter == n.Addr
+	declaredBDR := n.BackupDesignatedRouter == n.Addr
+
+	n.Addr = src
+	// The O-bit is only set in Database Description packets, so keep
+	// what we learned from them. See RFC 5250, section 3.
+	n.Options = hello.options | n.Options&optO
+
+	// A restarting neighbor doesn't know who the DR is yet, so until it's
+	// done, we keep using what it told us before restarting. See RFC
+	// 3623, section 3.2.
+	if n.helper == nil {
+		n.Priority = hello.routerPriority
+		n.DesignatedRouter = hello.designatedRouter
+		n.BackupDesignatedRouter = hello.backupDesignatedRouter
+	}
+
+	n.handleEvent(neHelloReceived)
+
+	// On NBMA networks, routers that aren't eligible to become DR only send
+	// periodic Hellos to the DR and BDR, so they answer the others.
+	if i.Type == InterfaceNBMA && !i.sendsHellosTo(n) && n.Priority > 0 {
+		i.sendHelloTo(i.buildHello(), n.Addr)
+	}
+
+	if !slices.Contains(hello.neighbors, i.instance.RouterID) {
+		n.handleEvent(ne1WayReceived)
+		return
+	}
+
+	n.handleEvent(ne2WayReceived)
+
+	if n.helper != nil {
+		return
+	}
+
+	// If we were DR before restarting gracefully, our neighbors still say
+	// so, and we become DR again. See RFC 3623, section 2.2.
+	if i.instance.isRestarting() && i.State == iWaiting && n.DesignatedRouter == i.Prefix.Addr() {
+		i.reclaimDR = true
+		i.handleEvent(ieBackupSeen)
+	}
+
+	declaresDR := n.DesignatedRouter == n.Addr
+	declaresBDR := n.BackupDesignatedRouter == n.Addr
+	noBDR := !n.BackupDesignatedRouter.IsValid() || n.BackupDesignatedRouter.IsUnspecified()
+
+	var neighborChange bool
+
+	if n.Priority != oldPriority {
+		neighborChange = true
+	}
+
+	if declaresDR && noBDR && i.State == iWaiting {
+		i.handleEvent(ieBackupSeen)
+	} else if declaresDR != declaredDR {
+		neighborChange = true
+	}
+
+	if declaresBDR && i.State == iWaiting {
+		i.handleEvent(ieBackupSeen)
+	} else if declaresBDR != declaredBDR {
+		neighborChange = true
+	}
+
+	if neighborChange {
+		i.handleEvent(ieNeighborChange)
+	}
+}
@@ -0,0 +1,312 @@
+package ospf
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"github.com/davidbalbert/chatter/config"
+)
+
+func TestPrefixMask(t *testing.T) {
+	tests := []struct {
+		bits     int
+		expected string
+	}{
+		{0, "0.0.0.0"},
+		{8, "255.0.0.0"},
+		{24, "255.255.255.0"},
+		{30, "255.255.255.252"},
+		{32, "255.255.255.255"},
+	}
+
+	for _, test := range tests {
+		mask := prefixMask(test.bits)
+		if mask != netip.MustParseAddr(test.expected) {
+			t.Errorf("prefixMask(%d) = %s, expected %s", test.bits, mask, test.expected)
+		}
+	}
+}
+
+func neighborStateOf(iface *Interface, id string) neighborState {
+	n, ok := iface.Neighbors[mustParseRouterID(id)]
+	if !ok {
+		return nDown
+	}
+
+	return n.state
+}
+
+func TestHelloTwoWay(t *testing.T) {
+	network := newMemNetwork()
+	confs := map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig()}
+
+	r1 := newTestInstance(t, "1.1.1.1", network, confs)
+	r2 := newTestInstance(t, "2.2.2.2", network, confs)
+
+	i1 := startTestInterface(t, r1, testNetif("eth0", 1, "10.0.0.1/24"))
+	i2 := startTestInterface(t, r2, testNetif("eth0", 2, "10.0.0.2/24"))
+
+	waitFor(t, r1, 5*time.Second, "2-Way on r1", func() bool {
+		return neighborStateOf(i1, "2.2.2.2") >= n2Way
+	})
+
+	waitFor(t, r2, 5*time.Second, "2-Way on r2", func() bool {
+		return neighborStateOf(i2, "1.1.1.1") >= n2Way
+	})
+
+	r1.mu.Lock()
+	defer r1.mu.Unlock()
+
+	n := i1.Neighbors[mustParseRouterID("2.2.2.2")]
+	if n.Addr != netip.MustParseAddr("10.0.0.2") {
+		t.Errorf("neighbor address = %s, expected 10.0.0.2", n.Addr)
+	}
+
+	if n.Options&optE == 0 {
+		t.Errorf("expected E-bit to be set in neighbor options")
+	}
+
+	if i1.Stats.HellosSent == 0 || i1.Stats.HellosReceived == 0 {
+		t.Errorf("expected hellos to be sent and received: %+v", i1.Stats)
+	}
+}
+
+func TestHelloIntervalMismatch(t *testing.T) {
+	network := newMemNetwork()
+
+	c1 := testInterfaceConfig()
+	c2 := testInterfaceConfig()
+	c2.HelloInterval = 2
+
+	r1 := newTestInstance(t, "1.1.1.1", network, map[string]config.OSPFInterfaceConfig{"eth0": c1})
+	r2 := newTestInstance(t, "2.2.2.2", network, map[string]config.OSPFInterfaceConfig{"eth0": c2})
+
+	i1 := startTestInterface(t, r1, testNetif("eth0", 1, "10.0.0.1/24"))
+	startTestInterface(t, r2, testNetif("eth0", 2, "10.0.0.2/24"))
+
+	waitFor(t, r1, 5*time.Second, "hello interval mismatch", func() bool {
+		return i1.Stats.HelloIntervalMismatches > 0
+	})
+
+	r1.mu.Lock()
+	defer r1.mu.Unlock()
+
+	if len(i1.Neighbors) != 0 {
+		t.Errorf("expected no neighbors, got %d", len(i1.Neighbors))
+	}
+}
+
+// newTestBroadcastInterface returns an Interface that isn't running, for
+// calling handleHello and friends directly. It's registered with its
+// Instance so that flooding can find it.
+func newTestBroadcastInterface(t *testing.T) *Interface {
+	t.Helper()
+
+	netif := testNetif("eth0", 1, "10.0.0.1/24")
+	inst := newTestInstance(t, "1.1.1.1", newMemNetwork(), map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig()})
+
+	iface, err := newInterface(inst, testInterfaceConfig(), netif, netif.Prefixes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		inst.mu.Lock()
+		iface.killNeighbors()
+		inst.mu.Unlock()
+
+		iface.transport.close()
+	})
+
+	iface.Type = InterfaceBroadcast
+	iface.State = iDROther
+
+	inst.Interfaces[interfaceID{name: netif.Name, prefix: netif.Prefixes[0]}] = iface
+
+	return iface
+}
+
+func testHello(from string) *Hello {
+	return &Hello{
+		PacketHeader:       PacketHeader{routerID: mustParseRouterID(from)},
+		networkMask:        netip.MustParseAddr("255.255.255.0"),
+		helloInterval:      1,
+		options:            optE,
+		routerPriority:     1,
+		routerDeadInterval: 4,
+	}
+}
+
+func TestHandleHelloMismatches(t *testing.T) {
+	src := netip.MustParseAddr("10.0.0.2")
+
+	tests := []struct {
+		name   string
+		modify func(h *Hello)
+		count  func(s InterfaceStats) uint64
+	}{
+		{
+			"network mask",
+			func(h *Hello) { h.networkMask = netip.MustParseAddr("255.255.0.0") },
+			func(s InterfaceStats) uint64 { return s.NetworkMaskMismatches },
+		},
+		{
+			"hello interval",
+			func(h *Hello) { h.helloInterval = 10 },
+			func(s InterfaceStats) uint64 { return s.HelloIntervalMismatches },
+		},
+		{
+			"dead interval",
+			func(h *Hello) { h.routerDeadInterval = 40 },
+			func(s InterfaceStats) uint64 { return s.DeadIntervalMismatches },
+		},
+		{
+			"E-bit",
+			func(h *Hello) { h.options = 0 },
+			func(s InterfaceStats) uint64 { return s.OptionsMismatches },
+		},
+		{
+			"N-bit",
+			func(h *Hello) { h.options |= optNP },
+			func(s InterfaceStats) uint64 { return s.OptionsMismatches },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			iface := newTestBroadcastInterface(t)
+
+			h := testHello("2.2.2.2")
+			test.modify(h)
+
+			iface.handleHello(h, src)
+			iface.handleHello(h, src)
+
+			if n := test.count(iface.Stats); n != 2 {
+				t.Errorf("expected 2 mismatches, got %d", n)
+			}
+
+			if len(iface.Neighbors) != 0 {
+				t.Errorf("expected no neighbors, got %d", len(iface.Neighbors))
+			}
+		})
+	}
+}
+
+func TestHandleHelloNeighborStates(t *testing.T) {
+	iface := newTestBroadcastInterface(t)
+	src := netip.MustParseAddr("10.0.0.2")
+	id := mustParseRouterID("2.2.2.2")
+
+	h := testHello("2.2.2.2")
+	iface.handleHello(h, src)
+
+	n := iface.Neighbors[id]
+	if n == nil || n.state != nInit {
+		t.Fatalf("expected neighbor in Init, got %+v", n)
+	}
+
+	h.neighbors = []common.RouterID{mustParseRouterID("3.3.3.3"), mustParseRouterID("1.1.1.1")}
+	h.routerPriority = 5
+	h.designatedRouter = src
+	iface.handleHello(h, src)
+
+	if n.Priority != 5 || n.DesignatedRouter != src {
+		t.Errorf("neighbor not updated from hello: %+v", n)
+	}
+
+	// The neighbor declared itself DR, so we should be adjacent to it.
+	if iface.DR.ID != id || iface.State != iBackup {
+		t.Errorf("expected DR %s and Backup, got %s and %s", id, iface.DR.ID, iface.State)
+	}
+
+	if n.state != nExStart {
+		t.Fatalf("expected neighbor in ExStart, got %s", n.state)
+	}
+
+	// We've disappeared from its neighbor list.
+	h.neighbors = nil
+	iface.handleHello(h, src)
+
+	if n.state != nInit {
+		t.Fatalf("expected neighbor in Init, got %s", n.state)
+	}
+}
+
+func TestPointToPointIgnoresNetworkMask(t *testing.T) {
+	iface := newTestBroadcastInterface(t)
+	iface.Type = InterfacePointToPoint
+	iface.State = iPointToPoint
+
+	h := testHello("2.2.2.2")
+	h.networkMask = netip.IPv4Unspecified()
+	iface.handleHello(h, netip.MustParseAddr("10.0.0.2"))
+
+	if iface.Stats.NetworkMaskMismatches != 0 {
+		t.Errorf("expected no network mask mismatches")
+	}
+
+	if _, ok := iface.Neighbors[mustParseRouterID("2.2.2.2")]; !ok {
+		t.Errorf("expected neighbor")
+	}
+}
+
+func TestPassiveInterface(t *testing.T) {
+	iface := newTestBroadcastInterface(t)
+	iface.State = iDown
+	iface.Passive = true
+
+	iface.instance.mu.Lock()
+	defer iface.instance.mu.Unlock()
+
+	iface.handleEvent(ieInterfaceUp)
+
+	if iface.State != iDROther {
+		t.Fatalf("expected DROther, got %s", iface.State)
+	}
+
+	if iface.transport.(*memTransport).isMember(AllSPFRouters) {
+		t.Errorf("expected not to join %s", AllSPFRouters)
+	}
+
+	if iface.HelloTimer.Stop() {
+		t.Errorf("expected the Hello timer to be stopped")
+	}
+
+	data, err := testHello("2.2.2.2").MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iface.handlePacket(&receivedPacket{
+		data:    data,
+		src:     netip.MustParseAddr("10.0.0.2"),
+		dst:     AllSPFRouters,
+		ifindex: iface.ifindex,
+	})
+
+	if len(iface.Neighbors) != 0 || iface.Stats.HellosReceived != 0 {
+		t.Errorf("expected the Hello to be ignored, got %d neighbors", len(iface.Neighbors))
+	}
+}
+
+func TestPassiveInterfaceHasNoSocket(t *testing.T) {
+	conf := testInterfaceConfig()
+	conf.Passive = true
+
+	inst := newTestInstance(t, "1.1.1.1", newMemNetwork(), map[string]config.OSPFInterfaceConfig{"eth0": conf})
+	inst.newTransport = func(iface *Interface) (transport, error) {
+		return nil, errors.New("permission denied")
+	}
+
+	iface := startTestInterface(t, inst, testNetif("eth0", 1, "10.0.0.1/24"))
+	if iface == nil {
+		t.Fatal("expected the passive interface to start without a socket")
+	}
+
+	if _, ok := iface.transport.(*passiveTransport); !ok {
+		t.Errorf("expected a passiveTransport, got %T", iface.transport)
+	}
+}
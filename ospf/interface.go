@@ -3,11 +3,13 @@ package ospf
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/netip"
 	"time"
 
 	"github.com/davidbalbert/chatter/chatterd/common"
 	"github.com/davidbalbert/chatter/config"
+	"github.com/davidbalbert/chatter/net/netmon"
 )
 
 type interfaceID struct {
@@ -123,11 +125,24 @@ func (r Router) IsValid() bool {
 type AuthType int
 
 const (
-	authTypeNull AuthType = iota
-	authTypePlain
-	authTypeMD5
+	AuthTypeNull AuthType = iota
+	AuthTypeSimple
+	AuthTypeCryptographic
 )
 
+func (at AuthType) String() string {
+	switch at {
+	case AuthTypeNull:
+		return "null"
+	case AuthTypeSimple:
+		return "simple"
+	case AuthTypeCryptographic:
+		return "cryptographic"
+	default:
+		return fmt.Sprintf("AuthType(%d)", int(at))
+	}
+}
+
 type dispatch struct {
 	e interfaceEvent
 	c chan struct{}
@@ -142,91 +157,386 @@ type Interface struct {
 	RouterDeadInterval uint32
 	InfTransDelay      int
 	RouterPriority     uint8
+	Passive            bool // the prefix is advertised, but no packets are sent or received
 
 	HelloTimer *time.Timer
 	WaitTimer  *time.Timer
+	AckTimer   *time.Timer
 
-	Neighbors map[common.RouterID]Neighbor
+	Neighbors map[common.RouterID]*Neighbor
 	DR        Router
 	BDR       Router
+	reclaimDR bool // we were DR before restarting gracefully, and declare ourselves DR in the next election
+
+	// On NBMA networks, neighbors are configured rather than discovered.
+	// Hellos are sent to configured neighbors in state Down every
+	// PollInterval.
+	PollInterval     uint32
+	staticNeighbors  map[netip.Addr]config.OSPFNeighborConfig
+	pendingNeighbors map[netip.Addr]*Neighbor // configured neighbors we haven't heard from
+	lastPoll         time.Time
 
 	Cost              uint16
 	RxmtInterval      int
 	AuType            AuthType
-	AuthenticationKey uint64
+	AuthenticationKey uint64 // for simple password authentication
+
+	// For cryptographic authentication. cryptoSequenceNumber is the
+	// sequence number in the last packet we sent.
+	authKeys             map[uint8]config.OSPFAuthKeyConfig
+	cryptoSequenceNumber uint32
+
+	Stats InterfaceStats
+
+	lsdb        lsdb // link-local opaque LSAs
+	delayedAcks []*lsaHeader
+
+	name    string
+	ifindex int
+	mtu     int
 
-	name string
+	instance  *Instance
+	transport transport
+	vlink     *virtualLink // nil unless Type is InterfaceVirtualLink
 
-	events chan dispatch
+	events  chan dispatch
+	packets chan *receivedPacket
+	done    chan struct{} // closed when Run returns
 }
 
-func newInterface(conf config.OSPFInterfaceConfig, areaID common.AreaID, name string, prefix netip.Prefix) *Interface {
-	helloTimer := time.NewTimer(0)
-	if !helloTimer.Stop() {
-		<-helloTimer.C
+// An InterfaceSnapshot is a copy of an interface's state, for reporting.
+type InterfaceSnapshot struct {
+	Name               string
+	Prefix             netip.Prefix
+	AreaID             common.AreaID
+	Type               interfaceType
+	State              interfaceState
+	Cost               uint16
+	RouterPriority     uint8
+	HelloInterval      uint16
+	RouterDeadInterval uint32
+	DR                 Router
+	BDR                Router
+	Neighbors          int
+	AdjacentNeighbors  int // in state Full
+	Passive            bool
+	AuthType           AuthType
+	AuthKeyID          uint8 // the key we send with, for cryptographic authentication
+	AuthAlgorithm      config.OSPFAuthAlgorithm
+	Stats              InterfaceStats
+
+	// For virtual links.
+	TransitAreaID common.AreaID
+	Endpoint      common.RouterID
+	RemoteAddr    netip.Addr
+}
+
+func (i *Interface) snapshot() InterfaceSnapshot {
+	s := InterfaceSnapshot{
+		Name:               i.name,
+		Prefix:             i.Prefix,
+		AreaID:             i.AreaID,
+		Type:               i.Type,
+		State:              i.State,
+		Cost:               i.Cost,
+		RouterPriority:     i.RouterPriority,
+		HelloInterval:      i.HelloInterval,
+		RouterDeadInterval: i.RouterDeadInterval,
+		DR:                 i.DR,
+		BDR:                i.BDR,
+		Neighbors:          len(i.Neighbors),
+		Passive:            i.Passive,
+		AuthType:           i.AuType,
+		Stats:              i.Stats,
+	}
+
+	if id, ok := selectKey(i.authKeys, time.Now(), sendLifetime); ok {
+		s.AuthKeyID = id
+		s.AuthAlgorithm = i.authKeys[id].Algorithm
 	}
 
-	waitTimer := time.NewTimer(0)
-	if !waitTimer.Stop() {
-		<-waitTimer.C
+	for _, n := range i.Neighbors {
+		if n.state == nFull {
+			s.AdjacentNeighbors++
+		}
 	}
 
-	return &Interface{
-		Type:               InterfacePointToPoint,
+	if i.vlink != nil {
+		s.TransitAreaID = i.vlink.transitAreaID
+		s.Endpoint = i.vlink.endpoint
+		s.RemoteAddr = i.vlink.remoteAddr
+	}
+
+	return s
+}
+
+func newInterface(inst *Instance, conf config.OSPFInterfaceConfig, netif netmon.Interface, prefix netip.Prefix) (*Interface, error) {
+	i := &Interface{
+		Type:               interfaceTypeFor(conf, netif),
 		State:              iDown,
 		Prefix:             prefix,
+		AreaID:             conf.AreaID,
 		HelloInterval:      conf.HelloInterval,
 		RouterDeadInterval: conf.RouterDeadInterval,
 		InfTransDelay:      1, // TODO: conf.InfTransDelay,
-		RouterPriority:     1, // TODO: conf.RouterPriority,
+		RouterPriority:     conf.RouterPriority,
+		Passive:            conf.Passive,
 
 		// Maybe these should be time.Tickers?
-		HelloTimer: helloTimer,
-		WaitTimer:  waitTimer,
+		HelloTimer: newStoppedTimer(),
+		WaitTimer:  newStoppedTimer(),
+		AckTimer:   newStoppedTimer(),
+
+		Neighbors:        make(map[common.RouterID]*Neighbor),
+		PollInterval:     conf.PollInterval,
+		staticNeighbors:  conf.Neighbors,
+		pendingNeighbors: make(map[netip.Addr]*Neighbor),
+		Cost:             conf.Cost,
+		RxmtInterval:     5, // TODO: conf.RxmtInterval,
+
+		lsdb: newLSDB(),
+
+		name:    netif.Name,
+		ifindex: netif.Index,
+		mtu:     netif.MTU,
+
+		instance: inst,
+
+		events:  make(chan dispatch),
+		packets: make(chan *receivedPacket),
+		done:    make(chan struct{}),
+	}
+
+	i.configureAuth(conf.Authentication)
+
+	// Passive interfaces don't send or receive packets, so they don't
+	// need a socket.
+	if i.Passive {
+		i.transport = newPassiveTransport()
+		return i, nil
+	}
+
+	t, err := inst.newTransport(i)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", i.name, i.Prefix, err)
+	}
+	i.transport = t
+
+	return i, nil
+}
 
-		Neighbors:         make(map[common.RouterID]Neighbor),
-		Cost:              conf.Cost,
-		RxmtInterval:      5,            // TODO: conf.RxmtInterval,
-		AuType:            authTypeNull, // TODO: conf.AuType,
-		AuthenticationKey: 0,            // TODO: conf.AuthenticationKey,
+func newStoppedTimer() *time.Timer {
+	t := time.NewTimer(0)
+	if !t.Stop() {
+		<-t.C
+	}
 
-		name: name,
+	return t
+}
 
-		events: make(chan dispatch),
+// interfaceTypeFor returns the configured OSPF interface type, or picks one
+// based on the network interface's flags if there isn't one.
+func interfaceTypeFor(conf config.OSPFInterfaceConfig, netif netmon.Interface) interfaceType {
+	switch conf.Network {
+	case config.OSPFNetworkBroadcast:
+		return InterfaceBroadcast
+	case config.OSPFNetworkPointToPoint:
+		return InterfacePointToPoint
+	case config.OSPFNetworkNBMA:
+		return InterfaceNBMA
+	case config.OSPFNetworkPointToMultipoint:
+		return InterfacePointToMultipoint
+	default:
+		return interfaceTypeForNetif(netif)
 	}
 }
 
+// interfaceTypeForNetif picks an OSPF interface type based on the network
+// interface's flags. NBMA networks are never picked, because their
+// neighbors have to be configured.
+func interfaceTypeForNetif(netif netmon.Interface) interfaceType {
+	if netif.Flags&net.FlagPointToPoint != 0 {
+		return InterfacePointToPoint
+	} else if netif.Flags&net.FlagBroadcast != 0 {
+		return InterfaceBroadcast
+	}
+
+	return InterfacePointToPoint
+}
+
 func (i *Interface) Run(ctx context.Context) error {
+	defer close(i.done)
+	defer i.transport.close()
+
+	go i.receive(ctx)
+
+	var vlinkChanged chan struct{}
+	if i.vlink != nil {
+		vlinkChanged = i.vlink.changed
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
-			if !i.HelloTimer.Stop() {
-				select {
-				case <-i.HelloTimer.C:
-				default:
-				}
-			}
+			stopTimer(i.HelloTimer)
+			stopTimer(i.WaitTimer)
+			stopTimer(i.AckTimer)
 
-			if !i.WaitTimer.Stop() {
-				select {
-				case <-i.WaitTimer.C:
-				default:
-				}
-			}
+			i.instance.mu.Lock()
+			i.killNeighbors()
+			i.instance.mu.Unlock()
 
 			return nil
 		case <-i.HelloTimer.C:
-			fmt.Printf("hello timer expired: %s %s\n", i.name, i.Prefix)
+			i.instance.mu.Lock()
+			i.sendHello()
 			i.HelloTimer.Reset(time.Duration(i.HelloInterval) * time.Second)
+			i.instance.mu.Unlock()
 		case <-i.WaitTimer.C:
-			fmt.Printf("wait timer expired: %s %s\n", i.name, i.Prefix)
+			i.instance.mu.Lock()
+			i.handleEvent(ieWaitTimer)
+			i.instance.mu.Unlock()
+		case <-i.AckTimer.C:
+			i.instance.mu.Lock()
+			i.sendDelayedAcks()
+			i.instance.mu.Unlock()
 		case d := <-i.events:
+			i.instance.mu.Lock()
 			i.handleEvent(d.e)
+			i.instance.mu.Unlock()
 
 			if d.c != nil {
 				d.c <- struct{}{}
 			}
+		case p := <-i.packets:
+			i.instance.mu.Lock()
+			i.handlePacket(p)
+			i.instance.mu.Unlock()
+		case <-vlinkChanged:
+			i.instance.mu.Lock()
+			i.syncVirtualLinkState()
+			i.instance.mu.Unlock()
+		}
+	}
+}
+
+// receive reads packets from the transport and hands them to Run. It returns
+// when the transport is closed.
+func (i *Interface) receive(ctx context.Context) {
+	for {
+		p, err := i.transport.receive()
+		if err == errTransportClosed {
+			return
+		} else if err != nil {
+			fmt.Printf("ospf: %s %s: receive failed: %v\n", i.name, i.Prefix, err)
+			continue
+		}
+
+		select {
+		case i.packets <- p:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sendPacket fills in the OSPF header for p and sends it to dst.
+func (i *Interface) sendPacket(p Packet, dst netip.Addr) error {
+	h := p.Header()
+	h.routerID = i.instance.RouterID
+	h.areaID = i.AreaID
+
+	key, err := i.setAuthField(h)
+	if err != nil {
+		return err
+	}
+
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	// The digest follows the packet, and isn't included in its length.
+	if key != nil {
+		data = append(data, authDigest(*key, data)...)
+	}
+
+	return i.transport.send(data, dst, linkTTL)
+}
+
+// handlePacket performs the generic checks from RFC 2328, section 8.2 and
+// dispatches the packet based on its type.
+func (i *Interface) handlePacket(rp *receivedPacket) {
+	if !i.isUp() || i.isLoopback() || i.Passive {
+		return
+	}
+
+	// Virtual links get their packets from interfaces in the transit
+	// area, which have already checked where they were sent.
+	if !i.isVirtualLink() {
+		if rp.ifindex != i.ifindex {
+			return
+		}
+
+		if rp.dst != i.Prefix.Addr() && rp.dst != AllSPFRouters && !(rp.dst == AllDRouters && (i.State == iDR || i.State == iBackup)) {
+			return
+		}
+	}
+
+	p, err := ParsePacket(rp.data)
+	if err != nil {
+		fmt.Printf("ospf: %s %s: dropping packet from %s: %v\n", i.name, i.Prefix, rp.src, err)
+		return
+	}
+
+	h := p.Header()
+
+	if h.routerID == i.instance.RouterID {
+		return
+	}
+
+	// The other end of a virtual link might not be on this network.
+	if vl := i.virtualLinkFor(h, rp); vl != nil {
+		vl.vlink.deliver(rp)
+		return
+	}
+
+	// We're bound to the link, so we'll see packets for all the link's
+	// prefixes. Ignore the ones that aren't for us.
+	if !i.isPTP() && !i.isVirtualLink() && !i.Prefix.Contains(rp.src) {
+		return
+	}
+
+	if h.areaID != i.AreaID {
+		fmt.Printf("ospf: %s %s: dropping packet from %s: area mismatch: %s\n", i.name, i.Prefix, rp.src, h.areaID)
+		return
+	}
+
+	seq, ok := i.authenticate(h, rp.data, rp.src)
+	if !ok {
+		return
+	}
+
+	// Sequence numbers from the neighbor must never decrease.
+	defer func() {
+		if n, ok := i.Neighbors[h.routerID]; ok && i.AuType == AuthTypeCryptographic {
+			n.cryptoSequenceNumber = seq
 		}
+	}()
+
+	switch p := p.(type) {
+	case *Hello:
+		i.handleHello(p, rp.src)
+	case *DD:
+		i.handleDD(p, rp.src)
+	case *LSReq:
+		i.handleLSReq(p, rp.src)
+	case *LSUpd:
+		i.handleLSUpd(p, rp.src)
+	case *LSAck:
+		i.handleLSAck(p, rp.src)
+	default:
+		fmt.Printf("ospf: %s %s: received %s from %s (%s)\n", i.name, i.Prefix, h.t, h.routerID, rp.src)
 	}
 }
 
@@ -254,51 +564,186 @@ func (i *Interface) handleEvent(e interfaceEvent) {
 	fmt.Printf("interface event: %s %s: %s\n", i.name, i.Prefix, e)
 	switch e {
 	case ieInterfaceUp:
-		i.HelloTimer.Reset(time.Duration(i.HelloInterval) * time.Second)
+		if i.State != iDown {
+			return
+		}
+
+		// Passive interfaces don't send Hellos, so they never have
+		// neighbors or a DR.
+		if !i.Passive {
+			// Send the first Hello right away rather than waiting a
+			// whole HelloInterval.
+			i.HelloTimer.Reset(0)
+
+			err := i.transport.joinGroup(AllSPFRouters)
+			if err != nil {
+				fmt.Printf("ospf: %s %s: failed to join %s: %v\n", i.name, i.Prefix, AllSPFRouters, err)
+			}
+		}
 
 		if i.isPTP() || i.isPTMP() || i.isVirtualLink() {
-			i.State = iPointToPoint
+			i.setState(iPointToPoint)
+		} else if i.Passive {
+			// There's no one to elect a DR with.
+			i.setState(iDROther)
 		} else if i.RouterPriority == 0 {
-			// > Else, if the router is not eligible to
-			// > become Designated Router the interface state
-			// > transitions to DR Other.
-			//
-			// TODO: confirm that this is what the above means
-			i.State = iDROther
+			// We're not eligible to become DR, so there's no reason to
+			// wait. We'll learn who the DR is from our neighbors' Hellos.
+			i.setState(iDROther)
 		} else {
-			i.WaitTimer.Reset(time.Duration(i.InfTransDelay) * time.Second)
-			i.State = iWaiting
-
-			// TODO:
-			// > Additionally, if the
-			// > network is an NBMA network examine the configured
-			// > list of neighbors for this interface and generate
-			// > the neighbor event Start for each neighbor that is
-			// > also eligible to become Designated Router.
+			i.WaitTimer.Reset(time.Duration(i.RouterDeadInterval) * time.Second)
+			i.setState(iWaiting)
 		}
 
-		i.State = iPointToPoint
+		if i.Type == InterfaceNBMA && !i.Passive {
+			i.addStaticNeighbors()
+
+			// Eligible routers start talking to the configured
+			// neighbors that are also eligible to become DR.
+			if i.RouterPriority > 0 {
+				i.startStaticNeighbors(func(n *Neighbor) bool {
+					return n.Priority > 0
+				})
+			}
+		}
 	case ieWaitTimer:
-		// TODO
+		if i.State == iWaiting {
+			i.electDR()
+		}
 	case ieBackupSeen:
-		// TODO
+		if i.State == iWaiting {
+			stopTimer(i.WaitTimer)
+			i.electDR()
+		}
 	case ieNeighborChange:
-		// TODO
+		if i.State == iDR || i.State == iBackup || i.State == iDROther {
+			i.electDR()
+		}
 	case ieLoopInd:
-		i.State = iLoopback
+		if i.State == iLoopback {
+			return
+		}
+
+		i.reset(iLoopback)
 	case ieUnloopInd:
-		i.State = iPointToPoint
+		if i.State != iLoopback {
+			return
+		}
+
+		i.setState(iDown)
 	case ieInterfaceDown:
-		i.State = iDown
+		if i.State == iDown {
+			return
+		}
+
+		i.reset(iDown)
+	}
+}
+
+func (i *Interface) setState(s interfaceState) {
+	if s == i.State {
+		return
+	}
+
+	fmt.Printf("ospf: %s %s: %s -> %s\n", i.name, i.Prefix, i.State, s)
+
+	wasDR := i.State == iDR || i.State == iBackup
+	isDR := s == iDR || s == iBackup
+
+	i.State = s
+
+	if isDR && !wasDR {
+		err := i.transport.joinGroup(AllDRouters)
+		if err != nil {
+			fmt.Printf("ospf: %s %s: failed to join %s: %v\n", i.name, i.Prefix, AllDRouters, err)
+		}
+	} else if !isDR && wasDR {
+		err := i.transport.leaveGroup(AllDRouters)
+		if err != nil {
+			fmt.Printf("ospf: %s %s: failed to leave %s: %v\n", i.name, i.Prefix, AllDRouters, err)
+		}
+	}
+
+	i.instance.scheduleRouterLSAs()
+	i.scheduleNetworkLSA()
+}
+
+// reset moves the interface to s, which is either Down or Loopback. It
+// disables the interface's timers, destroys its neighbors and clears its DR
+// and BDR.
+func (i *Interface) reset(s interfaceState) {
+	wasUp := i.State != iDown && i.State != iLoopback
+
+	stopTimer(i.HelloTimer)
+	stopTimer(i.WaitTimer)
+	stopTimer(i.AckTimer)
+	i.delayedAcks = nil
+
+	// Change state before killing neighbors so that the resulting
+	// NeighborChange events are ignored.
+	i.setState(s)
+	i.killNeighbors()
+
+	i.DR = Router{}
+	i.BDR = Router{}
+
+	if wasUp && !i.Passive {
+		err := i.transport.leaveGroup(AllSPFRouters)
+		if err != nil {
+			fmt.Printf("ospf: %s %s: failed to leave %s: %v\n", i.name, i.Prefix, AllSPFRouters, err)
+		}
+	}
+}
+
+func (i *Interface) killNeighbors() {
+	for _, n := range i.Neighbors {
+		n.handleEvent(neKillNbr)
+	}
+
+	for _, n := range i.pendingNeighbors {
+		n.handleEvent(neKillNbr)
+	}
+}
+
+// removeNeighbor destroys n, stopping its goroutine.
+func (i *Interface) removeNeighbor(n *Neighbor) {
+	if i.Neighbors[n.ID] == n {
+		delete(i.Neighbors, n.ID)
+	}
+
+	if i.pendingNeighbors[n.Addr] == n {
+		delete(i.pendingNeighbors, n.Addr)
 	}
+
+	close(n.stop)
 }
 
+// sendEvent and sendEventWait do nothing if Run has returned. They must not
+// be called with i.instance.mu held.
 func (i *Interface) sendEvent(e interfaceEvent) {
-	i.events <- dispatch{e, nil}
+	select {
+	case i.events <- dispatch{e, nil}:
+	case <-i.done:
+	}
 }
 
 func (i *Interface) sendEventWait(e interfaceEvent) {
 	c := make(chan struct{})
-	i.events <- dispatch{e, c}
-	<-c
+
+	select {
+	case i.events <- dispatch{e, c}:
+		<-c
+	case <-i.done:
+	}
+}
+
+// stopTimer stops t and drains its channel. It must only be called from the
+// goroutine that receives from t.C.
+func stopTimer(t *time.Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
 }
@@ -0,0 +1,252 @@
+package ospf
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+)
+
+func testLSAHeader(advertisingRouter string, seq int32) lsaHeader {
+	return lsaHeader{
+		age:               1,
+		options:           optE,
+		advertisingRouter: mustParseRouterID(advertisingRouter),
+		sequenceNumber:    seq,
+	}
+}
+
+// testRouterLSA returns a router-LSA with no links.
+func testRouterLSA(id string, seq int32) *routerLSA {
+	return newRouterLSA(testLSAHeader(id, seq), 0, nil)
+}
+
+// reparse encodes lsa and parses it again with ParseLSA.
+func reparse(t *testing.T, lsa LSA) LSA {
+	t.Helper()
+
+	if !lsa.IsChecksumValid() {
+		t.Fatalf("invalid checksum")
+	}
+
+	if int(lsa.Length()) != len(lsa.Bytes()) {
+		t.Fatalf("expected length %d, got %d", len(lsa.Bytes()), lsa.Length())
+	}
+
+	parsed, err := ParseLSA(lsa.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return parsed
+}
+
+func TestRouterLSARoundTrip(t *testing.T) {
+	links := []routerLink{
+		{
+			ID:     netip.MustParseAddr("10.0.0.1"),
+			Data:   netip.MustParseAddr("10.0.0.2"),
+			Type:   linkTransit,
+			Metric: 10,
+		},
+		{
+			ID:     netip.MustParseAddr("192.168.0.0"),
+			Data:   netip.MustParseAddr("255.255.255.0"),
+			Type:   linkStub,
+			Metric: 1,
+			TOS:    []tosMetric{{TOS: 2, Metric: 5}},
+		},
+	}
+
+	lsa := newRouterLSA(testLSAHeader("2.2.2.2", initialSequenceNumber), routerFlagB|routerFlagE, links)
+
+	parsed, ok := reparse(t, lsa).(*routerLSA)
+	if !ok {
+		t.Fatalf("expected *routerLSA")
+	}
+
+	if parsed.ID() != netip.MustParseAddr("2.2.2.2") {
+		t.Errorf("expected ID 2.2.2.2, got %s", parsed.ID())
+	}
+
+	if !parsed.IsABR() || !parsed.IsASBR() || parsed.IsVirtualLinkEndpoint() {
+		t.Errorf("unexpected flags %x", parsed.Flags())
+	}
+
+	if !reflect.DeepEqual(parsed.Links(), links) {
+		t.Errorf("expected links %v, got %v", links, parsed.Links())
+	}
+}
+
+func TestNetworkLSARoundTrip(t *testing.T) {
+	h := testLSAHeader("2.2.2.2", initialSequenceNumber)
+	h.id = netip.MustParseAddr("10.0.0.2")
+
+	routers := []common.RouterID{mustParseRouterID("1.1.1.1"), mustParseRouterID("2.2.2.2")}
+	lsa := newNetworkLSA(h, netip.MustParseAddr("255.255.255.0"), routers)
+
+	parsed, ok := reparse(t, lsa).(*networkLSA)
+	if !ok {
+		t.Fatalf("expected *networkLSA")
+	}
+
+	if parsed.Prefix() != netip.MustParsePrefix("10.0.0.0/24") {
+		t.Errorf("expected 10.0.0.0/24, got %s", parsed.Prefix())
+	}
+
+	if !reflect.DeepEqual(parsed.AttachedRouters(), routers) {
+		t.Errorf("expected attached routers %v, got %v", routers, parsed.AttachedRouters())
+	}
+}
+
+func TestSummaryLSARoundTrip(t *testing.T) {
+	h := testLSAHeader("2.2.2.2", initialSequenceNumber)
+	h.id = netip.MustParseAddr("10.1.0.0")
+
+	lsa := newSummaryLSA(h, netip.MustParseAddr("255.255.0.0"), 0xabcdef)
+
+	parsed, ok := reparse(t, lsa).(*summaryLSA)
+	if !ok {
+		t.Fatalf("expected *summaryLSA")
+	}
+
+	if parsed.Prefix() != netip.MustParsePrefix("10.1.0.0/16") || parsed.Metric() != 0xabcdef {
+		t.Errorf("expected 10.1.0.0/16 with metric %d, got %s with metric %d", 0xabcdef, parsed.Prefix(), parsed.Metric())
+	}
+
+	h.id = netip.MustParseAddr("3.3.3.3")
+	asbr := newASBRSummaryLSA(h, 20)
+
+	parsedASBR, ok := reparse(t, asbr).(*asbrSummaryLSA)
+	if !ok {
+		t.Fatalf("expected *asbrSummaryLSA")
+	}
+
+	if parsedASBR.Metric() != 20 || parsedASBR.NetworkMask() != netip.IPv4Unspecified() {
+		t.Errorf("expected metric 20 and no mask, got %d, %s", parsedASBR.Metric(), parsedASBR.NetworkMask())
+	}
+}
+
+func TestASExternalLSARoundTrip(t *testing.T) {
+	h := testLSAHeader("2.2.2.2", initialSequenceNumber)
+	h.id = netip.MustParseAddr("0.0.0.0")
+
+	r := externalRoute{
+		Type2:             true,
+		Metric:            100,
+		ForwardingAddress: netip.MustParseAddr("10.0.0.5"),
+		RouteTag:          42,
+	}
+
+	lsa := newASExternalLSA(h, netip.MustParseAddr("0.0.0.0"), r)
+
+	parsed, ok := reparse(t, lsa).(*asExternalLSA)
+	if !ok {
+		t.Fatalf("expected *asExternalLSA")
+	}
+
+	if parsed.Prefix() != netip.MustParsePrefix("0.0.0.0/0") {
+		t.Errorf("expected default route, got %s", parsed.Prefix())
+	}
+
+	if !parsed.IsType2() || parsed.Metric() != 100 || parsed.ForwardingAddress() != r.ForwardingAddress || parsed.RouteTag() != 42 {
+		t.Errorf("expected %+v, got %+v", r, parsed.Routes()[0])
+	}
+}
+
+func TestNSSALSARoundTrip(t *testing.T) {
+	h := testLSAHeader("2.2.2.2", initialSequenceNumber)
+	h.options = optNP
+	h.id = netip.MustParseAddr("172.16.0.0")
+
+	r := externalRoute{
+		Metric:            20,
+		ForwardingAddress: netip.MustParseAddr("10.0.0.5"),
+	}
+
+	lsa := newNSSALSA(h, prefixMask(16), r)
+
+	parsed, ok := reparse(t, lsa).(*nssaLSA)
+	if !ok {
+		t.Fatalf("expected *nssaLSA")
+	}
+
+	if parsed.Type() != lsTypeNSSA || parsed.Prefix() != netip.MustParsePrefix("172.16.0.0/16") {
+		t.Errorf("expected NSSA-LSA for 172.16.0.0/16, got %s for %s", parsed.Type(), parsed.Prefix())
+	}
+
+	if !parsed.Propagate() || parsed.IsType2() || parsed.Metric() != 20 || parsed.ForwardingAddress() != r.ForwardingAddress {
+		t.Errorf("expected %+v with P-bit, got %+v (options %#x)", r, parsed.Routes()[0], parsed.Options())
+	}
+}
+
+func TestOpaqueLSARoundTrip(t *testing.T) {
+	h := testLSAHeader("2.2.2.2", initialSequenceNumber)
+	h.id = opaqueLSAID(4, 0x123456)
+
+	lsa := newOpaqueLSA(h, lsTypeOpaqueArea, []byte{1, 2, 3, 4, 5})
+
+	parsed, ok := reparse(t, lsa).(*opaqueLSA)
+	if !ok {
+		t.Fatalf("expected *opaqueLSA")
+	}
+
+	if parsed.Type() != lsTypeOpaqueArea || parsed.ID() != netip.MustParseAddr("4.18.52.86") {
+		t.Errorf("expected area-local opaque-LSA 4.18.52.86, got %s %s", parsed.Type(), parsed.ID())
+	}
+
+	if parsed.OpaqueType() != 4 || parsed.OpaqueID() != 0x123456 {
+		t.Errorf("expected opaque type 4, ID 0x123456, got %d, %#x", parsed.OpaqueType(), parsed.OpaqueID())
+	}
+
+	if !reflect.DeepEqual(parsed.Data(), []byte{1, 2, 3, 4, 5}) {
+		t.Errorf("expected data [1 2 3 4 5], got %v", parsed.Data())
+	}
+}
+
+func TestParseLSAMalformed(t *testing.T) {
+	tests := []struct {
+		name     string
+		t        lsType
+		body     []byte
+		expected error
+	}{
+		{"router-LSA too short", lsTypeRouter, make([]byte, 2), ErrTruncated},
+		{"router-LSA missing link", lsTypeRouter, []byte{0, 0, 0, 1}, ErrTruncated},
+		{"router-LSA trailing data", lsTypeRouter, make([]byte, 8), ErrBadLength},
+		{"router-LSA missing TOS", lsTypeRouter, []byte{0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 3, 1, 0, 10}, ErrTruncated},
+		{"network-LSA too short", lsTypeNetwork, make([]byte, 2), ErrTruncated},
+		{"network-LSA partial router", lsTypeNetwork, make([]byte, 6), ErrBadLength},
+		{"summary-LSA too short", lsTypeSummary, make([]byte, 4), ErrTruncated},
+		{"ASBR-summary-LSA partial TOS", lsTypeASBRSummary, make([]byte, 10), ErrBadLength},
+		{"AS-external-LSA too short", lsTypeASExternal, make([]byte, 8), ErrTruncated},
+		{"AS-external-LSA partial TOS", lsTypeASExternal, make([]byte, 20), ErrBadLength},
+		{"NSSA-LSA too short", lsTypeNSSA, make([]byte, 8), ErrTruncated},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lsa := buildLSA(testLSAHeader("1.1.1.1", initialSequenceNumber), test.t, test.body)
+
+			_, err := ParseLSA(lsa.Bytes())
+			if !errors.Is(err, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestParseLSAUnknownType(t *testing.T) {
+	lsa := buildLSA(testLSAHeader("1.1.1.1", initialSequenceNumber), 99, make([]byte, 3))
+
+	parsed, err := ParseLSA(lsa.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := parsed.(*lsaBase); !ok {
+		t.Errorf("expected *lsaBase, got %T", parsed)
+	}
+}
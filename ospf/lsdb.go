@@ -1,7 +1,9 @@
 package ospf
 
 import (
+	"context"
 	"encoding/binary"
+	"fmt"
 	"math"
 	"net/netip"
 	"time"
@@ -15,8 +17,14 @@ const (
 	maxAge                = 3600 // 1 hour
 	maxAgeDiff            = 900  // 15 minutes
 	minLSArrival          = 1    // 1 second
+	lsRefreshTime         = 1800 // 30 minutes
+	lsInfinity            = 0xffffff
 )
 
+// checkAgeInterval is how often the database is scanned for LSAs that need
+// to be refreshed or flushed.
+const checkAgeInterval = 1 * time.Second
+
 type LSAMetadata interface {
 	Age() uint16
 	Options() uint8
@@ -46,15 +54,267 @@ type lsdbKey struct {
 
 type lsdb map[lsdbKey]*installedLSA
 
+// An installedLSA is an LSA in the database. Its age increases by one
+// every second after it's installed.
 type installedLSA struct {
 	LSA
 	installedAt time.Time
+	refresh     bool // it has the same contents as the instance it replaced
 }
 
 func newLSDB() lsdb {
 	return lsdb(make(map[lsdbKey]*installedLSA))
 }
 
+// Age returns the LSA's current age, which never exceeds MaxAge.
+func (l *installedLSA) Age() uint16 {
+	age := int(l.LSA.Age()) + int(time.Since(l.installedAt)/time.Second)
+	if age > maxAge {
+		age = maxAge
+	}
+
+	return uint16(age)
+}
+
+func (l *installedLSA) Compare(other LSAMetadata) int {
+	return compareLSAs(l, other)
+}
+
+// An lsaScope is where an LSA is stored and flooded. Link-local opaque
+// LSAs belong to a single interface, and everything else to an area.
+// AS-external-LSAs and AS-scoped opaque LSAs are stored once for the whole
+// AS, so their area doesn't matter. See RFC 5250, section 3.
+type lsaScope struct {
+	areaID common.AreaID
+	iface  *Interface // for link-local opaque LSAs
+}
+
+func areaScope(areaID common.AreaID) lsaScope {
+	return lsaScope{areaID: areaID}
+}
+
+// scopeFor returns the scope of LSAs of type t received on, or originated
+// for, the interface.
+func (i *Interface) scopeFor(t lsType) lsaScope {
+	if t == lsTypeOpaqueLink {
+		return lsaScope{areaID: i.AreaID, iface: i}
+	}
+
+	return areaScope(i.AreaID)
+}
+
+// isASScoped reports whether LSAs of type t are flooded throughout the AS.
+func isASScoped(t lsType) bool {
+	return t == lsTypeASExternal || t == lsTypeOpaqueAS
+}
+
+// lsdbFor returns the database that LSAs of type t in area areaID are stored
+// in. AS-scoped LSAs aren't associated with any area.
+func (inst *Instance) lsdbFor(areaID common.AreaID, t lsType) lsdb {
+	if isASScoped(t) {
+		return inst.lsdb
+	}
+
+	return inst.Areas[areaID].lsdb
+}
+
+// lsdbIn returns the database that LSAs of type t in scope s are stored in.
+func (inst *Instance) lsdbIn(s lsaScope, t lsType) lsdb {
+	if t == lsTypeOpaqueLink {
+		if s.iface == nil {
+			return nil
+		}
+
+		return s.iface.lsdb
+	}
+
+	return inst.lsdbFor(s.areaID, t)
+}
+
+func (inst *Instance) lookupLSA(areaID common.AreaID, key lsdbKey) (*installedLSA, bool) {
+	return inst.lookupLSAIn(areaScope(areaID), key)
+}
+
+func (inst *Instance) lookupLSAIn(s lsaScope, key lsdbKey) (*installedLSA, bool) {
+	if !isKnownLSType(key.Type) {
+		return nil, false
+	}
+
+	lsa, ok := inst.lsdbIn(s, key.Type)[key]
+	return lsa, ok
+}
+
+// installLSA adds lsa to the database, replacing any older instance. If
+// its contents changed, the routing table is recalculated. See RFC 2328,
+// section 13.2.
+func (inst *Instance) installLSA(areaID common.AreaID, lsa LSA) {
+	inst.installLSAIn(areaScope(areaID), lsa)
+}
+
+// installLSAIn is installLSA for any scope. Opaque LSAs don't affect the
+// routing table, but changes to them are passed on to the application
+// that registered their opaque type.
+func (inst *Instance) installLSAIn(s lsaScope, lsa LSA) {
+	db := inst.lsdbIn(s, lsa.Type())
+
+	existing, ok := db[lsa.Key()]
+	changed := !ok || (existing.Age() == maxAge) != (lsa.Age() == maxAge) || !sameContents(existing, lsa)
+
+	db[lsa.Key()] = &installedLSA{
+		LSA:         lsa,
+		installedAt: time.Now(),
+		refresh:     !changed,
+	}
+
+	if !changed {
+		return
+	}
+
+	if isGraceLSA(lsa) {
+		inst.handleGraceLSA(s, lsa)
+	} else if isOpaque(lsa.Type()) {
+		inst.notifyOpaque(s, lsa)
+	} else {
+		inst.scheduleSPF(s, lsa, false)
+	}
+
+	inst.checkHelping(s, lsa)
+	inst.checkRestartConsistency(s, lsa)
+}
+
+// originateLSA installs and floods a new instance of lsa, which we
+// originated. lsa's age and sequence number are ignored: the new instance
+// has age 0 and the sequence number after the one in the database. If the
+// database copy already has the maximum sequence number, it's flushed first,
+// and the new instance is originated once that's done. See RFC 2328,
+// section 12.1.6.
+func (inst *Instance) originateLSA(s lsaScope, lsa LSA) {
+	key := lsa.Key()
+	okey := newScopedOriginationKey(s, key)
+	seq := int32(initialSequenceNumber)
+
+	if existing, ok := inst.lookupLSAIn(s, key); ok {
+		if _, ok := inst.pendingOriginations[okey]; ok || existing.SequenceNumber() == maxSequenceNumber {
+			inst.pendingOriginations[okey] = lsa
+
+			if existing.Age() < maxAge {
+				inst.flushLSA(s, existing)
+			}
+
+			return
+		}
+
+		seq = existing.SequenceNumber() + 1
+	}
+
+	lsa = withSequenceNumber(lsa, seq)
+
+	inst.installLSAIn(s, lsa)
+	inst.floodLSA(lsa, s, nil)
+}
+
+// flushLSA prematurely ages lsa to MaxAge and floods it, removing it from
+// the routing domain. It's deleted from the database once every neighbor
+// has acknowledged it.
+func (inst *Instance) flushLSA(s lsaScope, lsa LSA) {
+	c, _ := ParseLSA(lsa.Bytes())
+	c.SetAge(maxAge)
+
+	inst.installLSAIn(s, c)
+	inst.floodLSA(c, s, nil)
+}
+
+// runAging ages the database until ctx is done, and then cancels deferred
+// originations and routing table calculations.
+func (inst *Instance) runAging(ctx context.Context) {
+	ticker := time.NewTicker(checkAgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			inst.mu.Lock()
+			inst.stopTimers()
+			inst.mu.Unlock()
+			return
+		case <-ticker.C:
+			inst.mu.Lock()
+			inst.ageLSDB()
+			inst.mu.Unlock()
+		}
+	}
+}
+
+// stopTimers cancels deferred originations, routing table calculations,
+// NSSA translator stability timers, the end of a graceful restart and the
+// end of the on-startup max-metric period.
+func (inst *Instance) stopTimers() {
+	inst.stopOriginations()
+	inst.stopSPF()
+	inst.stopTranslatorTimers()
+	inst.stopRestart()
+	inst.stopMaxMetricStartup()
+}
+
+// ageLSDB refloods LSAs that have reached MaxAge, removes them once they've
+// been acknowledged, and refreshes self-originated LSAs every
+// LSRefreshTime. See RFC 2328, section 14.
+func (inst *Instance) ageLSDB() {
+	for id, area := range inst.Areas {
+		inst.ageDB(areaScope(id), area.lsdb)
+	}
+
+	inst.ageDB(areaScope(0), inst.lsdb)
+
+	for _, iface := range inst.Interfaces {
+		inst.ageDB(iface.scopeFor(lsTypeOpaqueLink), iface.lsdb)
+	}
+}
+
+func (inst *Instance) ageDB(s lsaScope, db lsdb) {
+	for key, lsa := range db {
+		age := lsa.Age()
+
+		switch {
+		case age == maxAge && lsa.LSA.Age() != maxAge:
+			// It just reached MaxAge.
+			inst.flushLSA(s, lsa)
+		case age == maxAge:
+			if inst.hasExchangingNeighbors() || inst.onRetransmissionList(s, key) {
+				continue
+			}
+
+			delete(db, key)
+			if !isOpaque(key.Type) {
+				inst.scheduleSPF(s, lsa, true)
+			}
+
+			okey := newScopedOriginationKey(s, key)
+			if p, ok := inst.pendingOriginations[okey]; ok {
+				delete(inst.pendingOriginations, okey)
+				inst.originateLSA(s, p)
+			}
+		case age >= lsRefreshTime && lsa.AdvertisingRouter() == inst.RouterID:
+			inst.originateLSA(s, lsa.LSA)
+		}
+	}
+}
+
+// onRetransmissionList reports whether any instance of the LSA identified
+// by key in scope s is on the retransmission list of a neighbor in its
+// flooding scope. Instances from other areas with the same key don't count.
+func (inst *Instance) onRetransmissionList(s lsaScope, key lsdbKey) bool {
+	for _, iface := range inst.floodingScope(key.Type, s) {
+		for _, n := range iface.Neighbors {
+			if n.retransmissionIndex(key) != -1 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // Rules of LSAs:
 //
 // - With the exception of age, all fields are immutable.
@@ -86,6 +346,12 @@ const (
 	lsTypeSummary     lsType = 3
 	lsTypeASBRSummary lsType = 4
 	lsTypeASExternal  lsType = 5
+	lsTypeNSSA        lsType = 7 // RFC 3101
+
+	// Opaque LSAs (RFC 5250), which differ only in flooding scope.
+	lsTypeOpaqueLink lsType = 9
+	lsTypeOpaqueArea lsType = 10
+	lsTypeOpaqueAS   lsType = 11
 )
 
 type lsaBase struct {
@@ -126,9 +392,13 @@ func (h *lsaHeader) Length() uint16 {
 }
 
 func (h *lsaHeader) Compare(other LSAMetadata) int {
-	// lessRecent := -1
-	// moreRecent := 1
+	return compareLSAs(h, other)
+}
 
+// compareLSAs determines which of two instances of an LSA is more recent,
+// as described in RFC 2328, section 13.1. It returns 1 if h is more recent,
+// -1 if other is, and 0 if they're the same instance.
+func compareLSAs(h, other LSAMetadata) int {
 	s1, s2 := h.SequenceNumber(), other.SequenceNumber()
 	if s1 < s2 {
 		return -1
@@ -168,6 +438,55 @@ func (h *lsaHeader) Key() lsdbKey {
 	}
 }
 
+func parseLSAHeader(data []byte) (*lsaHeader, error) {
+	if len(data) < lsaHeaderLen {
+		return nil, fmt.Errorf("LSA header too short: %d bytes", len(data))
+	}
+
+	b := make([]byte, lsaHeaderLen)
+	copy(b, data)
+
+	return decodeLSAHeader(b), nil
+}
+
+// decodeLSAHeader decodes b without copying it. b must be at least
+// lsaHeaderLen bytes long.
+func decodeLSAHeader(b []byte) *lsaHeader {
+	return &lsaHeader{
+		age:               binary.BigEndian.Uint16(b[0:2]),
+		options:           b[2],
+		type_:             lsType(b[3]),
+		id:                addrFromSlice(b[4:8]),
+		advertisingRouter: common.RouterID(binary.BigEndian.Uint32(b[8:12])),
+		sequenceNumber:    int32(binary.BigEndian.Uint32(b[12:16])),
+		checksum:          binary.BigEndian.Uint16(b[16:18]),
+		length:            binary.BigEndian.Uint16(b[18:20]),
+		bytes:             b[:lsaHeaderLen],
+	}
+}
+
+// parseLSA decodes an entire LSA, including the header. The LSA's length
+// must match len(data).
+func parseLSA(data []byte) (*lsaBase, error) {
+	if len(data) < lsaHeaderLen {
+		return nil, fmt.Errorf("LSA too short: %d bytes", len(data))
+	}
+
+	b := make([]byte, len(data))
+	copy(b, data)
+
+	h := decodeLSAHeader(b)
+	if int(h.length) != len(b) {
+		return nil, fmt.Errorf("LSA length %d doesn't match data length %d", h.length, len(b))
+	}
+
+	// h.bytes shares storage with b so that SetAge updates both.
+	return &lsaBase{
+		lsaHeader: *h,
+		bytes:     b,
+	}, nil
+}
+
 func (base *lsaBase) SetAge(age uint16) {
 	base.age = age
 	binary.BigEndian.PutUint16(base.bytes[0:2], age)
@@ -215,23 +534,3 @@ func fletcher16GenerateChecksum(data []byte, offset int) uint16 {
 
 	return uint16(x<<8 | y)
 }
-
-type routerLSA struct {
-	lsaBase
-}
-
-type networkLSA struct {
-	lsaBase
-}
-
-type summaryLSA struct {
-	lsaBase
-}
-
-type asbrSummaryLSA struct {
-	lsaBase
-}
-
-type asExternalLSA struct {
-	lsaBase
-}
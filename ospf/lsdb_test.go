@@ -0,0 +1,173 @@
+package ospf
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+)
+
+func newTestLSDBInstance(t *testing.T) *Instance {
+	t.Helper()
+
+	return newTestInstance(t, "1.1.1.1", newMemNetwork(), map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig()})
+}
+
+// installAged installs lsa in area 0 as though it had been in the database
+// for d.
+func installAged(inst *Instance, lsa LSA, d time.Duration) *installedLSA {
+	inst.installLSA(0, lsa)
+
+	installed, _ := inst.lookupLSA(0, lsa.Key())
+	installed.installedAt = time.Now().Add(-d)
+
+	return installed
+}
+
+func TestInstalledLSAAge(t *testing.T) {
+	inst := newTestLSDBInstance(t)
+
+	lsa := installAged(inst, testRouterLSA("2.2.2.2", initialSequenceNumber), 10*time.Second)
+	if lsa.Age() != 11 {
+		t.Errorf("expected age 11, got %d", lsa.Age())
+	}
+
+	if h := headerOf(lsa); h.Age() != 11 {
+		t.Errorf("expected header with age 11, got %d", h.Age())
+	}
+
+	lsa.installedAt = time.Now().Add(-2 * maxAge * time.Second)
+	if lsa.Age() != maxAge {
+		t.Errorf("expected age to stop at MaxAge, got %d", lsa.Age())
+	}
+}
+
+func TestMaxAgeFlush(t *testing.T) {
+	iface, nbrs, peers := newTestFloodingInterface(t)
+	inst := iface.instance
+
+	lsa := testRouterLSA("2.2.2.2", initialSequenceNumber)
+	installAged(inst, lsa, maxAge*time.Second)
+
+	// Reaching MaxAge floods the LSA, which stays in the database until it's
+	// acknowledged.
+	inst.ageLSDB()
+
+	upd := receiveTestPacket[*LSUpd](t, peers[0])
+	if upd.lsas[0].Age() != maxAge {
+		t.Errorf("expected MaxAge LSA to be flooded, got age %d", upd.lsas[0].Age())
+	}
+
+	inst.ageLSDB()
+	if _, ok := inst.lookupLSA(0, lsa.Key()); !ok {
+		t.Fatalf("expected LSA to stay in the database until acknowledged")
+	}
+
+	for _, n := range nbrs {
+		iface.handleLSAck(&LSAck{PacketHeader: PacketHeader{routerID: n.ID}, lsaHeaders: []*lsaHeader{headerOf(upd.lsas[0])}}, n.Addr)
+	}
+
+	// A neighbor in Exchange might still need it.
+	nbrs[0].state = nExchange
+	inst.ageLSDB()
+	if _, ok := inst.lookupLSA(0, lsa.Key()); !ok {
+		t.Fatalf("expected LSA to stay in the database while a neighbor is in Exchange")
+	}
+
+	nbrs[0].state = nFull
+	inst.ageLSDB()
+	if _, ok := inst.lookupLSA(0, lsa.Key()); ok {
+		t.Fatalf("expected LSA to be flushed")
+	}
+}
+
+func TestRefreshSelfOriginatedLSA(t *testing.T) {
+	inst := newTestLSDBInstance(t)
+
+	mine := installAged(inst, testRouterLSA("1.1.1.1", initialSequenceNumber), lsRefreshTime*time.Second)
+	theirs := installAged(inst, testRouterLSA("2.2.2.2", initialSequenceNumber), lsRefreshTime*time.Second)
+
+	inst.ageLSDB()
+
+	refreshed, _ := inst.lookupLSA(0, mine.Key())
+	if refreshed.SequenceNumber() != initialSequenceNumber+1 || refreshed.Age() != 0 {
+		t.Errorf("expected refreshed LSA with sequence number %d and age 0, got %d and %d", initialSequenceNumber+1, refreshed.SequenceNumber(), refreshed.Age())
+	}
+
+	if !refreshed.IsChecksumValid() {
+		t.Errorf("expected refreshed LSA to have a valid checksum")
+	}
+
+	if _, ok := refreshed.LSA.(*routerLSA); !ok {
+		t.Errorf("expected *routerLSA, got %T", refreshed.LSA)
+	}
+
+	other, _ := inst.lookupLSA(0, theirs.Key())
+	if other.SequenceNumber() != initialSequenceNumber {
+		t.Errorf("expected other routers' LSAs to be left alone")
+	}
+}
+
+func TestSequenceNumberWrap(t *testing.T) {
+	inst := newTestLSDBInstance(t)
+
+	lsa := testRouterLSA("1.1.1.1", maxSequenceNumber)
+	inst.installLSA(0, lsa)
+
+	inst.originateLSA(areaScope(0), lsa)
+
+	// The old instance is flushed before the sequence number wraps.
+	flushed, _ := inst.lookupLSA(0, lsa.Key())
+	if flushed.Age() != maxAge || flushed.SequenceNumber() != maxSequenceNumber {
+		t.Fatalf("expected MaxAge LSA with sequence number %d, got age %d and %d", int32(maxSequenceNumber), flushed.Age(), flushed.SequenceNumber())
+	}
+
+	inst.ageLSDB()
+
+	wrapped, ok := inst.lookupLSA(0, lsa.Key())
+	if !ok || wrapped.SequenceNumber() != initialSequenceNumber || wrapped.Age() != 0 {
+		t.Fatalf("expected new instance with sequence number %d, got %+v", initialSequenceNumber, wrapped)
+	}
+}
+
+func TestMaxAgeFlushIgnoresOtherAreasRetransmissionLists(t *testing.T) {
+	inst := newTestLSDBInstance(t)
+
+	conf := testInterfaceConfig()
+	conf.AreaID = 1
+	inst.Areas[1] = newArea(1, config.OSPFAreaConfig{})
+
+	netif := testNetif("eth1", 2, "10.0.1.1/24")
+	eth1, err := newInterface(inst, conf, netif, netif.Prefixes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { eth1.transport.close() })
+
+	eth1.State = iDR
+	inst.Interfaces[interfaceID{name: netif.Name, prefix: netif.Prefixes[0]}] = eth1
+
+	nbr := newNeighbor(eth1, mustParseRouterID("3.3.3.3"), netip.MustParseAddr("10.0.1.3"))
+	nbr.state = nFull
+	eth1.Neighbors[nbr.ID] = nbr
+
+	// 4.4.4.4 is another area border router, so its router-LSAs in both
+	// areas have the same key.
+	lsa := testRouterLSA("4.4.4.4", initialSequenceNumber)
+	installAged(inst, lsa, maxAge*time.Second)
+
+	inst.installLSA(1, lsa)
+	nbr.addToRetransmissionList(lsa)
+
+	inst.ageLSDB()
+	inst.ageLSDB()
+
+	if _, ok := inst.lookupLSA(0, lsa.Key()); ok {
+		t.Errorf("expected the area 0 instance to be flushed")
+	}
+
+	if _, ok := inst.lookupLSA(1, lsa.Key()); !ok {
+		t.Errorf("expected the area 1 instance to stay in the database")
+	}
+}
@@ -0,0 +1,135 @@
+package ospf
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+)
+
+// Stub router advertisement, described in RFC 6987. While it's in effect,
+// every link in our router-LSAs other than stub links has MaxLinkMetric, so
+// other routers only forward through us if there's no other path, but can
+// still reach the networks we're attached to. It's in effect for a while
+// after we start, so that traffic doesn't go through us before our routing
+// table has converged, and on request, while we're drained for maintenance.
+
+// maxLinkMetric is the cost of our links while we're advertising ourselves
+// as a stub router. See RFC 6987, section 2.
+const maxLinkMetric = 0xffff
+
+// A maxMetricStartup is the period after starting during which we advertise
+// MaxLinkMetric.
+type maxMetricStartup struct {
+	deadline time.Time
+	timer    *time.Timer
+}
+
+// startMaxMetric begins the on-startup period, if one is configured. A
+// router that's restarting gracefully keeps forwarding with its old routes,
+// so there's no reason to avoid it.
+func (inst *Instance) startMaxMetric() {
+	seconds := inst.config.MaxMetric.OnStartup
+	if seconds == 0 || inst.isRestarting() {
+		return
+	}
+
+	d := time.Duration(seconds) * time.Second
+
+	s := &maxMetricStartup{
+		deadline: time.Now().Add(d),
+	}
+
+	s.timer = time.AfterFunc(d, func() {
+		inst.mu.Lock()
+		defer inst.mu.Unlock()
+
+		if inst.maxMetricStartup == s {
+			inst.maxMetricStartup = nil
+			inst.maxMetricChanged("on-startup period ended")
+		}
+	})
+
+	inst.maxMetricStartup = s
+
+	fmt.Printf("ospf: advertising max-metric for %s after starting\n", d)
+}
+
+func (inst *Instance) stopMaxMetricStartup() {
+	if inst.maxMetricStartup != nil {
+		inst.maxMetricStartup.timer.Stop()
+		inst.maxMetricStartup = nil
+	}
+}
+
+// isMaxMetric reports whether our router-LSAs advertise MaxLinkMetric.
+func (inst *Instance) isMaxMetric() bool {
+	return inst.maxMetric || inst.maxMetricStartup != nil
+}
+
+// SetMaxMetric starts or stops advertising MaxLinkMetric on request.
+// Stopping also ends the on-startup period early.
+func (i *Instance) SetMaxMetric(on bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	was := i.isMaxMetric()
+
+	i.maxMetric = on
+	if !on {
+		i.stopMaxMetricStartup()
+	}
+
+	if i.isMaxMetric() != was {
+		if on {
+			i.maxMetricChanged("requested")
+		} else {
+			i.maxMetricChanged("stopped on request")
+		}
+	}
+}
+
+// maxMetricChanged re-originates our router-LSAs with new link costs.
+func (inst *Instance) maxMetricChanged(reason string) {
+	if inst.isMaxMetric() {
+		fmt.Printf("ospf: advertising max-metric: %s\n", reason)
+	} else {
+		fmt.Printf("ospf: no longer advertising max-metric: %s\n", reason)
+	}
+
+	inst.scheduleRouterLSAs()
+}
+
+// setMaxLinkMetric gives every link other than stub links MaxLinkMetric. Stub
+// links keep their costs, so that the networks we're attached to are still
+// reachable through us.
+func setMaxLinkMetric(links []routerLink) {
+	for i := range links {
+		if links[i].Type != linkStub {
+			links[i].Metric = maxLinkMetric
+		}
+	}
+}
+
+// A MaxMetricSnapshot describes stub router advertisement, for reporting.
+// StartupRemaining is only set during the on-startup period.
+type MaxMetricSnapshot struct {
+	config.OSPFMaxMetricConfig
+	Advertising      bool
+	OnRequest        bool
+	StartupRemaining time.Duration
+}
+
+func (inst *Instance) maxMetricSnapshot() MaxMetricSnapshot {
+	s := MaxMetricSnapshot{
+		OSPFMaxMetricConfig: inst.config.MaxMetric,
+		Advertising:         inst.isMaxMetric(),
+		OnRequest:           inst.maxMetric,
+	}
+
+	if inst.maxMetricStartup != nil {
+		s.StartupRemaining = time.Until(inst.maxMetricStartup.deadline)
+	}
+
+	return s
+}
@@ -0,0 +1,136 @@
+package ospf
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSetMaxLinkMetric(t *testing.T) {
+	links := []routerLink{
+		testLink(linkPointToPoint, "2.2.2.2", "10.0.0.1", 10),
+		testLink(linkTransit, "10.0.1.1", "10.0.1.2", 10),
+		testLink(linkVirtual, "3.3.3.3", "10.0.2.1", 20),
+		testLink(linkStub, "10.0.0.0", "255.255.255.0", 10),
+	}
+
+	setMaxLinkMetric(links)
+
+	for _, l := range links[:3] {
+		if l.Metric != maxLinkMetric {
+			t.Errorf("expected %v link to %s to have metric %#x, got %#x", l.Type, l.ID, maxLinkMetric, l.Metric)
+		}
+	}
+
+	// Stub networks are still reachable through us.
+	if links[3].Metric != 10 {
+		t.Errorf("expected stub link to keep metric 10, got %d", links[3].Metric)
+	}
+}
+
+func TestSetMaxMetricReoriginatesRouterLSA(t *testing.T) {
+	iface, _, _ := newTestFloodingInterface(t)
+	inst := iface.instance
+
+	inst.SetMaxMetric(true)
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	key := lsdbKey{Type: lsTypeRouter, ID: addrFromRouterID(inst.RouterID), AdvertisingRouter: inst.RouterID}
+
+	lsa, ok := inst.lookupLSA(0, key)
+	if !ok {
+		t.Fatalf("expected our router-LSA to be originated")
+	}
+
+	links := lsa.LSA.(*routerLSA).Links()
+	if len(links) != 1 || links[0].Type != linkTransit || links[0].Metric != maxLinkMetric {
+		t.Errorf("expected a transit link with metric %#x, got %v", maxLinkMetric, links)
+	}
+
+	if !inst.maxMetricSnapshot().OnRequest {
+		t.Errorf("expected max-metric on request")
+	}
+}
+
+func TestMaxMetricOnStartup(t *testing.T) {
+	inst := newTestInstance(t, "1.1.1.1", newMemNetwork(), nil)
+	inst.config.MaxMetric.OnStartup = 1
+
+	inst.mu.Lock()
+	inst.startMaxMetric()
+
+	if !inst.isMaxMetric() {
+		t.Errorf("expected max-metric on startup")
+	}
+
+	if d := inst.maxMetricSnapshot().StartupRemaining; d <= 0 || d > time.Second {
+		t.Errorf("expected the on-startup period to end within 1s, got %s", d)
+	}
+	inst.mu.Unlock()
+
+	waitFor(t, inst, 5*time.Second, "the on-startup period to end", func() bool {
+		return !inst.isMaxMetric()
+	})
+}
+
+func TestStopMaxMetricEndsStartupPeriod(t *testing.T) {
+	inst := newTestInstance(t, "1.1.1.1", newMemNetwork(), nil)
+	inst.config.MaxMetric.OnStartup = 60
+
+	inst.mu.Lock()
+	inst.startMaxMetric()
+	inst.mu.Unlock()
+
+	inst.SetMaxMetric(false)
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if inst.isMaxMetric() {
+		t.Errorf("expected max-metric to stop")
+	}
+}
+
+func TestNoMaxMetricOnStartupDuringGracefulRestart(t *testing.T) {
+	inst := newTestInstance(t, "1.1.1.1", newMemNetwork(), nil)
+	inst.config.MaxMetric.OnStartup = 60
+
+	inst.Restart(&restartState{
+		routerID: inst.RouterID,
+		rib:      make(routingTable),
+		deadline: time.Now().Add(time.Minute),
+	})
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.startMaxMetric()
+
+	if inst.isMaxMetric() {
+		t.Errorf("expected no max-metric while restarting gracefully")
+	}
+}
+
+func TestMaxMetricOnRequestSurvivesRestart(t *testing.T) {
+	old := newTestInstance(t, "1.1.1.1", newMemNetwork(), nil)
+	old.SetMaxMetric(true)
+
+	// Graceful restart isn't enabled, so this doesn't wait for anything.
+	state := old.PrepareRestart(context.Background())
+
+	inst := newTestInstance(t, "1.1.1.1", newMemNetwork(), nil)
+	inst.Restart(state)
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if !inst.isMaxMetric() {
+		t.Errorf("expected max-metric on request to survive a restart")
+	}
+
+	if inst.isRestarting() {
+		t.Errorf("expected not to restart gracefully")
+	}
+}
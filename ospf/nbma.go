@@ -0,0 +1,129 @@
+package ospf
+
+import (
+	"net/netip"
+	"sort"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+)
+
+// On NBMA networks there's no multicast, so neighbors are configured rather
+// than discovered. Until we hear a configured neighbor's Hellos, we don't
+// know its router ID, so it's kept in pendingNeighbors, keyed by address,
+// using its configured priority. Once we hear from it, it moves to
+// Neighbors like any other neighbor. See RFC 2328, sections 9.5.1 and 10.
+
+// addStaticNeighbors adds a neighbor in state Down for each configured
+// neighbor that we haven't heard from.
+func (i *Interface) addStaticNeighbors() {
+	for addr := range i.staticNeighbors {
+		i.addStaticNeighbor(addr, nil)
+	}
+}
+
+// addStaticNeighbor adds a neighbor in state Down for the configured
+// neighbor at addr, carrying over history from its previous incarnation.
+func (i *Interface) addStaticNeighbor(addr netip.Addr, history []NeighborTransition) {
+	nc, ok := i.staticNeighbors[addr]
+	if !ok {
+		return
+	}
+
+	if _, ok := i.pendingNeighbors[addr]; ok {
+		return
+	}
+
+	for _, n := range i.Neighbors {
+		if n.Addr == addr {
+			return
+		}
+	}
+
+	n := newNeighbor(i, 0, addr)
+	n.Priority = nc.Priority
+	n.history = history
+	i.pendingNeighbors[addr] = n
+	go n.run()
+}
+
+// startStaticNeighbors sends the Start event to the configured neighbors
+// that we haven't heard from and that match f.
+func (i *Interface) startStaticNeighbors(f func(n *Neighbor) bool) {
+	for _, n := range i.sortedPendingNeighbors() {
+		if f(n) {
+			n.handleEvent(neStart)
+		}
+	}
+}
+
+// adoptStaticNeighbor returns the configured neighbor at addr that we
+// haven't heard from, and moves it to Neighbors under the router ID that
+// its Hellos carry. It returns nil if there's no such neighbor.
+func (i *Interface) adoptStaticNeighbor(id common.RouterID, addr netip.Addr) *Neighbor {
+	n, ok := i.pendingNeighbors[addr]
+	if !ok {
+		return nil
+	}
+
+	delete(i.pendingNeighbors, addr)
+	n.ID = id
+	i.Neighbors[id] = n
+
+	return n
+}
+
+// sortedPendingNeighbors returns the configured neighbors that we haven't
+// heard from, sorted by address.
+func (i *Interface) sortedPendingNeighbors() []*Neighbor {
+	var neighbors []*Neighbor
+	for _, n := range i.pendingNeighbors {
+		neighbors = append(neighbors, n)
+	}
+
+	sort.Slice(neighbors, func(a, b int) bool {
+		return neighbors[a].Addr.Less(neighbors[b].Addr)
+	})
+
+	return neighbors
+}
+
+// nbmaHelloDestinations returns the addresses of the neighbors that Hellos
+// are sent to on an NBMA network, as described in RFC 2328, section 9.5.1.
+// Routers that are eligible to become DR send Hellos to the other eligible
+// routers, and the DR and BDR send them to everyone. Routers that aren't
+// eligible only send them to the DR and BDR. Neighbors in state Down are
+// only sent Hellos if poll is true.
+func (i *Interface) nbmaHelloDestinations(poll bool) []netip.Addr {
+	neighbors := i.sortedPendingNeighbors()
+	for _, n := range i.Neighbors {
+		neighbors = append(neighbors, n)
+	}
+
+	var dsts []netip.Addr
+	for _, n := range neighbors {
+		if n.state == nDown && !poll {
+			continue
+		}
+
+		if i.sendsHellosTo(n) {
+			dsts = append(dsts, n.Addr)
+		}
+	}
+
+	sort.Slice(dsts, func(a, b int) bool {
+		return dsts[a].Less(dsts[b])
+	})
+
+	return dsts
+}
+
+func (i *Interface) sendsHellosTo(n *Neighbor) bool {
+	switch {
+	case i.State == iDR || i.State == iBackup:
+		return true
+	case i.RouterPriority > 0:
+		return n.Priority > 0
+	default:
+		return n.Addr == i.DR.Addr || n.Addr == i.BDR.Addr
+	}
+}
@@ -0,0 +1,253 @@
+package ospf
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+)
+
+// testNBMAConfig configures an NBMA network with an eligible neighbor at
+// 10.0.0.2 and an ineligible one at 10.0.0.3.
+func testNBMAConfig(priority uint8) config.OSPFInterfaceConfig {
+	conf := testInterfaceConfig()
+	conf.RouterPriority = priority
+	conf.Network = config.OSPFNetworkNBMA
+	conf.PollInterval = 120
+	conf.Neighbors = map[netip.Addr]config.OSPFNeighborConfig{
+		netip.MustParseAddr("10.0.0.2"): {Priority: 1},
+		netip.MustParseAddr("10.0.0.3"): {Priority: 0},
+	}
+
+	return conf
+}
+
+// newTestNBMAInterface returns an NBMA interface that isn't running, with
+// the neighbors from testNBMAConfig.
+func newTestNBMAInterface(t *testing.T, priority uint8) *Interface {
+	t.Helper()
+
+	conf := testNBMAConfig(priority)
+	netif := testNetif("eth0", 1, "10.0.0.1/24")
+	inst := newTestInstance(t, "1.1.1.1", newMemNetwork(), map[string]config.OSPFInterfaceConfig{"eth0": conf})
+
+	iface, err := newInterface(inst, conf, netif, netif.Prefixes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		inst.mu.Lock()
+		iface.killNeighbors()
+		inst.mu.Unlock()
+
+		iface.transport.close()
+	})
+
+	inst.Interfaces[interfaceID{name: netif.Name, prefix: netif.Prefixes[0]}] = iface
+
+	return iface
+}
+
+func pendingNeighbor(t *testing.T, iface *Interface, addr string) *Neighbor {
+	t.Helper()
+
+	n, ok := iface.pendingNeighbors[netip.MustParseAddr(addr)]
+	if !ok {
+		t.Fatalf("expected a pending neighbor at %s", addr)
+	}
+
+	return n
+}
+
+func assertAddrs(t *testing.T, what string, got []netip.Addr, expected ...string) {
+	t.Helper()
+
+	if len(got) != len(expected) {
+		t.Fatalf("expected %s to be %v, got %v", what, expected, got)
+	}
+
+	for i := range got {
+		if got[i] != netip.MustParseAddr(expected[i]) {
+			t.Fatalf("expected %s to be %v, got %v", what, expected, got)
+		}
+	}
+}
+
+func TestInterfaceTypeFor(t *testing.T) {
+	broadcast := testNetif("eth0", 1, "10.0.0.1/24")
+	broadcast.Flags |= net.FlagBroadcast
+
+	ptp := testNetif("tun0", 2, "10.0.0.1/32")
+	ptp.Flags |= net.FlagPointToPoint
+
+	tests := []struct {
+		network  config.OSPFNetworkType
+		netif    string
+		expected interfaceType
+	}{
+		{config.OSPFNetworkAuto, "broadcast", InterfaceBroadcast},
+		{config.OSPFNetworkAuto, "ptp", InterfacePointToPoint},
+		{config.OSPFNetworkPointToPoint, "broadcast", InterfacePointToPoint},
+		{config.OSPFNetworkNBMA, "broadcast", InterfaceNBMA},
+		{config.OSPFNetworkPointToMultipoint, "broadcast", InterfacePointToMultipoint},
+		{config.OSPFNetworkBroadcast, "ptp", InterfaceBroadcast},
+	}
+
+	for _, test := range tests {
+		netif := broadcast
+		if test.netif == "ptp" {
+			netif = ptp
+		}
+
+		conf := testInterfaceConfig()
+		conf.Network = test.network
+
+		if it := interfaceTypeFor(conf, netif); it != test.expected {
+			t.Errorf("network %s on %s: expected %s, got %s", test.network, test.netif, test.expected, it)
+		}
+	}
+}
+
+func TestNBMAInterfaceUp(t *testing.T) {
+	iface := newTestNBMAInterface(t, 1)
+
+	iface.instance.mu.Lock()
+	defer iface.instance.mu.Unlock()
+
+	iface.handleEvent(ieInterfaceUp)
+
+	if iface.State != iWaiting {
+		t.Fatalf("expected Waiting, got %s", iface.State)
+	}
+
+	// Eligible neighbors are started, and the others are left Down.
+	assertNeighborState(t, pendingNeighbor(t, iface, "10.0.0.2"), nAttempt)
+	assertNeighborState(t, pendingNeighbor(t, iface, "10.0.0.3"), nDown)
+
+	if iface.Stats.HellosSent != 1 {
+		t.Errorf("expected a Hello to be sent to the started neighbor, sent %d", iface.Stats.HellosSent)
+	}
+
+	// Ineligible neighbors aren't sent Hellos until we're DR or BDR.
+	assertAddrs(t, "Hello destinations", iface.helloDestinations(false), "10.0.0.2")
+	assertAddrs(t, "Hello destinations when polling", iface.helloDestinations(true), "10.0.0.2")
+
+	iface.electDR()
+
+	if iface.State != iDR {
+		t.Fatalf("expected DR, got %s", iface.State)
+	}
+
+	assertNeighborState(t, pendingNeighbor(t, iface, "10.0.0.3"), nAttempt)
+	assertAddrs(t, "Hello destinations as DR", iface.helloDestinations(false), "10.0.0.2", "10.0.0.3")
+}
+
+func TestNBMANeighborAdopted(t *testing.T) {
+	iface := newTestNBMAInterface(t, 1)
+
+	iface.instance.mu.Lock()
+	defer iface.instance.mu.Unlock()
+
+	iface.handleEvent(ieInterfaceUp)
+
+	pending := pendingNeighbor(t, iface, "10.0.0.2")
+
+	iface.handleHello(testHello("2.2.2.2"), netip.MustParseAddr("10.0.0.2"))
+
+	n := iface.Neighbors[mustParseRouterID("2.2.2.2")]
+	if n != pending {
+		t.Fatalf("expected the configured neighbor to be used for 2.2.2.2")
+	}
+
+	if _, ok := iface.pendingNeighbors[netip.MustParseAddr("10.0.0.2")]; ok {
+		t.Errorf("expected 10.0.0.2 to no longer be pending")
+	}
+
+	assertNeighborState(t, n, nInit)
+
+	if h := n.History(); len(h) != 2 || h[0].To != nAttempt || h[1].To != nInit {
+		t.Errorf("expected Down -> Attempt -> Init, got %+v", h)
+	}
+
+	// When it goes down, it's polled again.
+	n.handleEvent(neInactivityTimer)
+
+	if _, ok := iface.Neighbors[mustParseRouterID("2.2.2.2")]; ok {
+		t.Errorf("expected 2.2.2.2 to be removed")
+	}
+
+	n = pendingNeighbor(t, iface, "10.0.0.2")
+	assertNeighborState(t, n, nDown)
+
+	if n.Priority != 1 || len(n.History()) != 3 {
+		t.Errorf("expected priority 1 and three transitions, got %d and %+v", n.Priority, n.History())
+	}
+
+	assertAddrs(t, "Hello destinations", iface.helloDestinations(false))
+	assertAddrs(t, "Hello destinations when polling", iface.helloDestinations(true), "10.0.0.2")
+}
+
+func TestNBMAIneligibleRouter(t *testing.T) {
+	iface := newTestNBMAInterface(t, 0)
+
+	iface.instance.mu.Lock()
+	defer iface.instance.mu.Unlock()
+
+	iface.handleEvent(ieInterfaceUp)
+
+	if iface.State != iDROther {
+		t.Fatalf("expected DROther, got %s", iface.State)
+	}
+
+	assertNeighborState(t, pendingNeighbor(t, iface, "10.0.0.2"), nDown)
+	assertAddrs(t, "Hello destinations", iface.helloDestinations(true))
+
+	// We answer Hellos from eligible neighbors.
+	sent := iface.Stats.HellosSent
+	iface.handleHello(testHello("2.2.2.2"), netip.MustParseAddr("10.0.0.2"))
+
+	if iface.Stats.HellosSent != sent+1 {
+		t.Errorf("expected a Hello in reply")
+	}
+
+	// Once it's DR, it's sent periodic Hellos.
+	iface.DR = Router{ID: mustParseRouterID("2.2.2.2"), Addr: netip.MustParseAddr("10.0.0.2")}
+	assertAddrs(t, "Hello destinations", iface.helloDestinations(false), "10.0.0.2")
+}
+
+func TestNBMABetweenInstances(t *testing.T) {
+	network := newMemNetwork()
+
+	c1 := testNBMAConfig(1)
+	c1.Neighbors = map[netip.Addr]config.OSPFNeighborConfig{netip.MustParseAddr("10.0.0.2"): {Priority: 1}}
+
+	c2 := testNBMAConfig(1)
+	c2.Neighbors = map[netip.Addr]config.OSPFNeighborConfig{netip.MustParseAddr("10.0.0.1"): {Priority: 1}}
+
+	r1 := newTestInstance(t, "1.1.1.1", network, map[string]config.OSPFInterfaceConfig{"eth0": c1})
+	r2 := newTestInstance(t, "2.2.2.2", network, map[string]config.OSPFInterfaceConfig{"eth0": c2})
+
+	i1 := startTestInterface(t, r1, testNetif("eth0", 1, "10.0.0.1/24"))
+	i2 := startTestInterface(t, r2, testNetif("eth0", 2, "10.0.0.2/24"))
+
+	waitFor(t, r1, 20*time.Second, "r1 to be adjacent to r2", func() bool {
+		return neighborStateOf(i1, "2.2.2.2") == nFull && i1.State == iBackup
+	})
+
+	waitFor(t, r2, 20*time.Second, "r2 to be adjacent to r1", func() bool {
+		return neighborStateOf(i2, "1.1.1.1") == nFull && i2.State == iDR
+	})
+
+	r1.mu.Lock()
+	defer r1.mu.Unlock()
+
+	if i1.Type != InterfaceNBMA || i1.State != iBackup {
+		t.Errorf("expected an NBMA interface in state Backup, got %s in %s", i1.Type, i1.State)
+	}
+
+	if len(i1.pendingNeighbors) != 0 {
+		t.Errorf("expected no pending neighbors, got %d", len(i1.pendingNeighbors))
+	}
+}
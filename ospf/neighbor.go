@@ -1,14 +1,17 @@
 package ospf
 
 import (
+	"fmt"
 	"net/netip"
+	"time"
 
 	"github.com/davidbalbert/chatter/chatterd/common"
 )
 
 type Neighbor struct {
-	state neighborState
-	// TODO: InactivityTimer - single shot timer
+	state                  neighborState
+	InactivityTimer        *time.Timer
+	RxmtTimer              *time.Timer
 	Master                 bool
 	DDSequenceNumber       uint32
 	LastReceivedDD         *DD
@@ -16,13 +19,360 @@ type Neighbor struct {
 	Priority               uint8
 	Addr                   netip.Addr
 	Options                uint8
-	DesignatedRouter       common.RouterID
-	BackupDesignatedRouter common.RouterID
+	DesignatedRouter       netip.Addr
+	BackupDesignatedRouter netip.Addr
 
 	// TODO: make a type for these lists?
 	RetransmissionList   []*lsaHeader
 	DatabaseSummaryList  []*lsaHeader
 	LinkStateRequestList []*lsaHeader
+
+	// inactivityDeadline is when the inactivity timer should fire. The
+	// timer is restarted from the interface's goroutine, so a stale
+	// expiration might already be waiting in InactivityTimer.C. Checking
+	// against the deadline lets run ignore it.
+	inactivityDeadline time.Time
+
+	// rxmtDeadline is when the retransmission timer should fire, or the
+	// zero Time if it's not running.
+	rxmtDeadline time.Time
+
+	// lastSentDD is retransmitted by the master until the slave responds,
+	// and resent by the slave when it receives a duplicate from the master.
+	lastSentDD *DD
+
+	// lsReqPending holds the LSAs requested in our last Link State Request
+	// that we haven't received yet.
+	lsReqPending map[lsdbKey]bool
+
+	// cryptoSequenceNumber is the sequence number in the last packet we
+	// received from the neighbor with cryptographic authentication.
+	cryptoSequenceNumber uint32
+
+	// helper is non-nil while the neighbor is restarting gracefully with
+	// our help.
+	helper *restartHelper
+
+	history []NeighborTransition
+
+	Stats NeighborStats
+
+	iface *Interface
+	stop  chan struct{} // closed when the neighbor is destroyed
+}
+
+// A NeighborTransition records a change in a neighbor's state.
+type NeighborTransition struct {
+	Time  time.Time
+	Event neighborEvent
+	From  neighborState
+	To    neighborState
+}
+
+// maxNeighborHistory is the number of transitions kept for each neighbor.
+const maxNeighborHistory = 32
+
+func newNeighbor(iface *Interface, id common.RouterID, addr netip.Addr) *Neighbor {
+	inactivityTimer := time.NewTimer(0)
+	if !inactivityTimer.Stop() {
+		<-inactivityTimer.C
+	}
+
+	rxmtTimer := time.NewTimer(0)
+	if !rxmtTimer.Stop() {
+		<-rxmtTimer.C
+	}
+
+	return &Neighbor{
+		state:           nDown,
+		InactivityTimer: inactivityTimer,
+		RxmtTimer:       rxmtTimer,
+		ID:              id,
+		Addr:            addr,
+		iface:           iface,
+		stop:            make(chan struct{}),
+	}
+}
+
+// run handles the neighbor's timers. It returns when the neighbor is
+// destroyed.
+func (n *Neighbor) run() {
+	mu := &n.iface.instance.mu
+
+	for {
+		select {
+		case <-n.stop:
+			stopTimer(n.InactivityTimer)
+			stopTimer(n.RxmtTimer)
+			return
+		case <-n.InactivityTimer.C:
+			mu.Lock()
+			if n.isDestroyed() {
+				mu.Unlock()
+				return
+			}
+
+			if d := time.Until(n.inactivityDeadline); d > 0 {
+				n.InactivityTimer.Reset(d)
+			} else {
+				n.handleEvent(neInactivityTimer)
+			}
+			mu.Unlock()
+		case <-n.RxmtTimer.C:
+			mu.Lock()
+			if n.isDestroyed() {
+				mu.Unlock()
+				return
+			}
+
+			if d := time.Until(n.rxmtDeadline); d > 0 {
+				n.RxmtTimer.Reset(d)
+			} else {
+				n.handleRxmtTimer()
+			}
+			mu.Unlock()
+		}
+	}
+}
+
+func (n *Neighbor) isDestroyed() bool {
+	select {
+	case <-n.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (n *Neighbor) restartInactivityTimer() {
+	d := time.Duration(n.iface.RouterDeadInterval) * time.Second
+
+	n.inactivityDeadline = time.Now().Add(d)
+	n.InactivityTimer.Reset(d)
+}
+
+// History returns the neighbor's most recent state transitions, oldest first.
+func (n *Neighbor) History() []NeighborTransition {
+	history := make([]NeighborTransition, len(n.history))
+	copy(history, n.history)
+
+	return history
+}
+
+func (n *Neighbor) setState(e neighborEvent, s neighborState) {
+	if s == n.state {
+		return
+	}
+
+	fmt.Printf("ospf: %s %s: neighbor %s (%s): %s -> %s (%s)\n", n.iface.name, n.iface.Prefix, n.ID, n.Addr, n.state, s, e)
+
+	if len(n.history) == maxNeighborHistory {
+		n.history = append(n.history[:0], n.history[1:]...)
+	}
+	n.history = append(n.history, NeighborTransition{
+		Time:  time.Now(),
+		Event: e,
+		From:  n.state,
+		To:    s,
+	})
+
+	wasFull := n.state == nFull
+	n.state = s
+
+	if wasFull != (s == nFull) {
+		n.iface.instance.scheduleRouterLSAs()
+		n.iface.scheduleNetworkLSA()
+	}
+
+	if s == nFull {
+		n.iface.instance.checkRestart()
+	}
+}
+
+// handleEvent runs the neighbor state machine described in RFC 2328, section
+// 10.3. Events that aren't valid in the current state are ignored.
+func (n *Neighbor) handleEvent(e neighborEvent) {
+	old := n.state
+	n.step(e)
+
+	// RFC 2328, section 9.2: the interface needs to know when
+	// bidirectional communication with a neighbor is established or lost.
+	if (old >= n2Way) != (n.state >= n2Way) {
+		n.iface.handleEvent(ieNeighborChange)
+	}
+}
+
+func (n *Neighbor) step(e neighborEvent) {
+	switch e {
+	case neStart:
+		if n.state == nDown {
+			n.iface.sendHelloTo(n.iface.buildHello(), n.Addr)
+			n.restartInactivityTimer()
+			n.setState(e, nAttempt)
+		}
+	case neHelloReceived:
+		n.restartInactivityTimer()
+
+		if n.state == nDown || n.state == nAttempt {
+			n.setState(e, nInit)
+		}
+	case ne2WayReceived:
+		if n.state != nInit {
+			return
+		}
+
+		if n.shouldBeAdjacent() {
+			n.setState(e, nExStart)
+			n.startExStart()
+		} else {
+			n.setState(e, n2Way)
+		}
+	case neNegotiationDone:
+		if n.state != nExStart {
+			return
+		}
+
+		n.setState(e, nExchange)
+		n.buildDatabaseSummaryList()
+	case neExchangeDone:
+		if n.state != nExchange {
+			return
+		}
+
+		if len(n.LinkStateRequestList) == 0 {
+			n.setState(e, nFull)
+		} else {
+			n.setState(e, nLoading)
+		}
+	case neLoadingDone:
+		if n.state == nLoading {
+			n.setState(e, nFull)
+		}
+	case neAdjOK:
+		if n.state == n2Way && n.shouldBeAdjacent() {
+			n.setState(e, nExStart)
+			n.startExStart()
+		} else if n.state >= nExStart && !n.shouldBeAdjacent() {
+			n.clearLists()
+			n.setState(e, n2Way)
+		}
+	case neSeqNumberMismatch, neBadLSReq:
+		if n.state < nExchange {
+			return
+		}
+
+		n.clearLists()
+		n.setState(e, nExStart)
+		n.startExStart()
+	case ne1WayReceived:
+		// A restarting neighbor has forgotten about us, but we keep our
+		// adjacency until it's done. See RFC 3623, section 3.2.
+		if n.state >= n2Way && n.helper == nil {
+			n.clearLists()
+			n.setState(e, nInit)
+		}
+	case neKillNbr, neLLDown, neInactivityTimer:
+		if e == neInactivityTimer && n.helper != nil {
+			return
+		}
+
+		n.clearLists()
+		n.setState(e, nDown)
+
+		// Destroying the neighbor disables the inactivity timer.
+		n.iface.removeNeighbor(n)
+
+		// Configured neighbors on NBMA networks go back to being polled,
+		// unless the interface is going down.
+		if e != neKillNbr {
+			n.iface.addStaticNeighbor(n.Addr, n.history)
+		}
+	}
+}
+
+// shouldBeAdjacent reports whether an adjacency should be established with
+// the neighbor, as described in RFC 2328, section 10.4.
+func (n *Neighbor) shouldBeAdjacent() bool {
+	i := n.iface
+
+	if i.isPTP() || i.isPTMP() || i.isVirtualLink() {
+		return true
+	}
+
+	if i.State == iDR || i.State == iBackup {
+		return true
+	}
+
+	return i.DR.ID == n.ID || i.BDR.ID == n.ID
+}
+
+func (n *Neighbor) startExStart() {
+	if n.DDSequenceNumber == 0 {
+		n.DDSequenceNumber = uint32(time.Now().Unix())
+	} else {
+		n.DDSequenceNumber++
+	}
+
+	n.Master = true
+	n.sendInitialDD()
+}
+
+func (n *Neighbor) clearLists() {
+	n.RetransmissionList = nil
+	n.DatabaseSummaryList = nil
+	n.LinkStateRequestList = nil
+	n.LastReceivedDD = nil
+	n.lastSentDD = nil
+	n.lsReqPending = nil
+}
+
+// A NeighborSnapshot is a copy of a neighbor's state, for reporting.
+type NeighborSnapshot struct {
+	Interface              string
+	InterfacePrefix        netip.Prefix
+	ID                     common.RouterID
+	Addr                   netip.Addr
+	State                  neighborState
+	Priority               uint8
+	DesignatedRouter       netip.Addr
+	BackupDesignatedRouter netip.Addr
+	DeadTime               time.Duration // until the inactivity timer fires
+	History                []NeighborTransition
+	RetransmissionListLen  int
+	Stats                  NeighborStats
+	RestartHelper          bool          // the neighbor is restarting gracefully with our help
+	GraceRemaining         time.Duration // until we stop helping
+}
+
+func (n *Neighbor) snapshot() NeighborSnapshot {
+	// Configured neighbors on NBMA networks can be Down, with no
+	// inactivity timer running.
+	var deadTime time.Duration
+	if n.state != nDown {
+		deadTime = time.Until(n.inactivityDeadline)
+	}
+
+	var graceRemaining time.Duration
+	if n.helper != nil {
+		graceRemaining = time.Until(n.helper.deadline)
+	}
+
+	return NeighborSnapshot{
+		Interface:              n.iface.name,
+		InterfacePrefix:        n.iface.Prefix,
+		ID:                     n.ID,
+		Addr:                   n.Addr,
+		State:                  n.state,
+		Priority:               n.Priority,
+		DesignatedRouter:       n.DesignatedRouter,
+		BackupDesignatedRouter: n.BackupDesignatedRouter,
+		DeadTime:               deadTime,
+		History:                n.History(),
+		RetransmissionListLen:  len(n.RetransmissionList),
+		Stats:                  n.Stats,
+		RestartHelper:          n.helper != nil,
+		GraceRemaining:         graceRemaining,
+	}
 }
 
 type neighborState int
@@ -64,7 +414,8 @@ func (ns neighborState) String() string {
 type neighborEvent int
 
 const (
-	neHelloReceived neighborEvent = iota
+	neStart neighborEvent = iota
+	neHelloReceived
 	ne2WayReceived
 	neNegotiationDone
 	neExchangeDone
@@ -80,6 +431,8 @@ const (
 
 func (ne neighborEvent) String() string {
 	switch ne {
+	case neStart:
+		return "Start"
 	case neHelloReceived:
 		return "HelloReceived"
 	case ne2WayReceived:
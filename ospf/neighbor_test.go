@@ -0,0 +1,210 @@
+package ospf
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+)
+
+// newTestNeighbor returns a neighbor on a broadcast interface, having heard
+// a Hello from it. Neither we nor the neighbor are eligible to become DR, so
+// no election takes place unless the test sets a DR.
+func newTestNeighbor(t *testing.T) (*Interface, *Neighbor) {
+	t.Helper()
+
+	iface := newTestBroadcastInterface(t)
+	iface.RouterPriority = 0
+
+	h := testHello("2.2.2.2")
+	h.routerPriority = 0
+	iface.handleHello(h, netip.MustParseAddr("10.0.0.2"))
+
+	n := iface.Neighbors[mustParseRouterID("2.2.2.2")]
+	if n == nil || n.state != nInit {
+		t.Fatalf("expected neighbor in Init, got %+v", n)
+	}
+
+	return iface, n
+}
+
+func assertNeighborState(t *testing.T, n *Neighbor, expected neighborState) {
+	t.Helper()
+
+	if n.state != expected {
+		t.Fatalf("expected neighbor in %s, got %s", expected, n.state)
+	}
+}
+
+func TestNeighborStateMachine(t *testing.T) {
+	iface, n := newTestNeighbor(t)
+
+	n.handleEvent(ne2WayReceived)
+	assertNeighborState(t, n, n2Way)
+
+	// NegotiationDone isn't valid in 2-Way
+	n.handleEvent(neNegotiationDone)
+	assertNeighborState(t, n, n2Way)
+
+	// Still not adjacent
+	n.handleEvent(neAdjOK)
+	assertNeighborState(t, n, n2Way)
+
+	iface.DR = Router{ID: n.ID, Addr: n.Addr}
+	n.handleEvent(neAdjOK)
+	assertNeighborState(t, n, nExStart)
+
+	if !n.Master || n.DDSequenceNumber == 0 {
+		t.Errorf("expected to be master with a DD sequence number, got %v, %d", n.Master, n.DDSequenceNumber)
+	}
+
+	n.handleEvent(neNegotiationDone)
+	assertNeighborState(t, n, nExchange)
+
+	n.handleEvent(neExchangeDone)
+	assertNeighborState(t, n, nFull)
+
+	seq := n.DDSequenceNumber
+	n.handleEvent(neSeqNumberMismatch)
+	assertNeighborState(t, n, nExStart)
+
+	if n.DDSequenceNumber != seq+1 {
+		t.Errorf("expected DD sequence number to be incremented to %d, got %d", seq+1, n.DDSequenceNumber)
+	}
+
+	// No longer adjacent
+	iface.DR = Router{}
+	n.handleEvent(neAdjOK)
+	assertNeighborState(t, n, n2Way)
+
+	expected := []NeighborTransition{
+		{Event: neHelloReceived, From: nDown, To: nInit},
+		{Event: ne2WayReceived, From: nInit, To: n2Way},
+		{Event: neAdjOK, From: n2Way, To: nExStart},
+		{Event: neNegotiationDone, From: nExStart, To: nExchange},
+		{Event: neExchangeDone, From: nExchange, To: nFull},
+		{Event: neSeqNumberMismatch, From: nFull, To: nExStart},
+		{Event: neAdjOK, From: nExStart, To: n2Way},
+	}
+
+	history := n.History()
+	if len(history) != len(expected) {
+		t.Fatalf("expected %d transitions, got %d: %+v", len(expected), len(history), history)
+	}
+
+	for i, tr := range history {
+		if tr.Event != expected[i].Event || tr.From != expected[i].From || tr.To != expected[i].To {
+			t.Errorf("transition %d: expected %s: %s -> %s, got %s: %s -> %s", i, expected[i].Event, expected[i].From, expected[i].To, tr.Event, tr.From, tr.To)
+		}
+
+		if tr.Time.IsZero() {
+			t.Errorf("transition %d: missing time", i)
+		}
+	}
+}
+
+func TestNeighborLoading(t *testing.T) {
+	iface, n := newTestNeighbor(t)
+	iface.Type = InterfacePointToPoint
+	iface.State = iPointToPoint
+
+	n.handleEvent(ne2WayReceived)
+	assertNeighborState(t, n, nExStart)
+
+	n.handleEvent(neNegotiationDone)
+	n.LinkStateRequestList = []*lsaHeader{&testRouterLSA("2.2.2.2", 1).lsaHeader}
+
+	n.handleEvent(neExchangeDone)
+	assertNeighborState(t, n, nLoading)
+
+	n.handleEvent(neBadLSReq)
+	assertNeighborState(t, n, nExStart)
+
+	if n.LinkStateRequestList != nil {
+		t.Errorf("expected link state request list to be cleared")
+	}
+}
+
+func TestNeighbor1WayReceived(t *testing.T) {
+	iface, n := newTestNeighbor(t)
+	iface.Type = InterfacePointToPoint
+	iface.State = iPointToPoint
+
+	n.handleEvent(ne2WayReceived)
+	n.handleEvent(neNegotiationDone)
+	n.DatabaseSummaryList = []*lsaHeader{&testRouterLSA("1.1.1.1", 1).lsaHeader}
+
+	n.handleEvent(ne1WayReceived)
+	assertNeighborState(t, n, nInit)
+
+	if n.DatabaseSummaryList != nil {
+		t.Errorf("expected database summary list to be cleared")
+	}
+
+	// 1-WayReceived in Init is ignored
+	n.handleEvent(ne1WayReceived)
+	assertNeighborState(t, n, nInit)
+}
+
+func TestNeighborKillNbr(t *testing.T) {
+	iface, n := newTestNeighbor(t)
+
+	n.handleEvent(neKillNbr)
+	assertNeighborState(t, n, nDown)
+
+	if _, ok := iface.Neighbors[n.ID]; ok {
+		t.Errorf("expected neighbor to be removed")
+	}
+
+	if !n.isDestroyed() {
+		t.Errorf("expected neighbor to be destroyed")
+	}
+}
+
+func TestNeighborHistoryIsBounded(t *testing.T) {
+	_, n := newTestNeighbor(t)
+
+	for i := 0; i < maxNeighborHistory; i++ {
+		n.handleEvent(ne2WayReceived)
+		n.handleEvent(ne1WayReceived)
+	}
+
+	history := n.History()
+	if len(history) != maxNeighborHistory {
+		t.Fatalf("expected %d transitions, got %d", maxNeighborHistory, len(history))
+	}
+
+	last := history[len(history)-1]
+	if last.Event != ne1WayReceived || last.To != nInit {
+		t.Errorf("expected the most recent transition last, got %+v", last)
+	}
+}
+
+func TestNeighborInactivityTimer(t *testing.T) {
+	iface := newTestBroadcastInterface(t)
+	iface.RouterDeadInterval = 1
+
+	inst := iface.instance
+	id := mustParseRouterID("2.2.2.2")
+
+	h := testHello("2.2.2.2")
+	h.routerDeadInterval = 1
+
+	inst.mu.Lock()
+	iface.handleHello(h, netip.MustParseAddr("10.0.0.2"))
+	n := iface.Neighbors[id]
+	inst.mu.Unlock()
+
+	waitFor(t, inst, 3*time.Second, "neighbor to time out", func() bool {
+		_, ok := iface.Neighbors[id]
+		return !ok
+	})
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	history := n.History()
+	last := history[len(history)-1]
+	if last.Event != neInactivityTimer || last.To != nDown {
+		t.Errorf("expected InactivityTimer: Init -> Down, got %+v", last)
+	}
+}
@@ -0,0 +1,254 @@
+package ospf
+
+import (
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+	"golang.org/x/exp/maps"
+)
+
+// translatorStabilityInterval is how long an elected translator keeps
+// translating after another router wins the election. See RFC 3101.
+const translatorStabilityInterval = 40 * time.Second
+
+// NSSATranslatorState is whether we translate an NSSA's Type-7 LSAs into
+// AS-external-LSAs.
+type NSSATranslatorState int
+
+const (
+	NSSATranslatorDisabled NSSATranslatorState = iota
+	NSSATranslatorEnabled                      // our role is always
+	NSSATranslatorElected                      // our role is candidate, and we won the election
+)
+
+func (s NSSATranslatorState) String() string {
+	switch s {
+	case NSSATranslatorDisabled:
+		return "disabled"
+	case NSSATranslatorEnabled:
+		return "enabled"
+	case NSSATranslatorElected:
+		return "elected"
+	default:
+		return fmt.Sprintf("NSSATranslatorState(%d)", int(s))
+	}
+}
+
+// isTranslator reports whether we translate Type-7 LSAs for any NSSA. If
+// so, we're an AS boundary router.
+func (inst *Instance) isTranslator() bool {
+	for _, area := range inst.Areas {
+		if area.NSSA && area.TranslatorState != NSSATranslatorDisabled {
+			return true
+		}
+	}
+
+	return false
+}
+
+// electTranslators decides which NSSAs we translate for. Only area border
+// routers translate. If our role is candidate, we translate if no other
+// border router in the NSSA translates unconditionally, and we have the
+// highest router ID of the NSSA's border routers. See RFC 3101.
+func (inst *Instance) electTranslators() {
+	abr := inst.isABR()
+
+	for _, area := range inst.sortedAreas() {
+		if !area.NSSA {
+			continue
+		}
+
+		state := NSSATranslatorDisabled
+		switch {
+		case !abr:
+		case area.TranslatorRole == config.NSSATranslatorAlways:
+			state = NSSATranslatorEnabled
+		case inst.winsTranslatorElection(area):
+			state = NSSATranslatorElected
+		}
+
+		inst.setTranslatorState(area, state)
+	}
+}
+
+func (inst *Instance) winsTranslatorElection(area *Area) bool {
+	for id, br := range area.borderRouters {
+		if !br.abr || br.pathType != PathIntraArea {
+			continue
+		}
+
+		if lsa := routerLSAFor(area, addrFromRouterID(id)); lsa != nil && lsa.Flags()&routerFlagNt != 0 {
+			return false
+		}
+
+		if id > inst.RouterID {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setTranslatorState moves area to a new translator state. If we lose an
+// election, we keep translating for translatorStabilityInterval, in case
+// we win it back.
+func (inst *Instance) setTranslatorState(area *Area, state NSSATranslatorState) {
+	if state == area.TranslatorState {
+		area.stopTranslatorStabilityTimer()
+		return
+	}
+
+	if area.TranslatorState == NSSATranslatorElected && state == NSSATranslatorDisabled && inst.isABR() {
+		if area.translatorStabilityTimer != nil {
+			return
+		}
+
+		var t *time.Timer
+		t = time.AfterFunc(translatorStabilityInterval, func() {
+			inst.mu.Lock()
+			defer inst.mu.Unlock()
+
+			if area.translatorStabilityTimer != t {
+				return
+			}
+
+			area.translatorStabilityTimer = nil
+			inst.changeTranslatorState(area, NSSATranslatorDisabled)
+			inst.originateNSSALSAs()
+		})
+		area.translatorStabilityTimer = t
+
+		return
+	}
+
+	area.stopTranslatorStabilityTimer()
+	inst.changeTranslatorState(area, state)
+}
+
+func (inst *Instance) changeTranslatorState(area *Area, state NSSATranslatorState) {
+	fmt.Printf("ospf: area %s: NSSA translator %s -> %s\n", area.ID, area.TranslatorState, state)
+
+	area.TranslatorState = state
+
+	// Our router-LSAs advertise whether we're an AS boundary router.
+	inst.scheduleRouterLSAs()
+}
+
+func (a *Area) stopTranslatorStabilityTimer() {
+	if a.translatorStabilityTimer != nil {
+		a.translatorStabilityTimer.Stop()
+		a.translatorStabilityTimer = nil
+	}
+}
+
+func (inst *Instance) stopTranslatorTimers() {
+	for _, area := range inst.Areas {
+		area.stopTranslatorStabilityTimer()
+	}
+}
+
+// translationsFor returns the NSSA's Type-7 LSAs that we translate into
+// AS-external-LSAs, keyed by prefix. We translate the Type-7 LSAs with the
+// P-bit set and a forwarding address that our routing table uses. Default
+// routes aren't translated.
+func (inst *Instance) translationsFor(area *Area) map[netip.Prefix]*nssaLSA {
+	if area.TranslatorState == NSSATranslatorDisabled {
+		return nil
+	}
+
+	translations := make(map[netip.Prefix]*nssaLSA)
+
+	for _, lsa := range area.lsdb {
+		n, ok := lsa.LSA.(*nssaLSA)
+		if !ok || !n.Propagate() || lsa.Age() == maxAge || lsa.AdvertisingRouter() == inst.RouterID {
+			continue
+		}
+
+		fa := n.ForwardingAddress()
+		if !fa.IsValid() || fa.IsUnspecified() || n.Prefix().Bits() == 0 {
+			continue
+		}
+
+		if r, ok := inst.rib[n.Prefix()]; !ok || r.origin != lsa.LSA {
+			continue
+		}
+
+		translations[n.Prefix()] = n
+	}
+
+	return translations
+}
+
+// translate returns the AS-external-LSA with Link State ID id that we
+// originate for the Type-7 LSA n.
+func (inst *Instance) translate(n *nssaLSA, id netip.Addr) LSA {
+	h := lsaHeader{
+		options:           optE,
+		id:                id,
+		advertisingRouter: inst.RouterID,
+	}
+
+	return newASExternalLSA(h, n.NetworkMask(), externalRoute{
+		Type2:             n.IsType2(),
+		Metric:            n.Metric(),
+		ForwardingAddress: n.ForwardingAddress(),
+		RouteTag:          n.RouteTag(),
+	})
+}
+
+// defaultNSSALSA returns the default Type-7 LSA we originate into the
+// NSSA as an area border router, or nil if we don't originate one. It
+// doesn't have the P-bit set, so it's never translated.
+func (inst *Instance) defaultNSSALSA(area *Area) LSA {
+	if !area.DefaultOriginate || !inst.isABR() {
+		return nil
+	}
+
+	h := lsaHeader{
+		options:           area.options(),
+		id:                netip.IPv4Unspecified(),
+		advertisingRouter: inst.RouterID,
+	}
+
+	return newNSSALSA(h, prefixMask(0), externalRoute{
+		Type2:             true,
+		Metric:            area.StubDefaultCost,
+		ForwardingAddress: netip.IPv4Unspecified(),
+	})
+}
+
+// originateNSSALSAs brings the AS-external-LSAs we translate from Type-7
+// LSAs, and our default Type-7 LSAs, in line with the routing table.
+func (inst *Instance) originateNSSALSAs() {
+	desired := make(map[originationKey]LSA)
+	translations := make(map[netip.Prefix]*nssaLSA)
+
+	for _, area := range inst.sortedAreas() {
+		if !area.NSSA {
+			continue
+		}
+
+		if lsa := inst.defaultNSSALSA(area); lsa != nil {
+			desired[newOriginationKey(area.ID, lsa.Key())] = lsa
+		}
+
+		for prefix, n := range inst.translationsFor(area) {
+			if _, ok := translations[prefix]; !ok {
+				translations[prefix] = n
+			}
+		}
+	}
+
+	// Translated networks can share an address, whether they come from one
+	// NSSA or several, so their IDs are assigned together.
+	for prefix, id := range linkStateIDs(maps.Keys(translations)) {
+		lsa := inst.translate(translations[prefix], id)
+		desired[newOriginationKey(0, lsa.Key())] = lsa
+	}
+
+	inst.reconcileOriginations(desired, func(t lsType) bool {
+		return t == lsTypeASExternal || t == lsTypeNSSA
+	})
+}
@@ -0,0 +1,284 @@
+package ospf
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"github.com/davidbalbert/chatter/config"
+)
+
+func testNSSALSA(advertisingRouter, prefix string, p bool, r externalRoute) *nssaLSA {
+	pfx := netip.MustParsePrefix(prefix)
+
+	h := testLSAHeader(advertisingRouter, initialSequenceNumber)
+	h.id = pfx.Addr()
+	h.options = 0
+	if p {
+		h.options = optNP
+	}
+
+	if !r.ForwardingAddress.IsValid() {
+		r.ForwardingAddress = netip.IPv4Unspecified()
+	}
+
+	return newNSSALSA(h, prefixMask(pfx.Bits()), r)
+}
+
+// newTestNSSABR returns the area border router from newTestABR, with area
+// 1 as an NSSA. 3.3.3.3 advertises two Type-7 LSAs into it, only one of
+// which has the P-bit set.
+func newTestNSSABR(t *testing.T) *Instance {
+	t.Helper()
+
+	inst := newTestABR(t)
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	area := inst.Areas[1]
+	area.ExternalRoutingCapability = false
+	area.NSSA = true
+
+	fa := netip.MustParseAddr("10.1.0.3")
+
+	for _, lsa := range []LSA{
+		testNSSALSA("3.3.3.3", "198.51.100.0/24", true, externalRoute{Type2: true, Metric: 20, ForwardingAddress: fa, RouteTag: 7}),
+		testNSSALSA("3.3.3.3", "203.0.113.0/24", false, externalRoute{Metric: 5, ForwardingAddress: fa}),
+	} {
+		inst.installLSA(1, lsa)
+	}
+
+	return inst
+}
+
+// translated returns the AS-external-LSA we originated for prefix, if it
+// isn't being flushed.
+func translated(inst *Instance, prefix string) (*asExternalLSA, bool) {
+	p := netip.MustParsePrefix(prefix)
+
+	for _, lsa := range inst.lsdb {
+		ext, ok := lsa.LSA.(*asExternalLSA)
+		if ok && ext.AdvertisingRouter() == inst.RouterID && ext.Prefix() == p && lsa.Age() != maxAge {
+			return ext, true
+		}
+	}
+
+	return nil, false
+}
+
+func ourRouterLSA(inst *Instance, areaID common.AreaID) *routerLSA {
+	return routerLSAFor(inst.Areas[areaID], addrFromRouterID(inst.RouterID))
+}
+
+func TestNSSATranslation(t *testing.T) {
+	inst := newTestNSSABR(t)
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.runSPF()
+
+	expectRIBRoute(t, inst, "198.51.100.0/24", PathType2External, 10, 20, "10.1.0.3")
+	expectRIBRoute(t, inst, "203.0.113.0/24", PathType1External, 15, 0, "10.1.0.3")
+
+	// We're the only border router in the NSSA, so we're elected.
+	if s := inst.Areas[1].TranslatorState; s != NSSATranslatorElected {
+		t.Fatalf("expected translator to be elected, got %s", s)
+	}
+
+	ext, ok := translated(inst, "198.51.100.0/24")
+	if !ok {
+		t.Fatalf("expected 198.51.100.0/24 to be translated")
+	}
+
+	if !ext.IsType2() || ext.Metric() != 20 || ext.ForwardingAddress() != netip.MustParseAddr("10.1.0.3") || ext.RouteTag() != 7 {
+		t.Errorf("expected translation to keep the route, got %+v", ext.Routes()[0])
+	}
+
+	if _, ok := translated(inst, "203.0.113.0/24"); ok {
+		t.Errorf("expected no translation without the P-bit")
+	}
+
+	// As a translator, we're an AS boundary router outside the NSSA.
+	if lsa := ourRouterLSA(inst, 0); lsa == nil || !lsa.IsASBR() {
+		t.Errorf("expected the E-bit in our backbone router-LSA")
+	}
+
+	if lsa := ourRouterLSA(inst, 1); lsa == nil || lsa.IsASBR() {
+		t.Errorf("expected no E-bit in our NSSA router-LSA")
+	}
+}
+
+func TestNSSATranslationLinkStateIDs(t *testing.T) {
+	inst := newTestNSSABR(t)
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	// 4.4.4.4, another AS boundary router, joins 3.3.3.3 on our network in
+	// the NSSA. They advertise networks with the same address.
+	for _, lsa := range []LSA{
+		testRouterLSAWithLinks("4.4.4.4", routerFlagE,
+			testLink(linkTransit, "10.1.0.1", "10.1.0.4", 10),
+		),
+		withSequenceNumber(testNetworkLSA("10.1.0.1", "1.1.1.1", 24, "1.1.1.1", "3.3.3.3", "4.4.4.4"), initialSequenceNumber+1),
+		testNSSALSA("3.3.3.3", "198.18.0.0/24", true, externalRoute{Metric: 5, ForwardingAddress: netip.MustParseAddr("10.1.0.3")}),
+		testNSSALSA("4.4.4.4", "198.18.0.0/16", true, externalRoute{Metric: 6, ForwardingAddress: netip.MustParseAddr("10.1.0.4")}),
+	} {
+		inst.installLSA(1, lsa)
+	}
+
+	inst.runSPF()
+
+	// The less specific network gets the address as its ID, and the more
+	// specific one gets its host bits set. See RFC 2328, appendix E.
+	for prefix, id := range map[string]string{"198.18.0.0/16": "198.18.0.0", "198.18.0.0/24": "198.18.0.255"} {
+		ext, ok := translated(inst, prefix)
+		if !ok {
+			t.Errorf("expected %s to be translated", prefix)
+			continue
+		}
+
+		if ext.ID() != netip.MustParseAddr(id) {
+			t.Errorf("expected %s to have ID %s, got %s", prefix, id, ext.ID())
+		}
+	}
+}
+
+func TestNSSATranslatorElection(t *testing.T) {
+	inst := newTestNSSABR(t)
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	area := inst.Areas[1]
+
+	// 4.4.4.4, another border router with a higher router ID, joins the
+	// NSSA's network.
+	for _, lsa := range []LSA{
+		testRouterLSAWithLinks("4.4.4.4", routerFlagB,
+			testLink(linkTransit, "10.1.0.1", "10.1.0.4", 10),
+		),
+		testNetworkLSA("10.1.0.1", "1.1.1.1", 24, "1.1.1.1", "3.3.3.3", "4.4.4.4"),
+	} {
+		inst.installLSA(1, lsa)
+	}
+
+	inst.runSPF()
+
+	if area.TranslatorState != NSSATranslatorDisabled {
+		t.Errorf("expected 4.4.4.4 to win the election, got %s", area.TranslatorState)
+	}
+
+	if _, ok := translated(inst, "198.51.100.0/24"); ok {
+		t.Errorf("expected no translation")
+	}
+
+	// Translating unconditionally sets the Nt-bit.
+	area.TranslatorRole = config.NSSATranslatorAlways
+	allowOriginations(inst)
+	inst.runSPF()
+
+	if area.TranslatorState != NSSATranslatorEnabled {
+		t.Errorf("expected translator to be enabled, got %s", area.TranslatorState)
+	}
+
+	if lsa := ourRouterLSA(inst, 1); lsa == nil || lsa.Flags()&routerFlagNt == 0 {
+		t.Errorf("expected the Nt-bit in our NSSA router-LSA")
+	}
+
+	// When a border router translates unconditionally, candidates don't,
+	// even with a higher router ID. 0.4.4.4 replaces 4.4.4.4.
+	area.TranslatorRole = config.NSSATranslatorCandidate
+
+	inst.flushLSA(areaScope(1), testRouterLSAWithLinks("4.4.4.4", routerFlagB))
+	for _, lsa := range []LSA{
+		testRouterLSAWithLinks("0.4.4.4", routerFlagB|routerFlagNt,
+			testLink(linkTransit, "10.1.0.1", "10.1.0.4", 10),
+		),
+		testNetworkLSA("10.1.0.1", "1.1.1.1", 24, "0.4.4.4", "1.1.1.1", "3.3.3.3"),
+	} {
+		inst.installLSA(1, lsa)
+	}
+
+	inst.runSPF()
+
+	if area.TranslatorState != NSSATranslatorDisabled {
+		t.Errorf("expected translator to be disabled, got %s", area.TranslatorState)
+	}
+}
+
+func TestNSSATranslatorStability(t *testing.T) {
+	inst := newTestNSSABR(t)
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	area := inst.Areas[1]
+
+	inst.runSPF()
+
+	if area.TranslatorState != NSSATranslatorElected {
+		t.Fatalf("expected translator to be elected, got %s", area.TranslatorState)
+	}
+
+	// After losing the election, we keep translating for a while.
+	inst.installLSA(1, testRouterLSAWithLinks("4.4.4.4", routerFlagB|routerFlagNt,
+		testLink(linkTransit, "10.1.0.1", "10.1.0.4", 10),
+	))
+	inst.installLSA(1, testNetworkLSA("10.1.0.1", "1.1.1.1", 24, "1.1.1.1", "3.3.3.3", "4.4.4.4"))
+	inst.runSPF()
+
+	if area.TranslatorState != NSSATranslatorElected || area.translatorStabilityTimer == nil {
+		t.Errorf("expected to keep translating during the stability interval, got %s", area.TranslatorState)
+	}
+
+	if _, ok := translated(inst, "198.51.100.0/24"); !ok {
+		t.Errorf("expected translation to continue")
+	}
+
+	// Winning again cancels the timer.
+	inst.flushLSA(areaScope(1), testRouterLSAWithLinks("4.4.4.4", routerFlagB|routerFlagNt))
+	inst.runSPF()
+
+	if area.TranslatorState != NSSATranslatorElected || area.translatorStabilityTimer != nil {
+		t.Errorf("expected to stay elected with no stability timer, got %s", area.TranslatorState)
+	}
+}
+
+func TestNSSASummaries(t *testing.T) {
+	inst := newTestNSSABR(t)
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	area := inst.Areas[1]
+	area.DefaultOriginate = true
+	area.StubDefaultCost = 3
+
+	inst.runSPF()
+
+	// 3.3.3.3's routes leave the NSSA only as translated
+	// AS-external-LSAs, so there's no ASBR-summary-LSA for it.
+	if got := summaryMetrics(inst, 0); got["3.3.3.3"] != 0 {
+		t.Errorf("expected no ASBR-summary-LSA for an NSSA's ASBR, got %v", got)
+	}
+
+	if got := summaryMetrics(inst, 1); got["0.0.0.0/0"] != 0 || got["10.0.0.0/24"] != 10 {
+		t.Errorf("expected summaries and no default summary in the NSSA, got %v", got)
+	}
+
+	lsa, ok := inst.lookupLSA(1, lsdbKey{Type: lsTypeNSSA, ID: netip.IPv4Unspecified(), AdvertisingRouter: inst.RouterID})
+	if !ok {
+		t.Fatalf("expected a default Type-7 LSA")
+	}
+
+	if n := lsa.LSA.(*nssaLSA); n.Propagate() || n.Metric() != 3 {
+		t.Errorf("expected default with metric 3 and no P-bit, got %+v (options %#x)", n.Routes()[0], n.Options())
+	}
+
+	// A totally stubby NSSA gets a default summary instead of the others.
+	area.NoSummary = true
+	allowOriginations(inst)
+	inst.runSPF()
+
+	if got := summaryMetrics(inst, 1); len(got) != 1 || got["0.0.0.0/0"] != 3 {
+		t.Errorf("expected only a default summary, got %v", got)
+	}
+}
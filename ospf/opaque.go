@@ -0,0 +1,486 @@
+package ospf
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"github.com/davidbalbert/chatter/sync"
+)
+
+// OpaqueScope is how far an opaque LSA is flooded, which determines its LS
+// type. See RFC 5250, section 3.
+type OpaqueScope int
+
+const (
+	OpaqueLinkLocal OpaqueScope = iota // type 9, flooded over a single interface
+	OpaqueArea                         // type 10, flooded throughout an area
+	OpaqueAS                           // type 11, flooded like AS-external-LSAs
+)
+
+func (s OpaqueScope) String() string {
+	switch s {
+	case OpaqueLinkLocal:
+		return "link-local"
+	case OpaqueArea:
+		return "area"
+	case OpaqueAS:
+		return "AS"
+	default:
+		return fmt.Sprintf("OpaqueScope(%d)", int(s))
+	}
+}
+
+func (s OpaqueScope) lsType() lsType {
+	switch s {
+	case OpaqueLinkLocal:
+		return lsTypeOpaqueLink
+	case OpaqueArea:
+		return lsTypeOpaqueArea
+	default:
+		return lsTypeOpaqueAS
+	}
+}
+
+func opaqueScopeOf(t lsType) OpaqueScope {
+	switch t {
+	case lsTypeOpaqueLink:
+		return OpaqueLinkLocal
+	case lsTypeOpaqueArea:
+		return OpaqueArea
+	default:
+		return OpaqueAS
+	}
+}
+
+// An OpaqueLSA is an opaque LSA as seen by the application that registered
+// its opaque type. Link-local LSAs are identified by their interface, and
+// area-scoped LSAs by their area.
+type OpaqueLSA struct {
+	Scope             OpaqueScope
+	AreaID            common.AreaID
+	Interface         string
+	InterfacePrefix   netip.Prefix
+	OpaqueType        uint8
+	OpaqueID          uint32 // 24 bits
+	AdvertisingRouter common.RouterID
+	Data              []byte
+}
+
+// An OpaqueEvent is a change to an opaque LSA originated by another
+// router.
+type OpaqueEvent struct {
+	LSA       OpaqueLSA
+	Withdrawn bool // the LSA was flushed, or it aged out
+}
+
+// opaqueKey identifies an opaque LSA that an application originates.
+type opaqueKey struct {
+	scope    OpaqueScope
+	areaID   common.AreaID
+	iface    interfaceID
+	opaqueID uint32
+}
+
+func opaqueKeyFor(lsa OpaqueLSA) opaqueKey {
+	k := opaqueKey{scope: lsa.Scope, opaqueID: lsa.OpaqueID}
+
+	switch lsa.Scope {
+	case OpaqueLinkLocal:
+		k.iface = interfaceID{name: lsa.Interface, prefix: lsa.InterfacePrefix}
+	case OpaqueArea:
+		k.areaID = lsa.AreaID
+	}
+
+	return k
+}
+
+// An OpaqueRegistration is an application's claim on an opaque type. It's
+// used to originate and withdraw opaque LSAs of that type, and to hear
+// about the ones other routers originate.
+type OpaqueRegistration struct {
+	inst       *Instance
+	opaqueType uint8
+	lsas       map[opaqueKey]OpaqueLSA // the LSAs we originate
+	events     *sync.QueuedNotifier[OpaqueEvent]
+	closed     bool
+}
+
+// RegisterOpaqueType claims opaqueType for an application. Each opaque
+// type can only be registered once, and grace-LSAs' type is reserved.
+func (inst *Instance) RegisterOpaqueType(opaqueType uint8) (*OpaqueRegistration, error) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if opaqueType == opaqueTypeGrace {
+		return nil, fmt.Errorf("opaque type %d is used for grace-LSAs", opaqueType)
+	}
+
+	if _, ok := inst.opaqueTypes[opaqueType]; ok {
+		return nil, fmt.Errorf("opaque type %d is already registered", opaqueType)
+	}
+
+	r := &OpaqueRegistration{
+		inst:       inst,
+		opaqueType: opaqueType,
+		lsas:       make(map[opaqueKey]OpaqueLSA),
+		events:     sync.NewQueuedNotifier[OpaqueEvent](),
+	}
+
+	inst.opaqueTypes[opaqueType] = r
+
+	return r, nil
+}
+
+// Originate originates lsa, replacing any LSA we originate with the same
+// scope and opaque ID. Its OpaqueType and AdvertisingRouter are filled in.
+// Link-local LSAs are originated once their interface is running. Like
+// every LSA, new instances are originated at most once every
+// MinLSInterval. The LSA has to fit in a single Link State Update on the
+// interfaces it's flooded over.
+func (r *OpaqueRegistration) Originate(lsa OpaqueLSA) error {
+	inst := r.inst
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if r.closed {
+		return fmt.Errorf("opaque type %d is no longer registered", r.opaqueType)
+	}
+
+	if lsa.OpaqueID > maxOpaqueID {
+		return fmt.Errorf("opaque ID %d is more than 24 bits", lsa.OpaqueID)
+	}
+
+	switch lsa.Scope {
+	case OpaqueLinkLocal:
+		if lsa.Interface == "" {
+			return fmt.Errorf("link-local opaque LSAs need an interface")
+		}
+	case OpaqueArea:
+		if _, ok := inst.Areas[lsa.AreaID]; !ok {
+			return fmt.Errorf("unknown area %s", lsa.AreaID)
+		}
+	case OpaqueAS:
+	default:
+		return fmt.Errorf("unknown opaque scope %s", lsa.Scope)
+	}
+
+	// The LS length field is 16 bits.
+	if n := lsaHeaderLen + len(lsa.Data); n > 0xffff {
+		return fmt.Errorf("opaque LSA is %d bytes, more than the maximum of %d", n, 0xffff)
+	}
+
+	if n, iface := inst.maxOpaqueLen(lsa); lsaHeaderLen+len(lsa.Data) > n {
+		return fmt.Errorf("opaque LSA is %d bytes, too big for a Link State Update on %s %s, which fits %d", lsaHeaderLen+len(lsa.Data), iface.name, iface.Prefix, n)
+	}
+
+	lsa.OpaqueType = r.opaqueType
+	lsa.AdvertisingRouter = inst.RouterID
+	lsa.Data = append([]byte(nil), lsa.Data...)
+
+	k := opaqueKeyFor(lsa)
+	r.lsas[k] = lsa
+	r.schedule(k)
+
+	return nil
+}
+
+// maxOpaqueLen returns the length of the longest LSA, including its header,
+// that can be flooded in lsa's scope, and the interface that limits it. LSAs
+// can't be split across Link State Updates, so the LSA has to fit in one
+// sent out of every interface it's flooded over. Interfaces that aren't
+// running yet aren't considered. If none limit the length, iface is nil and
+// the length is the maximum an LSA can have.
+func (inst *Instance) maxOpaqueLen(lsa OpaqueLSA) (n int, iface *Interface) {
+	n = 0xffff
+
+	for id, i := range inst.Interfaces {
+		switch lsa.Scope {
+		case OpaqueLinkLocal:
+			if id != (interfaceID{name: lsa.Interface, prefix: lsa.InterfacePrefix}) {
+				continue
+			}
+		case OpaqueArea:
+			if i.AreaID != lsa.AreaID {
+				continue
+			}
+		case OpaqueAS:
+			// AS-scoped LSAs aren't flooded into stub areas or NSSAs.
+			if a, ok := inst.Areas[i.AreaID]; ok && a.areaType() != AreaNormal {
+				continue
+			}
+		}
+
+		if i.maxLSUpdLen() < n {
+			n, iface = i.maxLSUpdLen(), i
+		}
+	}
+
+	return n, iface
+}
+
+// Withdraw flushes the LSA we originate with lsa's scope and opaque ID.
+func (r *OpaqueRegistration) Withdraw(lsa OpaqueLSA) error {
+	r.inst.mu.Lock()
+	defer r.inst.mu.Unlock()
+
+	if r.closed {
+		return fmt.Errorf("opaque type %d is no longer registered", r.opaqueType)
+	}
+
+	k := opaqueKeyFor(lsa)
+	if _, ok := r.lsas[k]; !ok {
+		return nil
+	}
+
+	delete(r.lsas, k)
+	r.schedule(k)
+
+	return nil
+}
+
+// Close withdraws every LSA originated with the registration and gives up
+// the opaque type. Its subscriptions are closed, so calls to their Next,
+// including ones that are blocked, return an error.
+func (r *OpaqueRegistration) Close() {
+	inst := r.inst
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	for k := range r.lsas {
+		delete(r.lsas, k)
+		r.schedule(k)
+	}
+
+	r.closed = true
+	delete(inst.opaqueTypes, r.opaqueType)
+	r.events.UnregisterAll()
+}
+
+// schedule originates or flushes the LSA identified by k to match r.lsas.
+func (r *OpaqueRegistration) schedule(k opaqueKey) {
+	inst := r.inst
+
+	s := areaScope(k.areaID)
+	if k.scope == OpaqueLinkLocal {
+		iface, ok := inst.Interfaces[k.iface]
+		if !ok {
+			return
+		}
+
+		s = iface.scopeFor(lsTypeOpaqueLink)
+	}
+
+	key := lsdbKey{
+		Type:              k.scope.lsType(),
+		ID:                opaqueLSAID(r.opaqueType, k.opaqueID),
+		AdvertisingRouter: inst.RouterID,
+	}
+
+	inst.scheduleScopedLSA(s, key, func() LSA {
+		return r.build(s, k)
+	})
+}
+
+// build returns the LSA identified by k, or nil if we don't originate it.
+func (r *OpaqueRegistration) build(s lsaScope, k opaqueKey) LSA {
+	lsa, ok := r.lsas[k]
+	if !ok {
+		return nil
+	}
+
+	h := lsaHeader{
+		options:           optE,
+		id:                opaqueLSAID(r.opaqueType, k.opaqueID),
+		advertisingRouter: r.inst.RouterID,
+	}
+
+	if k.scope != OpaqueAS {
+		h.options = r.inst.Areas[s.areaID].options()
+	}
+
+	return newOpaqueLSA(h, k.scope.lsType(), lsa.Data)
+}
+
+// scheduleLinkOpaqueLSAs originates the link-local opaque LSAs that
+// applications have asked for on a new interface.
+func (inst *Instance) scheduleLinkOpaqueLSAs(id interfaceID) {
+	for _, r := range inst.opaqueTypes {
+		for k := range r.lsas {
+			if k.scope == OpaqueLinkLocal && k.iface == id {
+				r.schedule(k)
+			}
+		}
+	}
+}
+
+// removeLinkOriginations forgets the LSAs we originate on an interface
+// that's going away, along with its database.
+func (inst *Instance) removeLinkOriginations(iface *Interface) {
+	for key, o := range inst.originations {
+		if key.iface != iface {
+			continue
+		}
+
+		if o.timer != nil {
+			o.timer.Stop()
+		}
+
+		delete(inst.originations, key)
+	}
+
+	for key := range inst.pendingOriginations {
+		if key.iface == iface {
+			delete(inst.pendingOriginations, key)
+		}
+	}
+}
+
+// Subscribe returns the opaque LSAs of the registered type that other
+// routers originate, along with a Subscription that receives every change
+// made after the snapshot was taken. Callers must Close the subscription
+// when they're done with it.
+func (r *OpaqueRegistration) Subscribe() ([]OpaqueLSA, *OpaqueSubscription) {
+	inst := r.inst
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	var lsas []OpaqueLSA
+
+	add := func(s lsaScope, db lsdb) {
+		for _, lsa := range db {
+			if !isOpaque(lsa.Type()) || opaqueTypeOf(lsa.ID()) != r.opaqueType {
+				continue
+			}
+
+			if lsa.AdvertisingRouter() == inst.RouterID || lsa.Age() == maxAge {
+				continue
+			}
+
+			lsas = append(lsas, opaqueLSAFor(s, lsa))
+		}
+	}
+
+	for id, area := range inst.Areas {
+		add(areaScope(id), area.lsdb)
+	}
+
+	add(areaScope(0), inst.lsdb)
+
+	for _, iface := range inst.Interfaces {
+		add(iface.scopeFor(lsTypeOpaqueLink), iface.lsdb)
+	}
+
+	sort.Slice(lsas, func(a, b int) bool {
+		return opaqueLSALess(lsas[a], lsas[b])
+	})
+
+	sub := &OpaqueSubscription{r: r, t: r.events.Register()}
+
+	// Subscriptions to a closed registration will never receive events.
+	if r.closed {
+		sub.Close()
+	}
+
+	return lsas, sub
+}
+
+// opaqueLSALess orders opaque LSAs by scope, area, interface, advertising
+// router and opaque ID.
+func opaqueLSALess(a, b OpaqueLSA) bool {
+	switch {
+	case a.Scope != b.Scope:
+		return a.Scope < b.Scope
+	case a.AreaID != b.AreaID:
+		return a.AreaID < b.AreaID
+	case a.Interface != b.Interface:
+		return a.Interface < b.Interface
+	case a.InterfacePrefix != b.InterfacePrefix:
+		return comparePrefixes(a.InterfacePrefix, b.InterfacePrefix) < 0
+	case a.AdvertisingRouter != b.AdvertisingRouter:
+		return a.AdvertisingRouter < b.AdvertisingRouter
+	default:
+		return a.OpaqueID < b.OpaqueID
+	}
+}
+
+type OpaqueSubscription struct {
+	r *OpaqueRegistration
+	t sync.Token
+}
+
+// Next blocks until the next event is available, ctx is canceled, or the
+// subscription or its registration is closed.
+func (s *OpaqueSubscription) Next(ctx context.Context) (OpaqueEvent, error) {
+	e, ok := s.r.events.AwaitChange(ctx, s.t)
+	if ctx.Err() != nil {
+		return OpaqueEvent{}, ctx.Err()
+	} else if !ok {
+		return OpaqueEvent{}, fmt.Errorf("subscription closed")
+	}
+
+	return e, nil
+}
+
+// Close stops the subscription. Calls to Next, including ones that are
+// blocked, return an error.
+func (s *OpaqueSubscription) Close() {
+	s.r.events.Unregister(s.t)
+}
+
+// notifyOpaque tells the application that registered lsa's opaque type
+// that it changed. Only LSAs originated by other routers are passed on.
+func (inst *Instance) notifyOpaque(s lsaScope, lsa LSA) {
+	if lsa.AdvertisingRouter() == inst.RouterID {
+		return
+	}
+
+	r, ok := inst.opaqueTypes[opaqueTypeOf(lsa.ID())]
+	if !ok {
+		return
+	}
+
+	r.events.NotifyChange(OpaqueEvent{
+		LSA:       opaqueLSAFor(s, lsa),
+		Withdrawn: lsa.Age() == maxAge,
+	})
+}
+
+func opaqueLSAFor(s lsaScope, lsa LSA) OpaqueLSA {
+	id := lsa.ID().As4()
+
+	o := OpaqueLSA{
+		Scope:             opaqueScopeOf(lsa.Type()),
+		OpaqueType:        id[0],
+		OpaqueID:          uint24(id[1:4]),
+		AdvertisingRouter: lsa.AdvertisingRouter(),
+		Data:              append([]byte(nil), lsa.Bytes()[lsaHeaderLen:]...),
+	}
+
+	switch o.Scope {
+	case OpaqueLinkLocal:
+		o.AreaID = s.areaID
+		o.Interface = s.iface.name
+		o.InterfacePrefix = s.iface.Prefix
+	case OpaqueArea:
+		o.AreaID = s.areaID
+	}
+
+	return o
+}
+
+// exchangesLSType reports whether LSAs of type t are sent to the neighbor.
+// Opaque LSAs are only sent to neighbors that set the O-bit in their
+// Database Description packets. See RFC 5250, section 3.1.
+func (n *Neighbor) exchangesLSType(t lsType) bool {
+	return !isOpaque(t) || n.Options&optO != 0
+}
@@ -0,0 +1,390 @@
+package ospf
+
+import (
+	"context"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+)
+
+const testOpaqueType = 200
+
+func registerTestOpaqueType(t *testing.T, inst *Instance) *OpaqueRegistration {
+	t.Helper()
+
+	r, err := inst.RegisterOpaqueType(testOpaqueType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func opaqueKeyOf(t lsType, advertisingRouter string, opaqueID uint32) lsdbKey {
+	return lsdbKey{
+		Type:              t,
+		ID:                opaqueLSAID(testOpaqueType, opaqueID),
+		AdvertisingRouter: mustParseRouterID(advertisingRouter),
+	}
+}
+
+func testOpaqueLSA(t lsType, advertisingRouter string, opaqueID uint32, data ...byte) *opaqueLSA {
+	h := testLSAHeader(advertisingRouter, initialSequenceNumber)
+	h.id = opaqueLSAID(testOpaqueType, opaqueID)
+
+	return newOpaqueLSA(h, t, data)
+}
+
+func onRetransmissionList(n *Neighbor, key lsdbKey) bool {
+	return n.retransmissionIndex(key) != -1
+}
+
+// nextOpaqueEvent waits for the next event from sub.
+func nextOpaqueEvent(t *testing.T, sub *OpaqueSubscription) OpaqueEvent {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	e, err := sub.Next(ctx)
+	if err != nil {
+		t.Fatalf("expected an opaque event: %v", err)
+	}
+
+	return e
+}
+
+func TestRegisterOpaqueType(t *testing.T) {
+	inst := newTestLSDBInstance(t)
+	r := registerTestOpaqueType(t, inst)
+
+	if _, err := inst.RegisterOpaqueType(testOpaqueType); err == nil {
+		t.Errorf("expected opaque type %d to already be registered", testOpaqueType)
+	}
+
+	for _, lsa := range []OpaqueLSA{
+		{Scope: OpaqueLinkLocal},
+		{Scope: OpaqueArea, AreaID: 7},
+		{Scope: OpaqueAS, OpaqueID: 1 << 24},
+	} {
+		if err := r.Originate(lsa); err == nil {
+			t.Errorf("expected an error originating %+v", lsa)
+		}
+	}
+
+	r.Close()
+
+	if err := r.Originate(OpaqueLSA{Scope: OpaqueAS}); err == nil {
+		t.Errorf("expected an error originating after Close")
+	}
+
+	if _, err := inst.RegisterOpaqueType(testOpaqueType); err != nil {
+		t.Errorf("expected opaque type %d to be available again: %v", testOpaqueType, err)
+	}
+}
+
+func TestOriginateOpaqueTooLong(t *testing.T) {
+	inst := newTestLSDBInstance(t)
+	r := registerTestOpaqueType(t, inst)
+
+	// The length doesn't fit in the LS length field.
+	if err := r.Originate(OpaqueLSA{Scope: OpaqueAS, OpaqueID: 1, Data: make([]byte, 70000)}); err == nil {
+		t.Errorf("expected an error originating an LSA longer than 65535 bytes")
+	}
+
+	netif := testNetif("eth0", 1, "10.0.0.1/24")
+	iface := startTestInterface(t, inst, netif)
+
+	inst.mu.Lock()
+	maxLen := iface.maxLSUpdLen()
+	inst.mu.Unlock()
+
+	// The LSA has to fit in a single Link State Update on every interface
+	// it's flooded over.
+	for _, lsa := range []OpaqueLSA{
+		{Scope: OpaqueLinkLocal, Interface: "eth0", InterfacePrefix: netif.Prefixes[0]},
+		{Scope: OpaqueArea, AreaID: 0},
+		{Scope: OpaqueAS},
+	} {
+		lsa.OpaqueID = 2
+		lsa.Data = make([]byte, maxLen-lsaHeaderLen+1)
+		if err := r.Originate(lsa); err == nil {
+			t.Errorf("expected an error originating a %s LSA that doesn't fit in a Link State Update", lsa.Scope)
+		}
+
+		lsa.Data = make([]byte, maxLen-lsaHeaderLen)
+		if err := r.Originate(lsa); err != nil {
+			t.Errorf("expected a %s LSA that fits in a Link State Update to be originated: %v", lsa.Scope, err)
+		}
+	}
+}
+
+func TestOpaqueFloodingScope(t *testing.T) {
+	inst := newTestABR(t)
+	r := registerTestOpaqueType(t, inst)
+
+	inst.mu.Lock()
+	eth0 := inst.Interfaces[interfaceID{name: "eth0", prefix: netip.MustParsePrefix("10.0.0.1/24")}]
+	eth1 := inst.Interfaces[interfaceID{name: "eth1", prefix: netip.MustParsePrefix("10.1.0.1/24")}]
+	nbr := eth1.Neighbors[mustParseRouterID("3.3.3.3")]
+	nbr.Options = optO
+	inst.mu.Unlock()
+
+	lsas := []OpaqueLSA{
+		{Scope: OpaqueLinkLocal, Interface: "eth1", InterfacePrefix: eth1.Prefix, OpaqueID: 1, Data: []byte{1}},
+		{Scope: OpaqueArea, AreaID: 0, OpaqueID: 2, Data: []byte{2}},
+		{Scope: OpaqueAS, OpaqueID: 3, Data: []byte{3}},
+	}
+
+	for _, lsa := range lsas {
+		if err := r.Originate(lsa); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	link := opaqueKeyOf(lsTypeOpaqueLink, "1.1.1.1", 1)
+	area := opaqueKeyOf(lsTypeOpaqueArea, "1.1.1.1", 2)
+	as := opaqueKeyOf(lsTypeOpaqueAS, "1.1.1.1", 3)
+
+	if _, ok := eth1.lsdb[link]; !ok {
+		t.Errorf("expected the link-local LSA in eth1's database")
+	}
+
+	if _, ok := eth0.lsdb[link]; ok {
+		t.Errorf("expected no link-local LSA in eth0's database")
+	}
+
+	if _, ok := inst.Areas[0].lsdb[area]; !ok {
+		t.Errorf("expected the area-local LSA in area 0")
+	}
+
+	if _, ok := inst.lsdb[as]; !ok {
+		t.Errorf("expected the AS LSA in the AS database")
+	}
+
+	// 3.3.3.3 is in area 1, on eth1.
+	if !onRetransmissionList(nbr, link) || onRetransmissionList(nbr, area) || !onRetransmissionList(nbr, as) {
+		t.Errorf("expected the link-local and AS LSAs to be flooded to 3.3.3.3, got %v", nbr.RetransmissionList)
+	}
+
+	// Neighbors that don't support opaque LSAs don't get them.
+	nbr.Options = 0
+	nbr.RetransmissionList = nil
+	nbr.DatabaseSummaryList = nil
+	nbr.buildDatabaseSummaryList()
+
+	for _, h := range nbr.DatabaseSummaryList {
+		if isOpaque(h.Type()) {
+			t.Errorf("expected no opaque LSAs in the database summary list, got %v", h.Key())
+		}
+	}
+
+	allowOriginations(inst)
+	inst.originateLSA(areaScope(1), testOpaqueLSA(lsTypeOpaqueAS, "1.1.1.1", 4))
+
+	if len(nbr.RetransmissionList) != 0 {
+		t.Errorf("expected nothing to be flooded to 3.3.3.3, got %v", nbr.RetransmissionList)
+	}
+}
+
+func TestOpaqueWithdraw(t *testing.T) {
+	inst := newTestABR(t)
+	r := registerTestOpaqueType(t, inst)
+
+	lsa := OpaqueLSA{Scope: OpaqueArea, AreaID: 1, OpaqueID: 5, Data: []byte{1, 2, 3, 4}}
+	if err := r.Originate(lsa); err != nil {
+		t.Fatal(err)
+	}
+
+	key := opaqueKeyOf(lsTypeOpaqueArea, "1.1.1.1", 5)
+
+	inst.mu.Lock()
+	installed, ok := inst.lookupLSA(1, key)
+	if !ok || !reflect.DeepEqual(installed.LSA.(*opaqueLSA).Data(), lsa.Data) {
+		t.Fatalf("expected %v with data %v", key, lsa.Data)
+	}
+
+	allowOriginations(inst)
+	inst.mu.Unlock()
+
+	if err := r.Withdraw(lsa); err != nil {
+		t.Fatal(err)
+	}
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if installed, ok := inst.lookupLSA(1, key); !ok || installed.Age() != maxAge {
+		t.Errorf("expected %v to be flushed", key)
+	}
+
+	// If a neighbor has an instance from before we withdrew it, it's
+	// flushed again.
+	nbr := testOpaqueLSA(lsTypeOpaqueArea, "1.1.1.1", 5, 9)
+	nbr = withSequenceNumber(nbr, initialSequenceNumber+5).(*opaqueLSA)
+	inst.installLSA(1, nbr)
+	inst.handleSelfOriginated(areaScope(1), nbr)
+
+	if installed, ok := inst.lookupLSA(1, key); !ok || installed.Age() != maxAge || installed.SequenceNumber() != initialSequenceNumber+5 {
+		t.Errorf("expected the neighbor's instance to be flushed")
+	}
+}
+
+func TestOpaqueSubscribe(t *testing.T) {
+	iface, nbrs, _ := newTestFloodingInterface(t)
+	inst := iface.instance
+
+	r := registerTestOpaqueType(t, inst)
+
+	lsa := testOpaqueLSA(lsTypeOpaqueLink, "2.2.2.2", 1, 1, 2)
+
+	inst.mu.Lock()
+	iface.handleLSUpd(testLSUpd(nbrs[0], lsa), nbrs[0].Addr)
+	inst.mu.Unlock()
+
+	lsas, sub := r.Subscribe()
+	defer sub.Close()
+
+	expected := OpaqueLSA{
+		Scope:             OpaqueLinkLocal,
+		AreaID:            0,
+		Interface:         "eth0",
+		InterfacePrefix:   netip.MustParsePrefix("10.0.0.1/24"),
+		OpaqueType:        testOpaqueType,
+		OpaqueID:          1,
+		AdvertisingRouter: mustParseRouterID("2.2.2.2"),
+		Data:              []byte{1, 2},
+	}
+
+	if !reflect.DeepEqual(lsas, []OpaqueLSA{expected}) {
+		t.Errorf("expected %+v, got %+v", []OpaqueLSA{expected}, lsas)
+	}
+
+	// Other opaque types, and LSAs whose contents haven't changed, aren't
+	// passed on.
+	h := testLSAHeader("3.3.3.3", initialSequenceNumber)
+	h.id = opaqueLSAID(testOpaqueType+1, 2)
+
+	inst.mu.Lock()
+	inst.installLSA(0, newOpaqueLSA(h, lsTypeOpaqueArea, nil))
+	inst.installLSA(0, testOpaqueLSA(lsTypeOpaqueArea, "3.3.3.3", 2, 3))
+	inst.installLSA(0, testOpaqueLSA(lsTypeOpaqueArea, "3.3.3.3", 2, 3))
+	inst.mu.Unlock()
+
+	e := nextOpaqueEvent(t, sub)
+	if e.Withdrawn || e.LSA.Scope != OpaqueArea || e.LSA.AdvertisingRouter != mustParseRouterID("3.3.3.3") || !reflect.DeepEqual(e.LSA.Data, []byte{3}) {
+		t.Errorf("expected 3.3.3.3's area-local LSA, got %+v", e)
+	}
+
+	flushed, _ := ParseLSA(withSequenceNumber(lsa, initialSequenceNumber+1).Bytes())
+	flushed.SetAge(maxAge)
+
+	inst.mu.Lock()
+	iface.lsdbFor(lsTypeOpaqueLink)[lsa.Key()].installedAt = time.Now().Add(-minLSArrival * time.Second)
+	iface.handleLSUpd(testLSUpd(nbrs[0], flushed), nbrs[0].Addr)
+	inst.mu.Unlock()
+
+	e = nextOpaqueEvent(t, sub)
+	if !e.Withdrawn || !reflect.DeepEqual(e.LSA, expected) {
+		t.Errorf("expected %+v to be withdrawn, got %+v", expected, e)
+	}
+}
+
+// expectNextUnblocked calls stop while a call to sub.Next is blocked, and
+// checks that Next returns.
+func expectNextUnblocked(t *testing.T, sub *OpaqueSubscription, stop func()) {
+	t.Helper()
+
+	done := make(chan error)
+	go func() {
+		_, err := sub.Next(context.Background())
+		done <- err
+	}()
+
+	// Give Next time to block.
+	time.Sleep(50 * time.Millisecond)
+	stop()
+
+	select {
+	case err := <-done:
+		if err == nil || err.Error() != "subscription closed" {
+			t.Fatalf("expected subscription closed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Next didn't return")
+	}
+}
+
+func TestOpaqueSubscriptionClose(t *testing.T) {
+	inst := newTestLSDBInstance(t)
+	r := registerTestOpaqueType(t, inst)
+
+	_, sub := r.Subscribe()
+	expectNextUnblocked(t, sub, sub.Close)
+
+	// Closing the registration closes its subscriptions.
+	_, sub = r.Subscribe()
+	defer sub.Close()
+
+	expectNextUnblocked(t, sub, r.Close)
+
+	_, sub = r.Subscribe()
+	defer sub.Close()
+
+	if _, err := sub.Next(context.Background()); err == nil {
+		t.Errorf("expected subscribing after Close to return a closed subscription")
+	}
+}
+
+func TestOpaqueBetweenInstances(t *testing.T) {
+	network := newMemNetwork()
+
+	r1 := newTestInstance(t, "1.1.1.1", network, map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig()})
+	r2 := newTestInstance(t, "2.2.2.2", network, map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig()})
+
+	reg1 := registerTestOpaqueType(t, r1)
+	reg2 := registerTestOpaqueType(t, r2)
+
+	_, sub := reg2.Subscribe()
+	defer sub.Close()
+
+	// Link-local LSAs can be originated before their interface exists.
+	err := reg1.Originate(OpaqueLSA{
+		Scope:           OpaqueLinkLocal,
+		Interface:       "eth0",
+		InterfacePrefix: netip.MustParsePrefix("10.0.0.1/24"),
+		OpaqueID:        1,
+		Data:            []byte("link"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	startTestInterface(t, r1, testNetif("eth0", 1, "10.0.0.1/24"))
+	startTestInterface(t, r2, testNetif("eth0", 2, "10.0.0.2/24"))
+
+	if err := reg1.Originate(OpaqueLSA{Scope: OpaqueArea, OpaqueID: 2, Data: []byte("area")}); err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(map[OpaqueScope]OpaqueLSA)
+	for len(received) < 2 {
+		e := nextOpaqueEvent(t, sub)
+		received[e.LSA.Scope] = e.LSA
+	}
+
+	if l := received[OpaqueLinkLocal]; l.Interface != "eth0" || l.InterfacePrefix != netip.MustParsePrefix("10.0.0.2/24") || string(l.Data) != "link" {
+		t.Errorf("expected a link-local LSA on eth0 10.0.0.2/24, got %+v", l)
+	}
+
+	if l := received[OpaqueArea]; l.AdvertisingRouter != r1.RouterID || l.OpaqueID != 2 || string(l.Data) != "area" {
+		t.Errorf("expected an area-local LSA from %s, got %+v", r1.RouterID, l)
+	}
+}
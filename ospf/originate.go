@@ -0,0 +1,433 @@
+package ospf
+
+import (
+	"bytes"
+	"net/netip"
+	"sort"
+	"time"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"github.com/davidbalbert/chatter/config"
+	"golang.org/x/exp/slices"
+)
+
+// originationKey identifies an LSA we originate. Our router-LSAs have the
+// same lsdbKey in every area, so the scope is part of the key. AS-scoped
+// LSAs always use area 0.
+type originationKey struct {
+	lsaScope
+	lsdbKey
+}
+
+func newOriginationKey(areaID common.AreaID, key lsdbKey) originationKey {
+	return newScopedOriginationKey(areaScope(areaID), key)
+}
+
+func newScopedOriginationKey(s lsaScope, key lsdbKey) originationKey {
+	if isASScoped(key.Type) {
+		s = areaScope(0)
+	}
+
+	return originationKey{lsaScope: s, lsdbKey: key}
+}
+
+// An origination is an LSA that we originate. build returns the current
+// contents of the LSA, or nil if it should be flushed.
+type origination struct {
+	key   originationKey
+	build func() LSA
+
+	throttle             // last is when we last originated a new instance
+	timer    *time.Timer // non-nil while origination is deferred by the throttle
+}
+
+// scheduleLSA originates a new instance of the LSA identified by key,
+// unless its contents are unchanged. New instances are throttled, by
+// default to at most one every MinLSInterval, so if the last one was too
+// recent, origination is deferred. See RFC 2328, section 12.4.
+func (inst *Instance) scheduleLSA(areaID common.AreaID, key lsdbKey, build func() LSA) {
+	inst.scheduleScopedLSA(areaScope(areaID), key, build)
+}
+
+// scheduleScopedLSA is scheduleLSA for any scope.
+func (inst *Instance) scheduleScopedLSA(s lsaScope, key lsdbKey, build func() LSA) {
+	okey := newScopedOriginationKey(s, key)
+
+	o, ok := inst.originations[okey]
+	if !ok {
+		o = &origination{key: okey}
+		inst.originations[okey] = o
+	}
+
+	o.build = build
+
+	if o.timer != nil {
+		return
+	}
+
+	wait := o.delay(inst.config.LSAThrottle)
+	if wait <= 0 {
+		inst.runOrigination(o, false)
+		return
+	}
+
+	o.timer = time.AfterFunc(wait, func() {
+		inst.mu.Lock()
+		defer inst.mu.Unlock()
+
+		if inst.originations[okey] != o || o.timer == nil {
+			return
+		}
+
+		o.timer = nil
+		inst.runOrigination(o, false)
+	})
+}
+
+// runOrigination originates or flushes o's LSA. Unless force is true,
+// nothing happens if the database already has an LSA with the same
+// contents.
+func (inst *Instance) runOrigination(o *origination, force bool) {
+	// While we're restarting gracefully, the rest of the routing domain
+	// keeps using the LSAs we originated before restarting. See RFC 3623,
+	// section 2.2.
+	if inst.isRestarting() && !isOpaque(o.key.Type) {
+		return
+	}
+
+	lsa := o.build()
+	existing, ok := inst.lookupLSAIn(o.key.lsaScope, o.key.lsdbKey)
+
+	if lsa == nil {
+		if ok && existing.Age() < maxAge {
+			o.happened()
+			inst.flushLSA(o.key.lsaScope, existing)
+		}
+
+		return
+	}
+
+	if !force && ok && existing.Age() < maxAge && sameContents(existing, lsa) {
+		return
+	}
+
+	o.happened()
+	inst.originateLSA(o.key.lsaScope, lsa)
+}
+
+// sameContents reports whether two instances of an LSA have the same
+// options and body.
+func sameContents(a, b LSA) bool {
+	return a.Options() == b.Options() && bytes.Equal(a.Bytes()[lsaHeaderLen:], b.Bytes()[lsaHeaderLen:])
+}
+
+// stopOriginations cancels deferred originations.
+func (inst *Instance) stopOriginations() {
+	for _, o := range inst.originations {
+		if o.timer != nil {
+			o.timer.Stop()
+			o.timer = nil
+		}
+	}
+}
+
+// isSelfOriginated reports whether we originated lsa, either in this
+// incarnation or a previous one.
+func (inst *Instance) isSelfOriginated(lsa LSAMetadata) bool {
+	if lsa.AdvertisingRouter() == inst.RouterID {
+		return true
+	}
+
+	if lsa.Type() != lsTypeNetwork {
+		return false
+	}
+
+	for id := range inst.Interfaces {
+		if id.prefix.Addr() == lsa.ID() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handleSelfOriginated responds to receiving a newer instance of an LSA
+// that we originated, as described in RFC 2328, section 13.4. If we still
+// originate it, we originate a new instance with a higher sequence number.
+// Otherwise, we flush it. While we're restarting gracefully, we accept
+// our LSAs from before the restart, and sort them out when we're done.
+func (inst *Instance) handleSelfOriginated(s lsaScope, lsa LSA) {
+	if inst.isRestarting() {
+		return
+	}
+
+	if o, ok := inst.originations[newScopedOriginationKey(s, lsa.Key())]; ok {
+		inst.runOrigination(o, true)
+		return
+	}
+
+	inst.flushLSA(s, lsa)
+}
+
+// isABR reports whether we're an area border router, with active
+// interfaces in more than one area.
+func (inst *Instance) isABR() bool {
+	areas := make(map[common.AreaID]bool)
+
+	for _, iface := range inst.Interfaces {
+		if iface.State != iDown {
+			areas[iface.AreaID] = true
+		}
+	}
+
+	return len(areas) > 1
+}
+
+// areaInterfaces returns the interfaces in an area, sorted by name and
+// prefix.
+func (inst *Instance) areaInterfaces(areaID common.AreaID) []*Interface {
+	var ifaces []*Interface
+	for _, iface := range inst.Interfaces {
+		if iface.AreaID == areaID {
+			ifaces = append(ifaces, iface)
+		}
+	}
+
+	sort.Slice(ifaces, func(a, b int) bool {
+		if ifaces[a].name != ifaces[b].name {
+			return ifaces[a].name < ifaces[b].name
+		}
+
+		return ifaces[a].Prefix.Addr().Less(ifaces[b].Prefix.Addr())
+	})
+
+	return ifaces
+}
+
+// scheduleRouterLSAs re-originates our router-LSA in every area. Whether
+// we're an area border router depends on all of our interfaces, so they're
+// all done together.
+func (inst *Instance) scheduleRouterLSAs() {
+	for id := range inst.Areas {
+		areaID := id
+
+		key := lsdbKey{
+			Type:              lsTypeRouter,
+			ID:                addrFromRouterID(inst.RouterID),
+			AdvertisingRouter: inst.RouterID,
+		}
+
+		inst.scheduleLSA(areaID, key, func() LSA {
+			return inst.buildRouterLSA(areaID)
+		})
+	}
+}
+
+// buildRouterLSA builds our router-LSA for an area, as described in RFC
+// 2328, section 12.4.1.
+func (inst *Instance) buildRouterLSA(areaID common.AreaID) LSA {
+	area := inst.Areas[areaID]
+
+	var flags routerLSAFlags
+	if inst.isABR() {
+		flags |= routerFlagB
+	}
+
+	// Translators originate AS-external-LSAs, and border routers can
+	// originate a default Type-7 LSA into an NSSA. Either makes us an AS
+	// boundary router where those LSAs are flooded.
+	if inst.isTranslator() && area.ExternalRoutingCapability {
+		flags |= routerFlagE
+	} else if area.NSSA && area.DefaultOriginate && inst.isABR() {
+		flags |= routerFlagE
+	}
+
+	if area.NSSA && inst.isABR() && area.TranslatorRole == config.NSSATranslatorAlways {
+		flags |= routerFlagNt
+	}
+
+	if inst.hasFullVirtualLink(areaID) {
+		flags |= routerFlagV
+	}
+
+	var links []routerLink
+	for _, iface := range inst.areaInterfaces(areaID) {
+		links = append(links, iface.routerLinks()...)
+	}
+
+	if inst.isMaxMetric() {
+		setMaxLinkMetric(links)
+	}
+
+	h := lsaHeader{
+		options:           area.options(),
+		advertisingRouter: inst.RouterID,
+	}
+
+	return newRouterLSA(h, flags, links)
+}
+
+// fullNeighbors returns the interface's neighbors that our LSAs describe
+// as fully adjacent, sorted by router ID.
+func (i *Interface) fullNeighbors() []*Neighbor {
+	var neighbors []*Neighbor
+	for _, n := range i.Neighbors {
+		if n.isAdvertisedAsFull() {
+			neighbors = append(neighbors, n)
+		}
+	}
+
+	sort.Slice(neighbors, func(a, b int) bool {
+		return neighbors[a].ID < neighbors[b].ID
+	})
+
+	return neighbors
+}
+
+func (i *Interface) stubLink() routerLink {
+	return routerLink{
+		ID:     i.Prefix.Masked().Addr(),
+		Data:   prefixMask(i.Prefix.Bits()),
+		Type:   linkStub,
+		Metric: i.Cost,
+	}
+}
+
+func hostLink(addr netip.Addr, cost uint16) routerLink {
+	return routerLink{
+		ID:     addr,
+		Data:   prefixMask(32),
+		Type:   linkStub,
+		Metric: cost,
+	}
+}
+
+// isTransit reports whether the interface is described by a transit link:
+// either we're DR and fully adjacent to at least one other router, or we're
+// fully adjacent to the DR.
+func (i *Interface) isTransit() bool {
+	if i.State == iDR {
+		return len(i.fullNeighbors()) > 0
+	}
+
+	n, ok := i.Neighbors[i.DR.ID]
+	return ok && n.isAdvertisedAsFull()
+}
+
+// routerLinks describes the interface in our router-LSA.
+func (i *Interface) routerLinks() []routerLink {
+	switch i.State {
+	case iDown:
+		return nil
+	case iLoopback:
+		// Loopbacks are advertised as host routes. See RFC 2328, section
+		// 12.4.1.
+		return []routerLink{hostLink(i.Prefix.Addr(), 0)}
+	}
+
+	// Passive interfaces have no neighbors, so their network is a stub.
+	if i.Passive {
+		return []routerLink{i.stubLink()}
+	}
+
+	var links []routerLink
+
+	switch {
+	case i.isPTP():
+		for _, n := range i.fullNeighbors() {
+			links = append(links, routerLink{
+				ID:     addrFromRouterID(n.ID),
+				Data:   i.Prefix.Addr(),
+				Type:   linkPointToPoint,
+				Metric: i.Cost,
+			})
+		}
+
+		if i.Prefix.Bits() < 32 {
+			links = append(links, i.stubLink())
+		} else {
+			for _, n := range i.fullNeighbors() {
+				links = append(links, hostLink(n.Addr, i.Cost))
+			}
+		}
+	case i.isPTMP():
+		links = append(links, hostLink(i.Prefix.Addr(), 0))
+
+		for _, n := range i.fullNeighbors() {
+			links = append(links, routerLink{
+				ID:     addrFromRouterID(n.ID),
+				Data:   i.Prefix.Addr(),
+				Type:   linkPointToPoint,
+				Metric: i.Cost,
+			})
+		}
+	case i.isVirtualLink():
+		for _, n := range i.fullNeighbors() {
+			links = append(links, routerLink{
+				ID:     addrFromRouterID(n.ID),
+				Data:   i.Prefix.Addr(),
+				Type:   linkVirtual,
+				Metric: i.Cost,
+			})
+		}
+	default:
+		if i.State != iWaiting && i.isTransit() {
+			links = append(links, routerLink{
+				ID:     i.DR.Addr,
+				Data:   i.Prefix.Addr(),
+				Type:   linkTransit,
+				Metric: i.Cost,
+			})
+		} else {
+			links = append(links, i.stubLink())
+		}
+	}
+
+	return links
+}
+
+func (i *Interface) networkLSAKey() lsdbKey {
+	return lsdbKey{
+		Type:              lsTypeNetwork,
+		ID:                i.Prefix.Addr(),
+		AdvertisingRouter: i.instance.RouterID,
+	}
+}
+
+// scheduleNetworkLSA originates or flushes the network-LSA for the
+// interface's network.
+func (i *Interface) scheduleNetworkLSA() {
+	if i.isPTP() || i.isPTMP() || i.isVirtualLink() {
+		return
+	}
+
+	i.instance.scheduleLSA(i.AreaID, i.networkLSAKey(), i.buildNetworkLSA)
+}
+
+// buildNetworkLSA builds the network-LSA for the interface's network, as
+// described in RFC 2328, section 12.4.2. There's only a network-LSA if
+// we're DR and fully adjacent to at least one other router.
+func (i *Interface) buildNetworkLSA() LSA {
+	if i.State != iDR {
+		return nil
+	}
+
+	neighbors := i.fullNeighbors()
+	if len(neighbors) == 0 {
+		return nil
+	}
+
+	routers := []common.RouterID{i.instance.RouterID}
+	for _, n := range neighbors {
+		routers = append(routers, n.ID)
+	}
+	slices.Sort(routers)
+
+	h := lsaHeader{
+		options:           i.area().options(),
+		id:                i.Prefix.Addr(),
+		advertisingRouter: i.instance.RouterID,
+	}
+
+	return newNetworkLSA(h, prefixMask(i.Prefix.Bits()), routers)
+}
@@ -0,0 +1,314 @@
+package ospf
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"github.com/davidbalbert/chatter/config"
+)
+
+func TestRouterLinksBroadcast(t *testing.T) {
+	iface, nbrs, _ := newTestFloodingInterface(t)
+
+	transit := func(dr string) []routerLink {
+		return []routerLink{{
+			ID:     netip.MustParseAddr(dr),
+			Data:   netip.MustParseAddr("10.0.0.1"),
+			Type:   linkTransit,
+			Metric: 10,
+		}}
+	}
+
+	stub := []routerLink{{
+		ID:     netip.MustParseAddr("10.0.0.0"),
+		Data:   netip.MustParseAddr("255.255.255.0"),
+		Type:   linkStub,
+		Metric: 10,
+	}}
+
+	if links := iface.routerLinks(); !reflect.DeepEqual(links, transit("10.0.0.1")) {
+		t.Errorf("DR: expected %v, got %v", transit("10.0.0.1"), links)
+	}
+
+	iface.State = iDROther
+	iface.DR = Router{ID: nbrs[0].ID, Addr: nbrs[0].Addr}
+
+	if links := iface.routerLinks(); !reflect.DeepEqual(links, transit("10.0.0.2")) {
+		t.Errorf("DROther: expected %v, got %v", transit("10.0.0.2"), links)
+	}
+
+	// Not fully adjacent to the DR.
+	nbrs[0].state = nLoading
+
+	if links := iface.routerLinks(); !reflect.DeepEqual(links, stub) {
+		t.Errorf("DROther, DR not Full: expected %v, got %v", stub, links)
+	}
+
+	iface.State = iWaiting
+
+	if links := iface.routerLinks(); !reflect.DeepEqual(links, stub) {
+		t.Errorf("Waiting: expected %v, got %v", stub, links)
+	}
+
+	iface.State = iDown
+
+	if links := iface.routerLinks(); len(links) != 0 {
+		t.Errorf("Down: expected no links, got %v", links)
+	}
+}
+
+func TestRouterLinksPointToPoint(t *testing.T) {
+	iface, nbrs, _ := newTestFloodingInterface(t)
+	iface.Type = InterfacePointToPoint
+	iface.State = iPointToPoint
+	iface.DR = Router{}
+
+	delete(iface.Neighbors, nbrs[1].ID)
+
+	expected := []routerLink{
+		{
+			ID:     netip.MustParseAddr("2.2.2.2"),
+			Data:   netip.MustParseAddr("10.0.0.1"),
+			Type:   linkPointToPoint,
+			Metric: 10,
+		},
+		{
+			ID:     netip.MustParseAddr("10.0.0.0"),
+			Data:   netip.MustParseAddr("255.255.255.0"),
+			Type:   linkStub,
+			Metric: 10,
+		},
+	}
+
+	if links := iface.routerLinks(); !reflect.DeepEqual(links, expected) {
+		t.Errorf("expected %v, got %v", expected, links)
+	}
+
+	// On an unnumbered or /32 link, the neighbor's address is a host route.
+	iface.Prefix = netip.MustParsePrefix("10.0.0.1/32")
+	expected[1] = routerLink{
+		ID:     netip.MustParseAddr("10.0.0.2"),
+		Data:   netip.MustParseAddr("255.255.255.255"),
+		Type:   linkStub,
+		Metric: 10,
+	}
+
+	if links := iface.routerLinks(); !reflect.DeepEqual(links, expected) {
+		t.Errorf("expected %v, got %v", expected, links)
+	}
+}
+
+func TestRouterLinksLoopback(t *testing.T) {
+	iface := newTestBroadcastInterface(t)
+	iface.State = iLoopback
+
+	expected := []routerLink{{
+		ID:   netip.MustParseAddr("10.0.0.1"),
+		Data: netip.MustParseAddr("255.255.255.255"),
+		Type: linkStub,
+	}}
+
+	if links := iface.routerLinks(); !reflect.DeepEqual(links, expected) {
+		t.Errorf("expected %v, got %v", expected, links)
+	}
+}
+
+func TestRouterLinksPassive(t *testing.T) {
+	iface, _, _ := newTestFloodingInterface(t)
+	iface.Passive = true
+
+	expected := []routerLink{{
+		ID:     netip.MustParseAddr("10.0.0.0"),
+		Data:   netip.MustParseAddr("255.255.255.0"),
+		Type:   linkStub,
+		Metric: 10,
+	}}
+
+	if links := iface.routerLinks(); !reflect.DeepEqual(links, expected) {
+		t.Errorf("expected %v, got %v", expected, links)
+	}
+
+	// Loopback interfaces are advertised as host routes, whether or not
+	// they're passive.
+	iface.State = iLoopback
+
+	if links := iface.routerLinks(); !reflect.DeepEqual(links, []routerLink{hostLink(iface.Prefix.Addr(), 0)}) {
+		t.Errorf("Loopback: expected a host route to %s, got %v", iface.Prefix.Addr(), links)
+	}
+
+	iface.State = iDown
+
+	if links := iface.routerLinks(); len(links) != 0 {
+		t.Errorf("Down: expected no links, got %v", links)
+	}
+}
+
+func TestBuildNetworkLSA(t *testing.T) {
+	iface, nbrs, _ := newTestFloodingInterface(t)
+
+	lsa, ok := iface.buildNetworkLSA().(*networkLSA)
+	if !ok {
+		t.Fatalf("expected a network-LSA")
+	}
+
+	if lsa.ID() != netip.MustParseAddr("10.0.0.1") || lsa.Prefix() != netip.MustParsePrefix("10.0.0.0/24") {
+		t.Errorf("expected 10.0.0.1 describing 10.0.0.0/24, got %s describing %s", lsa.ID(), lsa.Prefix())
+	}
+
+	routers := []common.RouterID{mustParseRouterID("1.1.1.1"), mustParseRouterID("2.2.2.2"), mustParseRouterID("3.3.3.3")}
+	if !reflect.DeepEqual(lsa.AttachedRouters(), routers) {
+		t.Errorf("expected %v, got %v", routers, lsa.AttachedRouters())
+	}
+
+	// Only fully adjacent routers are listed.
+	nbrs[1].state = nExchange
+	lsa = iface.buildNetworkLSA().(*networkLSA)
+
+	if !reflect.DeepEqual(lsa.AttachedRouters(), routers[:2]) {
+		t.Errorf("expected %v, got %v", routers[:2], lsa.AttachedRouters())
+	}
+
+	nbrs[0].state = nExchange
+	if lsa := iface.buildNetworkLSA(); lsa != nil {
+		t.Errorf("expected no network-LSA without adjacencies, got %v", lsa)
+	}
+
+	iface.State = iBackup
+	if lsa := iface.buildNetworkLSA(); lsa != nil {
+		t.Errorf("expected no network-LSA when not DR, got %v", lsa)
+	}
+}
+
+func TestMinLSInterval(t *testing.T) {
+	iface, nbrs, _ := newTestFloodingInterface(t)
+	inst := iface.instance
+
+	inst.scheduleRouterLSAs()
+
+	key := lsdbKey{Type: lsTypeRouter, ID: netip.MustParseAddr("1.1.1.1"), AdvertisingRouter: inst.RouterID}
+	first, ok := inst.lookupLSA(0, key)
+	if !ok {
+		t.Fatalf("expected router-LSA to be originated")
+	}
+
+	// Nothing changed, so there's no new instance.
+	inst.scheduleRouterLSAs()
+
+	if lsa, _ := inst.lookupLSA(0, key); lsa.SequenceNumber() != first.SequenceNumber() {
+		t.Errorf("expected unchanged router-LSA not to be re-originated")
+	}
+
+	// A change within MinLSInterval is deferred.
+	nbrs[0].setState(neKillNbr, nDown)
+	nbrs[1].setState(neKillNbr, nDown)
+
+	if lsa, _ := inst.lookupLSA(0, key); lsa.SequenceNumber() != first.SequenceNumber() {
+		t.Fatalf("expected re-origination to be deferred")
+	}
+
+	o := inst.originations[newOriginationKey(0, key)]
+	if o.timer == nil {
+		t.Fatalf("expected a deferred origination")
+	}
+
+	o.timer.Stop()
+	o.timer = nil
+	o.last = time.Now().Add(-testLSAThrottle.Hold)
+
+	inst.scheduleRouterLSAs()
+
+	lsa, _ := inst.lookupLSA(0, key)
+	if lsa.SequenceNumber() != first.SequenceNumber()+1 {
+		t.Fatalf("expected sequence number %d, got %d", first.SequenceNumber()+1, lsa.SequenceNumber())
+	}
+
+	if links := lsa.LSA.(*routerLSA).Links(); len(links) != 1 || links[0].Type != linkStub {
+		t.Errorf("expected a single stub link, got %v", links)
+	}
+}
+
+func TestReceiveSelfOriginatedLSA(t *testing.T) {
+	iface, nbrs, _ := newTestFloodingInterface(t)
+	inst := iface.instance
+
+	inst.scheduleRouterLSAs()
+
+	key := lsdbKey{Type: lsTypeRouter, ID: netip.MustParseAddr("1.1.1.1"), AdvertisingRouter: inst.RouterID}
+	iface.lsdbFor(lsTypeRouter)[key].installedAt = time.Now().Add(-minLSArrival * time.Second)
+
+	// A neighbor has an instance from before we restarted.
+	old := testRouterLSA("1.1.1.1", initialSequenceNumber+5)
+	iface.handleLSUpd(testLSUpd(nbrs[0], old), nbrs[0].Addr)
+
+	lsa, _ := inst.lookupLSA(0, key)
+	if lsa.SequenceNumber() != initialSequenceNumber+6 {
+		t.Errorf("expected sequence number %d, got %d", initialSequenceNumber+6, lsa.SequenceNumber())
+	}
+
+	if links := lsa.LSA.(*routerLSA).Links(); len(links) != 1 || links[0].Type != linkTransit {
+		t.Errorf("expected our current links, got %v", links)
+	}
+
+	// A network-LSA for a network we're no longer DR on is flushed.
+	h := testLSAHeader("1.1.1.1", initialSequenceNumber)
+	h.id = iface.Prefix.Addr()
+	stale := newNetworkLSA(h, netip.MustParseAddr("255.255.255.0"), nil)
+
+	iface.State = iBackup
+	iface.handleLSUpd(testLSUpd(nbrs[0], stale), nbrs[0].Addr)
+
+	flushed, ok := inst.lookupLSA(0, stale.Key())
+	if !ok || flushed.Age() != maxAge {
+		t.Errorf("expected stale network-LSA to be flushed, got %v", flushed)
+	}
+}
+
+func TestOriginationBetweenInstances(t *testing.T) {
+	network := newMemNetwork()
+
+	r1 := newTestInstance(t, "1.1.1.1", network, map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig()})
+	r2 := newTestInstance(t, "2.2.2.2", network, map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig()})
+
+	for i, inst := range []*Instance{r1, r2} {
+		netif := testNetif("eth0", i+1, fmt.Sprintf("10.0.0.%d/24", i+1))
+		netif.Flags |= net.FlagBroadcast
+
+		startTestInterface(t, inst, netif)
+	}
+
+	// Each router ends up with both router-LSAs describing a transit link
+	// to the DR, and the DR's network-LSA listing both routers.
+	for _, inst := range []*Instance{r1, r2} {
+		waitFor(t, inst, 20*time.Second, fmt.Sprintf("%s to have a complete database", inst.RouterID), func() bool {
+			db := inst.Areas[0].lsdb
+
+			var drAddr netip.Addr
+			for _, id := range []string{"1.1.1.1", "2.2.2.2"} {
+				lsa, ok := db[lsdbKey{Type: lsTypeRouter, ID: netip.MustParseAddr(id), AdvertisingRouter: mustParseRouterID(id)}]
+				if !ok {
+					return false
+				}
+
+				links := lsa.LSA.(*routerLSA).Links()
+				if len(links) != 1 || links[0].Type != linkTransit {
+					return false
+				}
+
+				drAddr = links[0].ID
+			}
+
+			for _, lsa := range db {
+				if n, ok := lsa.LSA.(*networkLSA); ok && n.ID() == drAddr && len(n.AttachedRouters()) == 2 {
+					return true
+				}
+			}
+
+			return false
+		})
+	}
+}
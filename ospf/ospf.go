@@ -3,14 +3,15 @@ package ospf
 import (
 	"context"
 	"fmt"
-	"net"
 	"net/netip"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/davidbalbert/chatter/chatterd/common"
 	"github.com/davidbalbert/chatter/chatterd/services"
 	"github.com/davidbalbert/chatter/config"
 	"github.com/davidbalbert/chatter/net/netmon"
-	"go4.org/netipx"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -20,19 +21,36 @@ var (
 )
 
 type Instance struct {
+	// mu protects the protocol state of the instance, its areas, interfaces
+	// and neighbors, which is shared between the goroutines running each
+	// Interface.
+	mu sync.Mutex
+
 	RouterID common.RouterID
 	Areas    map[common.AreaID]*Area
-	// TODO: VirtualLinks
-	// TODO: ExternalRoutes
-	// TODO: LSDB (or maybe just AS external?)
-	// TODO: RIB
+	lsdb     lsdb         // AS-external-LSAs. Everything else is stored in its area.
+	rib      routingTable // intra-area, inter-area and external routes
+
+	originations        map[originationKey]*origination
+	pendingOriginations map[originationKey]LSA // waiting for an instance with MaxSequenceNumber to be flushed
+	spfTimer            *time.Timer            // non-nil while a calculation is scheduled
+	spfThrottle         throttle
+	spfTriggers         []SPFTrigger // changes since the last calculation
+	spfTriggerCount     int
+	spfRuns             uint64
+	spfHistory          []SPFRun
+	opaqueTypes         map[uint8]*OpaqueRegistration
+	restart             *gracefulRestart  // non-nil while we're restarting gracefully
+	maxMetric           bool              // advertising MaxLinkMetric on request
+	maxMetricStartup    *maxMetricStartup // non-nil while we're advertising MaxLinkMetric after starting
 
 	// TODO: this should be some sort of service tree. It's the same thing as service manager.
-	Interfaces  map[interfaceID]*Interface
+	Interfaces  map[interfaceID]*Interface // including virtual links, which are backbone interfaces
 	cancelFuncs map[interfaceID]context.CancelFunc
 
 	serviceManager *services.ServiceManager
 	config         *config.OSPFConfig
+	newTransport   transportFactory
 }
 
 func NewInstance(serviceManager *services.ServiceManager, conf any) (services.Runner, error) {
@@ -54,12 +72,19 @@ func NewInstance(serviceManager *services.ServiceManager, conf any) (services.Ru
 	return &Instance{
 		RouterID: ospfConf.RouterID,
 		Areas:    areas,
+		lsdb:     newLSDB(),
+
+		originations:        make(map[originationKey]*origination),
+		pendingOriginations: make(map[originationKey]LSA),
+		opaqueTypes:         make(map[uint8]*OpaqueRegistration),
+		maxMetric:           ospfConf.MaxMetric.Administrative,
 
 		Interfaces:  make(map[interfaceID]*Interface),
 		cancelFuncs: make(map[interfaceID]context.CancelFunc),
 
 		serviceManager: serviceManager,
 		config:         ospfConf,
+		newTransport:   newRawTransport,
 	}, nil
 }
 
@@ -73,193 +98,256 @@ func (i *Instance) Run(ctx context.Context) error {
 
 	interfaceMonitor, ok := s.(*netmon.Monitor)
 	if !ok {
-		return fmt.Errorf("expected *ifmon.Monitor but got %v", s)
+		return fmt.Errorf("expected *netmon.Monitor but got %v", s)
 	}
 
-	intCh := make(chan struct{}, 1)
+	snapshot, sub := interfaceMonitor.Subscribe()
+	defer sub.Close()
 
-	g.Go(func() error {
-		seq := interfaceMonitor.LastSeq()
-		for {
-			select {
-			case <-ctx.Done():
-				return nil
-			case intCh <- struct{}{}:
-			}
+	i.mu.Lock()
+	i.startMaxMetric()
+	i.mu.Unlock()
 
-			seq = interfaceMonitor.AwaitChange(ctx, seq)
+	for _, netif := range snapshot {
+		for _, prefix := range netif.PrefixesV4() {
+			i.addInterface(ctx, g, netif, prefix)
 		}
+	}
+
+	i.startVirtualLinks(ctx, g)
+
+	g.Go(func() error {
+		i.runAging(ctx)
+		return nil
 	})
 
 	g.Go(func() error {
 		for {
-			select {
-			case <-ctx.Done():
+			e, err := sub.Next(ctx)
+			if err != nil {
 				return nil
-			case <-intCh:
-				err := i.updateInterfaces(ctx, g)
-				if err != nil {
-					return err
-				}
 			}
+
+			i.handleNetifEvent(ctx, g, e)
 		}
 	})
 
 	return g.Wait()
 }
 
-type netifAndPrefixes struct {
-	netif    net.Interface
-	prefixes []netip.Prefix
+func (i *Instance) handleNetifEvent(ctx context.Context, g *errgroup.Group, e netmon.Event) {
+	netif := e.Interface
+
+	switch e.Type {
+	case netmon.EventLinkAdded:
+		for _, prefix := range netif.PrefixesV4() {
+			i.addInterface(ctx, g, netif, prefix)
+		}
+	case netmon.EventLinkRemoved:
+		for id := range i.netifInterfaces(netif.Name) {
+			i.removeInterface(id)
+		}
+	case netmon.EventLinkUp:
+		i.sendEventToNetif(netif.Name, ieInterfaceUp)
+	case netmon.EventLinkDown:
+		i.sendEventToNetif(netif.Name, ieInterfaceDown)
+	case netmon.EventFlagsChanged:
+		// Interfaces ignore LoopInd and UnloopInd if they're already in
+		// the right state.
+		if netif.IsLoopback() {
+			i.sendEventToNetif(netif.Name, ieLoopInd)
+		} else {
+			// UnloopInd leaves the interface Down.
+			i.sendEventToNetif(netif.Name, ieUnloopInd)
+
+			if netif.IsUp() {
+				i.sendEventToNetif(netif.Name, ieInterfaceUp)
+			}
+		}
+	case netmon.EventMTUChanged:
+		for _, iface := range i.netifInterfaces(netif.Name) {
+			i.mu.Lock()
+			iface.mtu = netif.MTU
+			i.mu.Unlock()
+		}
+	case netmon.EventAddrAdded:
+		if e.Prefix.Addr().Is4() {
+			i.addInterface(ctx, g, netif, e.Prefix)
+		}
+	case netmon.EventAddrRemoved:
+		i.removeInterface(interfaceID{name: netif.Name, prefix: e.Prefix})
+	}
 }
 
-func (i *Instance) updateInterfaces(ctx context.Context, g *errgroup.Group) error {
-	netifs, err := net.Interfaces()
-	if err != nil {
-		return fmt.Errorf("failed to get interfaces: %w", err)
+// addInterface starts running OSPF on prefix if netif is configured for OSPF.
+// Loopback addresses like 127.0.0.1 aren't routable, so they're skipped.
+//
+// One interface failing to start, e.g. because we can't open a socket on it,
+// shouldn't take down the rest of the instance, so the error is logged and
+// the interface is skipped. It's tried again the next time the link or
+// address is added.
+func (i *Instance) addInterface(ctx context.Context, g *errgroup.Group, netif netmon.Interface, prefix netip.Prefix) {
+	conf, ok := i.config.InterfaceConfigs()[netif.Name]
+	if !ok || prefix.Addr().IsLoopback() {
+		return
 	}
 
-	nameToNetif := make(map[string]netifAndPrefixes)
+	id := interfaceID{name: netif.Name, prefix: prefix}
 
-	for _, netif := range netifs {
-		prefixes, err := netifPrefixesV4(netif)
-		if err != nil {
-			return err
-		}
+	i.mu.Lock()
+	_, ok = i.Interfaces[id]
+	i.mu.Unlock()
 
-		nameToNetif[netif.Name] = netifAndPrefixes{netif, prefixes}
+	if ok {
+		return
 	}
 
-	// update existing interfaces and remove any that are gone
-	for id, iface := range i.Interfaces {
-		nap, ok := nameToNetif[id.name]
-		if !ok { // interface was removed
-			iface.sendEventWait(ieInterfaceDown)
-
-			stop, ok := i.cancelFuncs[id]
-			if !ok {
-				return fmt.Errorf("no cancel func for interface %s %s", id.name, id.prefix)
-			}
-			stop()
-			delete(i.Interfaces, id)
-			continue
-		}
+	iface, err := newInterface(i, conf, netif, prefix)
+	if err != nil {
+		fmt.Printf("ospf: skipping interface: %v\n", err)
+		return
+	}
 
-		netif, prefixes := nap.netif, nap.prefixes
+	ctx, cancel := context.WithCancel(ctx)
 
-		found := false
-		for _, prefix := range prefixes {
-			if prefixEquals(prefix, iface.Prefix) {
-				found = true
-				break
-			}
-		}
+	i.mu.Lock()
+	i.Interfaces[id] = iface
+	i.cancelFuncs[id] = cancel
+	i.scheduleLinkOpaqueLSAs(id)
+	i.mu.Unlock()
 
-		// address was removed
-		if !found {
-			iface.sendEventWait(ieInterfaceDown)
-			stop, ok := i.cancelFuncs[id]
-			if !ok {
-				return fmt.Errorf("no cancel func for interface %s %s", id.name, id.prefix)
-			}
-			stop()
-			delete(i.Interfaces, id)
-			continue
-		}
+	g.Go(func() error {
+		return iface.Run(ctx)
+	})
 
-		// detect changes to interface state
-		if isUp(netif) && !iface.isUp() {
-			iface.sendEvent(ieInterfaceUp)
-		} else if !isUp(netif) && iface.isUp() {
-			iface.sendEvent(ieInterfaceDown)
-		}
+	if netif.IsUp() {
+		iface.sendEvent(ieInterfaceUp)
+	}
 
-		if isLoopback(netif) && !iface.isLoopback() {
-			iface.sendEvent(ieLoopInd)
-		} else if !isLoopback(netif) && iface.isLoopback() {
-			iface.sendEvent(ieUnloopInd)
-		}
+	if netif.IsLoopback() {
+		iface.sendEvent(ieLoopInd)
 	}
+}
 
-	// add new interfaces
-	configs := i.config.InterfaceConfigs()
-	for name, conf := range configs {
-		nap, ok := nameToNetif[name]
-		if !ok {
-			continue
-		}
+func (i *Instance) removeInterface(id interfaceID) {
+	i.mu.Lock()
+	iface, ok := i.Interfaces[id]
+	cancel := i.cancelFuncs[id]
+	delete(i.cancelFuncs, id)
+	delete(i.Interfaces, id)
+	if ok {
+		i.removeLinkOriginations(iface)
+	}
+	i.mu.Unlock()
 
-		netif, prefixes := nap.netif, nap.prefixes
+	if !ok {
+		return
+	}
 
-		for _, prefix := range prefixes {
-			id := interfaceID{name: name, prefix: prefix}
+	iface.sendEventWait(ieInterfaceDown)
+	cancel()
+}
 
-			if _, ok := i.Interfaces[id]; ok {
-				continue
-			}
+// An InstanceSnapshot is a copy of the instance's state, for reporting.
+type InstanceSnapshot struct {
+	RouterID        common.RouterID
+	GracefulRestart RestartSnapshot
+	MaxMetric       MaxMetricSnapshot
+	SPF             SPFSnapshot
+	LSAThrottle     config.OSPFThrottleConfig
+}
 
-			i.Interfaces[id] = newInterface(conf, conf.AreaID, name, prefix)
+// InstanceSnapshot returns the state of the instance.
+func (i *Instance) InstanceSnapshot() InstanceSnapshot {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return InstanceSnapshot{
+		RouterID:        i.RouterID,
+		GracefulRestart: i.restartSnapshot(),
+		MaxMetric:       i.maxMetricSnapshot(),
+		SPF:             i.spfSnapshot(),
+		LSAThrottle:     i.config.LSAThrottle,
+	}
+}
 
-			ctx, cancel := context.WithCancel(ctx)
-			g.Go(func() error {
-				return i.Interfaces[id].Run(ctx)
-			})
-			i.cancelFuncs[id] = cancel
+// InterfaceSnapshots returns the state of every interface, sorted by name
+// and prefix.
+func (i *Instance) InterfaceSnapshots() []InterfaceSnapshot {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-			if isUp(netif) {
-				i.Interfaces[id].sendEvent(ieInterfaceUp)
-			}
+	var ifaces []InterfaceSnapshot
+	for _, iface := range i.Interfaces {
+		ifaces = append(ifaces, iface.snapshot())
+	}
 
-			if isLoopback(netif) {
-				i.Interfaces[id].sendEvent(ieLoopInd)
-			}
+	sort.Slice(ifaces, func(a, b int) bool {
+		if ifaces[a].Name != ifaces[b].Name {
+			return ifaces[a].Name < ifaces[b].Name
 		}
-	}
 
-	return nil
+		return ifaces[a].Prefix.Addr().Less(ifaces[b].Prefix.Addr())
+	})
+
+	return ifaces
 }
 
-func netifPrefixesV4(netif net.Interface) ([]netip.Prefix, error) {
-	addrs, err := netif.Addrs()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get addresses for interface %s: %w", netif.Name, err)
-	}
+// NeighborSnapshots returns the state of every neighbor on every interface,
+// sorted by interface and router ID. Configured neighbors on NBMA networks
+// that we haven't heard from have a zero router ID.
+func (i *Instance) NeighborSnapshots() []NeighborSnapshot {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var neighbors []NeighborSnapshot
+	for _, iface := range i.Interfaces {
+		for _, n := range iface.Neighbors {
+			neighbors = append(neighbors, n.snapshot())
+		}
 
-	var prefixes []netip.Prefix
-	for _, addr := range addrs {
-		prefix, ok := prefixFromSTDNetAddr(addr)
-		if ok && prefix.Addr().Is4() {
-			prefixes = append(prefixes, prefix)
+		for _, n := range iface.pendingNeighbors {
+			neighbors = append(neighbors, n.snapshot())
 		}
 	}
 
-	return prefixes, nil
-}
+	sort.Slice(neighbors, func(a, b int) bool {
+		if neighbors[a].Interface != neighbors[b].Interface {
+			return neighbors[a].Interface < neighbors[b].Interface
+		}
 
-// net.Interface.Addrs() returns []net.Addr which is really
-// []*net.IPNet.
-func prefixFromSTDNetAddr(addr net.Addr) (netip.Prefix, bool) {
-	ipnet, ok := addr.(*net.IPNet)
-	if !ok {
-		return netip.Prefix{}, false
-	}
+		if neighbors[a].InterfacePrefix != neighbors[b].InterfacePrefix {
+			return neighbors[a].InterfacePrefix.Addr().Less(neighbors[b].InterfacePrefix.Addr())
+		}
 
-	prefix, ok := netipx.FromStdIPNet(ipnet)
-	if !ok {
-		return netip.Prefix{}, false
-	}
+		if neighbors[a].ID != neighbors[b].ID {
+			return neighbors[a].ID < neighbors[b].ID
+		}
 
-	return prefix, true
-}
+		return neighbors[a].Addr.Less(neighbors[b].Addr)
+	})
 
-func isUp(netif net.Interface) bool {
-	return netif.Flags&net.FlagUp != 0
+	return neighbors
 }
 
-func isLoopback(netif net.Interface) bool {
-	return netif.Flags&net.FlagLoopback != 0
+func (i *Instance) sendEventToNetif(name string, e interfaceEvent) {
+	for _, iface := range i.netifInterfaces(name) {
+		iface.sendEvent(e)
+	}
 }
 
-func prefixEquals(a, b netip.Prefix) bool {
-	return a.Addr().Compare(b.Addr()) == 0 && a.Bits() == b.Bits()
+// netifInterfaces returns the OSPF interfaces running on the named network
+// interface.
+func (i *Instance) netifInterfaces(name string) map[interfaceID]*Interface {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	ifaces := make(map[interfaceID]*Interface)
+	for id, iface := range i.Interfaces {
+		if id.name == name {
+			ifaces[id] = iface
+		}
+	}
+
+	return ifaces
 }
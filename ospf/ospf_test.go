@@ -0,0 +1,201 @@
+package ospf
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"github.com/davidbalbert/chatter/config"
+	"github.com/davidbalbert/chatter/net/netmon"
+	"golang.org/x/sync/errgroup"
+)
+
+// testInterfaceConfig uses short intervals so that tests run quickly.
+func testInterfaceConfig() config.OSPFInterfaceConfig {
+	return config.OSPFInterfaceConfig{
+		AreaID:             0,
+		Cost:               10,
+		HelloInterval:      1,
+		RouterDeadInterval: 4,
+		RouterPriority:     1,
+	}
+}
+
+// testSPFThrottle and testLSAThrottle are the defaults.
+var (
+	testSPFThrottle = config.OSPFThrottleConfig{
+		InitialDelay: 200 * time.Millisecond,
+		Hold:         1 * time.Second,
+		MaxWait:      10 * time.Second,
+	}
+
+	testLSAThrottle = config.OSPFThrottleConfig{
+		Hold:    5 * time.Second,
+		MaxWait: 5 * time.Second,
+	}
+)
+
+// newTestInstance returns an Instance with router ID routerID whose
+// interfaces are configured with confs, keyed by name. Its interfaces are
+// attached to network.
+func newTestInstance(t *testing.T, routerID string, network *memNetwork, confs map[string]config.OSPFInterfaceConfig) *Instance {
+	t.Helper()
+
+	conf := &config.OSPFConfig{
+		RouterID:    mustParseRouterID(routerID),
+		SPFThrottle: testSPFThrottle,
+		LSAThrottle: testLSAThrottle,
+		Areas:       make(map[common.AreaID]config.OSPFAreaConfig),
+	}
+
+	for name, ic := range confs {
+		ac, ok := conf.Areas[ic.AreaID]
+		if !ok {
+			ac = config.OSPFAreaConfig{Interfaces: make(map[string]config.OSPFInterfaceConfig)}
+		}
+
+		ac.Interfaces[name] = ic
+		conf.Areas[ic.AreaID] = ac
+	}
+
+	r, err := NewInstance(nil, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inst := r.(*Instance)
+	inst.newTransport = network.newTransport
+
+	t.Cleanup(func() {
+		inst.mu.Lock()
+		inst.stopTimers()
+		inst.mu.Unlock()
+	})
+
+	return inst
+}
+
+func testNetif(name string, index int, prefix string) netmon.Interface {
+	return netmon.Interface{
+		Index:    index,
+		Name:     name,
+		MTU:      1500,
+		Flags:    net.FlagUp | net.FlagMulticast,
+		Prefixes: []netip.Prefix{netip.MustParsePrefix(prefix)},
+	}
+}
+
+// startTestInterface starts running OSPF on netif's first prefix. The
+// interface is stopped when the test finishes.
+func startTestInterface(t *testing.T, inst *Instance, netif netmon.Interface) *Interface {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+
+	t.Cleanup(func() {
+		cancel()
+		g.Wait()
+	})
+
+	inst.addInterface(ctx, g, netif, netif.Prefixes[0])
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	return inst.Interfaces[interfaceID{name: netif.Name, prefix: netif.Prefixes[0]}]
+}
+
+// waitFor polls cond, which is called with inst.mu held, until it returns
+// true or timeout elapses.
+func waitFor(t *testing.T, inst *Instance, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+
+	for {
+		inst.mu.Lock()
+		ok := cond()
+		inst.mu.Unlock()
+
+		if ok {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAddInterfaceSkipsLoopbackAddresses(t *testing.T) {
+	inst := newTestInstance(t, "1.1.1.1", newMemNetwork(), map[string]config.OSPFInterfaceConfig{"lo": testInterfaceConfig()})
+
+	if iface := startTestInterface(t, inst, testNetif("lo", 1, "127.0.0.1/8")); iface != nil {
+		t.Errorf("expected no interface for 127.0.0.1/8")
+	}
+}
+
+func TestAddInterfaceSkipsInterfacesWithoutTransport(t *testing.T) {
+	network := newMemNetwork()
+	inst := newTestInstance(t, "1.1.1.1", network, map[string]config.OSPFInterfaceConfig{
+		"eth0": testInterfaceConfig(),
+		"eth1": testInterfaceConfig(),
+	})
+
+	inst.newTransport = func(iface *Interface) (transport, error) {
+		if iface.name == "eth0" {
+			return nil, errors.New("permission denied")
+		}
+
+		return network.newTransport(iface)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+
+	t.Cleanup(func() {
+		cancel()
+		g.Wait()
+	})
+
+	eth0 := testNetif("eth0", 1, "10.0.0.1/24")
+	eth1 := testNetif("eth1", 2, "10.0.1.1/24")
+
+	inst.handleNetifEvent(ctx, g, netmon.Event{Type: netmon.EventLinkAdded, Interface: eth0})
+	inst.handleNetifEvent(ctx, g, netmon.Event{Type: netmon.EventLinkAdded, Interface: eth1})
+
+	id0 := interfaceID{name: "eth0", prefix: eth0.Prefixes[0]}
+	id1 := interfaceID{name: "eth1", prefix: eth1.Prefixes[0]}
+
+	inst.mu.Lock()
+	_, ok0 := inst.Interfaces[id0]
+	_, ok1 := inst.Interfaces[id1]
+	inst.mu.Unlock()
+
+	if ok0 {
+		t.Errorf("expected eth0 to be skipped")
+	}
+
+	if !ok1 {
+		t.Errorf("expected eth1 to be added")
+	}
+
+	// eth0 is tried again when its address is added.
+	inst.newTransport = network.newTransport
+	inst.handleNetifEvent(ctx, g, netmon.Event{Type: netmon.EventAddrAdded, Interface: eth0, Prefix: eth0.Prefixes[0]})
+
+	inst.mu.Lock()
+	_, ok0 = inst.Interfaces[id0]
+	inst.mu.Unlock()
+
+	if !ok0 {
+		t.Errorf("expected eth0 to be added on retry")
+	}
+}
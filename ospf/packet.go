@@ -1,8 +1,56 @@
 package ospf
 
-import "github.com/davidbalbert/chatter/chatterd/common"
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+)
+
+const (
+	ospfVersion     = 2
+	packetHeaderLen = 24
+
+	helloLen     = 20 // not including neighbors
+	ddLen        = 8  // not including LSA headers
+	lsReqItemLen = 12
+	lsUpdLen     = 4 // not including LSAs
+)
+
+var (
+	ErrTruncated         = errors.New("truncated")
+	ErrBadVersion        = errors.New("bad version")
+	ErrBadLength         = errors.New("bad length")
+	ErrBadChecksum       = errors.New("bad checksum")
+	ErrUnknownPacketType = errors.New("unknown packet type")
+)
+
+// ParseError describes where and why a packet failed to parse. Err is one
+// of the Err* values above.
+type ParseError struct {
+	Type   packetType // pUnknown if the header couldn't be parsed
+	Offset int        // byte offset of the problem, relative to the start of the packet
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.Type == pUnknown {
+		return fmt.Sprintf("ospf: malformed packet at offset %d: %v", e.Offset, e.Err)
+	}
+
+	return fmt.Sprintf("ospf: malformed %s packet at offset %d: %v", e.Type, e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
 
 type Packet interface {
+	encoding.BinaryMarshaler
+	Header() *PacketHeader
 }
 
 type PacketHeader struct {
@@ -18,29 +66,399 @@ type PacketHeader struct {
 type packetType uint8
 
 const (
-	pHello packetType = iota
+	pUnknown packetType = iota
+	pHello
 	pDD
 	pLSReq
 	pLSUpd
 	pLSAck
 )
 
+func (t packetType) String() string {
+	switch t {
+	case pHello:
+		return "Hello"
+	case pDD:
+		return "Database Description"
+	case pLSReq:
+		return "Link State Request"
+	case pLSUpd:
+		return "Link State Update"
+	case pLSAck:
+		return "Link State Acknowledgment"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(t))
+	}
+}
+
+func (h *PacketHeader) Header() *PacketHeader {
+	return h
+}
+
+// marshal encodes the header followed by body. The length and checksum
+// fields are computed, and the values in h are ignored. As specified in
+// RFC 2328, no checksum is calculated for cryptographic authentication.
+func (h *PacketHeader) marshal(t packetType, body []byte) ([]byte, error) {
+	length := packetHeaderLen + len(body)
+	if length > 0xffff {
+		return nil, fmt.Errorf("ospf: %s packet too long: %d bytes", t, length)
+	}
+
+	b := make([]byte, length)
+
+	b[0] = ospfVersion
+	b[1] = byte(t)
+	binary.BigEndian.PutUint16(b[2:4], uint16(length))
+	binary.BigEndian.PutUint32(b[4:8], uint32(h.routerID))
+	binary.BigEndian.PutUint32(b[8:12], uint32(h.areaID))
+	binary.BigEndian.PutUint16(b[14:16], h.authType)
+	copy(b[24:], body)
+
+	// The checksum excludes the 64-bit authentication field, so we
+	// calculate it before filling the field in.
+	if h.authType != uint16(AuthTypeCryptographic) {
+		binary.BigEndian.PutUint16(b[12:14], ipChecksum(b))
+	}
+
+	binary.BigEndian.PutUint64(b[16:24], h.authData)
+
+	return b, nil
+}
+
+// ParsePacket decodes an OSPF packet, starting at the OSPF header. Bytes
+// past the length in the header are ignored. They may be present when
+// using cryptographic authentication.
+func ParsePacket(data []byte) (Packet, error) {
+	if len(data) < packetHeaderLen {
+		return nil, &ParseError{Offset: len(data), Err: ErrTruncated}
+	}
+
+	if data[0] != ospfVersion {
+		return nil, &ParseError{Offset: 0, Err: ErrBadVersion}
+	}
+
+	t := packetType(data[1])
+	if t < pHello || t > pLSAck {
+		return nil, &ParseError{Offset: 1, Err: ErrUnknownPacketType}
+	}
+
+	length := int(binary.BigEndian.Uint16(data[2:4]))
+	if length < packetHeaderLen || length > len(data) {
+		return nil, &ParseError{Type: t, Offset: 2, Err: ErrBadLength}
+	}
+
+	data = data[:length]
+
+	h := PacketHeader{
+		t:        t,
+		length:   uint16(length),
+		routerID: common.RouterID(binary.BigEndian.Uint32(data[4:8])),
+		areaID:   common.AreaID(binary.BigEndian.Uint32(data[8:12])),
+		checksum: binary.BigEndian.Uint16(data[12:14]),
+		authType: binary.BigEndian.Uint16(data[14:16]),
+		authData: binary.BigEndian.Uint64(data[16:24]),
+	}
+
+	if h.authType != uint16(AuthTypeCryptographic) {
+		// The checksum covers everything but the authentication field.
+		if ipChecksum(data[:16], data[24:]) != 0 {
+			return nil, &ParseError{Type: t, Offset: 12, Err: ErrBadChecksum}
+		}
+	}
+
+	body := data[packetHeaderLen:]
+
+	var (
+		p   Packet
+		err error
+	)
+
+	switch t {
+	case pHello:
+		p, err = parseHello(h, body)
+	case pDD:
+		p, err = parseDD(h, body)
+	case pLSReq:
+		p, err = parseLSReq(h, body)
+	case pLSUpd:
+		p, err = parseLSUpd(h, body)
+	case pLSAck:
+		p, err = parseLSAck(h, body)
+	}
+
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		// parse functions report offsets relative to the body
+		perr.Type = t
+		perr.Offset += packetHeaderLen
+		return nil, perr
+	} else if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
 type Hello struct {
 	PacketHeader
+	networkMask            netip.Addr
+	helloInterval          uint16
+	options                uint8
+	routerPriority         uint8
+	routerDeadInterval     uint32
+	designatedRouter       netip.Addr
+	backupDesignatedRouter netip.Addr
+	neighbors              []common.RouterID
+}
+
+func parseHello(h PacketHeader, data []byte) (*Hello, error) {
+	if len(data) < helloLen {
+		return nil, &ParseError{Offset: len(data), Err: ErrTruncated}
+	}
+
+	if (len(data)-helloLen)%4 != 0 {
+		return nil, &ParseError{Offset: len(data), Err: ErrBadLength}
+	}
+
+	hello := &Hello{
+		PacketHeader:           h,
+		networkMask:            addrFromSlice(data[0:4]),
+		helloInterval:          binary.BigEndian.Uint16(data[4:6]),
+		options:                data[6],
+		routerPriority:         data[7],
+		routerDeadInterval:     binary.BigEndian.Uint32(data[8:12]),
+		designatedRouter:       addrFromSlice(data[12:16]),
+		backupDesignatedRouter: addrFromSlice(data[16:20]),
+	}
+
+	for i := helloLen; i < len(data); i += 4 {
+		hello.neighbors = append(hello.neighbors, common.RouterID(binary.BigEndian.Uint32(data[i:i+4])))
+	}
+
+	return hello, nil
+}
+
+func (p *Hello) MarshalBinary() ([]byte, error) {
+	b := make([]byte, helloLen+4*len(p.neighbors))
+
+	putAddr(b[0:4], p.networkMask)
+	binary.BigEndian.PutUint16(b[4:6], p.helloInterval)
+	b[6] = p.options
+	b[7] = p.routerPriority
+	binary.BigEndian.PutUint32(b[8:12], p.routerDeadInterval)
+	putAddr(b[12:16], p.designatedRouter)
+	putAddr(b[16:20], p.backupDesignatedRouter)
+
+	for i, id := range p.neighbors {
+		binary.BigEndian.PutUint32(b[helloLen+4*i:], uint32(id))
+	}
+
+	return p.marshal(pHello, b)
+}
+
+type ddFlags uint8
+
+const (
+	ddFlagMS ddFlags = 1 << iota // Master/Slave
+	ddFlagM                      // More
+	ddFlagI                      // Init
+
+	ddFlagsMask = ddFlagMS | ddFlagM | ddFlagI
+)
+
+func (f ddFlags) String() string {
+	s := ""
+	if f&ddFlagI != 0 {
+		s += "I"
+	}
+	if f&ddFlagM != 0 {
+		s += "M"
+	}
+	if f&ddFlagMS != 0 {
+		s += "MS"
+	}
+
+	return s
 }
 
 type DD struct {
 	PacketHeader
+	interfaceMTU   uint16
+	options        uint8
+	flags          ddFlags
+	sequenceNumber uint32
+	lsaHeaders     []*lsaHeader
+}
+
+func parseDD(h PacketHeader, data []byte) (*DD, error) {
+	if len(data) < ddLen {
+		return nil, &ParseError{Offset: len(data), Err: ErrTruncated}
+	}
+
+	if (len(data)-ddLen)%lsaHeaderLen != 0 {
+		return nil, &ParseError{Offset: len(data), Err: ErrBadLength}
+	}
+
+	dd := &DD{
+		PacketHeader:   h,
+		interfaceMTU:   binary.BigEndian.Uint16(data[0:2]),
+		options:        data[2],
+		flags:          ddFlags(data[3]) & ddFlagsMask,
+		sequenceNumber: binary.BigEndian.Uint32(data[4:8]),
+	}
+
+	for i := ddLen; i < len(data); i += lsaHeaderLen {
+		lsaHeader, err := parseLSAHeader(data[i : i+lsaHeaderLen])
+		if err != nil {
+			return nil, &ParseError{Offset: i, Err: err}
+		}
+
+		dd.lsaHeaders = append(dd.lsaHeaders, lsaHeader)
+	}
+
+	return dd, nil
+}
+
+func (p *DD) MarshalBinary() ([]byte, error) {
+	b := make([]byte, ddLen, ddLen+lsaHeaderLen*len(p.lsaHeaders))
+
+	binary.BigEndian.PutUint16(b[0:2], p.interfaceMTU)
+	b[2] = p.options
+	b[3] = byte(p.flags & ddFlagsMask)
+	binary.BigEndian.PutUint32(b[4:8], p.sequenceNumber)
+
+	for _, h := range p.lsaHeaders {
+		b = append(b, h.bytes...)
+	}
+
+	return p.marshal(pDD, b)
 }
 
 type LSReq struct {
 	PacketHeader
+	requests []lsdbKey
+}
+
+func parseLSReq(h PacketHeader, data []byte) (*LSReq, error) {
+	if len(data)%lsReqItemLen != 0 {
+		return nil, &ParseError{Offset: len(data), Err: ErrBadLength}
+	}
+
+	req := &LSReq{PacketHeader: h}
+
+	for i := 0; i < len(data); i += lsReqItemLen {
+		t := binary.BigEndian.Uint32(data[i : i+4])
+		if t > 0xff {
+			return nil, &ParseError{Offset: i, Err: fmt.Errorf("invalid LS type %d", t)}
+		}
+
+		req.requests = append(req.requests, lsdbKey{
+			Type:              lsType(t),
+			ID:                addrFromSlice(data[i+4 : i+8]),
+			AdvertisingRouter: common.RouterID(binary.BigEndian.Uint32(data[i+8 : i+12])),
+		})
+	}
+
+	return req, nil
+}
+
+func (p *LSReq) MarshalBinary() ([]byte, error) {
+	b := make([]byte, lsReqItemLen*len(p.requests))
+
+	for i, key := range p.requests {
+		off := i * lsReqItemLen
+		binary.BigEndian.PutUint32(b[off:off+4], uint32(key.Type))
+		putAddr(b[off+4:off+8], key.ID)
+		binary.BigEndian.PutUint32(b[off+8:off+12], uint32(key.AdvertisingRouter))
+	}
+
+	return p.marshal(pLSReq, b)
 }
 
 type LSUpd struct {
 	PacketHeader
+	lsas []LSA
+}
+
+func parseLSUpd(h PacketHeader, data []byte) (*LSUpd, error) {
+	if len(data) < lsUpdLen {
+		return nil, &ParseError{Offset: len(data), Err: ErrTruncated}
+	}
+
+	n := binary.BigEndian.Uint32(data[0:4])
+
+	upd := &LSUpd{PacketHeader: h}
+
+	off := lsUpdLen
+	for i := uint32(0); i < n; i++ {
+		if len(data)-off < lsaHeaderLen {
+			return nil, &ParseError{Offset: len(data), Err: ErrTruncated}
+		}
+
+		length := int(binary.BigEndian.Uint16(data[off+18 : off+20]))
+		if length < lsaHeaderLen {
+			return nil, &ParseError{Offset: off + 18, Err: ErrBadLength}
+		} else if off+length > len(data) {
+			return nil, &ParseError{Offset: len(data), Err: ErrTruncated}
+		}
+
+		lsa, err := ParseLSA(data[off : off+length])
+		if err != nil {
+			return nil, &ParseError{Offset: off, Err: err}
+		}
+
+		upd.lsas = append(upd.lsas, lsa)
+		off += length
+	}
+
+	if off != len(data) {
+		return nil, &ParseError{Offset: off, Err: ErrBadLength}
+	}
+
+	return upd, nil
+}
+
+func (p *LSUpd) MarshalBinary() ([]byte, error) {
+	b := make([]byte, lsUpdLen)
+	binary.BigEndian.PutUint32(b[0:4], uint32(len(p.lsas)))
+
+	for _, lsa := range p.lsas {
+		b = append(b, lsa.Bytes()...)
+	}
+
+	return p.marshal(pLSUpd, b)
 }
 
 type LSAck struct {
 	PacketHeader
+	lsaHeaders []*lsaHeader
+}
+
+func parseLSAck(h PacketHeader, data []byte) (*LSAck, error) {
+	if len(data)%lsaHeaderLen != 0 {
+		return nil, &ParseError{Offset: len(data), Err: ErrBadLength}
+	}
+
+	ack := &LSAck{PacketHeader: h}
+
+	for i := 0; i < len(data); i += lsaHeaderLen {
+		lsaHeader, err := parseLSAHeader(data[i : i+lsaHeaderLen])
+		if err != nil {
+			return nil, &ParseError{Offset: i, Err: err}
+		}
+
+		ack.lsaHeaders = append(ack.lsaHeaders, lsaHeader)
+	}
+
+	return ack, nil
+}
+
+func (p *LSAck) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, lsaHeaderLen*len(p.lsaHeaders))
+
+	for _, h := range p.lsaHeaders {
+		b = append(b, h.bytes...)
+	}
+
+	return p.marshal(pLSAck, b)
 }
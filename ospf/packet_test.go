@@ -0,0 +1,464 @@
+package ospf
+
+import (
+	"encoding/binary"
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+)
+
+func mustParseRouterID(s string) common.RouterID {
+	addr := netip.MustParseAddr(s)
+	return common.RouterID(binary.BigEndian.Uint32(addr.AsSlice()))
+}
+
+// newTestLSA builds a valid LSA with the given header fields and body.
+func newTestLSA(t lsType, id string, advertisingRouter string, seq int32, body []byte) *lsaBase {
+	b := make([]byte, lsaHeaderLen+len(body))
+
+	binary.BigEndian.PutUint16(b[0:2], 1)
+	b[2] = 0x02
+	b[3] = byte(t)
+	putAddr(b[4:8], netip.MustParseAddr(id))
+	binary.BigEndian.PutUint32(b[8:12], uint32(mustParseRouterID(advertisingRouter)))
+	binary.BigEndian.PutUint32(b[12:16], uint32(seq))
+	binary.BigEndian.PutUint16(b[18:20], uint16(len(b)))
+	copy(b[lsaHeaderLen:], body)
+
+	binary.BigEndian.PutUint16(b[16:18], fletcher16GenerateChecksum(b[2:], 14))
+
+	lsa, err := parseLSA(b)
+	if err != nil {
+		panic(err)
+	}
+
+	return lsa
+}
+
+func testHeader() PacketHeader {
+	return PacketHeader{
+		routerID: mustParseRouterID("1.1.1.1"),
+		areaID:   common.AreaID(1),
+		authType: uint16(AuthTypeNull),
+	}
+}
+
+func roundTrip(t *testing.T, p Packet) Packet {
+	b, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ipChecksum(b[:16], b[24:]) != 0 {
+		t.Fatalf("marshaled packet has invalid checksum")
+	}
+
+	parsed, err := ParsePacket(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := parsed.Header()
+	if int(h.length) != len(b) {
+		t.Fatalf("expected length %d, got %d", len(b), h.length)
+	}
+
+	if h.routerID != p.Header().routerID || h.areaID != p.Header().areaID {
+		t.Fatalf("header mismatch: expected %+v, got %+v", p.Header(), h)
+	}
+
+	b2, err := parsed.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(b, b2) {
+		t.Fatalf("re-marshaled packet differs:\n%x\n%x", b, b2)
+	}
+
+	return parsed
+}
+
+func TestIPChecksum(t *testing.T) {
+	// Example from RFC 1071, section 3.
+	data := []byte{0x00, 0x01, 0xf2, 0x03, 0xf4, 0xf5, 0xf6, 0xf7}
+
+	if c := ipChecksum(data); c != 0x220d {
+		t.Fatalf("expected 0x220d, got %#04x", c)
+	}
+
+	if c := ipChecksum(data[:3], data[3:]); c != 0x220d {
+		t.Fatalf("split at odd offset: expected 0x220d, got %#04x", c)
+	}
+
+	if c := ipChecksum(data[:7]); c != 0x2304 {
+		t.Fatalf("odd length: got %#04x", c)
+	}
+}
+
+func TestHelloRoundTrip(t *testing.T) {
+	hello := &Hello{
+		PacketHeader:           testHeader(),
+		networkMask:            netip.MustParseAddr("255.255.255.0"),
+		helloInterval:          10,
+		options:                0x02,
+		routerPriority:         1,
+		routerDeadInterval:     40,
+		designatedRouter:       netip.MustParseAddr("10.0.0.1"),
+		backupDesignatedRouter: netip.MustParseAddr("10.0.0.2"),
+		neighbors:              []common.RouterID{mustParseRouterID("2.2.2.2"), mustParseRouterID("3.3.3.3")},
+	}
+
+	parsed, ok := roundTrip(t, hello).(*Hello)
+	if !ok {
+		t.Fatalf("expected *Hello")
+	}
+
+	if parsed.t != pHello || parsed.length != packetHeaderLen+helloLen+8 {
+		t.Fatalf("bad header: %+v", parsed.PacketHeader)
+	}
+
+	hello.PacketHeader = parsed.PacketHeader
+	if !reflect.DeepEqual(hello, parsed) {
+		t.Fatalf("expected %+v, got %+v", hello, parsed)
+	}
+}
+
+func TestHelloNoNeighbors(t *testing.T) {
+	hello := &Hello{
+		PacketHeader:           testHeader(),
+		networkMask:            netip.MustParseAddr("255.255.255.252"),
+		helloInterval:          10,
+		routerDeadInterval:     40,
+		designatedRouter:       netip.IPv4Unspecified(),
+		backupDesignatedRouter: netip.IPv4Unspecified(),
+	}
+
+	parsed := roundTrip(t, hello).(*Hello)
+	if len(parsed.neighbors) != 0 {
+		t.Fatalf("expected no neighbors, got %v", parsed.neighbors)
+	}
+}
+
+func TestDDRoundTrip(t *testing.T) {
+	lsa1 := newTestLSA(lsTypeRouter, "1.1.1.1", "1.1.1.1", initialSequenceNumber, make([]byte, 4))
+	lsa2 := newTestLSA(lsTypeNetwork, "10.0.0.1", "1.1.1.1", initialSequenceNumber+5, make([]byte, 8))
+
+	dd := &DD{
+		PacketHeader:   testHeader(),
+		interfaceMTU:   1500,
+		options:        0x42,
+		flags:          ddFlagI | ddFlagM | ddFlagMS,
+		sequenceNumber: 0xdeadbeef,
+		lsaHeaders:     []*lsaHeader{&lsa1.lsaHeader, &lsa2.lsaHeader},
+	}
+
+	parsed := roundTrip(t, dd).(*DD)
+
+	if parsed.interfaceMTU != 1500 || parsed.options != 0x42 || parsed.flags != ddFlagI|ddFlagM|ddFlagMS || parsed.sequenceNumber != 0xdeadbeef {
+		t.Fatalf("unexpected DD: %+v", parsed)
+	}
+
+	if len(parsed.lsaHeaders) != 2 {
+		t.Fatalf("expected 2 LSA headers, got %d", len(parsed.lsaHeaders))
+	}
+
+	for i, h := range []*lsaHeader{&lsa1.lsaHeader, &lsa2.lsaHeader} {
+		if !reflect.DeepEqual(h, parsed.lsaHeaders[i]) {
+			t.Fatalf("LSA header %d: expected %+v, got %+v", i, h, parsed.lsaHeaders[i])
+		}
+	}
+}
+
+func TestDDIgnoresReservedFlags(t *testing.T) {
+	dd := &DD{PacketHeader: testHeader(), flags: ddFlagI}
+
+	b, err := dd.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// set reserved bits and fix up the checksum
+	b[packetHeaderLen+3] |= 0xf8
+	binary.BigEndian.PutUint16(b[12:14], 0)
+	binary.BigEndian.PutUint16(b[12:14], ipChecksum(b[:16], b[24:]))
+
+	p, err := ParsePacket(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.(*DD).flags != ddFlagI {
+		t.Fatalf("expected flags I, got %s", p.(*DD).flags)
+	}
+}
+
+func TestLSReqRoundTrip(t *testing.T) {
+	req := &LSReq{
+		PacketHeader: testHeader(),
+		requests: []lsdbKey{
+			{Type: lsTypeRouter, ID: netip.MustParseAddr("1.1.1.1"), AdvertisingRouter: mustParseRouterID("1.1.1.1")},
+			{Type: lsTypeASExternal, ID: netip.MustParseAddr("192.168.0.0"), AdvertisingRouter: mustParseRouterID("2.2.2.2")},
+		},
+	}
+
+	parsed := roundTrip(t, req).(*LSReq)
+
+	if !reflect.DeepEqual(req.requests, parsed.requests) {
+		t.Fatalf("expected %v, got %v", req.requests, parsed.requests)
+	}
+}
+
+func TestLSUpdRoundTrip(t *testing.T) {
+	lsa1 := newTestLSA(lsTypeRouter, "1.1.1.1", "1.1.1.1", initialSequenceNumber, make([]byte, 4))
+	lsa2 := newTestLSA(lsTypeSummary, "10.1.0.0", "1.1.1.1", initialSequenceNumber, []byte{255, 255, 0, 0, 0, 0, 0, 10})
+
+	upd := &LSUpd{
+		PacketHeader: testHeader(),
+		lsas:         []LSA{lsa1, lsa2},
+	}
+
+	parsed := roundTrip(t, upd).(*LSUpd)
+
+	if len(parsed.lsas) != 2 {
+		t.Fatalf("expected 2 LSAs, got %d", len(parsed.lsas))
+	}
+
+	for i, lsa := range []LSA{lsa1, lsa2} {
+		if !reflect.DeepEqual(lsa.Bytes(), parsed.lsas[i].Bytes()) {
+			t.Fatalf("LSA %d: expected %x, got %x", i, lsa.Bytes(), parsed.lsas[i].Bytes())
+		}
+
+		if !parsed.lsas[i].IsChecksumValid() {
+			t.Fatalf("LSA %d: invalid checksum", i)
+		}
+	}
+}
+
+func TestLSAckRoundTrip(t *testing.T) {
+	lsa := newTestLSA(lsTypeRouter, "1.1.1.1", "1.1.1.1", initialSequenceNumber, make([]byte, 4))
+
+	ack := &LSAck{
+		PacketHeader: testHeader(),
+		lsaHeaders:   []*lsaHeader{&lsa.lsaHeader},
+	}
+
+	parsed := roundTrip(t, ack).(*LSAck)
+
+	if len(parsed.lsaHeaders) != 1 || !reflect.DeepEqual(&lsa.lsaHeader, parsed.lsaHeaders[0]) {
+		t.Fatalf("unexpected LSA headers: %v", parsed.lsaHeaders)
+	}
+}
+
+func TestLSAHeaderSharesBytesWithLSA(t *testing.T) {
+	lsa := newTestLSA(lsTypeRouter, "1.1.1.1", "1.1.1.1", initialSequenceNumber, make([]byte, 4))
+
+	lsa.SetAge(maxAge)
+
+	if binary.BigEndian.Uint16(lsa.lsaHeader.bytes[0:2]) != maxAge {
+		t.Fatalf("expected header bytes to be updated by SetAge")
+	}
+
+	if !lsa.IsChecksumValid() {
+		t.Fatalf("checksum should not cover age")
+	}
+}
+
+func marshalHello(t *testing.T) []byte {
+	hello := &Hello{
+		PacketHeader:  testHeader(),
+		networkMask:   netip.MustParseAddr("255.255.255.0"),
+		helloInterval: 10,
+	}
+
+	b, err := hello.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func assertParseError(t *testing.T, b []byte, expected error, typ packetType, offset int) {
+	t.Helper()
+
+	_, err := ParsePacket(b)
+
+	if !errors.Is(err, expected) {
+		t.Fatalf("expected %v, got %v", expected, err)
+	}
+
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *ParseError, got %T", err)
+	}
+
+	if perr.Type != typ || perr.Offset != offset {
+		t.Fatalf("expected type %s offset %d, got type %s offset %d", typ, offset, perr.Type, perr.Offset)
+	}
+}
+
+func TestParsePacketErrors(t *testing.T) {
+	b := marshalHello(t)
+	assertParseError(t, b[:10], ErrTruncated, pUnknown, 10)
+
+	b = marshalHello(t)
+	b[0] = 3
+	assertParseError(t, b, ErrBadVersion, pUnknown, 0)
+
+	b = marshalHello(t)
+	b[1] = 6
+	assertParseError(t, b, ErrUnknownPacketType, pUnknown, 1)
+
+	b = marshalHello(t)
+	binary.BigEndian.PutUint16(b[2:4], uint16(len(b)+1))
+	assertParseError(t, b, ErrBadLength, pHello, 2)
+
+	b = marshalHello(t)
+	binary.BigEndian.PutUint16(b[2:4], 20)
+	assertParseError(t, b, ErrBadLength, pHello, 2)
+
+	b = marshalHello(t)
+	b[30]++
+	assertParseError(t, b, ErrBadChecksum, pHello, 12)
+}
+
+func TestParsePacketBodyErrors(t *testing.T) {
+	// A Hello with a truncated body: valid header, but only 8 bytes of body.
+	hello := marshalHello(t)[:packetHeaderLen+8]
+	binary.BigEndian.PutUint16(hello[2:4], uint16(len(hello)))
+	binary.BigEndian.PutUint16(hello[12:14], 0)
+	binary.BigEndian.PutUint16(hello[12:14], ipChecksum(hello[:16], hello[24:]))
+
+	assertParseError(t, hello, ErrTruncated, pHello, packetHeaderLen+8)
+
+	// An LSUpd that claims to contain more LSAs than it does.
+	lsa := newTestLSA(lsTypeRouter, "1.1.1.1", "1.1.1.1", initialSequenceNumber, make([]byte, 4))
+	upd := &LSUpd{PacketHeader: testHeader(), lsas: []LSA{lsa}}
+
+	b, err := upd.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary.BigEndian.PutUint32(b[24:28], 2)
+	binary.BigEndian.PutUint16(b[12:14], 0)
+	binary.BigEndian.PutUint16(b[12:14], ipChecksum(b[:16], b[24:]))
+
+	assertParseError(t, b, ErrTruncated, pLSUpd, len(b))
+}
+
+func TestParsePacketIgnoresTrailingData(t *testing.T) {
+	b := append(marshalHello(t), 1, 2, 3, 4)
+
+	p, err := ParsePacket(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if int(p.Header().length) != len(b)-4 {
+		t.Fatalf("unexpected length %d", p.Header().length)
+	}
+}
+
+func TestCryptographicAuthSkipsChecksum(t *testing.T) {
+	h := testHeader()
+	h.authType = uint16(AuthTypeCryptographic)
+	h.authData = 0x0000011000000001
+
+	hello := &Hello{PacketHeader: h, helloInterval: 10}
+
+	b, err := hello.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if binary.BigEndian.Uint16(b[12:14]) != 0 {
+		t.Fatalf("expected zero checksum")
+	}
+
+	p, err := ParsePacket(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Header().authData != h.authData {
+		t.Fatalf("expected auth data %#x, got %#x", h.authData, p.Header().authData)
+	}
+}
+
+func FuzzParsePacket(f *testing.F) {
+	lsa := newTestLSA(lsTypeRouter, "1.1.1.1", "1.1.1.1", initialSequenceNumber, make([]byte, 4))
+
+	packets := []Packet{
+		&Hello{PacketHeader: testHeader(), helloInterval: 10, neighbors: []common.RouterID{1, 2}},
+		&DD{PacketHeader: testHeader(), flags: ddFlagI | ddFlagM | ddFlagMS, lsaHeaders: []*lsaHeader{&lsa.lsaHeader}},
+		&LSReq{PacketHeader: testHeader(), requests: []lsdbKey{lsa.Key()}},
+		&LSUpd{PacketHeader: testHeader(), lsas: []LSA{lsa}},
+		&LSAck{PacketHeader: testHeader(), lsaHeaders: []*lsaHeader{&lsa.lsaHeader}},
+	}
+
+	for _, p := range packets {
+		b, err := p.MarshalBinary()
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p, err := ParsePacket(data)
+		if err != nil {
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected *ParseError, got %T: %v", err, err)
+			}
+
+			return
+		}
+
+		b, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p2, err := ParsePacket(b)
+		if err != nil {
+			t.Fatalf("failed to parse re-marshaled packet: %v", err)
+		}
+
+		b2, err := p2.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(b, b2) {
+			t.Fatalf("round trip mismatch:\n%x\n%x", b, b2)
+		}
+	})
+}
+
+func FuzzParseLSAHeader(f *testing.F) {
+	lsa := newTestLSA(lsTypeRouter, "1.1.1.1", "1.1.1.1", initialSequenceNumber, make([]byte, 4))
+	f.Add(lsa.Bytes())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		h, err := parseLSAHeader(data)
+		if len(data) < lsaHeaderLen {
+			if err == nil {
+				t.Fatalf("expected error for %d bytes", len(data))
+			}
+			return
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(h.bytes, data[:lsaHeaderLen]) {
+			t.Fatalf("expected %x, got %x", data[:lsaHeaderLen], h.bytes)
+		}
+	})
+}
@@ -0,0 +1,705 @@
+package ospf
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"github.com/davidbalbert/chatter/config"
+)
+
+// Graceful restart, described in RFC 3623. Before a planned restart, we
+// originate a grace-LSA on each interface, asking our neighbors to keep
+// advertising their adjacencies with us for a grace period. They act as
+// helpers: as long as the topology doesn't change, the rest of the routing
+// domain keeps forwarding through us while we restart. After restarting, we
+// keep forwarding with our old routes and don't originate any LSAs until
+// we've resynchronized our database with every neighbor we were adjacent to.
+
+// opaqueTypeGrace is the opaque type of grace-LSAs, which are link-local
+// opaque LSAs.
+const opaqueTypeGrace = 3
+
+// graceAckTimeout is how long we wait for our grace-LSAs to be
+// acknowledged before restarting anyway.
+const graceAckTimeout = 10 * time.Second
+
+// Grace-LSA TLV types. See RFC 3623, appendix A.
+const (
+	graceTLVPeriod  = 1
+	graceTLVReason  = 2
+	graceTLVAddress = 3
+)
+
+// RestartReason is why a router is restarting gracefully.
+type RestartReason uint8
+
+const (
+	RestartUnknown RestartReason = iota
+	RestartSoftwareRestart
+	RestartSoftwareUpgrade
+	RestartSwitchover
+)
+
+func (r RestartReason) String() string {
+	switch r {
+	case RestartUnknown:
+		return "unknown"
+	case RestartSoftwareRestart:
+		return "software restart"
+	case RestartSoftwareUpgrade:
+		return "software upgrade"
+	case RestartSwitchover:
+		return "switch to redundant control processor"
+	default:
+		return fmt.Sprintf("RestartReason(%d)", r)
+	}
+}
+
+// graceInfo is the contents of a grace-LSA. addr is the restarting
+// router's address on the network, which is required on broadcast, NBMA
+// and point-to-multipoint networks.
+type graceInfo struct {
+	period uint32 // seconds
+	reason RestartReason
+	addr   netip.Addr
+}
+
+func isGraceLSA(lsa LSAMetadata) bool {
+	return lsa.Type() == lsTypeOpaqueLink && opaqueTypeOf(lsa.ID()) == opaqueTypeGrace
+}
+
+func appendTLV(b []byte, t uint16, value []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, t)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(value)))
+	b = append(b, value...)
+
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+
+	return b
+}
+
+func newGraceLSA(h lsaHeader, g graceInfo) *opaqueLSA {
+	h.id = opaqueLSAID(opaqueTypeGrace, 0)
+
+	var data []byte
+	data = appendTLV(data, graceTLVPeriod, binary.BigEndian.AppendUint32(nil, g.period))
+	data = appendTLV(data, graceTLVReason, []byte{uint8(g.reason)})
+	if g.addr.IsValid() {
+		data = appendTLV(data, graceTLVAddress, g.addr.AsSlice())
+	}
+
+	return newOpaqueLSA(h, lsTypeOpaqueLink, data)
+}
+
+// parseGraceLSA returns the contents of a grace-LSA. Unknown TLVs are
+// ignored, but the grace period is required.
+func parseGraceLSA(lsa LSA) (graceInfo, error) {
+	var g graceInfo
+	var hasPeriod bool
+
+	data := lsa.Bytes()[lsaHeaderLen:]
+
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return g, fmt.Errorf("truncated TLV")
+		}
+
+		t := binary.BigEndian.Uint16(data[0:2])
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		padded := (length + 3) &^ 3
+
+		if len(data) < 4+padded {
+			return g, fmt.Errorf("TLV %d too long: %d", t, length)
+		}
+
+		value := data[4 : 4+length]
+
+		switch t {
+		case graceTLVPeriod:
+			if length != 4 {
+				return g, fmt.Errorf("grace period TLV has length %d", length)
+			}
+
+			g.period = binary.BigEndian.Uint32(value)
+			hasPeriod = true
+		case graceTLVReason:
+			if length != 1 {
+				return g, fmt.Errorf("restart reason TLV has length %d", length)
+			}
+
+			g.reason = RestartReason(value[0])
+		case graceTLVAddress:
+			if length != 4 {
+				return g, fmt.Errorf("interface address TLV has length %d", length)
+			}
+
+			g.addr = netip.AddrFrom4([4]byte(value))
+		}
+
+		data = data[4+padded:]
+	}
+
+	if !hasPeriod {
+		return g, fmt.Errorf("no grace period")
+	}
+
+	return g, nil
+}
+
+// A gracefulRestart is a graceful restart in progress. The instance that's
+// shutting down has one from when it originates its grace-LSAs, and its
+// replacement has one until it's resynchronized its database, or the grace
+// period ends.
+type gracefulRestart struct {
+	deadline time.Time
+	reason   RestartReason
+	timer    *time.Timer // ends the grace period; nil in the instance that's shutting down
+}
+
+// restartState is handed from an instance that's shutting down to its
+// replacement. Unless we're restarting gracefully, only maxMetric is set.
+type restartState struct {
+	routerID  common.RouterID
+	maxMetric bool // we were advertising MaxLinkMetric on request
+	rib       routingTable
+	deadline  time.Time // zero unless we're restarting gracefully
+	reason    RestartReason
+}
+
+// PrepareRestart asks our neighbors to help us restart gracefully by
+// originating a grace-LSA on each interface, and waits for them to be
+// acknowledged. From then on, we don't originate any other LSAs, so
+// shutting down doesn't tell the rest of the routing domain that our
+// adjacencies are gone. It returns the routes our replacement keeps
+// forwarding with if graceful restart is enabled. Either way, it tells our
+// replacement whether to keep advertising MaxLinkMetric. See RFC 3623,
+// section 2.1.
+func (i *Instance) PrepareRestart(ctx context.Context) any {
+	conf := i.config.GracefulRestart
+
+	i.mu.Lock()
+
+	state := &restartState{
+		routerID:  i.RouterID,
+		maxMetric: i.maxMetric,
+	}
+
+	if !conf.Enabled {
+		i.mu.Unlock()
+		return state
+	}
+
+	r := &gracefulRestart{
+		deadline: time.Now().Add(time.Duration(conf.GracePeriod) * time.Second),
+		reason:   RestartSoftwareRestart,
+	}
+	i.restart = r
+
+	for _, iface := range i.Interfaces {
+		if iface.State == iDown || iface.State == iLoopback || iface.Passive {
+			continue
+		}
+
+		i.originateLSA(iface.scopeFor(lsTypeOpaqueLink), iface.buildGraceLSA(conf.GracePeriod, r.reason))
+	}
+
+	state.rib = i.rib
+	state.deadline = r.deadline
+	state.reason = r.reason
+
+	i.mu.Unlock()
+
+	fmt.Printf("ospf: restarting gracefully, grace period %ds\n", conf.GracePeriod)
+
+	ctx, cancel := context.WithTimeout(ctx, graceAckTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		i.mu.Lock()
+		acked := !i.hasUnacknowledgedGraceLSAs()
+		i.mu.Unlock()
+
+		if acked {
+			return state
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Printf("ospf: restarting before every grace-LSA was acknowledged\n")
+			return state
+		case <-ticker.C:
+		}
+	}
+}
+
+func (i *Interface) buildGraceLSA(period uint32, reason RestartReason) LSA {
+	h := lsaHeader{
+		options:           i.area().options(),
+		advertisingRouter: i.instance.RouterID,
+	}
+
+	return newGraceLSA(h, graceInfo{
+		period: period,
+		reason: reason,
+		addr:   i.Prefix.Addr(),
+	})
+}
+
+// hasUnacknowledgedGraceLSAs reports whether any neighbor has one of our
+// grace-LSAs on its retransmission list.
+func (i *Instance) hasUnacknowledgedGraceLSAs() bool {
+	for _, iface := range i.Interfaces {
+		for _, n := range iface.Neighbors {
+			for _, h := range n.RetransmissionList {
+				if isGraceLSA(h) && h.AdvertisingRouter() == i.RouterID {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// Restart continues a graceful restart begun by the instance we're
+// replacing, which must have had the same router ID. Until it's finished,
+// we keep forwarding with the old instance's routes, and the rest of the
+// routing domain keeps using the LSAs it originated. See RFC 3623, section
+// 2.2. If the old instance was advertising MaxLinkMetric on request, so do
+// we, whether or not we're restarting gracefully.
+func (i *Instance) Restart(state any) {
+	s, ok := state.(*restartState)
+	if !ok || s == nil {
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if s.maxMetric {
+		i.maxMetric = true
+	}
+
+	if s.deadline.IsZero() {
+		return
+	}
+
+	if s.routerID != i.RouterID {
+		fmt.Printf("ospf: not restarting gracefully: router ID changed from %s to %s\n", s.routerID, i.RouterID)
+		return
+	}
+
+	remaining := time.Until(s.deadline)
+	if remaining <= 0 {
+		return
+	}
+
+	r := &gracefulRestart{
+		deadline: s.deadline,
+		reason:   s.reason,
+	}
+
+	r.timer = time.AfterFunc(remaining, func() {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+
+		if i.restart == r {
+			i.finishRestart("grace period expired")
+		}
+	})
+
+	i.restart = r
+	i.rib = s.rib
+}
+
+// A RestartSnapshot describes graceful restart, for reporting.
+// GraceRemaining and Reason are only set while we're restarting.
+type RestartSnapshot struct {
+	config.OSPFGracefulRestartConfig
+	Restarting     bool
+	GraceRemaining time.Duration
+	Reason         RestartReason
+}
+
+func (inst *Instance) restartSnapshot() RestartSnapshot {
+	s := RestartSnapshot{
+		OSPFGracefulRestartConfig: inst.config.GracefulRestart,
+	}
+
+	if r := inst.restart; r != nil && r.timer != nil {
+		s.Restarting = true
+		s.GraceRemaining = time.Until(r.deadline)
+		s.Reason = r.reason
+	}
+
+	return s
+}
+
+// isRestarting reports whether we're restarting gracefully.
+func (inst *Instance) isRestarting() bool {
+	return inst.restart != nil
+}
+
+func (inst *Instance) stopRestart() {
+	if inst.restart != nil && inst.restart.timer != nil {
+		inst.restart.timer.Stop()
+	}
+}
+
+// checkRestart finishes a graceful restart once we're fully adjacent again
+// with every neighbor we were adjacent to before restarting. See RFC 3623,
+// section 2.3.
+func (inst *Instance) checkRestart() {
+	if inst.restart == nil || inst.restart.timer == nil {
+		return
+	}
+
+	for _, area := range inst.Areas {
+		if !inst.adjacenciesRestored(area) {
+			return
+		}
+	}
+
+	inst.finishRestart("adjacencies reestablished")
+}
+
+// adjacenciesRestored reports whether every adjacency described by the
+// router-LSA we originated in the area before restarting is Full again. We
+// learn that LSA from our neighbors. If we haven't, we can only be sure
+// that there weren't any adjacencies if none of the area's interfaces can
+// have neighbors.
+func (inst *Instance) adjacenciesRestored(area *Area) bool {
+	ifaces := inst.areaInterfaces(area.ID)
+
+	self := routerLSAFor(area, addrFromRouterID(inst.RouterID))
+	if self == nil {
+		for _, iface := range ifaces {
+			if iface.State != iDown && iface.State != iLoopback && !iface.Passive {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for _, link := range self.Links() {
+		switch link.Type {
+		case linkPointToPoint, linkVirtual:
+			if !hasFullNeighbor(ifaces, func(n *Neighbor) bool {
+				return n.ID == routerIDFromAddr(link.ID)
+			}) {
+				return false
+			}
+		case linkTransit:
+			var iface *Interface
+			for _, i := range ifaces {
+				if i.Prefix.Addr() == link.Data {
+					iface = i
+				}
+			}
+
+			if iface == nil {
+				return false
+			}
+
+			if link.ID != link.Data {
+				if !hasFullNeighbor([]*Interface{iface}, func(n *Neighbor) bool {
+					return n.Addr == link.ID
+				}) {
+					return false
+				}
+
+				continue
+			}
+
+			// We were DR, so our network-LSA lists the routers we were
+			// adjacent to.
+			key := lsdbKey{Type: lsTypeNetwork, ID: link.ID, AdvertisingRouter: inst.RouterID}
+			lsa, ok := area.lsdb[key]
+			if !ok || lsa.Age() == maxAge {
+				return false
+			}
+
+			network, ok := lsa.LSA.(*networkLSA)
+			if !ok {
+				return false
+			}
+
+			for _, id := range network.AttachedRouters() {
+				if id == inst.RouterID {
+					continue
+				}
+
+				if n, ok := iface.Neighbors[id]; !ok || n.state != nFull {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+func hasFullNeighbor(ifaces []*Interface, match func(n *Neighbor) bool) bool {
+	for _, iface := range ifaces {
+		for _, n := range iface.Neighbors {
+			if n.state == nFull && match(n) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// checkRestartConsistency finishes a graceful restart early if a neighbor
+// that our old router-LSA lists stops listing us in its own router-LSA,
+// which means it's stopped helping. See RFC 3623, section 2.3.
+func (inst *Instance) checkRestartConsistency(s lsaScope, lsa LSA) {
+	if inst.restart == nil || inst.restart.timer == nil || lsa.Type() != lsTypeRouter || lsa.AdvertisingRouter() == inst.RouterID {
+		return
+	}
+
+	area, ok := inst.Areas[s.areaID]
+	if !ok {
+		return
+	}
+
+	self := routerLSAFor(area, addrFromRouterID(inst.RouterID))
+	if self == nil || !listsRouter(self, lsa.AdvertisingRouter()) {
+		return
+	}
+
+	other, ok := lsa.(*routerLSA)
+	if ok && lsa.Age() < maxAge && listsRouter(other, inst.RouterID) {
+		return
+	}
+
+	inst.finishRestart(fmt.Sprintf("%s no longer lists us in its router-LSA", lsa.AdvertisingRouter()))
+}
+
+// listsRouter reports whether a router-LSA has a point-to-point link or
+// virtual link to id.
+func listsRouter(lsa *routerLSA, id common.RouterID) bool {
+	for _, link := range lsa.Links() {
+		if (link.Type == linkPointToPoint || link.Type == linkVirtual) && link.ID == addrFromRouterID(id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// finishRestart leaves graceful restart. We calculate our routes and
+// replace the ones we kept from before restarting, originate our LSAs, and
+// flush the ones we no longer originate, including our grace-LSAs. See RFC
+// 3623, section 2.3.
+func (inst *Instance) finishRestart(reason string) {
+	inst.stopRestart()
+	inst.restart = nil
+
+	fmt.Printf("ospf: graceful restart finished: %s\n", reason)
+
+	inst.stopSPF()
+	inst.runSPF()
+
+	inst.scheduleRouterLSAs()
+	for _, iface := range inst.Interfaces {
+		iface.scheduleNetworkLSA()
+	}
+
+	for _, o := range inst.originations {
+		if o.timer == nil {
+			inst.runOrigination(o, false)
+		}
+	}
+
+	inst.flushUnoriginatedLSAs()
+}
+
+// flushUnoriginatedLSAs flushes the LSAs we originated before restarting
+// that we no longer originate.
+func (inst *Instance) flushUnoriginatedLSAs() {
+	flush := func(s lsaScope, db lsdb) {
+		for key, lsa := range db {
+			if lsa.Age() == maxAge || !inst.isSelfOriginated(lsa) {
+				continue
+			}
+
+			if _, ok := inst.originations[newScopedOriginationKey(s, key)]; !ok {
+				inst.flushLSA(s, lsa.LSA)
+			}
+		}
+	}
+
+	for id, area := range inst.Areas {
+		flush(areaScope(id), area.lsdb)
+	}
+
+	flush(areaScope(0), inst.lsdb)
+
+	for _, iface := range inst.Interfaces {
+		flush(iface.scopeFor(lsTypeOpaqueLink), iface.lsdb)
+	}
+}
+
+// A restartHelper is the state of a neighbor that's restarting gracefully
+// with our help. See RFC 3623, section 3.
+type restartHelper struct {
+	deadline time.Time
+	reason   RestartReason
+	timer    *time.Timer
+}
+
+// handleGraceLSA starts helping a neighbor that's asked to restart
+// gracefully, if we can, and stops helping when it flushes its grace-LSA.
+// See RFC 3623, section 3.1.
+func (inst *Instance) handleGraceLSA(s lsaScope, lsa LSA) {
+	i := s.iface
+
+	if lsa.AdvertisingRouter() == inst.RouterID {
+		return
+	}
+
+	n, ok := i.Neighbors[lsa.AdvertisingRouter()]
+	if !ok {
+		return
+	}
+
+	if lsa.Age() == maxAge {
+		if n.helper != nil {
+			n.stopHelping("grace-LSA flushed")
+		}
+
+		return
+	}
+
+	g, err := parseGraceLSA(lsa)
+	if err != nil {
+		fmt.Printf("ospf: %s %s: ignoring grace-LSA from %s: %v\n", i.name, i.Prefix, n.ID, err)
+		return
+	}
+
+	if reason := inst.refuseToHelp(n, lsa, g); reason != "" {
+		fmt.Printf("ospf: %s %s: not helping %s restart: %s\n", i.name, i.Prefix, n.ID, reason)
+
+		if n.helper != nil {
+			n.stopHelping(reason)
+		}
+
+		return
+	}
+
+	remaining := time.Duration(g.period)*time.Second - time.Duration(lsa.Age())*time.Second
+
+	if n.helper == nil {
+		fmt.Printf("ospf: %s %s: helping %s restart (%s), grace period ends in %s\n", i.name, i.Prefix, n.ID, g.reason, remaining)
+	} else {
+		n.helper.timer.Stop()
+	}
+
+	h := &restartHelper{
+		deadline: time.Now().Add(remaining),
+		reason:   g.reason,
+	}
+
+	h.timer = time.AfterFunc(remaining, func() {
+		inst.mu.Lock()
+		defer inst.mu.Unlock()
+
+		if n.helper == h && !n.isDestroyed() {
+			n.stopHelping("grace period expired")
+		}
+	})
+
+	n.helper = h
+}
+
+// refuseToHelp returns why we can't help n restart, or "" if we can. A
+// neighbor we're already helping can extend its grace period.
+func (inst *Instance) refuseToHelp(n *Neighbor, lsa LSA, g graceInfo) string {
+	conf := inst.config.GracefulRestart
+
+	switch {
+	case !conf.Helper:
+		return "helper mode is disabled"
+	case inst.isRestarting():
+		return "we're restarting"
+	case n.helper == nil && n.state != nFull:
+		return "not fully adjacent"
+	case uint32(lsa.Age()) >= g.period:
+		return "grace period expired"
+	case conf.StrictLSAChecking && n.helper == nil && n.retransmittingChanges():
+		return "topology changed"
+	default:
+		return ""
+	}
+}
+
+// retransmittingChanges reports whether the neighbor's retransmission list
+// has an LSA whose contents changed, rather than being refreshed, other
+// than a grace-LSA.
+func (n *Neighbor) retransmittingChanges() bool {
+	for _, h := range n.RetransmissionList {
+		lsa, ok := n.iface.lookupLSA(h.Key())
+		if ok && !lsa.refresh && !isGraceLSA(h) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkHelping stops helping restarting neighbors that lsa will be flooded
+// to, if strict LSA checking is enabled. lsa has changed, and the topology
+// that a restarting neighbor keeps forwarding with is out of date. See RFC
+// 3623, section 3.2.
+func (inst *Instance) checkHelping(s lsaScope, lsa LSA) {
+	if !inst.config.GracefulRestart.StrictLSAChecking || isGraceLSA(lsa) {
+		return
+	}
+
+	for _, iface := range inst.floodingScope(lsa.Type(), s) {
+		for _, n := range iface.Neighbors {
+			if n.helper != nil && n.exchangesLSType(lsa.Type()) {
+				n.stopHelping(fmt.Sprintf("%v changed", lsa.Key()))
+			}
+		}
+	}
+}
+
+// stopHelping leaves helper mode. Our LSAs describe our adjacency with the
+// neighbor as it really is again, which might mean electing a new DR, and
+// if we haven't heard from it in RouterDeadInterval, it's killed. See RFC
+// 3623, section 3.2.
+func (n *Neighbor) stopHelping(reason string) {
+	n.helper.timer.Stop()
+	n.helper = nil
+
+	fmt.Printf("ospf: %s %s: stopped helping %s restart: %s\n", n.iface.name, n.iface.Prefix, n.ID, reason)
+
+	n.iface.handleEvent(ieNeighborChange)
+	n.iface.instance.scheduleRouterLSAs()
+	n.iface.scheduleNetworkLSA()
+
+	// The inactivity timer is ignored while we're helping, so it might
+	// already have fired.
+	if !time.Now().Before(n.inactivityDeadline) {
+		n.InactivityTimer.Reset(0)
+	}
+}
+
+// isAdvertisedAsFull reports whether our LSAs describe the neighbor as
+// fully adjacent: either it's Full, or it's restarting with our help.
+func (n *Neighbor) isAdvertisedAsFull() bool {
+	return n.state == nFull || n.helper != nil
+}
@@ -0,0 +1,445 @@
+package ospf
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+	"github.com/davidbalbert/chatter/net/netmon"
+	"golang.org/x/sync/errgroup"
+)
+
+func testGraceLSA(advertisingRouter string, period uint32, addr string) LSA {
+	h := testLSAHeader(advertisingRouter, initialSequenceNumber)
+
+	return newGraceLSA(h, graceInfo{
+		period: period,
+		reason: RestartSoftwareRestart,
+		addr:   netip.MustParseAddr(addr),
+	})
+}
+
+// newTestHelper returns a flooding interface whose instance helps
+// neighbors restart, with strict LSA checking.
+func newTestHelper(t *testing.T) (*Interface, []*Neighbor) {
+	t.Helper()
+
+	iface, nbrs, _ := newTestFloodingInterface(t)
+	iface.instance.config.GracefulRestart = config.OSPFGracefulRestartConfig{
+		Helper:            true,
+		StrictLSAChecking: true,
+	}
+
+	// Originate our LSAs up front, and pretend they've been acknowledged,
+	// so that they don't count as changes that end the grace period.
+	iface.instance.mu.Lock()
+	iface.instance.scheduleRouterLSAs()
+	iface.scheduleNetworkLSA()
+	for _, n := range nbrs {
+		n.RetransmissionList = nil
+	}
+	iface.instance.mu.Unlock()
+
+	return iface, nbrs
+}
+
+func installGraceLSA(iface *Interface, lsa LSA) {
+	iface.instance.mu.Lock()
+	defer iface.instance.mu.Unlock()
+
+	iface.installLSA(lsa)
+}
+
+func TestGraceLSARoundTrip(t *testing.T) {
+	lsa := reparse(t, testGraceLSA("2.2.2.2", 120, "10.0.0.2"))
+
+	if !isGraceLSA(lsa) {
+		t.Fatalf("expected a grace-LSA, got %v", lsa.Key())
+	}
+
+	g, err := parseGraceLSA(lsa)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := graceInfo{period: 120, reason: RestartSoftwareRestart, addr: netip.MustParseAddr("10.0.0.2")}
+	if g != expected {
+		t.Errorf("expected %+v, got %+v", expected, g)
+	}
+
+	h := testLSAHeader("2.2.2.2", initialSequenceNumber)
+	h.id = opaqueLSAID(opaqueTypeGrace, 0)
+	noPeriod := newOpaqueLSA(h, lsTypeOpaqueLink, appendTLV(nil, graceTLVReason, []byte{1}))
+
+	if _, err := parseGraceLSA(noPeriod); err == nil {
+		t.Errorf("expected an error for a grace-LSA without a grace period")
+	}
+}
+
+func TestRegisterGraceOpaqueType(t *testing.T) {
+	inst := newTestInstance(t, "1.1.1.1", newMemNetwork(), nil)
+
+	if _, err := inst.RegisterOpaqueType(opaqueTypeGrace); err == nil {
+		t.Errorf("expected grace-LSAs' opaque type to be reserved")
+	}
+}
+
+func TestHelperKeepsAdjacency(t *testing.T) {
+	iface, nbrs := newTestHelper(t)
+	inst := iface.instance
+	n := nbrs[0]
+
+	installGraceLSA(iface, testGraceLSA("2.2.2.2", 60, "10.0.0.2"))
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if n.helper == nil {
+		t.Fatalf("expected to be helping %s", n.ID)
+	}
+
+	if d := time.Until(n.helper.deadline); d <= 55*time.Second || d > 60*time.Second {
+		t.Errorf("expected the grace period to end in 60s, got %s", d)
+	}
+
+	// The restarting router has forgotten about us, and its inactivity
+	// timer has fired, but it stays Full.
+	n.handleEvent(ne1WayReceived)
+	n.handleEvent(neInactivityTimer)
+
+	if n.state != nFull || n.isDestroyed() {
+		t.Fatalf("expected %s to stay Full, got %s", n.ID, n.state)
+	}
+
+	// It's resynchronizing its database with us, but we still advertise it
+	// as fully adjacent.
+	n.handleEvent(neSeqNumberMismatch)
+
+	if n.state != nExStart {
+		t.Fatalf("expected %s to be in ExStart, got %s", n.ID, n.state)
+	}
+
+	found := false
+	for _, full := range iface.fullNeighbors() {
+		if full == n {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("expected %s to be advertised as fully adjacent", n.ID)
+	}
+}
+
+func TestHelperStopsWhenGraceLSAFlushed(t *testing.T) {
+	iface, nbrs := newTestHelper(t)
+	inst := iface.instance
+	n := nbrs[0]
+
+	lsa := testGraceLSA("2.2.2.2", 60, "10.0.0.2")
+	installGraceLSA(iface, lsa)
+
+	flushed, _ := ParseLSA(withSequenceNumber(lsa, initialSequenceNumber+1).Bytes())
+	flushed.SetAge(maxAge)
+	installGraceLSA(iface, flushed)
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if n.helper != nil {
+		t.Errorf("expected to stop helping %s", n.ID)
+	}
+}
+
+func TestHelperStrictLSAChecking(t *testing.T) {
+	iface, nbrs := newTestHelper(t)
+	inst := iface.instance
+
+	lsa := testRouterLSA("3.3.3.3", initialSequenceNumber)
+
+	inst.mu.Lock()
+	inst.installLSA(0, lsa)
+	inst.mu.Unlock()
+
+	installGraceLSA(iface, testGraceLSA("2.2.2.2", 60, "10.0.0.2"))
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	// A refresh isn't a change.
+	inst.installLSA(0, withSequenceNumber(lsa, initialSequenceNumber+1))
+
+	if nbrs[0].helper == nil {
+		t.Fatalf("expected to be helping %s", nbrs[0].ID)
+	}
+
+	inst.installLSA(0, testRouterLSAWithLinks("3.3.3.3", 0, testLink(linkStub, "10.3.0.0", "255.255.255.0", 1)))
+
+	if nbrs[0].helper != nil {
+		t.Errorf("expected to stop helping %s after a topology change", nbrs[0].ID)
+	}
+}
+
+func TestHelperWithoutStrictLSAChecking(t *testing.T) {
+	iface, nbrs := newTestHelper(t)
+	inst := iface.instance
+	inst.config.GracefulRestart.StrictLSAChecking = false
+
+	installGraceLSA(iface, testGraceLSA("2.2.2.2", 60, "10.0.0.2"))
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.installLSA(0, testRouterLSAWithLinks("3.3.3.3", 0, testLink(linkStub, "10.3.0.0", "255.255.255.0", 1)))
+
+	if nbrs[0].helper == nil {
+		t.Errorf("expected to keep helping %s", nbrs[0].ID)
+	}
+}
+
+func TestHelperRefuses(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(iface *Interface, n *Neighbor)
+		lsa   func() LSA
+	}{
+		{
+			name: "helper disabled",
+			setup: func(iface *Interface, n *Neighbor) {
+				iface.instance.config.GracefulRestart.Helper = false
+			},
+		},
+		{
+			name: "not Full",
+			setup: func(iface *Interface, n *Neighbor) {
+				n.state = nLoading
+			},
+		},
+		{
+			name: "changed LSA on the retransmission list",
+			setup: func(iface *Interface, n *Neighbor) {
+				lsa := testRouterLSA("3.3.3.3", initialSequenceNumber)
+				iface.installLSA(lsa)
+				n.addToRetransmissionList(lsa)
+			},
+		},
+		{
+			name: "grace period expired",
+			lsa: func() LSA {
+				lsa := testGraceLSA("2.2.2.2", 60, "10.0.0.2")
+				lsa.SetAge(60)
+				return lsa
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			iface, nbrs := newTestHelper(t)
+			inst := iface.instance
+			n := nbrs[0]
+
+			lsa := testGraceLSA("2.2.2.2", 60, "10.0.0.2")
+			if test.lsa != nil {
+				lsa = test.lsa()
+			}
+
+			inst.mu.Lock()
+			defer inst.mu.Unlock()
+
+			if test.setup != nil {
+				test.setup(iface, n)
+			}
+
+			iface.installLSA(lsa)
+
+			if n.helper != nil {
+				t.Errorf("expected not to help %s", n.ID)
+			}
+		})
+	}
+}
+
+func TestHelperGracePeriodExpires(t *testing.T) {
+	iface, nbrs := newTestHelper(t)
+	inst := iface.instance
+	n := nbrs[0]
+
+	// The grace-LSA is a second old, so there's a second left.
+	installGraceLSA(iface, testGraceLSA("2.2.2.2", 2, "10.0.0.2"))
+
+	inst.mu.Lock()
+	helping := n.helper != nil
+	inst.mu.Unlock()
+
+	if !helping {
+		t.Fatalf("expected to be helping %s", n.ID)
+	}
+
+	waitFor(t, inst, 5*time.Second, "the grace period to end", func() bool {
+		return n.helper == nil
+	})
+}
+
+// startRestartableInterface is like startTestInterface, but returns a
+// function that stops the interface.
+func startRestartableInterface(t *testing.T, inst *Instance, netif netmon.Interface) (*Interface, func()) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+
+	stop := func() {
+		cancel()
+		g.Wait()
+	}
+	t.Cleanup(stop)
+
+	inst.addInterface(ctx, g, netif, netif.Prefixes[0])
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	return inst.Interfaces[interfaceID{name: netif.Name, prefix: netif.Prefixes[0]}], stop
+}
+
+func TestGracefulRestartBetweenInstances(t *testing.T) {
+	network := newMemNetwork()
+	confs := map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig()}
+	restarting := config.OSPFGracefulRestartConfig{Enabled: true, GracePeriod: 60}
+	helping := config.OSPFGracefulRestartConfig{Helper: true, StrictLSAChecking: true}
+
+	// r1 has the higher router ID, so it's DR.
+	r1 := newTestInstance(t, "3.3.3.3", network, confs)
+	r1.config.GracefulRestart = restarting
+
+	r2 := newTestInstance(t, "2.2.2.2", network, confs)
+	r2.config.GracefulRestart = helping
+
+	netif1 := testNetif("eth0", 1, "10.0.0.3/24")
+	netif1.Flags |= net.FlagBroadcast
+
+	netif2 := testNetif("eth0", 2, "10.0.0.2/24")
+	netif2.Flags |= net.FlagBroadcast
+
+	i1, stop := startRestartableInterface(t, r1, netif1)
+	i2 := startTestInterface(t, r2, netif2)
+
+	r1Key := lsdbKey{Type: lsTypeRouter, ID: netip.MustParseAddr("3.3.3.3"), AdvertisingRouter: r1.RouterID}
+	r2Key := lsdbKey{Type: lsTypeRouter, ID: netip.MustParseAddr("2.2.2.2"), AdvertisingRouter: r2.RouterID}
+
+	waitFor(t, r2, 20*time.Second, "r2 to have r1's router-LSA with a transit link", func() bool {
+		lsa, ok := r2.lookupLSA(0, r1Key)
+		return ok && neighborStateOf(i2, "3.3.3.3") == nFull && len(lsa.LSA.(*routerLSA).Links()) == 1 && lsa.LSA.(*routerLSA).Links()[0].Type == linkTransit
+	})
+
+	waitFor(t, r1, 5*time.Second, "r1 to be DR with routes", func() bool {
+		return i1.State == iDR && len(r1.rib) > 0
+	})
+
+	// With strict LSA checking, r2 won't help while it's still flooding
+	// changes to r1.
+	waitFor(t, r2, 20*time.Second, "r2 to finish flooding to r1", func() bool {
+		n, ok := i2.Neighbors[r1.RouterID]
+		return ok && len(n.RetransmissionList) == 0
+	})
+
+	r2.mu.Lock()
+	r1Seq := r2.Areas[0].lsdb[r1Key].SequenceNumber()
+	r2Seq := r2.Areas[0].lsdb[r2Key].SequenceNumber()
+	r2.mu.Unlock()
+
+	state := r1.PrepareRestart(context.Background())
+
+	r2.mu.Lock()
+	helper := i2.Neighbors[r1.RouterID].helper
+	r2.mu.Unlock()
+
+	if helper == nil {
+		t.Fatalf("expected r2 to help r1 restart")
+	}
+
+	stop()
+
+	r1 = newTestInstance(t, "3.3.3.3", network, confs)
+	r1.config.GracefulRestart = restarting
+	r1.Restart(state)
+
+	if !r1.InstanceSnapshot().GracefulRestart.Restarting {
+		t.Fatalf("expected r1 to be restarting")
+	}
+
+	if len(r1.RouteSnapshots()) == 0 {
+		t.Errorf("expected r1 to keep its routes")
+	}
+
+	// Long enough for r2's inactivity timer to fire.
+	time.Sleep(5 * time.Second)
+
+	i1 = startTestInterface(t, r1, netif1)
+
+	waitFor(t, r1, 20*time.Second, "r1 to finish restarting", func() bool {
+		return !r1.isRestarting()
+	})
+
+	waitFor(t, r2, 5*time.Second, "r2 to stop helping", func() bool {
+		n, ok := i2.Neighbors[r1.RouterID]
+		return ok && n.helper == nil && n.state == nFull
+	})
+
+	r1.mu.Lock()
+	if i1.State != iDR {
+		t.Errorf("expected r1 to be DR again, got %s", i1.State)
+	}
+	r1.mu.Unlock()
+
+	r2.mu.Lock()
+	defer r2.mu.Unlock()
+
+	// Neither router originated a new router-LSA, because r1 was advertised
+	// as fully adjacent throughout.
+	if seq := r2.Areas[0].lsdb[r1Key].SequenceNumber(); seq != r1Seq {
+		t.Errorf("expected r1's router-LSA to keep sequence number %#x, got %#x", r1Seq, seq)
+	}
+
+	if seq := r2.Areas[0].lsdb[r2Key].SequenceNumber(); seq != r2Seq {
+		t.Errorf("expected r2's router-LSA to keep sequence number %#x, got %#x", r2Seq, seq)
+	}
+
+	for _, lsa := range i2.lsdb {
+		if isGraceLSA(lsa) && lsa.Age() != maxAge {
+			t.Errorf("expected r1's grace-LSA to be flushed")
+		}
+	}
+}
+
+func TestGracefulRestartGracePeriodExpires(t *testing.T) {
+	inst := newTestInstance(t, "1.1.1.1", newMemNetwork(), nil)
+
+	inst.Restart(&restartState{
+		routerID: inst.RouterID,
+		rib:      make(routingTable),
+		deadline: time.Now().Add(100 * time.Millisecond),
+	})
+
+	waitFor(t, inst, 5*time.Second, "the grace period to end", func() bool {
+		return !inst.isRestarting()
+	})
+}
+
+func TestRestartWithDifferentRouterID(t *testing.T) {
+	inst := newTestInstance(t, "1.1.1.1", newMemNetwork(), nil)
+
+	inst.Restart(&restartState{
+		routerID: mustParseRouterID("9.9.9.9"),
+		rib:      make(routingTable),
+		deadline: time.Now().Add(time.Minute),
+	})
+
+	if inst.InstanceSnapshot().GracefulRestart.Restarting {
+		t.Errorf("expected not to restart gracefully with a new router ID")
+	}
+}
@@ -0,0 +1,590 @@
+package ospf
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"golang.org/x/exp/slices"
+)
+
+// PathType is the type of path to a destination. Earlier types are
+// preferred. See RFC 2328, section 11.
+type PathType uint8
+
+const (
+	PathIntraArea PathType = iota
+	PathInterArea
+	PathType1External
+	PathType2External
+)
+
+func (t PathType) String() string {
+	switch t {
+	case PathIntraArea:
+		return "intra-area"
+	case PathInterArea:
+		return "inter-area"
+	case PathType1External:
+		return "E1"
+	case PathType2External:
+		return "E2"
+	default:
+		return fmt.Sprintf("PathType(%d)", t)
+	}
+}
+
+// A nextHop is an outgoing interface and, unless the destination is
+// directly connected, the address of the next router.
+type nextHop struct {
+	iface *Interface
+	addr  netip.Addr
+}
+
+// addNextHops adds the next hops in b to a, skipping duplicates. Next hops
+// are shared between vertices and routes, so a is never modified in place.
+func addNextHops(a, b []nextHop) []nextHop {
+	a = slices.Clip(a)
+
+	for _, nh := range b {
+		found := false
+		for _, existing := range a {
+			if existing == nh {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			a = append(a, nh)
+		}
+	}
+
+	return a
+}
+
+// A route is an entry in the OSPF routing table, described in RFC 2328,
+// section 11. For type 2 external routes, cost is the cost to the ASBR or
+// forwarding address, and type2Cost is the external metric. Discard routes
+// cover an area border router's active address ranges, and have no next
+// hops.
+type route struct {
+	prefix    netip.Prefix
+	pathType  PathType
+	cost      uint32
+	type2Cost uint32
+	areaID    common.AreaID
+	nextHops  []nextHop
+	discard   bool
+
+	advertisingRouter common.RouterID // for inter-area and external routes
+	origin            LSA             // for external routes, the AS-external-LSA or Type-7 LSA
+}
+
+// compareRoutes returns a negative number if a is preferred to b, a
+// positive number if b is preferred to a, and 0 if they're equally good.
+// See RFC 2328, sections 11 and 16.4.
+func compareRoutes(a, b *route) int {
+	if a.pathType != b.pathType {
+		return int(a.pathType) - int(b.pathType)
+	}
+
+	if a.pathType == PathType2External && a.type2Cost != b.type2Cost {
+		return compareCosts(a.type2Cost, b.type2Cost)
+	}
+
+	return compareCosts(a.cost, b.cost)
+}
+
+func compareCosts(a, b uint32) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// routingTable maps destination networks to routes.
+type routingTable map[netip.Prefix]*route
+
+// addRoute adds r to the table, unless there's already a better route to
+// the same destination. Equally good routes are merged.
+func (rt routingTable) addRoute(r *route) {
+	existing, ok := rt[r.prefix]
+	if !ok {
+		rt[r.prefix] = r
+		return
+	}
+
+	switch c := compareRoutes(r, existing); {
+	case c < 0:
+		rt[r.prefix] = r
+	case c == 0:
+		existing.nextHops = addNextHops(existing.nextHops, r.nextHops)
+	}
+}
+
+// lookup returns the most specific route containing addr, ignoring
+// external and discard routes.
+func (rt routingTable) lookup(addr netip.Addr) *route {
+	var best *route
+
+	for prefix, r := range rt {
+		if r.pathType > PathInterArea || r.discard || !prefix.Contains(addr) {
+			continue
+		}
+
+		if best == nil || prefix.Bits() > best.prefix.Bits() {
+			best = r
+		}
+	}
+
+	return best
+}
+
+// A routerRoute is a routing table entry for an area border router or AS
+// boundary router. Routes to routers are kept separately for each area.
+type routerRoute struct {
+	id       common.RouterID
+	pathType PathType
+	cost     uint32
+	areaID   common.AreaID
+	abr      bool
+	asbr     bool
+	nextHops []nextHop
+}
+
+// sortedAreas returns the instance's areas ordered by ID, so that the
+// routing table calculation is deterministic.
+func (inst *Instance) sortedAreas() []*Area {
+	areas := make([]*Area, 0, len(inst.Areas))
+	for _, area := range inst.Areas {
+		areas = append(areas, area)
+	}
+
+	sort.Slice(areas, func(a, b int) bool {
+		return areas[a].ID < areas[b].ID
+	})
+
+	return areas
+}
+
+// calculateRoutingTable builds the routing table from each area's
+// intra-area routes, summary-LSAs, AS-external-LSAs and Type-7 LSAs, and
+// then updates the summaries and translated LSAs we originate. See RFC
+// 2328, sections 16.2 through 16.4.
+func (inst *Instance) calculateRoutingTable() {
+	rib := make(routingTable)
+
+	for _, area := range inst.sortedAreas() {
+		for _, r := range area.routes {
+			c := *r
+			rib.addRoute(&c)
+		}
+	}
+
+	inst.rib = rib
+
+	inst.addDiscardRoutes()
+	inst.calculateInterAreaRoutes()
+	inst.examineTransitAreas()
+	inst.calculateExternalRoutes()
+
+	inst.electTranslators()
+	inst.originateSummaries()
+	inst.originateNSSALSAs()
+}
+
+// summaryAreas returns the areas whose summary-LSAs we use. An area border
+// router only considers the backbone's. See RFC 2328, section 16.2.
+func (inst *Instance) summaryAreas() []*Area {
+	if inst.isABR() {
+		if backbone, ok := inst.Areas[0]; ok {
+			return []*Area{backbone}
+		}
+	}
+
+	return inst.sortedAreas()
+}
+
+// usableSummary returns the cost advertised by a summary-LSA in the area,
+// and the route to the area border router that originated it. It returns
+// false if the LSA should be ignored.
+func (inst *Instance) usableSummary(area *Area, lsa *installedLSA) (uint32, *routerRoute, bool) {
+	var metric uint32
+
+	switch s := lsa.LSA.(type) {
+	case *summaryLSA:
+		metric = s.Metric()
+	case *asbrSummaryLSA:
+		metric = s.Metric()
+	default:
+		return 0, nil, false
+	}
+
+	if metric >= lsInfinity || lsa.Age() == maxAge || lsa.AdvertisingRouter() == inst.RouterID {
+		return 0, nil, false
+	}
+
+	br, ok := area.borderRouters[lsa.AdvertisingRouter()]
+	if !ok || !br.abr || br.pathType != PathIntraArea {
+		return 0, nil, false
+	}
+
+	return metric, br, true
+}
+
+// calculateInterAreaRoutes examines summary-LSAs, as described in RFC 2328,
+// section 16.2.
+func (inst *Instance) calculateInterAreaRoutes() {
+	for _, area := range inst.summaryAreas() {
+		for _, lsa := range area.lsdb {
+			metric, br, ok := inst.usableSummary(area, lsa)
+			if !ok {
+				continue
+			}
+
+			cost := br.cost + metric
+
+			switch s := lsa.LSA.(type) {
+			case *summaryLSA:
+				if existing, ok := inst.rib[s.Prefix()]; ok && existing.pathType == PathIntraArea {
+					continue
+				}
+
+				inst.rib.addRoute(&route{
+					prefix:            s.Prefix(),
+					pathType:          PathInterArea,
+					cost:              cost,
+					areaID:            area.ID,
+					nextHops:          br.nextHops,
+					advertisingRouter: lsa.AdvertisingRouter(),
+				})
+			case *asbrSummaryLSA:
+				id := routerIDFromAddr(s.ID())
+				if id == inst.RouterID {
+					continue
+				}
+
+				existing, ok := area.borderRouters[id]
+				switch {
+				case !ok || (existing.pathType == PathInterArea && cost < existing.cost):
+					area.borderRouters[id] = &routerRoute{
+						id:       id,
+						pathType: PathInterArea,
+						cost:     cost,
+						areaID:   area.ID,
+						asbr:     true,
+						nextHops: br.nextHops,
+					}
+				case existing.pathType == PathInterArea && cost == existing.cost:
+					existing.nextHops = addNextHops(existing.nextHops, br.nextHops)
+				}
+			}
+		}
+	}
+}
+
+// examineTransitAreas looks for better paths through transit areas than
+// the ones found through the backbone, as described in RFC 2328, section
+// 16.3.
+func (inst *Instance) examineTransitAreas() {
+	if _, ok := inst.Areas[0]; !ok || !inst.isABR() {
+		return
+	}
+
+	for _, area := range inst.sortedAreas() {
+		if area.ID == 0 || !area.TransitCapability {
+			continue
+		}
+
+		for _, lsa := range area.lsdb {
+			metric, br, ok := inst.usableSummary(area, lsa)
+			if !ok {
+				continue
+			}
+
+			cost := br.cost + metric
+
+			switch s := lsa.LSA.(type) {
+			case *summaryLSA:
+				r, ok := inst.rib[s.Prefix()]
+				if !ok || r.areaID != 0 || r.pathType > PathInterArea {
+					continue
+				}
+
+				improveRoute(&r.cost, &r.nextHops, cost, br.nextHops)
+			case *asbrSummaryLSA:
+				r, ok := inst.Areas[0].borderRouters[routerIDFromAddr(s.ID())]
+				if !ok {
+					continue
+				}
+
+				improveRoute(&r.cost, &r.nextHops, cost, br.nextHops)
+			}
+		}
+	}
+}
+
+// improveRoute replaces a route's cost and next hops if cost is lower, and
+// adds to its next hops if it's the same.
+func improveRoute(cost *uint32, nextHops *[]nextHop, newCost uint32, newNextHops []nextHop) {
+	switch {
+	case newCost < *cost:
+		*cost = newCost
+		*nextHops = slices.Clone(newNextHops)
+	case newCost == *cost:
+		*nextHops = addNextHops(*nextHops, newNextHops)
+	}
+}
+
+// asbrRoute returns the preferred route to an AS boundary router, as
+// described in RFC 2328, section 16.4.1, with RFC1583Compatibility off:
+// intra-area routes through non-backbone areas are preferred, and the
+// rest, intra-area routes through the backbone and inter-area routes, are
+// equally preferred. Then lower costs are preferred, and then larger area
+// IDs. Routes through NSSAs are only used for Type-7 LSAs, so they're
+// ignored.
+func (inst *Instance) asbrRoute(id common.RouterID) *routerRoute {
+	var best *routerRoute
+
+	for _, area := range inst.sortedAreas() {
+		if area.NSSA {
+			continue
+		}
+
+		r, ok := area.borderRouters[id]
+		if !ok || !r.asbr {
+			continue
+		}
+
+		if best == nil || preferASBRRoute(r, best) {
+			best = r
+		}
+	}
+
+	return best
+}
+
+// preferASBRRoute reports whether a is preferred to b. See asbrRoute.
+func preferASBRRoute(a, b *routerRoute) bool {
+	aNonBackbone := a.pathType == PathIntraArea && a.areaID != 0
+	bNonBackbone := b.pathType == PathIntraArea && b.areaID != 0
+
+	if aNonBackbone != bNonBackbone {
+		return aNonBackbone
+	}
+
+	if a.cost != b.cost {
+		return a.cost < b.cost
+	}
+
+	return a.areaID > b.areaID
+}
+
+// calculateExternalRoutes examines AS-external-LSAs, as described in RFC
+// 2328, section 16.4, and the Type-7 LSAs in each NSSA, as described in
+// RFC 3101.
+func (inst *Instance) calculateExternalRoutes() {
+	for _, lsa := range inst.lsdb {
+		ext, ok := lsa.LSA.(*asExternalLSA)
+		if !ok || ext.Metric() >= lsInfinity || lsa.Age() == maxAge || lsa.AdvertisingRouter() == inst.RouterID {
+			continue
+		}
+
+		asbr := inst.asbrRoute(lsa.AdvertisingRouter())
+		if asbr == nil {
+			continue
+		}
+
+		inst.addExternalRoute(ext, ext, asbr, nil)
+	}
+
+	for _, area := range inst.sortedAreas() {
+		if !area.NSSA {
+			continue
+		}
+
+		for _, lsa := range area.lsdb {
+			n, ok := lsa.LSA.(*nssaLSA)
+			if !ok || n.Metric() >= lsInfinity || lsa.Age() == maxAge || lsa.AdvertisingRouter() == inst.RouterID {
+				continue
+			}
+
+			// Area border routers don't use each other's default
+			// Type-7 LSAs.
+			if n.Prefix().Bits() == 0 && inst.isABR() {
+				continue
+			}
+
+			asbr, ok := area.borderRouters[lsa.AdvertisingRouter()]
+			if !ok || !asbr.asbr || asbr.pathType != PathIntraArea {
+				continue
+			}
+
+			inst.addExternalRoute(n, &n.asExternalLSA, asbr, area)
+		}
+	}
+}
+
+// addExternalRoute adds the route described by ext, which came from
+// origin, through asbr. If nssa isn't nil, origin is a Type-7 LSA from
+// that NSSA, and the forwarding address must be reached through it.
+func (inst *Instance) addExternalRoute(origin LSA, ext *asExternalLSA, asbr *routerRoute, nssa *Area) {
+	cost := asbr.cost
+	nextHops := asbr.nextHops
+
+	if fa := ext.ForwardingAddress(); fa.IsValid() && !fa.IsUnspecified() {
+		r := inst.rib.lookup(fa)
+		if r == nil {
+			return
+		}
+
+		if nssa != nil && (r.pathType != PathIntraArea || r.areaID != nssa.ID) {
+			return
+		}
+
+		cost = r.cost
+		nextHops = forwardingNextHops(r, fa)
+	}
+
+	prefix := ext.Prefix()
+
+	existing, ok := inst.rib[prefix]
+	if ok && existing.pathType <= PathInterArea {
+		return
+	}
+
+	r := &route{
+		prefix:            prefix,
+		cost:              cost,
+		areaID:            asbr.areaID,
+		nextHops:          nextHops,
+		advertisingRouter: origin.AdvertisingRouter(),
+		origin:            origin,
+	}
+
+	if ext.IsType2() {
+		r.pathType = PathType2External
+		r.type2Cost = ext.Metric()
+	} else {
+		r.pathType = PathType1External
+		r.cost += ext.Metric()
+	}
+
+	if ok && compareRoutes(r, existing) == 0 {
+		if a, b := externalPreference(r), externalPreference(existing); a != b {
+			if a < b {
+				inst.rib[prefix] = r
+			}
+
+			return
+		}
+	}
+
+	inst.rib.addRoute(r)
+}
+
+// externalPreference ranks equally good external routes by the LSA they
+// came from. Lower is better: Type-7 LSAs with the P-bit set, then
+// AS-external-LSAs, then the remaining Type-7 LSAs. See RFC 3101.
+func externalPreference(r *route) int {
+	n, ok := r.origin.(*nssaLSA)
+
+	switch {
+	case !ok:
+		return 1
+	case n.Propagate():
+		return 0
+	default:
+		return 2
+	}
+}
+
+// forwardingNextHops returns the next hops for traffic sent to a forwarding
+// address fa using r. If fa is on a directly connected network, it's the
+// next hop itself.
+func forwardingNextHops(r *route, fa netip.Addr) []nextHop {
+	nextHops := make([]nextHop, len(r.nextHops))
+	for i, nh := range r.nextHops {
+		if !nh.addr.IsValid() {
+			nh.addr = fa
+		}
+
+		nextHops[i] = nh
+	}
+
+	return nextHops
+}
+
+// A NextHopSnapshot is a copy of a route's next hop, for reporting. Addr is
+// unset for directly connected destinations.
+type NextHopSnapshot struct {
+	Interface       string
+	InterfacePrefix netip.Prefix
+	Addr            netip.Addr
+}
+
+// A RouteSnapshot is a copy of an OSPF route, for reporting.
+type RouteSnapshot struct {
+	Prefix            netip.Prefix
+	PathType          PathType
+	Cost              uint32
+	Type2Cost         uint32
+	AreaID            common.AreaID
+	AdvertisingRouter common.RouterID
+	NextHops          []NextHopSnapshot
+	Discard           bool
+}
+
+func (r *route) snapshot() RouteSnapshot {
+	nextHops := make([]NextHopSnapshot, len(r.nextHops))
+	for i, nh := range r.nextHops {
+		nextHops[i] = NextHopSnapshot{
+			Interface:       nh.iface.name,
+			InterfacePrefix: nh.iface.Prefix,
+			Addr:            nh.addr,
+		}
+	}
+
+	return RouteSnapshot{
+		Prefix:            r.prefix,
+		PathType:          r.pathType,
+		Cost:              r.cost,
+		Type2Cost:         r.type2Cost,
+		AreaID:            r.areaID,
+		AdvertisingRouter: r.advertisingRouter,
+		NextHops:          nextHops,
+		Discard:           r.discard,
+	}
+}
+
+// RouteSnapshots returns the OSPF routing table, sorted by prefix.
+func (i *Instance) RouteSnapshots() []RouteSnapshot {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var routes []RouteSnapshot
+	for _, r := range i.rib {
+		routes = append(routes, r.snapshot())
+	}
+
+	sort.Slice(routes, func(a, b int) bool {
+		return comparePrefixes(routes[a].Prefix, routes[b].Prefix) < 0
+	})
+
+	return routes
+}
+
+// comparePrefixes orders prefixes by address, and then by length.
+func comparePrefixes(a, b netip.Prefix) int {
+	if c := a.Addr().Compare(b.Addr()); c != 0 {
+		return c
+	}
+
+	return a.Bits() - b.Bits()
+}
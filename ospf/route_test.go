@@ -0,0 +1,231 @@
+package ospf
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/davidbalbert/chatter/config"
+)
+
+func testSummaryLSA(advertisingRouter, prefix string, metric uint32) *summaryLSA {
+	p := netip.MustParsePrefix(prefix)
+
+	h := testLSAHeader(advertisingRouter, initialSequenceNumber)
+	h.id = p.Addr()
+
+	return newSummaryLSA(h, prefixMask(p.Bits()), metric)
+}
+
+func testASBRSummaryLSA(advertisingRouter, asbr string, metric uint32) *asbrSummaryLSA {
+	h := testLSAHeader(advertisingRouter, initialSequenceNumber)
+	h.id = netip.MustParseAddr(asbr)
+
+	return newASBRSummaryLSA(h, metric)
+}
+
+func testASExternalLSA(advertisingRouter, prefix string, r externalRoute) *asExternalLSA {
+	p := netip.MustParsePrefix(prefix)
+
+	h := testLSAHeader(advertisingRouter, initialSequenceNumber)
+	h.id = p.Addr()
+
+	if !r.ForwardingAddress.IsValid() {
+		r.ForwardingAddress = netip.IPv4Unspecified()
+	}
+
+	return newASExternalLSA(h, prefixMask(p.Bits()), r)
+}
+
+// expectRIBRoute checks the instance's routing table for a route to prefix
+// with the given path type and cost, through next hop nextHop.
+func expectRIBRoute(t *testing.T, inst *Instance, prefix string, pathType PathType, cost, type2Cost uint32, nextHop string) {
+	t.Helper()
+
+	r, ok := inst.rib[netip.MustParsePrefix(prefix)]
+	if !ok {
+		t.Errorf("expected route to %s", prefix)
+		return
+	}
+
+	if r.pathType != pathType || r.cost != cost || r.type2Cost != type2Cost {
+		t.Errorf("%s: expected %s with cost %d/%d, got %s with cost %d/%d", prefix, pathType, cost, type2Cost, r.pathType, r.cost, r.type2Cost)
+	}
+
+	if len(r.nextHops) != 1 || r.nextHops[0].addr != netip.MustParseAddr(nextHop) {
+		t.Errorf("%s: expected next hop %s, got %v", prefix, nextHop, r.nextHops)
+	}
+}
+
+// addTestInterface adds a broadcast interface to inst that isn't running.
+func addTestInterface(t *testing.T, inst *Instance, netif string, index int, prefix string, conf config.OSPFInterfaceConfig) *Interface {
+	t.Helper()
+
+	n := testNetif(netif, index, prefix)
+
+	iface, err := newInterface(inst, conf, n, n.Prefixes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { iface.transport.close() })
+
+	iface.Type = InterfaceBroadcast
+	iface.State = iDR
+	iface.DR = iface.self()
+
+	inst.Interfaces[interfaceID{name: n.Name, prefix: n.Prefixes[0]}] = iface
+
+	return iface
+}
+
+// newTestABR returns an area border router, 1.1.1.1, attached to area 0 on
+// eth0 (10.0.0.1/24) and area 1 on eth1 (10.1.0.1/24). In area 1, it's DR
+// for a network shared with 3.3.3.3, an AS boundary router with three stub
+// networks.
+func newTestABR(t *testing.T) *Instance {
+	t.Helper()
+
+	area1 := testInterfaceConfig()
+	area1.AreaID = 1
+
+	confs := map[string]config.OSPFInterfaceConfig{"eth0": testInterfaceConfig(), "eth1": area1}
+	inst := newTestInstance(t, "1.1.1.1", newMemNetwork(), confs)
+
+	addTestInterface(t, inst, "eth0", 1, "10.0.0.1/24", confs["eth0"])
+	eth1 := addTestInterface(t, inst, "eth1", 2, "10.1.0.1/24", confs["eth1"])
+
+	// So that our own router-LSA for area 1 has a transit link.
+	nbr := newNeighbor(eth1, mustParseRouterID("3.3.3.3"), netip.MustParseAddr("10.1.0.3"))
+	nbr.state = nFull
+	eth1.Neighbors[nbr.ID] = nbr
+
+	inst.installLSA(0, testRouterLSAWithLinks("1.1.1.1", routerFlagB,
+		testLink(linkStub, "10.0.0.0", "255.255.255.0", 10),
+	))
+
+	for _, lsa := range []LSA{
+		testRouterLSAWithLinks("1.1.1.1", routerFlagB,
+			testLink(linkTransit, "10.1.0.1", "10.1.0.1", 10),
+		),
+		testRouterLSAWithLinks("3.3.3.3", routerFlagE,
+			testLink(linkTransit, "10.1.0.1", "10.1.0.3", 10),
+			testLink(linkStub, "172.16.1.0", "255.255.255.0", 1),
+			testLink(linkStub, "172.16.2.0", "255.255.255.0", 5),
+			testLink(linkStub, "192.168.0.0", "255.255.255.0", 1),
+		),
+		testNetworkLSA("10.1.0.1", "1.1.1.1", 24, "1.1.1.1", "3.3.3.3"),
+	} {
+		inst.installLSA(1, lsa)
+	}
+
+	return inst
+}
+
+func TestInterAreaAndExternalRoutes(t *testing.T) {
+	iface, nbrs, _ := newTestFloodingInterface(t)
+	inst := iface.instance
+
+	iface.Type = InterfacePointToPoint
+	iface.State = iPointToPoint
+	delete(iface.Neighbors, nbrs[1].ID)
+
+	// We're attached to area 0 only, over a point-to-point link to
+	// 2.2.2.2, an area border router. It advertises a route to
+	// 172.16.0.0/16 and to 9.9.9.9, an AS boundary router in another area.
+	lsas := []LSA{
+		testRouterLSAWithLinks("1.1.1.1", 0,
+			testLink(linkPointToPoint, "2.2.2.2", "10.0.0.1", 10),
+			testLink(linkStub, "10.0.0.0", "255.255.255.0", 10),
+		),
+		testRouterLSAWithLinks("2.2.2.2", routerFlagB,
+			testLink(linkPointToPoint, "1.1.1.1", "10.0.0.2", 10),
+			testLink(linkStub, "10.0.0.0", "255.255.255.0", 10),
+		),
+		testSummaryLSA("2.2.2.2", "172.16.0.0/16", 5),
+		testSummaryLSA("2.2.2.2", "10.0.0.0/24", 1),
+		testSummaryLSA("2.2.2.2", "172.17.0.0/16", lsInfinity),
+		testSummaryLSA("3.3.3.3", "172.18.0.0/16", 1),
+		testASBRSummaryLSA("2.2.2.2", "9.9.9.9", 7),
+		testASExternalLSA("9.9.9.9", "0.0.0.0/0", externalRoute{Type2: true, Metric: 100}),
+		testASExternalLSA("9.9.9.9", "203.0.113.0/24", externalRoute{Metric: 10}),
+		testASExternalLSA("9.9.9.9", "198.51.100.0/24", externalRoute{Type2: true, Metric: 50, ForwardingAddress: netip.MustParseAddr("10.0.0.2")}),
+		testASExternalLSA("9.9.9.9", "192.0.2.0/24", externalRoute{Type2: true, Metric: 50, ForwardingAddress: netip.MustParseAddr("10.99.0.1")}),
+		testASExternalLSA("8.8.8.8", "198.18.0.0/15", externalRoute{Metric: 1}),
+	}
+
+	for _, lsa := range lsas {
+		inst.installLSA(0, lsa)
+	}
+
+	inst.runSPF()
+
+	expectRIBRoute(t, inst, "172.16.0.0/16", PathInterArea, 15, 0, "10.0.0.2")
+	expectRIBRoute(t, inst, "0.0.0.0/0", PathType2External, 17, 100, "10.0.0.2")
+	expectRIBRoute(t, inst, "203.0.113.0/24", PathType1External, 27, 0, "10.0.0.2")
+
+	// The forwarding address is on our network, so traffic goes straight
+	// there, and the cost is our cost to reach it.
+	expectRIBRoute(t, inst, "198.51.100.0/24", PathType2External, 10, 50, "10.0.0.2")
+
+	if r := inst.rib[netip.MustParsePrefix("10.0.0.0/24")]; r == nil || r.pathType != PathIntraArea {
+		t.Errorf("expected intra-area route to 10.0.0.0/24 to be preferred, got %+v", r)
+	}
+
+	for _, prefix := range []string{"172.17.0.0/16", "172.18.0.0/16", "192.0.2.0/24", "198.18.0.0/15"} {
+		if r, ok := inst.rib[netip.MustParsePrefix(prefix)]; ok {
+			t.Errorf("expected no route to %s, got %+v", prefix, r)
+		}
+	}
+}
+
+func TestCompareRoutes(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b route
+	}{
+		{"intra-area beats inter-area", route{pathType: PathIntraArea, cost: 100}, route{pathType: PathInterArea, cost: 1}},
+		{"inter-area beats external", route{pathType: PathInterArea, cost: 100}, route{pathType: PathType1External, cost: 1}},
+		{"E1 beats E2", route{pathType: PathType1External, cost: 100}, route{pathType: PathType2External, cost: 1, type2Cost: 1}},
+		{"lower E2 metric wins", route{pathType: PathType2External, cost: 100, type2Cost: 1}, route{pathType: PathType2External, cost: 1, type2Cost: 2}},
+		{"then lower E2 cost", route{pathType: PathType2External, cost: 1, type2Cost: 1}, route{pathType: PathType2External, cost: 2, type2Cost: 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if compareRoutes(&test.a, &test.b) >= 0 || compareRoutes(&test.b, &test.a) <= 0 {
+				t.Errorf("expected %+v to be preferred to %+v", test.a, test.b)
+			}
+		})
+	}
+}
+
+func TestASBRRoutePrefersNonBackboneIntraArea(t *testing.T) {
+	inst := newTestABR(t)
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	// 3.3.3.3 is also attached to area 0, on our network there, and it's
+	// cheaper to reach it that way.
+	for _, lsa := range []LSA{
+		withSequenceNumber(testRouterLSAWithLinks("1.1.1.1", routerFlagB,
+			testLink(linkTransit, "10.0.0.1", "10.0.0.1", 1),
+		), initialSequenceNumber+1),
+		testRouterLSAWithLinks("3.3.3.3", routerFlagB|routerFlagE,
+			testLink(linkTransit, "10.0.0.1", "10.0.0.3", 1),
+		),
+		testNetworkLSA("10.0.0.1", "1.1.1.1", 24, "1.1.1.1", "3.3.3.3"),
+	} {
+		inst.installLSA(0, lsa)
+	}
+
+	inst.installLSA(0, testASExternalLSA("3.3.3.3", "203.0.113.0/24", externalRoute{Metric: 10}))
+
+	inst.runSPF()
+
+	if r := inst.Areas[0].borderRouters[mustParseRouterID("3.3.3.3")]; r == nil || r.pathType != PathIntraArea || r.cost != 1 {
+		t.Fatalf("expected an intra-area route to 3.3.3.3 through area 0 with cost 1, got %+v", r)
+	}
+
+	// Intra-area routes through non-backbone areas are preferred, even
+	// though they cost more. See RFC 2328, section 16.4.1.
+	expectRIBRoute(t, inst, "203.0.113.0/24", PathType1External, 20, 0, "10.1.0.3")
+}
@@ -0,0 +1,462 @@
+package ospf
+
+import (
+	"net/netip"
+	"time"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+)
+
+type vertexType uint8
+
+const (
+	vertexRouter vertexType = iota
+	vertexNetwork
+)
+
+// vertexID identifies a vertex in the shortest-path tree. Routers are
+// identified by router ID, and transit networks by the address of their DR,
+// which is the Link State ID of their network-LSA.
+type vertexID struct {
+	t  vertexType
+	id netip.Addr
+}
+
+// A vertex is a router or transit network in an area's shortest-path tree.
+type vertex struct {
+	vertexID
+	lsa      LSA
+	distance uint32
+	nextHops []nextHop
+}
+
+// isDirectlyConnectedNetwork reports whether v is a transit network
+// attached to the root.
+func (v *vertex) isDirectlyConnectedNetwork() bool {
+	return v.t == vertexNetwork && len(v.nextHops) > 0 && !v.nextHops[0].addr.IsValid()
+}
+
+// scheduleSPF recalculates the routing table because lsa changed in scope
+// s, unless a calculation is already scheduled. We wait a little first, so
+// that a burst of changes results in a single calculation, and longer if
+// the database keeps changing. See config.OSPFThrottleConfig.
+func (inst *Instance) scheduleSPF(s lsaScope, lsa LSAMetadata, flushed bool) {
+	inst.addSPFTrigger(s, lsa, flushed)
+
+	if inst.spfTimer != nil {
+		return
+	}
+
+	inst.spfTimer = time.AfterFunc(inst.spfThrottle.delay(inst.config.SPFThrottle), func() {
+		inst.mu.Lock()
+		defer inst.mu.Unlock()
+
+		if inst.spfTimer == nil {
+			return
+		}
+
+		inst.spfTimer = nil
+		inst.runSPF()
+	})
+}
+
+func (inst *Instance) stopSPF() {
+	if inst.spfTimer != nil {
+		inst.spfTimer.Stop()
+		inst.spfTimer = nil
+	}
+}
+
+// runSPF recalculates the routing table. See RFC 2328, section 16. Virtual
+// links get their costs and next hops from their transit areas, so the
+// backbone is calculated last. While we're restarting gracefully, we keep
+// the routing table we had before restarting. See RFC 3623, section 2.2.
+func (inst *Instance) runSPF() {
+	start := time.Now()
+	inst.spfThrottle.happened()
+	defer inst.recordSPFRun(start)
+
+	for _, area := range inst.Areas {
+		if area.ID != 0 {
+			inst.calculateArea(area)
+		}
+	}
+
+	inst.updateVirtualLinks()
+
+	if backbone, ok := inst.Areas[0]; ok {
+		inst.calculateArea(backbone)
+	}
+
+	if inst.isRestarting() {
+		return
+	}
+
+	inst.calculateRoutingTable()
+}
+
+// routerLSAFor returns the router-LSA originated by id in the area, or nil
+// if there isn't one that's usable in the routing table calculation.
+func routerLSAFor(area *Area, id netip.Addr) *routerLSA {
+	key := lsdbKey{Type: lsTypeRouter, ID: id, AdvertisingRouter: routerIDFromAddr(id)}
+
+	lsa, ok := area.lsdb[key]
+	if !ok || lsa.Age() == maxAge {
+		return nil
+	}
+
+	r, ok := lsa.LSA.(*routerLSA)
+	if !ok {
+		return nil
+	}
+
+	return r
+}
+
+// networkLSAs indexes an area's network-LSAs by Link State ID, which is the
+// address of the network's DR.
+func networkLSAs(area *Area) map[netip.Addr]*networkLSA {
+	lsas := make(map[netip.Addr]*networkLSA)
+
+	for _, lsa := range area.lsdb {
+		n, ok := lsa.LSA.(*networkLSA)
+		if !ok || lsa.Age() == maxAge {
+			continue
+		}
+
+		lsas[n.ID()] = n
+	}
+
+	return lsas
+}
+
+// spfCalculation is the state of an area's shortest-path tree calculation.
+type spfCalculation struct {
+	area     *Area
+	root     *vertex
+	ifaces   []*Interface // our interfaces in the area
+	networks map[netip.Addr]*networkLSA
+}
+
+// calculateArea builds the area's shortest-path tree rooted at us and
+// calculates its intra-area routes, as described in RFC 2328, section 16.1.
+func (inst *Instance) calculateArea(area *Area) {
+	area.spt = make(map[vertexID]*vertex)
+	area.routes = make(routingTable)
+	area.borderRouters = make(map[common.RouterID]*routerRoute)
+	area.TransitCapability = false
+
+	rootLSA := routerLSAFor(area, addrFromRouterID(inst.RouterID))
+	if rootLSA == nil {
+		return
+	}
+
+	c := &spfCalculation{
+		area: area,
+		root: &vertex{
+			vertexID: vertexID{t: vertexRouter, id: rootLSA.ID()},
+			lsa:      rootLSA,
+		},
+		ifaces:   inst.areaInterfaces(area.ID),
+		networks: networkLSAs(area),
+	}
+
+	candidates := make(map[vertexID]*vertex)
+	v := c.root
+
+	// Stage 1: routers and transit networks.
+	for {
+		area.spt[v.vertexID] = v
+
+		if r, ok := v.lsa.(*routerLSA); ok && r.IsVirtualLinkEndpoint() {
+			area.TransitCapability = true
+		}
+
+		for _, e := range c.edges(v) {
+			w := e.to
+			if _, ok := area.spt[w.vertexID]; ok {
+				continue
+			}
+
+			if !linksBack(w.lsa, v) {
+				continue
+			}
+
+			w.distance = v.distance + e.cost
+
+			candidate, ok := candidates[w.vertexID]
+			if ok && w.distance > candidate.distance {
+				continue
+			}
+
+			nextHops := c.calculateNextHops(v, w, e.link)
+
+			if ok && w.distance == candidate.distance {
+				candidate.nextHops = addNextHops(candidate.nextHops, nextHops)
+				continue
+			}
+
+			w.nextHops = nextHops
+			candidates[w.vertexID] = w
+		}
+
+		v = closestCandidate(candidates)
+		if v == nil {
+			break
+		}
+
+		delete(candidates, v.vertexID)
+
+		switch lsa := v.lsa.(type) {
+		case *routerLSA:
+			if lsa.IsABR() || lsa.IsASBR() {
+				area.borderRouters[lsa.AdvertisingRouter()] = &routerRoute{
+					id:       lsa.AdvertisingRouter(),
+					pathType: PathIntraArea,
+					cost:     v.distance,
+					areaID:   area.ID,
+					abr:      lsa.IsABR(),
+					asbr:     lsa.IsASBR(),
+					nextHops: v.nextHops,
+				}
+			}
+		case *networkLSA:
+			area.routes.addRoute(&route{
+				prefix:   lsa.Prefix(),
+				pathType: PathIntraArea,
+				cost:     v.distance,
+				areaID:   area.ID,
+				nextHops: v.nextHops,
+			})
+		}
+	}
+
+	// Stage 2: stub networks. See RFC 2328, section 16.1, step 2.
+	for _, v := range area.spt {
+		r, ok := v.lsa.(*routerLSA)
+		if !ok {
+			continue
+		}
+
+		for _, link := range r.Links() {
+			if link.Type != linkStub {
+				continue
+			}
+
+			prefix := maskedPrefix(link.ID, link.Data)
+
+			nextHops := v.nextHops
+			if v == c.root {
+				nextHops = c.stubNextHops(prefix)
+			}
+
+			area.routes.addRoute(&route{
+				prefix:   prefix,
+				pathType: PathIntraArea,
+				cost:     v.distance + uint32(link.Metric),
+				areaID:   area.ID,
+				nextHops: nextHops,
+			})
+		}
+	}
+}
+
+// An edge leads from a vertex to one of its neighbors in the graph.
+type edge struct {
+	to   *vertex
+	cost uint32
+	link *routerLink // nil for edges from networks to routers
+}
+
+// edges returns the vertices adjacent to v whose LSAs are in the database.
+func (c *spfCalculation) edges(v *vertex) []edge {
+	var edges []edge
+
+	switch lsa := v.lsa.(type) {
+	case *routerLSA:
+		links := lsa.Links()
+
+		for i := range links {
+			link := &links[i]
+
+			switch link.Type {
+			case linkPointToPoint, linkVirtual:
+				r := routerLSAFor(c.area, link.ID)
+				if r == nil {
+					continue
+				}
+
+				edges = append(edges, edge{
+					to:   &vertex{vertexID: vertexID{t: vertexRouter, id: link.ID}, lsa: r},
+					cost: uint32(link.Metric),
+					link: link,
+				})
+			case linkTransit:
+				n, ok := c.networks[link.ID]
+				if !ok {
+					continue
+				}
+
+				edges = append(edges, edge{
+					to:   &vertex{vertexID: vertexID{t: vertexNetwork, id: link.ID}, lsa: n},
+					cost: uint32(link.Metric),
+					link: link,
+				})
+			}
+		}
+	case *networkLSA:
+		for _, id := range lsa.AttachedRouters() {
+			r := routerLSAFor(c.area, addrFromRouterID(id))
+			if r == nil {
+				continue
+			}
+
+			edges = append(edges, edge{
+				to: &vertex{vertexID: vertexID{t: vertexRouter, id: addrFromRouterID(id)}, lsa: r},
+			})
+		}
+	}
+
+	return edges
+}
+
+// linksBack reports whether the LSA for w has a link back to v. Vertices
+// are only added to the tree if the link between them is bidirectional.
+func linksBack(w LSA, v *vertex) bool {
+	switch lsa := w.(type) {
+	case *routerLSA:
+		for _, link := range lsa.Links() {
+			switch {
+			case v.t == vertexRouter && (link.Type == linkPointToPoint || link.Type == linkVirtual) && link.ID == v.id:
+				return true
+			case v.t == vertexNetwork && link.Type == linkTransit && link.ID == v.id:
+				return true
+			}
+		}
+	case *networkLSA:
+		if v.t != vertexRouter {
+			return false
+		}
+
+		for _, id := range lsa.AttachedRouters() {
+			if addrFromRouterID(id) == v.id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// closestCandidate returns the candidate with the smallest distance from
+// the root, or nil if there are none. Ties go to networks, so that all
+// routers reachable through a network get next hops on it, and then to the
+// lowest ID, so that the result is deterministic.
+func closestCandidate(candidates map[vertexID]*vertex) *vertex {
+	var closest *vertex
+
+	for _, v := range candidates {
+		switch {
+		case closest == nil || v.distance < closest.distance:
+			closest = v
+		case v.distance > closest.distance:
+		case v.t != closest.t:
+			if v.t == vertexNetwork {
+				closest = v
+			}
+		case v.id.Less(closest.id):
+			closest = v
+		}
+	}
+
+	return closest
+}
+
+// calculateNextHops returns the next hops for w, which is being reached
+// through v. See RFC 2328, section 16.1.1.
+func (c *spfCalculation) calculateNextHops(v, w *vertex, link *routerLink) []nextHop {
+	switch {
+	case v == c.root && link.Type == linkVirtual:
+		// Next hops over a virtual link are through the transit area.
+		vl := c.virtualLinkTo(routerIDFromAddr(w.id))
+		if vl == nil {
+			return nil
+		}
+
+		return vl.vlink.nextHops
+	case v == c.root:
+		iface := c.interfaceForLinkData(link.Data)
+		if iface == nil {
+			return nil
+		}
+
+		if w.t == vertexNetwork {
+			return []nextHop{{iface: iface}}
+		}
+
+		// A point-to-point link.
+		n, ok := iface.Neighbors[routerIDFromAddr(w.id)]
+		if !ok {
+			return nil
+		}
+
+		return []nextHop{{iface: iface, addr: n.Addr}}
+	case v.isDirectlyConnectedNetwork():
+		// w is a router on a network we're attached to, so the next hop
+		// is w's address on that network.
+		for _, l := range w.lsa.(*routerLSA).Links() {
+			if l.Type == linkTransit && l.ID == v.id {
+				return []nextHop{{iface: v.nextHops[0].iface, addr: l.Data}}
+			}
+		}
+
+		return nil
+	default:
+		return v.nextHops
+	}
+}
+
+// interfaceForLinkData returns our interface in the area whose address is
+// the Link Data of one of our router-LSA's links.
+func (c *spfCalculation) interfaceForLinkData(addr netip.Addr) *Interface {
+	for _, iface := range c.ifaces {
+		if iface.Prefix.Addr() == addr {
+			return iface
+		}
+	}
+
+	return nil
+}
+
+// virtualLinkTo returns our virtual link to the router id, if it's in the
+// area.
+func (c *spfCalculation) virtualLinkTo(id common.RouterID) *Interface {
+	for _, iface := range c.ifaces {
+		if iface.isVirtualLink() && iface.vlink.endpoint == id {
+			return iface
+		}
+	}
+
+	return nil
+}
+
+// stubNextHops returns the interface connecting us to one of our own stub
+// networks: either the interface on that network, a loopback with that
+// address, or a point-to-point interface whose neighbor has it.
+func (c *spfCalculation) stubNextHops(prefix netip.Prefix) []nextHop {
+	for _, iface := range c.ifaces {
+		if iface.Prefix.Masked() == prefix || iface.Prefix.Addr() == prefix.Addr() {
+			return []nextHop{{iface: iface}}
+		}
+	}
+
+	for _, iface := range c.ifaces {
+		for _, n := range iface.Neighbors {
+			if netip.PrefixFrom(n.Addr, 32) == prefix {
+				return []nextHop{{iface: iface}}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package ospf
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+)
+
+func testLink(t routerLinkType, id, data string, metric uint16) routerLink {
+	return routerLink{
+		ID:     netip.MustParseAddr(id),
+		Data:   netip.MustParseAddr(data),
+		Type:   t,
+		Metric: metric,
+	}
+}
+
+func testRouterLSAWithLinks(id string, flags routerLSAFlags, links ...routerLink) *routerLSA {
+	return newRouterLSA(testLSAHeader(id, initialSequenceNumber), flags, links)
+}
+
+func testNetworkLSA(dr string, advertisingRouter string, bits int, routers ...string) *networkLSA {
+	h := testLSAHeader(advertisingRouter, initialSequenceNumber)
+	h.id = netip.MustParseAddr(dr)
+
+	var ids []common.RouterID
+	for _, r := range routers {
+		ids = append(ids, mustParseRouterID(r))
+	}
+
+	return newNetworkLSA(h, prefixMask(bits), ids)
+}
+
+// expectRoute checks that area has a route to prefix with the given cost
+// and next hops, in any order. An empty next hop address means directly
+// connected.
+func expectRoute(t *testing.T, area *Area, prefix string, cost uint32, nextHops ...string) {
+	t.Helper()
+
+	r, ok := area.routes[netip.MustParsePrefix(prefix)]
+	if !ok {
+		t.Errorf("expected route to %s", prefix)
+		return
+	}
+
+	if r.cost != cost {
+		t.Errorf("%s: expected cost %d, got %d", prefix, cost, r.cost)
+	}
+
+	got := make(map[string]bool)
+	for _, nh := range r.nextHops {
+		if nh.addr.IsValid() {
+			got[nh.addr.String()] = true
+		} else {
+			got[""] = true
+		}
+	}
+
+	expected := make(map[string]bool)
+	for _, nh := range nextHops {
+		expected[nh] = true
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("%s: expected next hops %v, got %v", prefix, nextHops, r.nextHops)
+	}
+}
+
+func TestSPFBroadcast(t *testing.T) {
+	iface := newTestBroadcastInterface(t)
+	inst := iface.instance
+
+	// We (1.1.1.1) are on 10.0.0.0/24 with 2.2.2.2 (the DR) and 3.3.3.3.
+	// Both of them have point-to-point links to 4.4.4.4. 5.5.5.5 claims
+	// a link to 4.4.4.4 that 4.4.4.4 doesn't confirm.
+	lsas := []LSA{
+		testRouterLSAWithLinks("1.1.1.1", 0, testLink(linkTransit, "10.0.0.2", "10.0.0.1", 10)),
+		testRouterLSAWithLinks("2.2.2.2", 0,
+			testLink(linkTransit, "10.0.0.2", "10.0.0.2", 10),
+			testLink(linkPointToPoint, "4.4.4.4", "10.1.0.1", 10),
+		),
+		testRouterLSAWithLinks("3.3.3.3", 0,
+			testLink(linkTransit, "10.0.0.2", "10.0.0.3", 10),
+			testLink(linkPointToPoint, "4.4.4.4", "10.2.0.1", 10),
+			testLink(linkStub, "192.168.3.0", "255.255.255.0", 5),
+		),
+		testRouterLSAWithLinks("4.4.4.4", 0,
+			testLink(linkPointToPoint, "2.2.2.2", "10.1.0.2", 10),
+			testLink(linkPointToPoint, "3.3.3.3", "10.2.0.2", 10),
+			testLink(linkStub, "172.16.0.0", "255.255.0.0", 1),
+		),
+		testRouterLSAWithLinks("5.5.5.5", 0,
+			testLink(linkPointToPoint, "4.4.4.4", "10.3.0.1", 10),
+			testLink(linkStub, "192.168.5.0", "255.255.255.0", 1),
+		),
+		testNetworkLSA("10.0.0.2", "2.2.2.2", 24, "1.1.1.1", "2.2.2.2", "3.3.3.3"),
+	}
+
+	inst.mu.Lock()
+	for _, lsa := range lsas {
+		inst.installLSA(0, lsa)
+	}
+	inst.mu.Unlock()
+
+	// Installing the LSAs schedules a calculation.
+	area := inst.Areas[0]
+	waitFor(t, inst, 5*time.Second, "the routing table to be calculated", func() bool {
+		return len(area.routes) > 0
+	})
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	expectRoute(t, area, "10.0.0.0/24", 10, "")
+	expectRoute(t, area, "192.168.3.0/24", 15, "10.0.0.3")
+	expectRoute(t, area, "172.16.0.0/16", 21, "10.0.0.2", "10.0.0.3")
+
+	if _, ok := area.routes[netip.MustParsePrefix("192.168.5.0/24")]; ok {
+		t.Errorf("expected no route through a one-way link")
+	}
+
+	if len(area.spt) != 5 {
+		t.Errorf("expected 4 routers and a network in the tree, got %d vertices", len(area.spt))
+	}
+
+	if area.TransitCapability {
+		t.Errorf("expected no transit capability")
+	}
+}
+
+func TestSPFPointToPoint(t *testing.T) {
+	iface, nbrs, _ := newTestFloodingInterface(t)
+	inst := iface.instance
+
+	iface.Type = InterfacePointToPoint
+	iface.State = iPointToPoint
+	delete(iface.Neighbors, nbrs[1].ID)
+
+	lsas := []LSA{
+		testRouterLSAWithLinks("1.1.1.1", 0,
+			testLink(linkPointToPoint, "2.2.2.2", "10.0.0.1", 10),
+			testLink(linkStub, "10.0.0.0", "255.255.255.0", 10),
+		),
+		testRouterLSAWithLinks("2.2.2.2", routerFlagV,
+			testLink(linkPointToPoint, "1.1.1.1", "10.0.0.2", 10),
+			testLink(linkStub, "10.0.0.0", "255.255.255.0", 10),
+			testLink(linkStub, "192.168.2.0", "255.255.255.0", 1),
+		),
+	}
+
+	for _, lsa := range lsas {
+		inst.installLSA(0, lsa)
+	}
+
+	area := inst.Areas[0]
+	inst.calculateArea(area)
+
+	expectRoute(t, area, "10.0.0.0/24", 10, "")
+	expectRoute(t, area, "192.168.2.0/24", 11, "10.0.0.2")
+
+	if !area.TransitCapability {
+		t.Errorf("expected transit capability from a virtual link endpoint")
+	}
+
+	// Without our router-LSA, there's nothing to calculate.
+	inst.flushLSA(areaScope(0), lsas[0])
+	inst.calculateArea(area)
+
+	if len(area.routes) != 0 {
+		t.Errorf("expected no routes, got %d", len(area.routes))
+	}
+}
@@ -0,0 +1,145 @@
+package ospf
+
+import (
+	"net/netip"
+	"time"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"github.com/davidbalbert/chatter/config"
+)
+
+// A throttle spaces out routing table calculations, or new instances of one
+// of our LSAs, with exponential backoff. See config.OSPFThrottleConfig.
+type throttle struct {
+	last time.Time     // when it last happened
+	hold time.Duration // the current hold time
+	next time.Duration // the hold time once it happens again, or 0
+}
+
+// delay returns how long to wait before it happens again. After a quiet
+// period of at least twice MaxWait, we wait InitialDelay, and the hold time
+// starts over once it happens. Otherwise, we wait out the rest of the hold
+// time, or InitialDelay if it's already passed, and once it happens, the
+// hold time doubles, so changes that keep coming just slower than the hold
+// time still back off. Nothing changes until it actually happens, so being
+// scheduled without anything happening doesn't back off.
+func (t *throttle) delay(conf config.OSPFThrottleConfig) time.Duration {
+	since := time.Since(t.last)
+
+	if t.last.IsZero() || since >= 2*conf.MaxWait {
+		t.next = conf.Hold
+		return conf.InitialDelay
+	}
+
+	t.next = t.hold * 2
+	if t.next < conf.Hold {
+		t.next = conf.Hold
+	} else if t.next > conf.MaxWait {
+		t.next = conf.MaxWait
+	}
+
+	if since >= t.hold {
+		return conf.InitialDelay
+	}
+
+	return t.hold - since
+}
+
+// happened records that it happened, moving on to the hold time chosen
+// by delay. If it happened without being scheduled, the hold time stays
+// the same.
+func (t *throttle) happened() {
+	t.last = time.Now()
+
+	if t.next != 0 {
+		t.hold = t.next
+		t.next = 0
+	}
+}
+
+// maxSPFHistory is the number of routing table calculations we remember,
+// and maxSPFTriggers is the number of LSA changes we remember for each.
+const (
+	maxSPFHistory  = 16
+	maxSPFTriggers = 16
+)
+
+// An SPFTrigger is a change to the link state database that caused a
+// routing table calculation.
+type SPFTrigger struct {
+	AreaID            common.AreaID // 0 for AS-external-LSAs
+	Type              lsType
+	ID                netip.Addr
+	AdvertisingRouter common.RouterID
+	Flushed           bool // it reached MaxAge and was removed
+}
+
+// An SPFRun is a routing table calculation. Triggers has the first
+// maxSPFTriggers of the TriggerCount changes since the previous one.
+type SPFRun struct {
+	Start        time.Time
+	Duration     time.Duration
+	Triggers     []SPFTrigger
+	TriggerCount int
+}
+
+// addSPFTrigger records why a routing table calculation is scheduled.
+func (inst *Instance) addSPFTrigger(s lsaScope, lsa LSAMetadata, flushed bool) {
+	inst.spfTriggerCount++
+
+	if len(inst.spfTriggers) == maxSPFTriggers {
+		return
+	}
+
+	inst.spfTriggers = append(inst.spfTriggers, SPFTrigger{
+		AreaID:            s.areaID,
+		Type:              lsa.Type(),
+		ID:                lsa.ID(),
+		AdvertisingRouter: lsa.AdvertisingRouter(),
+		Flushed:           flushed,
+	})
+}
+
+// recordSPFRun adds a routing table calculation that began at start to the
+// history, along with the changes that triggered it.
+func (inst *Instance) recordSPFRun(start time.Time) {
+	inst.spfRuns++
+
+	if len(inst.spfHistory) == maxSPFHistory {
+		inst.spfHistory = append(inst.spfHistory[:0], inst.spfHistory[1:]...)
+	}
+
+	inst.spfHistory = append(inst.spfHistory, SPFRun{
+		Start:        start,
+		Duration:     time.Since(start),
+		Triggers:     inst.spfTriggers,
+		TriggerCount: inst.spfTriggerCount,
+	})
+
+	inst.spfTriggers = nil
+	inst.spfTriggerCount = 0
+}
+
+// An SPFSnapshot describes routing table calculations and how they're
+// throttled, for reporting. Hold is the current hold time. History is
+// oldest first.
+type SPFSnapshot struct {
+	Throttle  config.OSPFThrottleConfig
+	Hold      time.Duration
+	Scheduled bool
+	Runs      uint64
+	History   []SPFRun
+}
+
+func (inst *Instance) spfSnapshot() SPFSnapshot {
+	history := make([]SPFRun, len(inst.spfHistory))
+	copy(history, inst.spfHistory)
+
+	return SPFSnapshot{
+		Throttle:  inst.config.SPFThrottle,
+		Hold:      inst.spfThrottle.hold,
+		Scheduled: inst.spfTimer != nil,
+		Runs:      inst.spfRuns,
+		History:   history,
+	}
+}
@@ -0,0 +1,206 @@
+package ospf
+
+import (
+	"fmt"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/config"
+)
+
+func TestThrottleBackoff(t *testing.T) {
+	conf := config.OSPFThrottleConfig{
+		InitialDelay: 100 * time.Millisecond,
+		Hold:         1 * time.Second,
+		MaxWait:      4 * time.Second,
+	}
+
+	var th throttle
+
+	if d := th.delay(conf); d != conf.InitialDelay {
+		t.Fatalf("expected the initial delay, got %s", d)
+	}
+	th.happened()
+
+	// While changes keep coming, the hold time doubles up to the max wait.
+	for _, expected := range []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		if d := th.delay(conf); d > expected || d < expected-100*time.Millisecond {
+			t.Fatalf("expected to wait about %s, got %s", expected, d)
+		}
+		th.happened()
+	}
+
+	// After a quiet period, it starts over.
+	th.last = time.Now().Add(-8 * time.Second)
+
+	if d := th.delay(conf); d != conf.InitialDelay {
+		t.Errorf("expected the initial delay, got %s", d)
+	}
+	th.happened()
+
+	if th.hold != conf.Hold {
+		t.Errorf("expected the hold time to start over at %s, got %s", conf.Hold, th.hold)
+	}
+}
+
+func TestThrottleSustainedFlap(t *testing.T) {
+	conf := config.OSPFThrottleConfig{
+		InitialDelay: 100 * time.Millisecond,
+		Hold:         1 * time.Second,
+		MaxWait:      8 * time.Second,
+	}
+
+	var th throttle
+
+	th.delay(conf)
+	th.happened()
+
+	// A link that flaps just slower than the hold time never gives us a
+	// quiet period, so we keep backing off.
+	for _, expected := range []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+		th.last = time.Now().Add(-(th.hold + 100*time.Millisecond))
+
+		if d := th.delay(conf); d != conf.InitialDelay {
+			t.Fatalf("expected the initial delay once the hold time has passed, got %s", d)
+		}
+		th.happened()
+
+		if th.hold != expected {
+			t.Fatalf("expected the hold time to be %s, got %s", expected, th.hold)
+		}
+	}
+
+	// Once it's been quiet for twice the max wait, it starts over.
+	th.last = time.Now().Add(-2 * conf.MaxWait)
+
+	th.delay(conf)
+	th.happened()
+
+	if th.hold != conf.Hold {
+		t.Errorf("expected the hold time to start over at %s, got %s", conf.Hold, th.hold)
+	}
+}
+
+func TestSPFHistory(t *testing.T) {
+	iface, _, _ := newTestFloodingInterface(t)
+	inst := iface.instance
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	lsa := testRouterLSA("2.2.2.2", initialSequenceNumber)
+	inst.installLSA(0, lsa)
+
+	if inst.spfTimer == nil {
+		t.Fatalf("expected a routing table calculation to be scheduled")
+	}
+
+	inst.stopSPF()
+	inst.runSPF()
+
+	s := inst.spfSnapshot()
+
+	if s.Runs != 1 || len(s.History) != 1 {
+		t.Fatalf("expected 1 run, got %d with %d in the history", s.Runs, len(s.History))
+	}
+
+	run := s.History[0]
+	if run.TriggerCount != 1 || len(run.Triggers) != 1 {
+		t.Fatalf("expected 1 trigger, got %d", run.TriggerCount)
+	}
+
+	trigger := run.Triggers[0]
+	if trigger.Type != lsTypeRouter || trigger.AdvertisingRouter != lsa.AdvertisingRouter() || trigger.Flushed {
+		t.Errorf("expected %v to be the trigger, got %+v", lsa.Key(), trigger)
+	}
+
+	if len(inst.spfTriggers) != 0 || inst.spfTriggerCount != 0 {
+		t.Errorf("expected no pending triggers")
+	}
+}
+
+func TestSPFTriggersAreCapped(t *testing.T) {
+	iface, _, _ := newTestFloodingInterface(t)
+	inst := iface.instance
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	for i := 1; i <= maxSPFTriggers+4; i++ {
+		inst.installLSA(0, testRouterLSA(fmt.Sprintf("10.1.0.%d", i), initialSequenceNumber))
+	}
+
+	inst.stopSPF()
+	inst.runSPF()
+
+	run := inst.spfHistory[0]
+	if run.TriggerCount != maxSPFTriggers+4 || len(run.Triggers) != maxSPFTriggers {
+		t.Errorf("expected %d of %d triggers, got %d of %d", maxSPFTriggers, maxSPFTriggers+4, len(run.Triggers), run.TriggerCount)
+	}
+}
+
+func TestLSAThrottleInitialDelay(t *testing.T) {
+	iface, _, _ := newTestFloodingInterface(t)
+	inst := iface.instance
+	inst.config.LSAThrottle.InitialDelay = 100 * time.Millisecond
+
+	key := lsdbKey{Type: lsTypeRouter, ID: addrFromRouterID(inst.RouterID), AdvertisingRouter: inst.RouterID}
+
+	inst.mu.Lock()
+	inst.scheduleRouterLSAs()
+
+	if _, ok := inst.lookupLSA(0, key); ok {
+		t.Errorf("expected origination to be deferred")
+	}
+	inst.mu.Unlock()
+
+	waitFor(t, inst, 5*time.Second, "our router-LSA to be originated", func() bool {
+		_, ok := inst.lookupLSA(0, key)
+		return ok
+	})
+}
+
+func TestLSAThrottleUnchangedContents(t *testing.T) {
+	iface, _, _ := newTestFloodingInterface(t)
+	inst := iface.instance
+	inst.config.LSAThrottle = config.OSPFThrottleConfig{
+		Hold:    1 * time.Second,
+		MaxWait: 8 * time.Second,
+	}
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.scheduleRouterLSAs()
+
+	key := lsdbKey{Type: lsTypeRouter, ID: netip.MustParseAddr("1.1.1.1"), AdvertisingRouter: inst.RouterID}
+	first, ok := inst.lookupLSA(0, key)
+	if !ok {
+		t.Fatalf("expected router-LSA to be originated")
+	}
+
+	o := inst.originations[newOriginationKey(0, key)]
+
+	// Each of these waits out the hold time, but nothing changed, so there's
+	// no new instance, and no reason to back off.
+	for i := 0; i < 4; i++ {
+		inst.scheduleRouterLSAs()
+
+		if o.timer == nil {
+			t.Fatalf("expected a deferred origination")
+		}
+
+		o.timer.Stop()
+		o.timer = nil
+		inst.runOrigination(o, false)
+	}
+
+	if lsa, _ := inst.lookupLSA(0, key); lsa.SequenceNumber() != first.SequenceNumber() {
+		t.Errorf("expected unchanged router-LSA not to be re-originated")
+	}
+
+	if o.hold != inst.config.LSAThrottle.Hold {
+		t.Errorf("expected the hold time to stay at %s, got %s", inst.config.LSAThrottle.Hold, o.hold)
+	}
+}
@@ -0,0 +1,264 @@
+package ospf
+
+import (
+	"errors"
+	"net/netip"
+	"sync"
+)
+
+var errTransportClosed = errors.New("transport closed")
+
+// Packets to neighbors on an attached network are sent with a TTL of 1.
+// Virtual link packets are routed through the transit area, so they're
+// sent with a TTL that's large enough to reach the other end.
+const (
+	linkTTL        = 1
+	virtualLinkTTL = 64
+)
+
+type receivedPacket struct {
+	data    []byte // starting at the OSPF header
+	src     netip.Addr
+	dst     netip.Addr
+	ifindex int
+}
+
+// A transport sends and receives OSPF packets for a single Interface.
+// Implementations must be safe to call from multiple goroutines, and
+// send must not block waiting for the receiver.
+type transport interface {
+	// send sends data to dst, with the IP time to live set to ttl.
+	send(data []byte, dst netip.Addr, ttl int) error
+
+	// receive blocks until a packet arrives or the transport is closed,
+	// in which case it returns errTransportClosed.
+	receive() (*receivedPacket, error)
+
+	joinGroup(group netip.Addr) error
+	leaveGroup(group netip.Addr) error
+	close() error
+}
+
+type transportFactory func(iface *Interface) (transport, error)
+
+// A passiveTransport is used by passive interfaces, which never send or
+// receive OSPF packets, so that they don't need a socket. Sends are
+// dropped, and receive blocks until the transport is closed.
+type passiveTransport struct {
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newPassiveTransport() *passiveTransport {
+	return &passiveTransport{closed: make(chan struct{})}
+}
+
+func (t *passiveTransport) send(data []byte, dst netip.Addr, ttl int) error {
+	return nil
+}
+
+func (t *passiveTransport) receive() (*receivedPacket, error) {
+	<-t.closed
+	return nil, errTransportClosed
+}
+
+func (t *passiveTransport) joinGroup(group netip.Addr) error {
+	return nil
+}
+
+func (t *passiveTransport) leaveGroup(group netip.Addr) error {
+	return nil
+}
+
+func (t *passiveTransport) close() error {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+	})
+
+	return nil
+}
+
+// A memNetwork is a broadcast segment connecting memTransports in the same
+// process. Multicast packets are delivered to every other transport that has
+// joined the group, and unicast packets to the transport with the destination
+// address. Unicast packets for other networks are forwarded according to
+// the network's routes, as long as their TTL allows. Like a real network,
+// packets are dropped if a receiver falls too far behind.
+type memNetwork struct {
+	mu         sync.Mutex
+	transports []*memTransport
+	routes     []memRoute
+}
+
+// A memRoute forwards packets for prefix to another network, as if a
+// router were attached to both.
+type memRoute struct {
+	prefix netip.Prefix
+	next   *memNetwork
+}
+
+func newMemNetwork() *memNetwork {
+	return &memNetwork{}
+}
+
+const memTransportQueueLen = 256
+
+func (n *memNetwork) newTransport(iface *Interface) (transport, error) {
+	return n.attach(iface.Prefix.Addr(), iface.ifindex), nil
+}
+
+func (n *memNetwork) attach(addr netip.Addr, ifindex int) *memTransport {
+	t := &memTransport{
+		network: n,
+		addr:    addr,
+		ifindex: ifindex,
+		groups:  make(map[netip.Addr]bool),
+		packets: make(chan *receivedPacket, memTransportQueueLen),
+		closed:  make(chan struct{}),
+	}
+
+	n.mu.Lock()
+	n.transports = append(n.transports, t)
+	n.mu.Unlock()
+
+	return t
+}
+
+func (n *memNetwork) detach(t *memTransport) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for i, other := range n.transports {
+		if other == t {
+			n.transports = append(n.transports[:i], n.transports[i+1:]...)
+			return
+		}
+	}
+}
+
+// addRoute forwards unicast packets for prefix that aren't for a transport
+// on n to next.
+func (n *memNetwork) addRoute(prefix netip.Prefix, next *memNetwork) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.routes = append(n.routes, memRoute{prefix: prefix, next: next})
+}
+
+// deliver delivers a packet sent by from, which is nil if the packet was
+// forwarded from another network.
+func (n *memNetwork) deliver(from *memTransport, src netip.Addr, data []byte, dst netip.Addr, ttl int) {
+	n.mu.Lock()
+
+	delivered := false
+	for _, t := range n.transports {
+		if t == from {
+			continue
+		}
+
+		if (dst.IsMulticast() && t.isMember(dst)) || t.addr == dst {
+			b := make([]byte, len(data))
+			copy(b, data)
+
+			t.enqueue(&receivedPacket{
+				data:    b,
+				src:     src,
+				dst:     dst,
+				ifindex: t.ifindex,
+			})
+
+			delivered = true
+		}
+	}
+
+	var next *memNetwork
+	if !delivered && !dst.IsMulticast() && ttl > 1 {
+		for _, r := range n.routes {
+			if r.prefix.Contains(dst) {
+				next = r.next
+				break
+			}
+		}
+	}
+
+	n.mu.Unlock()
+
+	if next != nil {
+		next.deliver(nil, src, data, dst, ttl-1)
+	}
+}
+
+type memTransport struct {
+	network *memNetwork
+	addr    netip.Addr
+	ifindex int
+
+	mu     sync.Mutex
+	groups map[netip.Addr]bool
+
+	packets   chan *receivedPacket
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (t *memTransport) isMember(group netip.Addr) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.groups[group]
+}
+
+func (t *memTransport) enqueue(p *receivedPacket) {
+	select {
+	case t.packets <- p:
+	default:
+	}
+}
+
+func (t *memTransport) send(data []byte, dst netip.Addr, ttl int) error {
+	select {
+	case <-t.closed:
+		return errTransportClosed
+	default:
+	}
+
+	t.network.deliver(t, t.addr, data, dst, ttl)
+
+	return nil
+}
+
+func (t *memTransport) receive() (*receivedPacket, error) {
+	select {
+	case p := <-t.packets:
+		return p, nil
+	case <-t.closed:
+		return nil, errTransportClosed
+	}
+}
+
+func (t *memTransport) joinGroup(group netip.Addr) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.groups[group] = true
+
+	return nil
+}
+
+func (t *memTransport) leaveGroup(group netip.Addr) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.groups, group)
+
+	return nil
+}
+
+func (t *memTransport) close() error {
+	t.closeOnce.Do(func() {
+		t.network.detach(t)
+		close(t.closed)
+	})
+
+	return nil
+}
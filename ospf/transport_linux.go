@@ -0,0 +1,232 @@
+package ospf
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ipProtoOSPF = 89
+
+	// RFC 2328, Appendix A.1: OSPF packets are sent with IP precedence
+	// set to Internetwork Control.
+	tosInternetworkControl = 0xc0
+)
+
+// rawTransport sends and receives OSPF packets using a raw IP socket bound
+// to the interface's device.
+type rawTransport struct {
+	f       *os.File
+	rc      syscall.RawConn
+	ifindex int
+	src     netip.Addr
+	closed  atomic.Bool
+}
+
+func newRawTransport(iface *Interface) (transport, error) {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_RAW|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, ipProtoOSPF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create raw socket: %w", err)
+	}
+
+	err = configureRawSocket(fd, iface.name, iface.ifindex)
+	if err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to configure raw socket on %s: %w", iface.name, err)
+	}
+
+	// Wrapping the non-blocking fd in an *os.File registers it with the
+	// runtime poller, which lets close() interrupt a blocked receive.
+	f := os.NewFile(uintptr(fd), "ospf-"+iface.name)
+
+	rc, err := f.SyscallConn()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &rawTransport{
+		f:       f,
+		rc:      rc,
+		ifindex: iface.ifindex,
+		src:     iface.Prefix.Addr(),
+	}, nil
+}
+
+func configureRawSocket(fd int, name string, ifindex int) error {
+	err := unix.BindToDevice(fd, name)
+	if err != nil {
+		return fmt.Errorf("SO_BINDTODEVICE: %w", err)
+	}
+
+	intOpts := []struct {
+		name  string
+		opt   int
+		value int
+	}{
+		{"IP_PKTINFO", unix.IP_PKTINFO, 1},
+		{"IP_TTL", unix.IP_TTL, 1},
+		{"IP_MULTICAST_TTL", unix.IP_MULTICAST_TTL, 1},
+		{"IP_MULTICAST_LOOP", unix.IP_MULTICAST_LOOP, 0},
+		{"IP_TOS", unix.IP_TOS, tosInternetworkControl},
+	}
+
+	for _, o := range intOpts {
+		err := unix.SetsockoptInt(fd, unix.IPPROTO_IP, o.opt, o.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", o.name, err)
+		}
+	}
+
+	err = unix.SetsockoptIPMreqn(fd, unix.IPPROTO_IP, unix.IP_MULTICAST_IF, &unix.IPMreqn{Ifindex: int32(ifindex)})
+	if err != nil {
+		return fmt.Errorf("IP_MULTICAST_IF: %w", err)
+	}
+
+	return nil
+}
+
+func (t *rawTransport) send(data []byte, dst netip.Addr, ttl int) error {
+	sa := &unix.SockaddrInet4{Addr: dst.As4()}
+	oob := t.sendControlMessages(ttl)
+
+	var serr error
+	err := t.rc.Write(func(fd uintptr) bool {
+		serr = unix.Sendmsg(int(fd), data, oob, sa, 0)
+		return serr != unix.EAGAIN
+	})
+	if t.closed.Load() {
+		return errTransportClosed
+	} else if err != nil {
+		return err
+	}
+
+	return serr
+}
+
+// sendControlMessages returns the ancillary data for sending a packet.
+// IP_PKTINFO picks the source address, which matters when the interface has
+// more than one, and IP_TTL overrides the socket's TTL of 1 for packets
+// that are routed, like those sent over virtual links.
+func (t *rawTransport) sendControlMessages(ttl int) []byte {
+	oob := unix.PktInfo4(&unix.Inet4Pktinfo{
+		Ifindex:  int32(t.ifindex),
+		Spec_dst: t.src.As4(),
+	})
+
+	b := make([]byte, unix.CmsgSpace(4))
+
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[0]))
+	h.Level = unix.IPPROTO_IP
+	h.Type = unix.IP_TTL
+	h.SetLen(unix.CmsgLen(4))
+	*(*int32)(unsafe.Pointer(&b[unix.CmsgLen(0)])) = int32(ttl)
+
+	return append(oob, b...)
+}
+
+func (t *rawTransport) receive() (*receivedPacket, error) {
+	buf := make([]byte, 0xffff)
+	oob := make([]byte, unix.CmsgSpace(unix.SizeofInet4Pktinfo))
+
+	for {
+		var (
+			n, oobn int
+			rerr    error
+		)
+
+		err := t.rc.Read(func(fd uintptr) bool {
+			n, oobn, _, _, rerr = unix.Recvmsg(int(fd), buf, oob, 0)
+			return rerr != unix.EAGAIN
+		})
+		if t.closed.Load() {
+			return nil, errTransportClosed
+		} else if err != nil {
+			return nil, err
+		} else if rerr != nil {
+			return nil, rerr
+		}
+
+		p, ok := parseRawPacket(buf[:n], oob[:oobn])
+		if !ok {
+			// Not something we can make sense of. Try again.
+			continue
+		}
+
+		return p, nil
+	}
+}
+
+// parseRawPacket strips the IP header from b, and uses the IP_PKTINFO
+// control message in oob to determine the receiving interface.
+func parseRawPacket(b []byte, oob []byte) (*receivedPacket, bool) {
+	if len(b) < 20 {
+		return nil, false
+	}
+
+	ihl := int(b[0]&0x0f) * 4
+	if ihl < 20 || len(b) < ihl {
+		return nil, false
+	}
+
+	p := &receivedPacket{
+		src: addrFromSlice(b[12:16]),
+		dst: addrFromSlice(b[16:20]),
+	}
+
+	data := make([]byte, len(b)-ihl)
+	copy(data, b[ihl:])
+	p.data = data
+
+	msgs, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil, false
+	}
+
+	for _, m := range msgs {
+		if m.Header.Level != unix.IPPROTO_IP || m.Header.Type != unix.IP_PKTINFO || len(m.Data) < unix.SizeofInet4Pktinfo {
+			continue
+		}
+
+		pi := (*unix.Inet4Pktinfo)(unsafe.Pointer(&m.Data[0]))
+		p.ifindex = int(pi.Ifindex)
+	}
+
+	return p, p.ifindex != 0
+}
+
+func (t *rawTransport) setMembership(opt int, group netip.Addr) error {
+	mreq := &unix.IPMreqn{
+		Multiaddr: group.As4(),
+		Ifindex:   int32(t.ifindex),
+	}
+
+	var serr error
+	err := t.rc.Control(func(fd uintptr) {
+		serr = unix.SetsockoptIPMreqn(int(fd), unix.IPPROTO_IP, opt, mreq)
+	})
+	if err != nil {
+		return err
+	}
+
+	return serr
+}
+
+func (t *rawTransport) joinGroup(group netip.Addr) error {
+	return t.setMembership(unix.IP_ADD_MEMBERSHIP, group)
+}
+
+func (t *rawTransport) leaveGroup(group netip.Addr) error {
+	return t.setMembership(unix.IP_DROP_MEMBERSHIP, group)
+}
+
+func (t *rawTransport) close() error {
+	t.closed.Store(true)
+	return t.f.Close()
+}
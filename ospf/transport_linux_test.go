@@ -0,0 +1,133 @@
+package ospf
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func newLoopbackRawTransport(t *testing.T) transport {
+	lo, err := net.InterfaceByName("lo")
+	if err != nil {
+		t.Skipf("no loopback interface: %v", err)
+	}
+
+	iface := &Interface{
+		Prefix:  netip.MustParsePrefix("127.0.0.1/8"),
+		name:    lo.Name,
+		ifindex: lo.Index,
+	}
+
+	tr, err := newRawTransport(iface)
+	if errors.Is(err, unix.EPERM) || errors.Is(err, unix.EACCES) {
+		t.Skipf("raw sockets require CAP_NET_RAW: %v", err)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	return tr
+}
+
+func TestRawTransportLoopback(t *testing.T) {
+	a := newLoopbackRawTransport(t)
+	defer a.close()
+
+	b := newLoopbackRawTransport(t)
+	defer b.close()
+
+	err := b.joinGroup(AllSPFRouters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hello := &Hello{PacketHeader: testHeader(), helloInterval: 10}
+	data, err := hello.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = a.send(data, netip.MustParseAddr("127.0.0.1"), linkTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := receiveWithTimeout(t, b)
+
+	if p.src != netip.MustParseAddr("127.0.0.1") || p.dst != netip.MustParseAddr("127.0.0.1") {
+		t.Fatalf("unexpected addresses: %s -> %s", p.src, p.dst)
+	}
+
+	lo, _ := net.InterfaceByName("lo")
+	if p.ifindex != lo.Index {
+		t.Fatalf("expected ifindex %d, got %d", lo.Index, p.ifindex)
+	}
+
+	parsed, err := ParsePacket(p.data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.(*Hello).helloInterval != 10 {
+		t.Fatalf("unexpected packet: %+v", parsed)
+	}
+
+	if err := b.leaveGroup(AllSPFRouters); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRawTransportCloseUnblocksReceive(t *testing.T) {
+	tr := newLoopbackRawTransport(t)
+
+	done := make(chan error)
+	go func() {
+		_, err := tr.receive()
+		done <- err
+	}()
+
+	tr.close()
+
+	if err := <-done; err != errTransportClosed {
+		t.Fatalf("expected errTransportClosed, got %v", err)
+	}
+}
+
+func TestRawTransportTTL(t *testing.T) {
+	a := newLoopbackRawTransport(t)
+	defer a.close()
+
+	b := newLoopbackRawTransport(t).(*rawTransport)
+	defer b.close()
+
+	err := a.send([]byte("hello"), netip.MustParseAddr("127.0.0.1"), virtualLinkTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// receive strips the IP header, so read the packet ourselves.
+	b.f.SetReadDeadline(time.Now().Add(time.Second))
+
+	buf := make([]byte, 1500)
+
+	var (
+		n    int
+		rerr error
+	)
+	err = b.rc.Read(func(fd uintptr) bool {
+		n, _, _, _, rerr = unix.Recvmsg(int(fd), buf, nil, 0)
+		return rerr != unix.EAGAIN
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if rerr != nil {
+		t.Fatal(rerr)
+	}
+
+	if n < 20 || buf[8] != virtualLinkTTL {
+		t.Fatalf("expected TTL %d, got %d", virtualLinkTTL, buf[8])
+	}
+}
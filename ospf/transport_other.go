@@ -0,0 +1,12 @@
+//go:build !linux
+
+package ospf
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func newRawTransport(iface *Interface) (transport, error) {
+	return nil, fmt.Errorf("sending and receiving OSPF packets is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,169 @@
+package ospf
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func receiveWithTimeout(t *testing.T, tr transport) *receivedPacket {
+	t.Helper()
+
+	ch := make(chan *receivedPacket, 1)
+	go func() {
+		p, err := tr.receive()
+		if err == nil {
+			ch <- p
+		}
+	}()
+
+	select {
+	case p := <-ch:
+		return p
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for packet")
+		return nil
+	}
+}
+
+func assertNothingReceived(t *testing.T, tr *memTransport) {
+	t.Helper()
+
+	select {
+	case p := <-tr.packets:
+		t.Fatalf("unexpected packet from %s to %s", p.src, p.dst)
+	default:
+	}
+}
+
+func TestMemTransportMulticast(t *testing.T) {
+	n := newMemNetwork()
+
+	a := n.attach(netip.MustParseAddr("10.0.0.1"), 1)
+	b := n.attach(netip.MustParseAddr("10.0.0.2"), 2)
+	c := n.attach(netip.MustParseAddr("10.0.0.3"), 3)
+
+	a.joinGroup(AllSPFRouters)
+	b.joinGroup(AllSPFRouters)
+
+	err := a.send([]byte("hello"), AllSPFRouters, linkTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := receiveWithTimeout(t, b)
+	if string(p.data) != "hello" || p.src != a.addr || p.dst != AllSPFRouters || p.ifindex != 2 {
+		t.Fatalf("unexpected packet: %+v", p)
+	}
+
+	// not a member
+	assertNothingReceived(t, c)
+
+	// no loopback
+	assertNothingReceived(t, a)
+
+	b.leaveGroup(AllSPFRouters)
+	a.send([]byte("hello"), AllSPFRouters, linkTTL)
+	assertNothingReceived(t, b)
+}
+
+func TestMemTransportUnicast(t *testing.T) {
+	n := newMemNetwork()
+
+	a := n.attach(netip.MustParseAddr("10.0.0.1"), 1)
+	b := n.attach(netip.MustParseAddr("10.0.0.2"), 1)
+	c := n.attach(netip.MustParseAddr("10.0.0.3"), 1)
+
+	a.send([]byte("hi"), c.addr, linkTTL)
+
+	p := receiveWithTimeout(t, c)
+	if string(p.data) != "hi" || p.dst != c.addr {
+		t.Fatalf("unexpected packet: %+v", p)
+	}
+
+	assertNothingReceived(t, b)
+}
+
+func TestMemTransportForwarding(t *testing.T) {
+	n1 := newMemNetwork()
+	n2 := newMemNetwork()
+	n1.addRoute(netip.MustParsePrefix("10.0.1.0/24"), n2)
+
+	a := n1.attach(netip.MustParseAddr("10.0.0.1"), 1)
+	b := n2.attach(netip.MustParseAddr("10.0.1.2"), 2)
+
+	// A TTL of 1 doesn't survive being forwarded.
+	a.send([]byte("hi"), b.addr, linkTTL)
+	assertNothingReceived(t, b)
+
+	a.send([]byte("hi"), b.addr, 2)
+
+	p := receiveWithTimeout(t, b)
+	if string(p.data) != "hi" || p.src != a.addr || p.dst != b.addr || p.ifindex != 2 {
+		t.Fatalf("unexpected packet: %+v", p)
+	}
+}
+
+func TestMemTransportCopiesData(t *testing.T) {
+	n := newMemNetwork()
+
+	a := n.attach(netip.MustParseAddr("10.0.0.1"), 1)
+	b := n.attach(netip.MustParseAddr("10.0.0.2"), 1)
+
+	data := []byte("abc")
+	a.send(data, b.addr, linkTTL)
+	data[0] = 'x'
+
+	if p := receiveWithTimeout(t, b); string(p.data) != "abc" {
+		t.Fatalf("expected abc, got %s", p.data)
+	}
+}
+
+func TestMemTransportDropsWhenFull(t *testing.T) {
+	n := newMemNetwork()
+
+	a := n.attach(netip.MustParseAddr("10.0.0.1"), 1)
+	b := n.attach(netip.MustParseAddr("10.0.0.2"), 1)
+
+	for i := 0; i < memTransportQueueLen+10; i++ {
+		a.send([]byte{byte(i)}, b.addr, linkTTL)
+	}
+
+	if len(b.packets) != memTransportQueueLen {
+		t.Fatalf("expected %d queued packets, got %d", memTransportQueueLen, len(b.packets))
+	}
+}
+
+func TestMemTransportClose(t *testing.T) {
+	n := newMemNetwork()
+
+	a := n.attach(netip.MustParseAddr("10.0.0.1"), 1)
+	b := n.attach(netip.MustParseAddr("10.0.0.2"), 1)
+
+	done := make(chan error)
+	go func() {
+		_, err := b.receive()
+		done <- err
+	}()
+
+	b.close()
+
+	select {
+	case err := <-done:
+		if err != errTransportClosed {
+			t.Fatalf("expected errTransportClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("receive didn't return after close")
+	}
+
+	if err := b.send([]byte("x"), a.addr, linkTTL); err != errTransportClosed {
+		t.Fatalf("expected errTransportClosed, got %v", err)
+	}
+
+	// closed transports are detached from the network
+	a.send([]byte("x"), b.addr, linkTTL)
+	if len(b.packets) != 0 {
+		t.Fatalf("expected closed transport not to receive packets")
+	}
+}
@@ -1,6 +1,13 @@
 package ospf
 
-import "golang.org/x/exp/constraints"
+import (
+	"encoding/binary"
+	"math/bits"
+	"net/netip"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"golang.org/x/exp/constraints"
+)
 
 func abs[T constraints.Signed](a T) T {
 	if a < 0 {
@@ -9,3 +16,93 @@ func abs[T constraints.Signed](a T) T {
 		return a
 	}
 }
+
+// ipChecksum computes the Internet checksum (RFC 1071) of the concatenation
+// of data. Verifying a buffer that includes a valid checksum returns 0.
+func ipChecksum(data ...[]byte) uint16 {
+	var sum uint32
+	var odd bool
+	var hi byte
+
+	for _, d := range data {
+		for _, b := range d {
+			if odd {
+				sum += uint32(hi)<<8 | uint32(b)
+			} else {
+				hi = b
+			}
+			odd = !odd
+		}
+	}
+
+	if odd {
+		sum += uint32(hi) << 8
+	}
+
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+
+	return ^uint16(sum)
+}
+
+// addrFromSlice returns the IPv4 address in the first four bytes of b.
+func addrFromSlice(b []byte) netip.Addr {
+	return netip.AddrFrom4([4]byte(b[0:4]))
+}
+
+// putAddr writes the IPv4 address addr into the first four bytes of b.
+// The zero Addr is written as 0.0.0.0.
+func putAddr(b []byte, addr netip.Addr) {
+	if !addr.Is4() {
+		binary.BigEndian.PutUint32(b, 0)
+		return
+	}
+
+	a := addr.As4()
+	copy(b[0:4], a[:])
+}
+
+// prefixMask returns the IPv4 network mask for a prefix of length bits.
+func prefixMask(bits int) netip.Addr {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], ^uint32(0)<<(32-bits))
+
+	return netip.AddrFrom4(b)
+}
+
+// addrFromRouterID returns id as an IPv4 address, e.g. for use as the Link
+// State ID of a router-LSA.
+func addrFromRouterID(id common.RouterID) netip.Addr {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(id))
+
+	return netip.AddrFrom4(b)
+}
+
+// maskBits returns the length of the IPv4 network mask mask. Non-contiguous
+// masks are treated as the length of their leading ones.
+func maskBits(mask netip.Addr) int {
+	b := mask.As4()
+	return bits.LeadingZeros32(^binary.BigEndian.Uint32(b[:]))
+}
+
+// maskedPrefix returns the network with address addr and network mask mask.
+func maskedPrefix(addr, mask netip.Addr) netip.Prefix {
+	return netip.PrefixFrom(addr, maskBits(mask)).Masked()
+}
+
+// lastAddr returns the last address in prefix, which is its address with
+// all the host bits set.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Masked().Addr().As4()
+	binary.BigEndian.PutUint32(b[:], binary.BigEndian.Uint32(b[:])|^uint32(0)>>prefix.Bits())
+
+	return netip.AddrFrom4(b)
+}
+
+// routerIDFromAddr is the inverse of addrFromRouterID.
+func routerIDFromAddr(addr netip.Addr) common.RouterID {
+	b := addr.As4()
+	return common.RouterID(binary.BigEndian.Uint32(b[:]))
+}
@@ -0,0 +1,339 @@
+package ospf
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"sync"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"github.com/davidbalbert/chatter/config"
+	"golang.org/x/sync/errgroup"
+)
+
+// A virtualLink is the state of a virtual link, a point-to-point backbone
+// interface between two area border routers through a non-backbone transit
+// area. Its cost, addresses and next hops are calculated from the transit
+// area's shortest-path tree. See RFC 2328, section 15.
+//
+// A virtualLink is also its Interface's transport. Packets are sent as
+// unicast out of the transit area interface on the path to the other end,
+// and received by the transit area's interfaces, which hand them over with
+// deliver.
+type virtualLink struct {
+	iface         *Interface
+	transitAreaID common.AreaID
+	endpoint      common.RouterID
+
+	// Calculated from the transit area's shortest-path tree.
+	reachable  bool
+	remoteAddr netip.Addr // the other end's address
+	nextHops   []nextHop  // through interfaces in the transit area
+
+	changed chan struct{} // tells Run that reachable may have changed
+
+	packets   chan *receivedPacket
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+const virtualLinkQueueLen = 256
+
+func newVirtualLink(inst *Instance, transitAreaID common.AreaID, endpoint common.RouterID, conf config.OSPFVirtualLinkConfig) *Interface {
+	i := &Interface{
+		Type:               InterfaceVirtualLink,
+		State:              iDown,
+		AreaID:             0,
+		HelloInterval:      conf.HelloInterval,
+		RouterDeadInterval: conf.RouterDeadInterval,
+		InfTransDelay:      1,
+
+		HelloTimer: newStoppedTimer(),
+		WaitTimer:  newStoppedTimer(),
+		AckTimer:   newStoppedTimer(),
+
+		Neighbors:    make(map[common.RouterID]*Neighbor),
+		RxmtInterval: 5,
+
+		lsdb: newLSDB(),
+
+		name: fmt.Sprintf("vlink %s", endpoint),
+
+		instance: inst,
+
+		events:  make(chan dispatch),
+		packets: make(chan *receivedPacket),
+		done:    make(chan struct{}),
+	}
+
+	vl := &virtualLink{
+		iface:         i,
+		transitAreaID: transitAreaID,
+		endpoint:      endpoint,
+		changed:       make(chan struct{}, 1),
+		packets:       make(chan *receivedPacket, virtualLinkQueueLen),
+		closed:        make(chan struct{}),
+	}
+
+	i.vlink = vl
+	i.transport = vl
+	i.configureAuth(conf.Authentication)
+
+	return i
+}
+
+func virtualLinkID(endpoint common.RouterID) interfaceID {
+	return interfaceID{name: fmt.Sprintf("vlink %s", endpoint)}
+}
+
+// startVirtualLinks runs an Interface for each configured virtual link.
+// They're brought up once the routing table calculation finds the other
+// end.
+func (inst *Instance) startVirtualLinks(ctx context.Context, g *errgroup.Group) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	for areaID, ac := range inst.config.Areas {
+		for endpoint, vc := range ac.VirtualLinks {
+			vl := newVirtualLink(inst, areaID, endpoint, vc)
+			inst.Interfaces[virtualLinkID(endpoint)] = vl
+
+			g.Go(func() error {
+				return vl.Run(ctx)
+			})
+		}
+	}
+}
+
+// virtualLinks returns our virtual links, sorted by endpoint.
+func (inst *Instance) virtualLinks() []*Interface {
+	var vls []*Interface
+	for _, iface := range inst.Interfaces {
+		if iface.isVirtualLink() {
+			vls = append(vls, iface)
+		}
+	}
+
+	sort.Slice(vls, func(a, b int) bool {
+		return vls[a].vlink.endpoint < vls[b].vlink.endpoint
+	})
+
+	return vls
+}
+
+// virtualLinkFor returns the virtual link that a packet received on i was
+// sent over, or nil if it wasn't sent over one. Virtual link packets are
+// backbone packets that arrive on an interface in the transit area,
+// addressed to that interface.
+func (i *Interface) virtualLinkFor(h *PacketHeader, rp *receivedPacket) *Interface {
+	if h.areaID != 0 || i.AreaID == 0 || rp.dst != i.Prefix.Addr() {
+		return nil
+	}
+
+	vl, ok := i.instance.Interfaces[virtualLinkID(h.routerID)]
+	if !ok || vl.vlink.transitAreaID != i.AreaID {
+		return nil
+	}
+
+	return vl
+}
+
+// hasFullVirtualLink reports whether we have a fully adjacent virtual link
+// through the area, which sets the V-bit in our router-LSA for the area.
+func (inst *Instance) hasFullVirtualLink(areaID common.AreaID) bool {
+	for _, vl := range inst.virtualLinks() {
+		if vl.vlink.transitAreaID == areaID && len(vl.fullNeighbors()) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// updateVirtualLinks calculates each virtual link's parameters from its
+// transit area's shortest-path tree. See RFC 2328, section 16.1.
+func (inst *Instance) updateVirtualLinks() {
+	changed := false
+
+	for _, vl := range inst.virtualLinks() {
+		if vl.updateVirtualLink() {
+			changed = true
+		}
+	}
+
+	// Our backbone router-LSA has a link for each virtual link.
+	if changed {
+		inst.scheduleRouterLSAs()
+	}
+}
+
+// updateVirtualLink recalculates the virtual link's cost, addresses and
+// next hops, and tells Run to bring it up or down if the other end has
+// become reachable or unreachable. The other end must be an area border
+// router in the transit area. It reports whether the link's cost or local
+// address changed.
+func (i *Interface) updateVirtualLink() bool {
+	vl := i.vlink
+
+	var (
+		reachable bool
+		remote    netip.Addr
+		nextHops  []nextHop
+		prefix    netip.Prefix
+		cost      uint16
+	)
+
+	if area, ok := i.instance.Areas[vl.transitAreaID]; ok {
+		v, ok := area.spt[vertexID{t: vertexRouter, id: addrFromRouterID(vl.endpoint)}]
+		if ok && len(v.nextHops) > 0 && v.lsa.(*routerLSA).IsABR() {
+			remote = endpointAddr(area, v)
+			nextHops = v.nextHops
+			prefix = netip.PrefixFrom(nextHops[0].iface.Prefix.Addr(), 32)
+			cost = 0xffff
+			if v.distance < 0xffff {
+				cost = uint16(v.distance)
+			}
+
+			reachable = remote.IsValid()
+		}
+	}
+
+	if !reachable {
+		remote = netip.Addr{}
+		nextHops = nil
+		prefix = i.Prefix
+		cost = i.Cost
+	}
+
+	changed := cost != i.Cost || prefix != i.Prefix
+
+	if reachable && (changed || remote != vl.remoteAddr) {
+		fmt.Printf("ospf: %s: cost %d, local address %s, remote address %s\n", i.name, cost, prefix.Addr(), remote)
+	}
+
+	i.Cost = cost
+	i.Prefix = prefix
+	vl.remoteAddr = remote
+	vl.nextHops = nextHops
+	if reachable {
+		i.mtu = nextHops[0].iface.mtu
+	}
+
+	if reachable != vl.reachable {
+		vl.reachable = reachable
+
+		select {
+		case vl.changed <- struct{}{}:
+		default:
+		}
+	}
+
+	return changed
+}
+
+// endpointAddr returns the address of the interface that the other end of
+// a virtual link, v, is reached through: the Link Data of the link in its
+// router-LSA that leads back to its parent in the shortest-path tree.
+func endpointAddr(area *Area, v *vertex) netip.Addr {
+	for _, link := range v.lsa.(*routerLSA).Links() {
+		var parent *vertex
+		var cost uint32
+
+		switch link.Type {
+		case linkTransit:
+			parent = area.spt[vertexID{t: vertexNetwork, id: link.ID}]
+		case linkPointToPoint:
+			parent = area.spt[vertexID{t: vertexRouter, id: link.ID}]
+			if parent == nil {
+				continue
+			}
+
+			c, ok := linkCost(parent.lsa.(*routerLSA), v.id)
+			if !ok {
+				continue
+			}
+
+			cost = c
+		}
+
+		if parent != nil && parent.distance+cost == v.distance {
+			return link.Data
+		}
+	}
+
+	return netip.Addr{}
+}
+
+// linkCost returns the cost of r's point-to-point link to the router id.
+func linkCost(r *routerLSA, id netip.Addr) (uint32, bool) {
+	for _, link := range r.Links() {
+		if link.Type == linkPointToPoint && link.ID == id {
+			return uint32(link.Metric), true
+		}
+	}
+
+	return 0, false
+}
+
+// syncVirtualLinkState brings the virtual link up or down to match whether
+// the other end is reachable.
+func (i *Interface) syncVirtualLinkState() {
+	if i.vlink.reachable {
+		i.handleEvent(ieInterfaceUp)
+	} else {
+		i.handleEvent(ieInterfaceDown)
+	}
+}
+
+// send is called with the instance's lock held. The other end is usually
+// more than one hop away, so ttl is ignored in favor of virtualLinkTTL.
+func (vl *virtualLink) send(data []byte, dst netip.Addr, ttl int) error {
+	select {
+	case <-vl.closed:
+		return errTransportClosed
+	default:
+	}
+
+	if len(vl.nextHops) == 0 {
+		return fmt.Errorf("%s is unreachable", vl.endpoint)
+	}
+
+	return vl.nextHops[0].iface.transport.send(data, dst, virtualLinkTTL)
+}
+
+// deliver queues a packet received by an interface in the transit area.
+// Like a real network, packets are dropped if the virtual link falls too
+// far behind.
+func (vl *virtualLink) deliver(p *receivedPacket) {
+	select {
+	case vl.packets <- p:
+	default:
+	}
+}
+
+func (vl *virtualLink) receive() (*receivedPacket, error) {
+	select {
+	case p := <-vl.packets:
+		return p, nil
+	case <-vl.closed:
+		return nil, errTransportClosed
+	}
+}
+
+// Virtual links are unicast only.
+func (vl *virtualLink) joinGroup(group netip.Addr) error {
+	return nil
+}
+
+func (vl *virtualLink) leaveGroup(group netip.Addr) error {
+	return nil
+}
+
+func (vl *virtualLink) close() error {
+	vl.closeOnce.Do(func() {
+		close(vl.closed)
+	})
+
+	return nil
+}
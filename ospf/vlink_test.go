@@ -0,0 +1,353 @@
+package ospf
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/davidbalbert/chatter/chatterd/common"
+	"github.com/davidbalbert/chatter/config"
+	"golang.org/x/sync/errgroup"
+)
+
+func testVirtualLinkConfig() config.OSPFVirtualLinkConfig {
+	return config.OSPFVirtualLinkConfig{
+		HelloInterval:      1,
+		RouterDeadInterval: 4,
+	}
+}
+
+// newTestVirtualLinkABR returns the area border router from newTestABR,
+// with 3.3.3.3 also an area border router, and a virtual link to it
+// through area 1 that isn't running.
+func newTestVirtualLinkABR(t *testing.T) (*Instance, *Interface) {
+	t.Helper()
+
+	inst := newTestABR(t)
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.installLSA(1, testRouterLSAWithLinks("3.3.3.3", routerFlagB|routerFlagE,
+		testLink(linkTransit, "10.1.0.1", "10.1.0.3", 10),
+		testLink(linkStub, "172.16.1.0", "255.255.255.0", 1),
+	))
+
+	vl := newVirtualLink(inst, 1, mustParseRouterID("3.3.3.3"), testVirtualLinkConfig())
+	inst.Interfaces[virtualLinkID(vl.vlink.endpoint)] = vl
+
+	return inst, vl
+}
+
+// fullVirtualNeighbor makes the other end of the virtual link a Full
+// neighbor, as if the link were up and adjacent.
+func fullVirtualNeighbor(vl *Interface) {
+	vl.State = iPointToPoint
+
+	n := newNeighbor(vl, vl.vlink.endpoint, vl.vlink.remoteAddr)
+	n.state = nFull
+	vl.Neighbors[n.ID] = n
+}
+
+func TestVirtualLinkFromTransitArea(t *testing.T) {
+	inst, vl := newTestVirtualLinkABR(t)
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.runSPF()
+
+	if !vl.vlink.reachable {
+		t.Fatalf("expected 3.3.3.3 to be reachable")
+	}
+
+	select {
+	case <-vl.vlink.changed:
+	default:
+		t.Errorf("expected Run to be told the link is reachable")
+	}
+
+	if vl.Cost != 10 {
+		t.Errorf("expected cost 10, got %d", vl.Cost)
+	}
+
+	if vl.Prefix != netip.MustParsePrefix("10.1.0.1/32") {
+		t.Errorf("expected local address 10.1.0.1/32, got %s", vl.Prefix)
+	}
+
+	if vl.vlink.remoteAddr != netip.MustParseAddr("10.1.0.3") {
+		t.Errorf("expected remote address 10.1.0.3, got %s", vl.vlink.remoteAddr)
+	}
+
+	if len(vl.vlink.nextHops) != 1 || vl.vlink.nextHops[0].iface.name != "eth1" {
+		t.Errorf("expected next hop through eth1, got %v", vl.vlink.nextHops)
+	}
+
+	if dsts := vl.helloDestinations(false); len(dsts) != 1 || dsts[0] != vl.vlink.remoteAddr {
+		t.Errorf("expected Hellos to be sent to %s, got %v", vl.vlink.remoteAddr, dsts)
+	}
+
+	// 3.3.3.3 is no longer an area border router.
+	inst.installLSA(1, testRouterLSAWithLinks("3.3.3.3", routerFlagE,
+		testLink(linkTransit, "10.1.0.1", "10.1.0.3", 10),
+	))
+	inst.runSPF()
+
+	if vl.vlink.reachable || len(vl.vlink.nextHops) != 0 {
+		t.Errorf("expected 3.3.3.3 to be unreachable over the virtual link")
+	}
+}
+
+func TestVirtualLinkRouterLSAs(t *testing.T) {
+	inst, vl := newTestVirtualLinkABR(t)
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.runSPF()
+
+	if ourRouterLSA(inst, 1).IsVirtualLinkEndpoint() {
+		t.Errorf("expected no V-bit before the virtual link is adjacent")
+	}
+
+	fullVirtualNeighbor(vl)
+
+	backbone := inst.buildRouterLSA(0).(*routerLSA)
+
+	var found bool
+	for _, link := range backbone.Links() {
+		if link.Type == linkVirtual {
+			found = true
+
+			if link.ID != netip.MustParseAddr("3.3.3.3") || link.Data != netip.MustParseAddr("10.1.0.1") || link.Metric != 10 {
+				t.Errorf("unexpected virtual link: %+v", link)
+			}
+		}
+	}
+
+	if !found {
+		t.Errorf("expected a virtual link in our backbone router-LSA, got %+v", backbone.Links())
+	}
+
+	if !inst.buildRouterLSA(1).(*routerLSA).IsVirtualLinkEndpoint() {
+		t.Errorf("expected the V-bit in our router-LSA for area 1")
+	}
+
+	if inst.buildRouterLSA(0).(*routerLSA).IsVirtualLinkEndpoint() {
+		t.Errorf("expected no V-bit in our backbone router-LSA")
+	}
+}
+
+func TestVirtualLinkBackboneRoutes(t *testing.T) {
+	inst, vl := newTestVirtualLinkABR(t)
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.runSPF()
+	fullVirtualNeighbor(vl)
+	allowOriginations(inst)
+	inst.scheduleRouterLSAs()
+
+	inst.installLSA(0, testRouterLSAWithLinks("3.3.3.3", routerFlagB,
+		testLink(linkVirtual, "1.1.1.1", "10.1.0.3", 10),
+		testLink(linkStub, "192.0.2.0", "255.255.255.0", 5),
+	))
+	inst.runSPF()
+
+	expectRIBRoute(t, inst, "192.0.2.0/24", PathIntraArea, 15, 0, "10.1.0.3")
+
+	if r := inst.rib[netip.MustParsePrefix("192.0.2.0/24")]; r.areaID != 0 || r.nextHops[0].iface.name != "eth1" {
+		t.Errorf("expected a backbone route through eth1, got area %s through %s", r.areaID, r.nextHops[0].iface.name)
+	}
+
+	// Backbone routes through the transit area aren't summarized back
+	// into it.
+	if _, ok := summaryMetrics(inst, 1)["192.0.2.0/24"]; ok {
+		t.Errorf("expected no summary of 192.0.2.0/24 in area 1")
+	}
+}
+
+func TestVirtualLinkReceive(t *testing.T) {
+	inst, vl := newTestVirtualLinkABR(t)
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.runSPF()
+
+	eth1 := inst.Interfaces[interfaceID{name: "eth1", prefix: netip.MustParsePrefix("10.1.0.1/24")}]
+
+	hello := testHello("3.3.3.3")
+	hello.areaID = 0
+
+	data, err := hello.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Addressed to the interface in the transit area, from an address that
+	// isn't on its network.
+	eth1.handlePacket(&receivedPacket{
+		data:    data,
+		src:     netip.MustParseAddr("10.9.0.3"),
+		dst:     netip.MustParseAddr("10.1.0.1"),
+		ifindex: eth1.ifindex,
+	})
+
+	select {
+	case p := <-vl.vlink.packets:
+		if p.src != netip.MustParseAddr("10.9.0.3") {
+			t.Errorf("unexpected packet from %s", p.src)
+		}
+	default:
+		t.Fatalf("expected the packet to be handed to the virtual link")
+	}
+}
+
+// startTestVirtualLinks runs inst's configured virtual links until the test
+// finishes.
+func startTestVirtualLinks(t *testing.T, inst *Instance) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+
+	t.Cleanup(func() {
+		cancel()
+		g.Wait()
+	})
+
+	inst.startVirtualLinks(ctx, g)
+}
+
+func TestVirtualLinkBetweenInstances(t *testing.T) {
+	transit := newMemNetwork()
+
+	area1 := testInterfaceConfig()
+	area1.AreaID = 1
+
+	area2 := testInterfaceConfig()
+	area2.AreaID = 2
+
+	// r1 is attached to the backbone and area 1. r2 is attached to areas 1
+	// and 2, and reaches the backbone through a virtual link to r1.
+	r1 := newTestInstance(t, "1.1.1.1", transit, map[string]config.OSPFInterfaceConfig{"eth0": area1, "eth1": testInterfaceConfig()})
+	r2 := newTestInstance(t, "2.2.2.2", transit, map[string]config.OSPFInterfaceConfig{"eth0": area1, "eth1": area2})
+	r2.Areas[0] = newArea(0, config.OSPFAreaConfig{})
+
+	for _, inst := range []*Instance{r1, r2} {
+		other := r1.RouterID
+		if inst == r1 {
+			other = r2.RouterID
+		}
+
+		ac := inst.config.Areas[1]
+		ac.VirtualLinks = map[common.RouterID]config.OSPFVirtualLinkConfig{other: testVirtualLinkConfig()}
+		inst.config.Areas[1] = ac
+	}
+
+	startTestInterface(t, r1, testNetif("eth0", 1, "10.1.0.1/24"))
+	startTestInterface(t, r2, testNetif("eth0", 2, "10.1.0.2/24"))
+
+	// Each router's other network has no other routers on it.
+	r1.newTransport = newMemNetwork().newTransport
+	startTestInterface(t, r1, testNetif("eth1", 3, "10.0.0.1/24"))
+
+	r2.newTransport = newMemNetwork().newTransport
+	startTestInterface(t, r2, testNetif("eth1", 4, "10.2.0.2/24"))
+
+	for _, inst := range []*Instance{r1, r2} {
+		startTestVirtualLinks(t, inst)
+	}
+
+	for _, inst := range []*Instance{r1, r2} {
+		waitFor(t, inst, 20*time.Second, fmt.Sprintf("%s to have an adjacent virtual link", inst.RouterID), func() bool {
+			for _, vl := range inst.virtualLinks() {
+				if len(vl.fullNeighbors()) == 1 {
+					return true
+				}
+			}
+
+			return false
+		})
+	}
+
+	// r2 reaches the backbone network through the virtual link, and r1
+	// learns about area 2 from the summary r2 originates into the
+	// backbone.
+	waitFor(t, r2, 20*time.Second, "r2 to have a backbone route to 10.0.0.0/24", func() bool {
+		r, ok := r2.rib[netip.MustParsePrefix("10.0.0.0/24")]
+		return ok && r.areaID == 0 && r.pathType == PathIntraArea && len(r.nextHops) == 1 && r.nextHops[0].addr == netip.MustParseAddr("10.1.0.1")
+	})
+
+	waitFor(t, r1, 20*time.Second, "r1 to have a route to 10.2.0.0/24 through the backbone", func() bool {
+		r, ok := r1.rib[netip.MustParsePrefix("10.2.0.0/24")]
+		return ok && r.areaID == 0 && r.pathType == PathInterArea
+	})
+}
+
+func TestVirtualLinkAcrossRouters(t *testing.T) {
+	a := newMemNetwork()
+	b := newMemNetwork()
+
+	// r3 forwards packets between a and b.
+	a.addRoute(netip.MustParsePrefix("10.3.0.0/24"), b)
+	b.addRoute(netip.MustParsePrefix("10.1.0.0/24"), a)
+
+	area1 := testInterfaceConfig()
+	area1.AreaID = 1
+
+	area2 := testInterfaceConfig()
+	area2.AreaID = 2
+
+	// r1 is attached to the backbone and area 1, and r2 to areas 1 and 2.
+	// Their networks in area 1 are joined by r3, so the virtual link
+	// between them crosses more than one hop.
+	r1 := newTestInstance(t, "1.1.1.1", a, map[string]config.OSPFInterfaceConfig{"eth0": area1, "eth1": testInterfaceConfig()})
+	r2 := newTestInstance(t, "2.2.2.2", b, map[string]config.OSPFInterfaceConfig{"eth0": area1, "eth1": area2})
+	r3 := newTestInstance(t, "3.3.3.3", a, map[string]config.OSPFInterfaceConfig{"eth0": area1, "eth1": area1})
+	r2.Areas[0] = newArea(0, config.OSPFAreaConfig{})
+
+	for _, inst := range []*Instance{r1, r2} {
+		other := r1.RouterID
+		if inst == r1 {
+			other = r2.RouterID
+		}
+
+		ac := inst.config.Areas[1]
+		ac.VirtualLinks = map[common.RouterID]config.OSPFVirtualLinkConfig{other: testVirtualLinkConfig()}
+		inst.config.Areas[1] = ac
+	}
+
+	startTestInterface(t, r1, testNetif("eth0", 1, "10.1.0.1/24"))
+	startTestInterface(t, r3, testNetif("eth0", 2, "10.1.0.3/24"))
+
+	r3.newTransport = b.newTransport
+	startTestInterface(t, r3, testNetif("eth1", 3, "10.3.0.3/24"))
+	startTestInterface(t, r2, testNetif("eth0", 4, "10.3.0.2/24"))
+
+	r1.newTransport = newMemNetwork().newTransport
+	startTestInterface(t, r1, testNetif("eth1", 5, "10.0.0.1/24"))
+
+	r2.newTransport = newMemNetwork().newTransport
+	startTestInterface(t, r2, testNetif("eth1", 6, "10.2.0.2/24"))
+
+	for _, inst := range []*Instance{r1, r2} {
+		startTestVirtualLinks(t, inst)
+	}
+
+	for _, inst := range []*Instance{r1, r2} {
+		waitFor(t, inst, 30*time.Second, fmt.Sprintf("%s to have an adjacent virtual link", inst.RouterID), func() bool {
+			for _, vl := range inst.virtualLinks() {
+				if len(vl.fullNeighbors()) == 1 {
+					return true
+				}
+			}
+
+			return false
+		})
+	}
+}
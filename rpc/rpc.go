@@ -14,6 +14,13 @@ type APIService interface {
 	GetServices(ctx context.Context) ([]config.ServiceID, error)
 
 	GetInterfaces(ctx context.Context) ([]*Interface, error)
+
+	GetOSPFInstance(ctx context.Context) (*OSPFInstance, error)
+	GetOSPFInterfaces(ctx context.Context) ([]*OSPFInterface, error)
+	GetOSPFNeighbors(ctx context.Context) ([]*OSPFNeighbor, error)
+	GetOSPFRoutes(ctx context.Context) ([]*OSPFRoute, error)
+	GetOSPFAreas(ctx context.Context) ([]*OSPFArea, error)
+	SetOSPFMaxMetric(ctx context.Context, enabled bool) error
 }
 
 type Server struct {
@@ -77,3 +84,67 @@ func (s *Server) GetInterfaces(ctx context.Context, req *GetInterfacesRequest) (
 		Interfaces: ifaces,
 	}, nil
 }
+
+func (s *Server) GetOSPFInstance(ctx context.Context, req *GetOSPFInstanceRequest) (*GetOSPFInstanceReply, error) {
+	instance, err := s.apiService.GetOSPFInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetOSPFInstanceReply{
+		Instance: instance,
+	}, nil
+}
+
+func (s *Server) GetOSPFInterfaces(ctx context.Context, req *GetOSPFInterfacesRequest) (*GetOSPFInterfacesReply, error) {
+	ifaces, err := s.apiService.GetOSPFInterfaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetOSPFInterfacesReply{
+		Interfaces: ifaces,
+	}, nil
+}
+
+func (s *Server) GetOSPFNeighbors(ctx context.Context, req *GetOSPFNeighborsRequest) (*GetOSPFNeighborsReply, error) {
+	neighbors, err := s.apiService.GetOSPFNeighbors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetOSPFNeighborsReply{
+		Neighbors: neighbors,
+	}, nil
+}
+
+func (s *Server) GetOSPFRoutes(ctx context.Context, req *GetOSPFRoutesRequest) (*GetOSPFRoutesReply, error) {
+	routes, err := s.apiService.GetOSPFRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetOSPFRoutesReply{
+		Routes: routes,
+	}, nil
+}
+
+func (s *Server) GetOSPFAreas(ctx context.Context, req *GetOSPFAreasRequest) (*GetOSPFAreasReply, error) {
+	areas, err := s.apiService.GetOSPFAreas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetOSPFAreasReply{
+		Areas: areas,
+	}, nil
+}
+
+func (s *Server) SetOSPFMaxMetric(ctx context.Context, req *SetOSPFMaxMetricRequest) (*SetOSPFMaxMetricReply, error) {
+	err := s.apiService.SetOSPFMaxMetric(ctx, req.Enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SetOSPFMaxMetricReply{}, nil
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.28.1
+// 	protoc-gen-go v1.30.0
 // 	protoc        v3.21.12
 // source: rpc.proto
 
@@ -548,6 +548,1980 @@ func (x *Prefix) GetPrefixLen() int32 {
 	return 0
 }
 
+type GetOSPFInstanceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetOSPFInstanceRequest) Reset() {
+	*x = GetOSPFInstanceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOSPFInstanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOSPFInstanceRequest) ProtoMessage() {}
+
+func (x *GetOSPFInstanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOSPFInstanceRequest.ProtoReflect.Descriptor instead.
+func (*GetOSPFInstanceRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{11}
+}
+
+type GetOSPFInstanceReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Instance *OSPFInstance `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+}
+
+func (x *GetOSPFInstanceReply) Reset() {
+	*x = GetOSPFInstanceReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOSPFInstanceReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOSPFInstanceReply) ProtoMessage() {}
+
+func (x *GetOSPFInstanceReply) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOSPFInstanceReply.ProtoReflect.Descriptor instead.
+func (*GetOSPFInstanceReply) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetOSPFInstanceReply) GetInstance() *OSPFInstance {
+	if x != nil {
+		return x.Instance
+	}
+	return nil
+}
+
+type OSPFInstance struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RouterId        uint32               `protobuf:"varint,1,opt,name=router_id,json=routerId,proto3" json:"router_id,omitempty"`
+	GracefulRestart *OSPFGracefulRestart `protobuf:"bytes,2,opt,name=graceful_restart,json=gracefulRestart,proto3" json:"graceful_restart,omitempty"`
+	MaxMetric       *OSPFMaxMetric       `protobuf:"bytes,3,opt,name=max_metric,json=maxMetric,proto3" json:"max_metric,omitempty"`
+	Spf             *OSPFSPF             `protobuf:"bytes,4,opt,name=spf,proto3" json:"spf,omitempty"`
+	LsaThrottle     *OSPFThrottle        `protobuf:"bytes,5,opt,name=lsa_throttle,json=lsaThrottle,proto3" json:"lsa_throttle,omitempty"`
+}
+
+func (x *OSPFInstance) Reset() {
+	*x = OSPFInstance{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFInstance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFInstance) ProtoMessage() {}
+
+func (x *OSPFInstance) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFInstance.ProtoReflect.Descriptor instead.
+func (*OSPFInstance) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *OSPFInstance) GetRouterId() uint32 {
+	if x != nil {
+		return x.RouterId
+	}
+	return 0
+}
+
+func (x *OSPFInstance) GetGracefulRestart() *OSPFGracefulRestart {
+	if x != nil {
+		return x.GracefulRestart
+	}
+	return nil
+}
+
+func (x *OSPFInstance) GetMaxMetric() *OSPFMaxMetric {
+	if x != nil {
+		return x.MaxMetric
+	}
+	return nil
+}
+
+func (x *OSPFInstance) GetSpf() *OSPFSPF {
+	if x != nil {
+		return x.Spf
+	}
+	return nil
+}
+
+func (x *OSPFInstance) GetLsaThrottle() *OSPFThrottle {
+	if x != nil {
+		return x.LsaThrottle
+	}
+	return nil
+}
+
+type OSPFGracefulRestart struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Enabled           bool   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	GracePeriod       uint32 `protobuf:"varint,2,opt,name=grace_period,json=gracePeriod,proto3" json:"grace_period,omitempty"`
+	Helper            bool   `protobuf:"varint,3,opt,name=helper,proto3" json:"helper,omitempty"`
+	StrictLsaChecking bool   `protobuf:"varint,4,opt,name=strict_lsa_checking,json=strictLsaChecking,proto3" json:"strict_lsa_checking,omitempty"`
+	Restarting        bool   `protobuf:"varint,5,opt,name=restarting,proto3" json:"restarting,omitempty"`
+	GraceRemaining    int64  `protobuf:"varint,6,opt,name=grace_remaining,json=graceRemaining,proto3" json:"grace_remaining,omitempty"`
+	Reason            string `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *OSPFGracefulRestart) Reset() {
+	*x = OSPFGracefulRestart{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFGracefulRestart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFGracefulRestart) ProtoMessage() {}
+
+func (x *OSPFGracefulRestart) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFGracefulRestart.ProtoReflect.Descriptor instead.
+func (*OSPFGracefulRestart) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *OSPFGracefulRestart) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *OSPFGracefulRestart) GetGracePeriod() uint32 {
+	if x != nil {
+		return x.GracePeriod
+	}
+	return 0
+}
+
+func (x *OSPFGracefulRestart) GetHelper() bool {
+	if x != nil {
+		return x.Helper
+	}
+	return false
+}
+
+func (x *OSPFGracefulRestart) GetStrictLsaChecking() bool {
+	if x != nil {
+		return x.StrictLsaChecking
+	}
+	return false
+}
+
+func (x *OSPFGracefulRestart) GetRestarting() bool {
+	if x != nil {
+		return x.Restarting
+	}
+	return false
+}
+
+func (x *OSPFGracefulRestart) GetGraceRemaining() int64 {
+	if x != nil {
+		return x.GraceRemaining
+	}
+	return 0
+}
+
+func (x *OSPFGracefulRestart) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type OSPFMaxMetric struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Administrative   bool   `protobuf:"varint,1,opt,name=administrative,proto3" json:"administrative,omitempty"`
+	OnStartup        uint32 `protobuf:"varint,2,opt,name=on_startup,json=onStartup,proto3" json:"on_startup,omitempty"`
+	Advertising      bool   `protobuf:"varint,3,opt,name=advertising,proto3" json:"advertising,omitempty"`
+	OnRequest        bool   `protobuf:"varint,4,opt,name=on_request,json=onRequest,proto3" json:"on_request,omitempty"`
+	StartupRemaining int64  `protobuf:"varint,5,opt,name=startup_remaining,json=startupRemaining,proto3" json:"startup_remaining,omitempty"`
+}
+
+func (x *OSPFMaxMetric) Reset() {
+	*x = OSPFMaxMetric{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFMaxMetric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFMaxMetric) ProtoMessage() {}
+
+func (x *OSPFMaxMetric) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFMaxMetric.ProtoReflect.Descriptor instead.
+func (*OSPFMaxMetric) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *OSPFMaxMetric) GetAdministrative() bool {
+	if x != nil {
+		return x.Administrative
+	}
+	return false
+}
+
+func (x *OSPFMaxMetric) GetOnStartup() uint32 {
+	if x != nil {
+		return x.OnStartup
+	}
+	return 0
+}
+
+func (x *OSPFMaxMetric) GetAdvertising() bool {
+	if x != nil {
+		return x.Advertising
+	}
+	return false
+}
+
+func (x *OSPFMaxMetric) GetOnRequest() bool {
+	if x != nil {
+		return x.OnRequest
+	}
+	return false
+}
+
+func (x *OSPFMaxMetric) GetStartupRemaining() int64 {
+	if x != nil {
+		return x.StartupRemaining
+	}
+	return 0
+}
+
+type OSPFThrottle struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	InitialDelay int64 `protobuf:"varint,1,opt,name=initial_delay,json=initialDelay,proto3" json:"initial_delay,omitempty"`
+	Hold         int64 `protobuf:"varint,2,opt,name=hold,proto3" json:"hold,omitempty"`
+	MaxWait      int64 `protobuf:"varint,3,opt,name=max_wait,json=maxWait,proto3" json:"max_wait,omitempty"`
+}
+
+func (x *OSPFThrottle) Reset() {
+	*x = OSPFThrottle{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFThrottle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFThrottle) ProtoMessage() {}
+
+func (x *OSPFThrottle) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFThrottle.ProtoReflect.Descriptor instead.
+func (*OSPFThrottle) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *OSPFThrottle) GetInitialDelay() int64 {
+	if x != nil {
+		return x.InitialDelay
+	}
+	return 0
+}
+
+func (x *OSPFThrottle) GetHold() int64 {
+	if x != nil {
+		return x.Hold
+	}
+	return 0
+}
+
+func (x *OSPFThrottle) GetMaxWait() int64 {
+	if x != nil {
+		return x.MaxWait
+	}
+	return 0
+}
+
+type OSPFSPF struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Throttle  *OSPFThrottle `protobuf:"bytes,1,opt,name=throttle,proto3" json:"throttle,omitempty"`
+	Hold      int64         `protobuf:"varint,2,opt,name=hold,proto3" json:"hold,omitempty"`
+	Scheduled bool          `protobuf:"varint,3,opt,name=scheduled,proto3" json:"scheduled,omitempty"`
+	Runs      uint64        `protobuf:"varint,4,opt,name=runs,proto3" json:"runs,omitempty"`
+	History   []*OSPFSPFRun `protobuf:"bytes,5,rep,name=history,proto3" json:"history,omitempty"`
+}
+
+func (x *OSPFSPF) Reset() {
+	*x = OSPFSPF{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFSPF) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFSPF) ProtoMessage() {}
+
+func (x *OSPFSPF) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFSPF.ProtoReflect.Descriptor instead.
+func (*OSPFSPF) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *OSPFSPF) GetThrottle() *OSPFThrottle {
+	if x != nil {
+		return x.Throttle
+	}
+	return nil
+}
+
+func (x *OSPFSPF) GetHold() int64 {
+	if x != nil {
+		return x.Hold
+	}
+	return 0
+}
+
+func (x *OSPFSPF) GetScheduled() bool {
+	if x != nil {
+		return x.Scheduled
+	}
+	return false
+}
+
+func (x *OSPFSPF) GetRuns() uint64 {
+	if x != nil {
+		return x.Runs
+	}
+	return 0
+}
+
+func (x *OSPFSPF) GetHistory() []*OSPFSPFRun {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+type OSPFSPFRun struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StartUnixNano int64             `protobuf:"varint,1,opt,name=start_unix_nano,json=startUnixNano,proto3" json:"start_unix_nano,omitempty"`
+	Duration      int64             `protobuf:"varint,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	Triggers      []*OSPFSPFTrigger `protobuf:"bytes,3,rep,name=triggers,proto3" json:"triggers,omitempty"`
+	TriggerCount  uint32            `protobuf:"varint,4,opt,name=trigger_count,json=triggerCount,proto3" json:"trigger_count,omitempty"`
+}
+
+func (x *OSPFSPFRun) Reset() {
+	*x = OSPFSPFRun{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFSPFRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFSPFRun) ProtoMessage() {}
+
+func (x *OSPFSPFRun) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFSPFRun.ProtoReflect.Descriptor instead.
+func (*OSPFSPFRun) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *OSPFSPFRun) GetStartUnixNano() int64 {
+	if x != nil {
+		return x.StartUnixNano
+	}
+	return 0
+}
+
+func (x *OSPFSPFRun) GetDuration() int64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *OSPFSPFRun) GetTriggers() []*OSPFSPFTrigger {
+	if x != nil {
+		return x.Triggers
+	}
+	return nil
+}
+
+func (x *OSPFSPFRun) GetTriggerCount() uint32 {
+	if x != nil {
+		return x.TriggerCount
+	}
+	return 0
+}
+
+type OSPFSPFTrigger struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AreaId            uint32 `protobuf:"varint,1,opt,name=area_id,json=areaId,proto3" json:"area_id,omitempty"`
+	Type              string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Id                []byte `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	AdvertisingRouter uint32 `protobuf:"varint,4,opt,name=advertising_router,json=advertisingRouter,proto3" json:"advertising_router,omitempty"`
+	Flushed           bool   `protobuf:"varint,5,opt,name=flushed,proto3" json:"flushed,omitempty"`
+}
+
+func (x *OSPFSPFTrigger) Reset() {
+	*x = OSPFSPFTrigger{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFSPFTrigger) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFSPFTrigger) ProtoMessage() {}
+
+func (x *OSPFSPFTrigger) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFSPFTrigger.ProtoReflect.Descriptor instead.
+func (*OSPFSPFTrigger) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *OSPFSPFTrigger) GetAreaId() uint32 {
+	if x != nil {
+		return x.AreaId
+	}
+	return 0
+}
+
+func (x *OSPFSPFTrigger) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *OSPFSPFTrigger) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *OSPFSPFTrigger) GetAdvertisingRouter() uint32 {
+	if x != nil {
+		return x.AdvertisingRouter
+	}
+	return 0
+}
+
+func (x *OSPFSPFTrigger) GetFlushed() bool {
+	if x != nil {
+		return x.Flushed
+	}
+	return false
+}
+
+type SetOSPFMaxMetricRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+}
+
+func (x *SetOSPFMaxMetricRequest) Reset() {
+	*x = SetOSPFMaxMetricRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetOSPFMaxMetricRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOSPFMaxMetricRequest) ProtoMessage() {}
+
+func (x *SetOSPFMaxMetricRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOSPFMaxMetricRequest.ProtoReflect.Descriptor instead.
+func (*SetOSPFMaxMetricRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SetOSPFMaxMetricRequest) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type SetOSPFMaxMetricReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SetOSPFMaxMetricReply) Reset() {
+	*x = SetOSPFMaxMetricReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetOSPFMaxMetricReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOSPFMaxMetricReply) ProtoMessage() {}
+
+func (x *SetOSPFMaxMetricReply) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOSPFMaxMetricReply.ProtoReflect.Descriptor instead.
+func (*SetOSPFMaxMetricReply) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{21}
+}
+
+type GetOSPFInterfacesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetOSPFInterfacesRequest) Reset() {
+	*x = GetOSPFInterfacesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOSPFInterfacesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOSPFInterfacesRequest) ProtoMessage() {}
+
+func (x *GetOSPFInterfacesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOSPFInterfacesRequest.ProtoReflect.Descriptor instead.
+func (*GetOSPFInterfacesRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{22}
+}
+
+type GetOSPFInterfacesReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Interfaces []*OSPFInterface `protobuf:"bytes,1,rep,name=interfaces,proto3" json:"interfaces,omitempty"`
+}
+
+func (x *GetOSPFInterfacesReply) Reset() {
+	*x = GetOSPFInterfacesReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOSPFInterfacesReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOSPFInterfacesReply) ProtoMessage() {}
+
+func (x *GetOSPFInterfacesReply) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOSPFInterfacesReply.ProtoReflect.Descriptor instead.
+func (*GetOSPFInterfacesReply) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetOSPFInterfacesReply) GetInterfaces() []*OSPFInterface {
+	if x != nil {
+		return x.Interfaces
+	}
+	return nil
+}
+
+type OSPFInterface struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name                   string              `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Addr                   *Prefix             `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	AreaId                 uint32              `protobuf:"varint,3,opt,name=area_id,json=areaId,proto3" json:"area_id,omitempty"`
+	Type                   string              `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	State                  string              `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	Cost                   uint32              `protobuf:"varint,6,opt,name=cost,proto3" json:"cost,omitempty"`
+	Priority               uint32              `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	HelloInterval          uint32              `protobuf:"varint,8,opt,name=hello_interval,json=helloInterval,proto3" json:"hello_interval,omitempty"`
+	DeadInterval           uint32              `protobuf:"varint,9,opt,name=dead_interval,json=deadInterval,proto3" json:"dead_interval,omitempty"`
+	DesignatedRouter       *OSPFRouter         `protobuf:"bytes,10,opt,name=designated_router,json=designatedRouter,proto3" json:"designated_router,omitempty"`
+	BackupDesignatedRouter *OSPFRouter         `protobuf:"bytes,11,opt,name=backup_designated_router,json=backupDesignatedRouter,proto3" json:"backup_designated_router,omitempty"`
+	Neighbors              uint32              `protobuf:"varint,12,opt,name=neighbors,proto3" json:"neighbors,omitempty"`
+	AdjacentNeighbors      uint32              `protobuf:"varint,13,opt,name=adjacent_neighbors,json=adjacentNeighbors,proto3" json:"adjacent_neighbors,omitempty"`
+	Stats                  *OSPFInterfaceStats `protobuf:"bytes,14,opt,name=stats,proto3" json:"stats,omitempty"`
+	TransitAreaId          uint32              `protobuf:"varint,15,opt,name=transit_area_id,json=transitAreaId,proto3" json:"transit_area_id,omitempty"`
+	VirtualLinkEndpoint    uint32              `protobuf:"varint,16,opt,name=virtual_link_endpoint,json=virtualLinkEndpoint,proto3" json:"virtual_link_endpoint,omitempty"`
+	RemoteAddr             []byte              `protobuf:"bytes,17,opt,name=remote_addr,json=remoteAddr,proto3" json:"remote_addr,omitempty"`
+	AuthType               string              `protobuf:"bytes,18,opt,name=auth_type,json=authType,proto3" json:"auth_type,omitempty"`
+	AuthKeyId              uint32              `protobuf:"varint,19,opt,name=auth_key_id,json=authKeyId,proto3" json:"auth_key_id,omitempty"`
+	AuthAlgorithm          string              `protobuf:"bytes,20,opt,name=auth_algorithm,json=authAlgorithm,proto3" json:"auth_algorithm,omitempty"`
+	Passive                bool                `protobuf:"varint,21,opt,name=passive,proto3" json:"passive,omitempty"`
+}
+
+func (x *OSPFInterface) Reset() {
+	*x = OSPFInterface{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFInterface) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFInterface) ProtoMessage() {}
+
+func (x *OSPFInterface) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFInterface.ProtoReflect.Descriptor instead.
+func (*OSPFInterface) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *OSPFInterface) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *OSPFInterface) GetAddr() *Prefix {
+	if x != nil {
+		return x.Addr
+	}
+	return nil
+}
+
+func (x *OSPFInterface) GetAreaId() uint32 {
+	if x != nil {
+		return x.AreaId
+	}
+	return 0
+}
+
+func (x *OSPFInterface) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *OSPFInterface) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *OSPFInterface) GetCost() uint32 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *OSPFInterface) GetPriority() uint32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *OSPFInterface) GetHelloInterval() uint32 {
+	if x != nil {
+		return x.HelloInterval
+	}
+	return 0
+}
+
+func (x *OSPFInterface) GetDeadInterval() uint32 {
+	if x != nil {
+		return x.DeadInterval
+	}
+	return 0
+}
+
+func (x *OSPFInterface) GetDesignatedRouter() *OSPFRouter {
+	if x != nil {
+		return x.DesignatedRouter
+	}
+	return nil
+}
+
+func (x *OSPFInterface) GetBackupDesignatedRouter() *OSPFRouter {
+	if x != nil {
+		return x.BackupDesignatedRouter
+	}
+	return nil
+}
+
+func (x *OSPFInterface) GetNeighbors() uint32 {
+	if x != nil {
+		return x.Neighbors
+	}
+	return 0
+}
+
+func (x *OSPFInterface) GetAdjacentNeighbors() uint32 {
+	if x != nil {
+		return x.AdjacentNeighbors
+	}
+	return 0
+}
+
+func (x *OSPFInterface) GetStats() *OSPFInterfaceStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *OSPFInterface) GetTransitAreaId() uint32 {
+	if x != nil {
+		return x.TransitAreaId
+	}
+	return 0
+}
+
+func (x *OSPFInterface) GetVirtualLinkEndpoint() uint32 {
+	if x != nil {
+		return x.VirtualLinkEndpoint
+	}
+	return 0
+}
+
+func (x *OSPFInterface) GetRemoteAddr() []byte {
+	if x != nil {
+		return x.RemoteAddr
+	}
+	return nil
+}
+
+func (x *OSPFInterface) GetAuthType() string {
+	if x != nil {
+		return x.AuthType
+	}
+	return ""
+}
+
+func (x *OSPFInterface) GetAuthKeyId() uint32 {
+	if x != nil {
+		return x.AuthKeyId
+	}
+	return 0
+}
+
+func (x *OSPFInterface) GetAuthAlgorithm() string {
+	if x != nil {
+		return x.AuthAlgorithm
+	}
+	return ""
+}
+
+func (x *OSPFInterface) GetPassive() bool {
+	if x != nil {
+		return x.Passive
+	}
+	return false
+}
+
+type OSPFRouter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RouterId uint32 `protobuf:"varint,1,opt,name=router_id,json=routerId,proto3" json:"router_id,omitempty"`
+	Addr     []byte `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+}
+
+func (x *OSPFRouter) Reset() {
+	*x = OSPFRouter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFRouter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFRouter) ProtoMessage() {}
+
+func (x *OSPFRouter) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFRouter.ProtoReflect.Descriptor instead.
+func (*OSPFRouter) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *OSPFRouter) GetRouterId() uint32 {
+	if x != nil {
+		return x.RouterId
+	}
+	return 0
+}
+
+func (x *OSPFRouter) GetAddr() []byte {
+	if x != nil {
+		return x.Addr
+	}
+	return nil
+}
+
+type OSPFInterfaceStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	HellosSent              uint64 `protobuf:"varint,1,opt,name=hellos_sent,json=hellosSent,proto3" json:"hellos_sent,omitempty"`
+	HellosReceived          uint64 `protobuf:"varint,2,opt,name=hellos_received,json=hellosReceived,proto3" json:"hellos_received,omitempty"`
+	NetworkMaskMismatches   uint64 `protobuf:"varint,3,opt,name=network_mask_mismatches,json=networkMaskMismatches,proto3" json:"network_mask_mismatches,omitempty"`
+	HelloIntervalMismatches uint64 `protobuf:"varint,4,opt,name=hello_interval_mismatches,json=helloIntervalMismatches,proto3" json:"hello_interval_mismatches,omitempty"`
+	DeadIntervalMismatches  uint64 `protobuf:"varint,5,opt,name=dead_interval_mismatches,json=deadIntervalMismatches,proto3" json:"dead_interval_mismatches,omitempty"`
+	OptionsMismatches       uint64 `protobuf:"varint,6,opt,name=options_mismatches,json=optionsMismatches,proto3" json:"options_mismatches,omitempty"`
+	AuthTypeMismatches      uint64 `protobuf:"varint,7,opt,name=auth_type_mismatches,json=authTypeMismatches,proto3" json:"auth_type_mismatches,omitempty"`
+	AuthKeyMismatches       uint64 `protobuf:"varint,8,opt,name=auth_key_mismatches,json=authKeyMismatches,proto3" json:"auth_key_mismatches,omitempty"`
+	AuthDigestMismatches    uint64 `protobuf:"varint,9,opt,name=auth_digest_mismatches,json=authDigestMismatches,proto3" json:"auth_digest_mismatches,omitempty"`
+	AuthSequenceErrors      uint64 `protobuf:"varint,10,opt,name=auth_sequence_errors,json=authSequenceErrors,proto3" json:"auth_sequence_errors,omitempty"`
+}
+
+func (x *OSPFInterfaceStats) Reset() {
+	*x = OSPFInterfaceStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFInterfaceStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFInterfaceStats) ProtoMessage() {}
+
+func (x *OSPFInterfaceStats) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFInterfaceStats.ProtoReflect.Descriptor instead.
+func (*OSPFInterfaceStats) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *OSPFInterfaceStats) GetHellosSent() uint64 {
+	if x != nil {
+		return x.HellosSent
+	}
+	return 0
+}
+
+func (x *OSPFInterfaceStats) GetHellosReceived() uint64 {
+	if x != nil {
+		return x.HellosReceived
+	}
+	return 0
+}
+
+func (x *OSPFInterfaceStats) GetNetworkMaskMismatches() uint64 {
+	if x != nil {
+		return x.NetworkMaskMismatches
+	}
+	return 0
+}
+
+func (x *OSPFInterfaceStats) GetHelloIntervalMismatches() uint64 {
+	if x != nil {
+		return x.HelloIntervalMismatches
+	}
+	return 0
+}
+
+func (x *OSPFInterfaceStats) GetDeadIntervalMismatches() uint64 {
+	if x != nil {
+		return x.DeadIntervalMismatches
+	}
+	return 0
+}
+
+func (x *OSPFInterfaceStats) GetOptionsMismatches() uint64 {
+	if x != nil {
+		return x.OptionsMismatches
+	}
+	return 0
+}
+
+func (x *OSPFInterfaceStats) GetAuthTypeMismatches() uint64 {
+	if x != nil {
+		return x.AuthTypeMismatches
+	}
+	return 0
+}
+
+func (x *OSPFInterfaceStats) GetAuthKeyMismatches() uint64 {
+	if x != nil {
+		return x.AuthKeyMismatches
+	}
+	return 0
+}
+
+func (x *OSPFInterfaceStats) GetAuthDigestMismatches() uint64 {
+	if x != nil {
+		return x.AuthDigestMismatches
+	}
+	return 0
+}
+
+func (x *OSPFInterfaceStats) GetAuthSequenceErrors() uint64 {
+	if x != nil {
+		return x.AuthSequenceErrors
+	}
+	return 0
+}
+
+type GetOSPFNeighborsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetOSPFNeighborsRequest) Reset() {
+	*x = GetOSPFNeighborsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOSPFNeighborsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOSPFNeighborsRequest) ProtoMessage() {}
+
+func (x *GetOSPFNeighborsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOSPFNeighborsRequest.ProtoReflect.Descriptor instead.
+func (*GetOSPFNeighborsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{27}
+}
+
+type GetOSPFNeighborsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Neighbors []*OSPFNeighbor `protobuf:"bytes,1,rep,name=neighbors,proto3" json:"neighbors,omitempty"`
+}
+
+func (x *GetOSPFNeighborsReply) Reset() {
+	*x = GetOSPFNeighborsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOSPFNeighborsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOSPFNeighborsReply) ProtoMessage() {}
+
+func (x *GetOSPFNeighborsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOSPFNeighborsReply.ProtoReflect.Descriptor instead.
+func (*GetOSPFNeighborsReply) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetOSPFNeighborsReply) GetNeighbors() []*OSPFNeighbor {
+	if x != nil {
+		return x.Neighbors
+	}
+	return nil
+}
+
+type OSPFNeighbor struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Interface              string                    `protobuf:"bytes,1,opt,name=interface,proto3" json:"interface,omitempty"`
+	InterfaceAddr          *Prefix                   `protobuf:"bytes,2,opt,name=interface_addr,json=interfaceAddr,proto3" json:"interface_addr,omitempty"`
+	RouterId               uint32                    `protobuf:"varint,3,opt,name=router_id,json=routerId,proto3" json:"router_id,omitempty"`
+	Addr                   []byte                    `protobuf:"bytes,4,opt,name=addr,proto3" json:"addr,omitempty"`
+	State                  string                    `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	Priority               uint32                    `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	DesignatedRouter       []byte                    `protobuf:"bytes,7,opt,name=designated_router,json=designatedRouter,proto3" json:"designated_router,omitempty"`
+	BackupDesignatedRouter []byte                    `protobuf:"bytes,8,opt,name=backup_designated_router,json=backupDesignatedRouter,proto3" json:"backup_designated_router,omitempty"`
+	DeadTime               int64                     `protobuf:"varint,9,opt,name=dead_time,json=deadTime,proto3" json:"dead_time,omitempty"`
+	History                []*OSPFNeighborTransition `protobuf:"bytes,10,rep,name=history,proto3" json:"history,omitempty"`
+	RetransmissionListLen  uint32                    `protobuf:"varint,11,opt,name=retransmission_list_len,json=retransmissionListLen,proto3" json:"retransmission_list_len,omitempty"`
+	Stats                  *OSPFNeighborStats        `protobuf:"bytes,12,opt,name=stats,proto3" json:"stats,omitempty"`
+	RestartHelper          bool                      `protobuf:"varint,13,opt,name=restart_helper,json=restartHelper,proto3" json:"restart_helper,omitempty"`
+	GraceRemaining         int64                     `protobuf:"varint,14,opt,name=grace_remaining,json=graceRemaining,proto3" json:"grace_remaining,omitempty"`
+}
+
+func (x *OSPFNeighbor) Reset() {
+	*x = OSPFNeighbor{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFNeighbor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFNeighbor) ProtoMessage() {}
+
+func (x *OSPFNeighbor) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFNeighbor.ProtoReflect.Descriptor instead.
+func (*OSPFNeighbor) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *OSPFNeighbor) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *OSPFNeighbor) GetInterfaceAddr() *Prefix {
+	if x != nil {
+		return x.InterfaceAddr
+	}
+	return nil
+}
+
+func (x *OSPFNeighbor) GetRouterId() uint32 {
+	if x != nil {
+		return x.RouterId
+	}
+	return 0
+}
+
+func (x *OSPFNeighbor) GetAddr() []byte {
+	if x != nil {
+		return x.Addr
+	}
+	return nil
+}
+
+func (x *OSPFNeighbor) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *OSPFNeighbor) GetPriority() uint32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *OSPFNeighbor) GetDesignatedRouter() []byte {
+	if x != nil {
+		return x.DesignatedRouter
+	}
+	return nil
+}
+
+func (x *OSPFNeighbor) GetBackupDesignatedRouter() []byte {
+	if x != nil {
+		return x.BackupDesignatedRouter
+	}
+	return nil
+}
+
+func (x *OSPFNeighbor) GetDeadTime() int64 {
+	if x != nil {
+		return x.DeadTime
+	}
+	return 0
+}
+
+func (x *OSPFNeighbor) GetHistory() []*OSPFNeighborTransition {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *OSPFNeighbor) GetRetransmissionListLen() uint32 {
+	if x != nil {
+		return x.RetransmissionListLen
+	}
+	return 0
+}
+
+func (x *OSPFNeighbor) GetStats() *OSPFNeighborStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *OSPFNeighbor) GetRestartHelper() bool {
+	if x != nil {
+		return x.RestartHelper
+	}
+	return false
+}
+
+func (x *OSPFNeighbor) GetGraceRemaining() int64 {
+	if x != nil {
+		return x.GraceRemaining
+	}
+	return 0
+}
+
+type OSPFNeighborStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Retransmissions uint64 `protobuf:"varint,1,opt,name=retransmissions,proto3" json:"retransmissions,omitempty"`
+	DuplicateLsas   uint64 `protobuf:"varint,2,opt,name=duplicate_lsas,json=duplicateLsas,proto3" json:"duplicate_lsas,omitempty"`
+}
+
+func (x *OSPFNeighborStats) Reset() {
+	*x = OSPFNeighborStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFNeighborStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFNeighborStats) ProtoMessage() {}
+
+func (x *OSPFNeighborStats) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFNeighborStats.ProtoReflect.Descriptor instead.
+func (*OSPFNeighborStats) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *OSPFNeighborStats) GetRetransmissions() uint64 {
+	if x != nil {
+		return x.Retransmissions
+	}
+	return 0
+}
+
+func (x *OSPFNeighborStats) GetDuplicateLsas() uint64 {
+	if x != nil {
+		return x.DuplicateLsas
+	}
+	return 0
+}
+
+type OSPFNeighborTransition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TimeUnixNano int64  `protobuf:"varint,1,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+	Event        string `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	From         string `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To           string `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+}
+
+func (x *OSPFNeighborTransition) Reset() {
+	*x = OSPFNeighborTransition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFNeighborTransition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFNeighborTransition) ProtoMessage() {}
+
+func (x *OSPFNeighborTransition) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFNeighborTransition.ProtoReflect.Descriptor instead.
+func (*OSPFNeighborTransition) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *OSPFNeighborTransition) GetTimeUnixNano() int64 {
+	if x != nil {
+		return x.TimeUnixNano
+	}
+	return 0
+}
+
+func (x *OSPFNeighborTransition) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *OSPFNeighborTransition) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *OSPFNeighborTransition) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type GetOSPFRoutesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetOSPFRoutesRequest) Reset() {
+	*x = GetOSPFRoutesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOSPFRoutesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOSPFRoutesRequest) ProtoMessage() {}
+
+func (x *GetOSPFRoutesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOSPFRoutesRequest.ProtoReflect.Descriptor instead.
+func (*GetOSPFRoutesRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{32}
+}
+
+type GetOSPFRoutesReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Routes []*OSPFRoute `protobuf:"bytes,1,rep,name=routes,proto3" json:"routes,omitempty"`
+}
+
+func (x *GetOSPFRoutesReply) Reset() {
+	*x = GetOSPFRoutesReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOSPFRoutesReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOSPFRoutesReply) ProtoMessage() {}
+
+func (x *GetOSPFRoutesReply) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOSPFRoutesReply.ProtoReflect.Descriptor instead.
+func (*GetOSPFRoutesReply) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetOSPFRoutesReply) GetRoutes() []*OSPFRoute {
+	if x != nil {
+		return x.Routes
+	}
+	return nil
+}
+
+type OSPFRoute struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Prefix            *Prefix        `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	PathType          string         `protobuf:"bytes,2,opt,name=path_type,json=pathType,proto3" json:"path_type,omitempty"`
+	Cost              uint32         `protobuf:"varint,3,opt,name=cost,proto3" json:"cost,omitempty"`
+	AreaId            uint32         `protobuf:"varint,4,opt,name=area_id,json=areaId,proto3" json:"area_id,omitempty"`
+	NextHops          []*OSPFNextHop `protobuf:"bytes,5,rep,name=next_hops,json=nextHops,proto3" json:"next_hops,omitempty"`
+	Type2Cost         uint32         `protobuf:"varint,6,opt,name=type2_cost,json=type2Cost,proto3" json:"type2_cost,omitempty"`
+	AdvertisingRouter uint32         `protobuf:"varint,7,opt,name=advertising_router,json=advertisingRouter,proto3" json:"advertising_router,omitempty"`
+	Discard           bool           `protobuf:"varint,8,opt,name=discard,proto3" json:"discard,omitempty"`
+}
+
+func (x *OSPFRoute) Reset() {
+	*x = OSPFRoute{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFRoute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFRoute) ProtoMessage() {}
+
+func (x *OSPFRoute) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFRoute.ProtoReflect.Descriptor instead.
+func (*OSPFRoute) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *OSPFRoute) GetPrefix() *Prefix {
+	if x != nil {
+		return x.Prefix
+	}
+	return nil
+}
+
+func (x *OSPFRoute) GetPathType() string {
+	if x != nil {
+		return x.PathType
+	}
+	return ""
+}
+
+func (x *OSPFRoute) GetCost() uint32 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *OSPFRoute) GetAreaId() uint32 {
+	if x != nil {
+		return x.AreaId
+	}
+	return 0
+}
+
+func (x *OSPFRoute) GetNextHops() []*OSPFNextHop {
+	if x != nil {
+		return x.NextHops
+	}
+	return nil
+}
+
+func (x *OSPFRoute) GetType2Cost() uint32 {
+	if x != nil {
+		return x.Type2Cost
+	}
+	return 0
+}
+
+func (x *OSPFRoute) GetAdvertisingRouter() uint32 {
+	if x != nil {
+		return x.AdvertisingRouter
+	}
+	return 0
+}
+
+func (x *OSPFRoute) GetDiscard() bool {
+	if x != nil {
+		return x.Discard
+	}
+	return false
+}
+
+type OSPFNextHop struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Interface     string  `protobuf:"bytes,1,opt,name=interface,proto3" json:"interface,omitempty"`
+	InterfaceAddr *Prefix `protobuf:"bytes,2,opt,name=interface_addr,json=interfaceAddr,proto3" json:"interface_addr,omitempty"`
+	Addr          []byte  `protobuf:"bytes,3,opt,name=addr,proto3" json:"addr,omitempty"`
+}
+
+func (x *OSPFNextHop) Reset() {
+	*x = OSPFNextHop{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFNextHop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFNextHop) ProtoMessage() {}
+
+func (x *OSPFNextHop) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFNextHop.ProtoReflect.Descriptor instead.
+func (*OSPFNextHop) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *OSPFNextHop) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *OSPFNextHop) GetInterfaceAddr() *Prefix {
+	if x != nil {
+		return x.InterfaceAddr
+	}
+	return nil
+}
+
+func (x *OSPFNextHop) GetAddr() []byte {
+	if x != nil {
+		return x.Addr
+	}
+	return nil
+}
+
+type GetOSPFAreasRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetOSPFAreasRequest) Reset() {
+	*x = GetOSPFAreasRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOSPFAreasRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOSPFAreasRequest) ProtoMessage() {}
+
+func (x *GetOSPFAreasRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOSPFAreasRequest.ProtoReflect.Descriptor instead.
+func (*GetOSPFAreasRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{36}
+}
+
+type GetOSPFAreasReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Areas []*OSPFArea `protobuf:"bytes,1,rep,name=areas,proto3" json:"areas,omitempty"`
+}
+
+func (x *GetOSPFAreasReply) Reset() {
+	*x = GetOSPFAreasReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOSPFAreasReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOSPFAreasReply) ProtoMessage() {}
+
+func (x *GetOSPFAreasReply) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOSPFAreasReply.ProtoReflect.Descriptor instead.
+func (*GetOSPFAreasReply) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetOSPFAreasReply) GetAreas() []*OSPFArea {
+	if x != nil {
+		return x.Areas
+	}
+	return nil
+}
+
+type OSPFArea struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AreaId          uint32 `protobuf:"varint,1,opt,name=area_id,json=areaId,proto3" json:"area_id,omitempty"`
+	Type            string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	NoSummary       bool   `protobuf:"varint,3,opt,name=no_summary,json=noSummary,proto3" json:"no_summary,omitempty"`
+	DefaultCost     uint32 `protobuf:"varint,4,opt,name=default_cost,json=defaultCost,proto3" json:"default_cost,omitempty"`
+	Lsas            uint32 `protobuf:"varint,5,opt,name=lsas,proto3" json:"lsas,omitempty"`
+	TranslatorRole  string `protobuf:"bytes,6,opt,name=translator_role,json=translatorRole,proto3" json:"translator_role,omitempty"`
+	TranslatorState string `protobuf:"bytes,7,opt,name=translator_state,json=translatorState,proto3" json:"translator_state,omitempty"`
+}
+
+func (x *OSPFArea) Reset() {
+	*x = OSPFArea{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OSPFArea) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OSPFArea) ProtoMessage() {}
+
+func (x *OSPFArea) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OSPFArea.ProtoReflect.Descriptor instead.
+func (*OSPFArea) Descriptor() ([]byte, []int) {
+	return file_rpc_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *OSPFArea) GetAreaId() uint32 {
+	if x != nil {
+		return x.AreaId
+	}
+	return 0
+}
+
+func (x *OSPFArea) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *OSPFArea) GetNoSummary() bool {
+	if x != nil {
+		return x.NoSummary
+	}
+	return false
+}
+
+func (x *OSPFArea) GetDefaultCost() uint32 {
+	if x != nil {
+		return x.DefaultCost
+	}
+	return 0
+}
+
+func (x *OSPFArea) GetLsas() uint32 {
+	if x != nil {
+		return x.Lsas
+	}
+	return 0
+}
+
+func (x *OSPFArea) GetTranslatorRole() string {
+	if x != nil {
+		return x.TranslatorRole
+	}
+	return ""
+}
+
+func (x *OSPFArea) GetTranslatorState() string {
+	if x != nil {
+		return x.TranslatorState
+	}
+	return ""
+}
+
 var File_rpc_proto protoreflect.FileDescriptor
 
 var file_rpc_proto_rawDesc = []byte{
@@ -587,26 +2561,343 @@ var file_rpc_proto_rawDesc = []byte{
 	0x04, 0x61, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x61, 0x64, 0x64,
 	0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x5f, 0x6c, 0x65, 0x6e, 0x18,
 	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x4c, 0x65, 0x6e,
-	0x32, 0x83, 0x02, 0x0a, 0x03, 0x41, 0x50, 0x49, 0x12, 0x3c, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x56,
-	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74,
-	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14,
-	0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x36, 0x0a, 0x08, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f,
-	0x77, 0x6e, 0x12, 0x14, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77,
-	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53,
-	0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3f,
-	0x0a, 0x0b, 0x47, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x17, 0x2e,
-	0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74,
-	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12,
-	0x45, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x73,
-	0x12, 0x19, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66,
-	0x61, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x72, 0x70,
-	0x63, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x73, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x25, 0x5a, 0x23, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
-	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64, 0x61, 0x76, 0x69, 0x64, 0x62, 0x61, 0x6c, 0x62, 0x65, 0x72,
-	0x74, 0x2f, 0x63, 0x68, 0x61, 0x74, 0x74, 0x65, 0x72, 0x2f, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x22, 0x18, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x49, 0x6e, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x45, 0x0a, 0x14, 0x47, 0x65,
+	0x74, 0x4f, 0x53, 0x50, 0x46, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x12, 0x2d, 0x0a, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4f, 0x53, 0x50, 0x46, 0x49,
+	0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x22, 0xf9, 0x01, 0x0a, 0x0c, 0x4f, 0x53, 0x50, 0x46, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x49, 0x64, 0x12,
+	0x43, 0x0a, 0x10, 0x67, 0x72, 0x61, 0x63, 0x65, 0x66, 0x75, 0x6c, 0x5f, 0x72, 0x65, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72, 0x70, 0x63, 0x2e,
+	0x4f, 0x53, 0x50, 0x46, 0x47, 0x72, 0x61, 0x63, 0x65, 0x66, 0x75, 0x6c, 0x52, 0x65, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x52, 0x0f, 0x67, 0x72, 0x61, 0x63, 0x65, 0x66, 0x75, 0x6c, 0x52, 0x65, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x12, 0x31, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x6d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4f,
+	0x53, 0x50, 0x46, 0x4d, 0x61, 0x78, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x52, 0x09, 0x6d, 0x61,
+	0x78, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x12, 0x1e, 0x0a, 0x03, 0x73, 0x70, 0x66, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4f, 0x53, 0x50, 0x46, 0x53,
+	0x50, 0x46, 0x52, 0x03, 0x73, 0x70, 0x66, 0x12, 0x34, 0x0a, 0x0c, 0x6c, 0x73, 0x61, 0x5f, 0x74,
+	0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x72, 0x70, 0x63, 0x2e, 0x4f, 0x53, 0x50, 0x46, 0x54, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65,
+	0x52, 0x0b, 0x6c, 0x73, 0x61, 0x54, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x22, 0xfb, 0x01,
+	0x0a, 0x13, 0x4f, 0x53, 0x50, 0x46, 0x47, 0x72, 0x61, 0x63, 0x65, 0x66, 0x75, 0x6c, 0x52, 0x65,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12,
+	0x21, 0x0a, 0x0c, 0x67, 0x72, 0x61, 0x63, 0x65, 0x5f, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x67, 0x72, 0x61, 0x63, 0x65, 0x50, 0x65, 0x72, 0x69,
+	0x6f, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x6c, 0x70, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x68, 0x65, 0x6c, 0x70, 0x65, 0x72, 0x12, 0x2e, 0x0a, 0x13, 0x73, 0x74,
+	0x72, 0x69, 0x63, 0x74, 0x5f, 0x6c, 0x73, 0x61, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x69, 0x6e,
+	0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x4c,
+	0x73, 0x61, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a,
+	0x72, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x27, 0x0a, 0x0f, 0x67, 0x72,
+	0x61, 0x63, 0x65, 0x5f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0e, 0x67, 0x72, 0x61, 0x63, 0x65, 0x52, 0x65, 0x6d, 0x61, 0x69, 0x6e,
+	0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0xc4, 0x01, 0x0a, 0x0d,
+	0x4f, 0x53, 0x50, 0x46, 0x4d, 0x61, 0x78, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x12, 0x26, 0x0a,
+	0x0e, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x76, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x69, 0x73, 0x74, 0x72,
+	0x61, 0x74, 0x69, 0x76, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6f, 0x6e, 0x5f, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x75, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x6f, 0x6e, 0x53, 0x74, 0x61,
+	0x72, 0x74, 0x75, 0x70, 0x12, 0x20, 0x0a, 0x0b, 0x61, 0x64, 0x76, 0x65, 0x72, 0x74, 0x69, 0x73,
+	0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x61, 0x64, 0x76, 0x65, 0x72,
+	0x74, 0x69, 0x73, 0x69, 0x6e, 0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x6f, 0x6e, 0x5f, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x6f, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x11, 0x73, 0x74, 0x61, 0x72, 0x74, 0x75, 0x70,
+	0x5f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x10, 0x73, 0x74, 0x61, 0x72, 0x74, 0x75, 0x70, 0x52, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69,
+	0x6e, 0x67, 0x22, 0x62, 0x0a, 0x0c, 0x4f, 0x53, 0x50, 0x46, 0x54, 0x68, 0x72, 0x6f, 0x74, 0x74,
+	0x6c, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x64, 0x65,
+	0x6c, 0x61, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x69, 0x6e, 0x69, 0x74, 0x69,
+	0x61, 0x6c, 0x44, 0x65, 0x6c, 0x61, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x6c, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x6d,
+	0x61, 0x78, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x6d,
+	0x61, 0x78, 0x57, 0x61, 0x69, 0x74, 0x22, 0xa9, 0x01, 0x0a, 0x07, 0x4f, 0x53, 0x50, 0x46, 0x53,
+	0x50, 0x46, 0x12, 0x2d, 0x0a, 0x08, 0x74, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4f, 0x53, 0x50, 0x46, 0x54,
+	0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c, 0x65, 0x52, 0x08, 0x74, 0x68, 0x72, 0x6f, 0x74, 0x74, 0x6c,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x04, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c,
+	0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75,
+	0x6c, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x75, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x04, 0x72, 0x75, 0x6e, 0x73, 0x12, 0x29, 0x0a, 0x07, 0x68, 0x69, 0x73, 0x74, 0x6f,
+	0x72, 0x79, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4f,
+	0x53, 0x50, 0x46, 0x53, 0x50, 0x46, 0x52, 0x75, 0x6e, 0x52, 0x07, 0x68, 0x69, 0x73, 0x74, 0x6f,
+	0x72, 0x79, 0x22, 0xa6, 0x01, 0x0a, 0x0a, 0x4f, 0x53, 0x50, 0x46, 0x53, 0x50, 0x46, 0x52, 0x75,
+	0x6e, 0x12, 0x26, 0x0a, 0x0f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f,
+	0x6e, 0x61, 0x6e, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x55, 0x6e, 0x69, 0x78, 0x4e, 0x61, 0x6e, 0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x64, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2f, 0x0a, 0x08, 0x74, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4f, 0x53,
+	0x50, 0x46, 0x53, 0x50, 0x46, 0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x52, 0x08, 0x74, 0x72,
+	0x69, 0x67, 0x67, 0x65, 0x72, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x72, 0x69, 0x67, 0x67, 0x65,
+	0x72, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x74,
+	0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x96, 0x01, 0x0a, 0x0e,
+	0x4f, 0x53, 0x50, 0x46, 0x53, 0x50, 0x46, 0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x12, 0x17,
+	0x0a, 0x07, 0x61, 0x72, 0x65, 0x61, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x06, 0x61, 0x72, 0x65, 0x61, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x02, 0x69, 0x64, 0x12, 0x2d, 0x0a, 0x12, 0x61,
+	0x64, 0x76, 0x65, 0x72, 0x74, 0x69, 0x73, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x6f, 0x75, 0x74, 0x65,
+	0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x11, 0x61, 0x64, 0x76, 0x65, 0x72, 0x74, 0x69,
+	0x73, 0x69, 0x6e, 0x67, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x66, 0x6c,
+	0x75, 0x73, 0x68, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x66, 0x6c, 0x75,
+	0x73, 0x68, 0x65, 0x64, 0x22, 0x33, 0x0a, 0x17, 0x53, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x4d,
+	0x61, 0x78, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x22, 0x17, 0x0a, 0x15, 0x53, 0x65, 0x74,
+	0x4f, 0x53, 0x50, 0x46, 0x4d, 0x61, 0x78, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x1a, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x49, 0x6e, 0x74,
+	0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x4c,
+	0x0a, 0x16, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61,
+	0x63, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x32, 0x0a, 0x0a, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x66, 0x61, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x72,
+	0x70, 0x63, 0x2e, 0x4f, 0x53, 0x50, 0x46, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65,
+	0x52, 0x0a, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x73, 0x22, 0x83, 0x06, 0x0a,
+	0x0d, 0x4f, 0x53, 0x50, 0x46, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x04, 0x61, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0b, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x52, 0x04, 0x61,
+	0x64, 0x64, 0x72, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x72, 0x65, 0x61, 0x5f, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x61, 0x72, 0x65, 0x61, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x73, 0x74, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x63, 0x6f, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72,
+	0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x72,
+	0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x5f,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d,
+	0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x23, 0x0a,
+	0x0d, 0x64, 0x65, 0x61, 0x64, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x64, 0x65, 0x61, 0x64, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76,
+	0x61, 0x6c, 0x12, 0x3c, 0x0a, 0x11, 0x64, 0x65, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x65, 0x64,
+	0x5f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e,
+	0x72, 0x70, 0x63, 0x2e, 0x4f, 0x53, 0x50, 0x46, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x52, 0x10,
+	0x64, 0x65, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x72,
+	0x12, 0x49, 0x0a, 0x18, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x5f, 0x64, 0x65, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4f, 0x53, 0x50, 0x46, 0x52, 0x6f, 0x75,
+	0x74, 0x65, 0x72, 0x52, 0x16, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x44, 0x65, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x65, 0x64, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x6e,
+	0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09,
+	0x6e, 0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x61, 0x64, 0x6a,
+	0x61, 0x63, 0x65, 0x6e, 0x74, 0x5f, 0x6e, 0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x73, 0x18,
+	0x0d, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x11, 0x61, 0x64, 0x6a, 0x61, 0x63, 0x65, 0x6e, 0x74, 0x4e,
+	0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x73, 0x12, 0x2d, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74,
+	0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4f, 0x53,
+	0x50, 0x46, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73,
+	0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x69, 0x74, 0x5f, 0x61, 0x72, 0x65, 0x61, 0x5f, 0x69, 0x64, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x41, 0x72, 0x65, 0x61, 0x49, 0x64, 0x12,
+	0x32, 0x0a, 0x15, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x5f,
+	0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x13,
+	0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x4c, 0x69, 0x6e, 0x6b, 0x45, 0x6e, 0x64, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x61, 0x64,
+	0x64, 0x72, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
+	0x41, 0x64, 0x64, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x61, 0x75, 0x74, 0x68, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x1e, 0x0a, 0x0b, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x69, 0x64,
+	0x18, 0x13, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x61, 0x75, 0x74, 0x68, 0x4b, 0x65, 0x79, 0x49,
+	0x64, 0x12, 0x25, 0x0a, 0x0e, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69,
+	0x74, 0x68, 0x6d, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x61, 0x75, 0x74, 0x68, 0x41,
+	0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x73, 0x73,
+	0x69, 0x76, 0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x70, 0x61, 0x73, 0x73, 0x69,
+	0x76, 0x65, 0x22, 0x3d, 0x0a, 0x0a, 0x4f, 0x53, 0x50, 0x46, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x72,
+	0x12, 0x1b, 0x0a, 0x09, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x08, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x49, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x61, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x61, 0x64, 0x64,
+	0x72, 0x22, 0x85, 0x04, 0x0a, 0x12, 0x4f, 0x53, 0x50, 0x46, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66,
+	0x61, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x68, 0x65, 0x6c, 0x6c,
+	0x6f, 0x73, 0x5f, 0x73, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x68,
+	0x65, 0x6c, 0x6c, 0x6f, 0x73, 0x53, 0x65, 0x6e, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x68, 0x65, 0x6c,
+	0x6c, 0x6f, 0x73, 0x5f, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x0e, 0x68, 0x65, 0x6c, 0x6c, 0x6f, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x64, 0x12, 0x36, 0x0a, 0x17, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x6d, 0x61,
+	0x73, 0x6b, 0x5f, 0x6d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x15, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x4d, 0x61, 0x73, 0x6b,
+	0x4d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x3a, 0x0a, 0x19, 0x68, 0x65,
+	0x6c, 0x6c, 0x6f, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x6d, 0x69, 0x73,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x17, 0x68,
+	0x65, 0x6c, 0x6c, 0x6f, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x4d, 0x69, 0x73, 0x6d,
+	0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x38, 0x0a, 0x18, 0x64, 0x65, 0x61, 0x64, 0x5f, 0x69,
+	0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x6d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68,
+	0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x16, 0x64, 0x65, 0x61, 0x64, 0x49, 0x6e,
+	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x4d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73,
+	0x12, 0x2d, 0x0a, 0x12, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x5f, 0x6d, 0x69, 0x73, 0x6d,
+	0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11, 0x6f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x4d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12,
+	0x30, 0x0a, 0x14, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x6d, 0x69, 0x73,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x61,
+	0x75, 0x74, 0x68, 0x54, 0x79, 0x70, 0x65, 0x4d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x73, 0x12, 0x2e, 0x0a, 0x13, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x6d, 0x69,
+	0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11,
+	0x61, 0x75, 0x74, 0x68, 0x4b, 0x65, 0x79, 0x4d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x73, 0x12, 0x34, 0x0a, 0x16, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x5f, 0x6d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x14, 0x61, 0x75, 0x74, 0x68, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x4d, 0x69, 0x73,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x61, 0x75, 0x74, 0x68, 0x5f,
+	0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x61, 0x75, 0x74, 0x68, 0x53, 0x65, 0x71, 0x75, 0x65,
+	0x6e, 0x63, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x22, 0x19, 0x0a, 0x17, 0x47, 0x65, 0x74,
+	0x4f, 0x53, 0x50, 0x46, 0x4e, 0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x22, 0x48, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x4e,
+	0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2f, 0x0a,
+	0x09, 0x6e, 0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4f, 0x53, 0x50, 0x46, 0x4e, 0x65, 0x69, 0x67, 0x68,
+	0x62, 0x6f, 0x72, 0x52, 0x09, 0x6e, 0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x73, 0x22, 0xb4,
+	0x04, 0x0a, 0x0c, 0x4f, 0x53, 0x50, 0x46, 0x4e, 0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x12,
+	0x1c, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x12, 0x32, 0x0a,
+	0x0e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x50, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x52, 0x0d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x41, 0x64, 0x64,
+	0x72, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x49, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x61, 0x64, 0x64, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x61, 0x64,
+	0x64, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x12, 0x2b, 0x0a, 0x11, 0x64, 0x65, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x65, 0x64, 0x5f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x10, 0x64, 0x65, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x52, 0x6f, 0x75, 0x74, 0x65,
+	0x72, 0x12, 0x38, 0x0a, 0x18, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x5f, 0x64, 0x65, 0x73, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x16, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x44, 0x65, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x65, 0x64, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x64,
+	0x65, 0x61, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08,
+	0x64, 0x65, 0x61, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x35, 0x0a, 0x07, 0x68, 0x69, 0x73, 0x74,
+	0x6f, 0x72, 0x79, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x72, 0x70, 0x63, 0x2e,
+	0x4f, 0x53, 0x50, 0x46, 0x4e, 0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12,
+	0x36, 0x0a, 0x17, 0x72, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x5f, 0x6c, 0x65, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x15, 0x72, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x4c, 0x69, 0x73, 0x74, 0x4c, 0x65, 0x6e, 0x12, 0x2c, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4f, 0x53, 0x50,
+	0x46, 0x4e, 0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x05,
+	0x73, 0x74, 0x61, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x5f, 0x68, 0x65, 0x6c, 0x70, 0x65, 0x72, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x72,
+	0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x48, 0x65, 0x6c, 0x70, 0x65, 0x72, 0x12, 0x27, 0x0a, 0x0f,
+	0x67, 0x72, 0x61, 0x63, 0x65, 0x5f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18,
+	0x0e, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x67, 0x72, 0x61, 0x63, 0x65, 0x52, 0x65, 0x6d, 0x61,
+	0x69, 0x6e, 0x69, 0x6e, 0x67, 0x22, 0x64, 0x0a, 0x11, 0x4f, 0x53, 0x50, 0x46, 0x4e, 0x65, 0x69,
+	0x67, 0x68, 0x62, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x72, 0x65,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0f, 0x72, 0x65, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6d, 0x69, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x64, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74,
+	0x65, 0x5f, 0x6c, 0x73, 0x61, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x64, 0x75,
+	0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x4c, 0x73, 0x61, 0x73, 0x22, 0x78, 0x0a, 0x16, 0x4f,
+	0x53, 0x50, 0x46, 0x4e, 0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x24, 0x0a, 0x0e, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x75, 0x6e,
+	0x69, 0x78, 0x5f, 0x6e, 0x61, 0x6e, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x74,
+	0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4e, 0x61, 0x6e, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x16, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46,
+	0x52, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x3c, 0x0a,
+	0x12, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x26, 0x0a, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4f, 0x53, 0x50, 0x46, 0x52, 0x6f,
+	0x75, 0x74, 0x65, 0x52, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x22, 0x91, 0x02, 0x0a, 0x09,
+	0x4f, 0x53, 0x50, 0x46, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x23, 0x0a, 0x06, 0x70, 0x72, 0x65,
+	0x66, 0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x72, 0x70, 0x63, 0x2e,
+	0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x1b,
+	0x0a, 0x09, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x70, 0x61, 0x74, 0x68, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63,
+	0x6f, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x63, 0x6f, 0x73, 0x74, 0x12,
+	0x17, 0x0a, 0x07, 0x61, 0x72, 0x65, 0x61, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x06, 0x61, 0x72, 0x65, 0x61, 0x49, 0x64, 0x12, 0x2d, 0x0a, 0x09, 0x6e, 0x65, 0x78, 0x74,
+	0x5f, 0x68, 0x6f, 0x70, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x72, 0x70,
+	0x63, 0x2e, 0x4f, 0x53, 0x50, 0x46, 0x4e, 0x65, 0x78, 0x74, 0x48, 0x6f, 0x70, 0x52, 0x08, 0x6e,
+	0x65, 0x78, 0x74, 0x48, 0x6f, 0x70, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x79, 0x70, 0x65, 0x32,
+	0x5f, 0x63, 0x6f, 0x73, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x74, 0x79, 0x70,
+	0x65, 0x32, 0x43, 0x6f, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x12, 0x61, 0x64, 0x76, 0x65, 0x72, 0x74,
+	0x69, 0x73, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x11, 0x61, 0x64, 0x76, 0x65, 0x72, 0x74, 0x69, 0x73, 0x69, 0x6e, 0x67, 0x52,
+	0x6f, 0x75, 0x74, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x69, 0x73, 0x63, 0x61, 0x72, 0x64,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x69, 0x73, 0x63, 0x61, 0x72, 0x64, 0x22,
+	0x73, 0x0a, 0x0b, 0x4f, 0x53, 0x50, 0x46, 0x4e, 0x65, 0x78, 0x74, 0x48, 0x6f, 0x70, 0x12, 0x1c,
+	0x0a, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x12, 0x32, 0x0a, 0x0e,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x50, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x52, 0x0d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x41, 0x64, 0x64, 0x72,
+	0x12, 0x12, 0x0a, 0x04, 0x61, 0x64, 0x64, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
+	0x61, 0x64, 0x64, 0x72, 0x22, 0x15, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x41,
+	0x72, 0x65, 0x61, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x38, 0x0a, 0x11, 0x47,
+	0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x41, 0x72, 0x65, 0x61, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x23, 0x0a, 0x05, 0x61, 0x72, 0x65, 0x61, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x0d, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4f, 0x53, 0x50, 0x46, 0x41, 0x72, 0x65, 0x61, 0x52, 0x05,
+	0x61, 0x72, 0x65, 0x61, 0x73, 0x22, 0xe1, 0x01, 0x0a, 0x08, 0x4f, 0x53, 0x50, 0x46, 0x41, 0x72,
+	0x65, 0x61, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x72, 0x65, 0x61, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x06, 0x61, 0x72, 0x65, 0x61, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12,
+	0x1d, 0x0a, 0x0a, 0x6e, 0x6f, 0x5f, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x6e, 0x6f, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x21,
+	0x0a, 0x0c, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x63, 0x6f, 0x73, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x43, 0x6f, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x73, 0x61, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x04, 0x6c, 0x73, 0x61, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61,
+	0x74, 0x6f, 0x72, 0x5f, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x52, 0x6f, 0x6c, 0x65, 0x12, 0x29,
+	0x0a, 0x10, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6c,
+	0x61, 0x74, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x32, 0xce, 0x05, 0x0a, 0x03, 0x41, 0x50,
+	0x49, 0x12, 0x3c, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x16, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65,
+	0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12,
+	0x36, 0x0a, 0x08, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x12, 0x14, 0x2e, 0x72, 0x70,
+	0x63, 0x2e, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x12, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3f, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x17, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x15, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x49,
+	0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x73, 0x12, 0x19, 0x2e, 0x72, 0x70, 0x63, 0x2e,
+	0x47, 0x65, 0x74, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x6e,
+	0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12,
+	0x4b, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x12, 0x1b, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46,
+	0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x19, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x49, 0x6e, 0x73,
+	0x74, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x51, 0x0a, 0x11,
+	0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65,
+	0x73, 0x12, 0x1d, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x49,
+	0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1b, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x49, 0x6e,
+	0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12,
+	0x4e, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x4e, 0x65, 0x69, 0x67, 0x68, 0x62,
+	0x6f, 0x72, 0x73, 0x12, 0x1c, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50,
+	0x46, 0x4e, 0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1a, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x4e,
+	0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12,
+	0x45, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x73,
+	0x12, 0x19, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x52, 0x6f,
+	0x75, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x72, 0x70,
+	0x63, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x42, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50,
+	0x46, 0x41, 0x72, 0x65, 0x61, 0x73, 0x12, 0x18, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74,
+	0x4f, 0x53, 0x50, 0x46, 0x41, 0x72, 0x65, 0x61, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x41, 0x72,
+	0x65, 0x61, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x4e, 0x0a, 0x10, 0x53, 0x65,
+	0x74, 0x4f, 0x53, 0x50, 0x46, 0x4d, 0x61, 0x78, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x12, 0x1c,
+	0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x4d, 0x61, 0x78, 0x4d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x72,
+	0x70, 0x63, 0x2e, 0x53, 0x65, 0x74, 0x4f, 0x53, 0x50, 0x46, 0x4d, 0x61, 0x78, 0x4d, 0x65, 0x74,
+	0x72, 0x69, 0x63, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x25, 0x5a, 0x23, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64, 0x61, 0x76, 0x69, 0x64, 0x62, 0x61,
+	0x6c, 0x62, 0x65, 0x72, 0x74, 0x2f, 0x63, 0x68, 0x61, 0x74, 0x74, 0x65, 0x72, 0x2f, 0x72, 0x70,
+	0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -621,37 +2912,99 @@ func file_rpc_proto_rawDescGZIP() []byte {
 	return file_rpc_proto_rawDescData
 }
 
-var file_rpc_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_rpc_proto_msgTypes = make([]protoimpl.MessageInfo, 39)
 var file_rpc_proto_goTypes = []interface{}{
-	(*GetVersionRequest)(nil),    // 0: rpc.GetVersionRequest
-	(*GetVersionReply)(nil),      // 1: rpc.GetVersionReply
-	(*ShutdownRequest)(nil),      // 2: rpc.ShutdownRequest
-	(*ShutdownReply)(nil),        // 3: rpc.ShutdownReply
-	(*GetServicesRequest)(nil),   // 4: rpc.GetServicesRequest
-	(*GetServicesReply)(nil),     // 5: rpc.GetServicesReply
-	(*Service)(nil),              // 6: rpc.Service
-	(*GetInterfacesRequest)(nil), // 7: rpc.GetInterfacesRequest
-	(*GetInterfacesReply)(nil),   // 8: rpc.GetInterfacesReply
-	(*Interface)(nil),            // 9: rpc.Interface
-	(*Prefix)(nil),               // 10: rpc.Prefix
+	(*GetVersionRequest)(nil),        // 0: rpc.GetVersionRequest
+	(*GetVersionReply)(nil),          // 1: rpc.GetVersionReply
+	(*ShutdownRequest)(nil),          // 2: rpc.ShutdownRequest
+	(*ShutdownReply)(nil),            // 3: rpc.ShutdownReply
+	(*GetServicesRequest)(nil),       // 4: rpc.GetServicesRequest
+	(*GetServicesReply)(nil),         // 5: rpc.GetServicesReply
+	(*Service)(nil),                  // 6: rpc.Service
+	(*GetInterfacesRequest)(nil),     // 7: rpc.GetInterfacesRequest
+	(*GetInterfacesReply)(nil),       // 8: rpc.GetInterfacesReply
+	(*Interface)(nil),                // 9: rpc.Interface
+	(*Prefix)(nil),                   // 10: rpc.Prefix
+	(*GetOSPFInstanceRequest)(nil),   // 11: rpc.GetOSPFInstanceRequest
+	(*GetOSPFInstanceReply)(nil),     // 12: rpc.GetOSPFInstanceReply
+	(*OSPFInstance)(nil),             // 13: rpc.OSPFInstance
+	(*OSPFGracefulRestart)(nil),      // 14: rpc.OSPFGracefulRestart
+	(*OSPFMaxMetric)(nil),            // 15: rpc.OSPFMaxMetric
+	(*OSPFThrottle)(nil),             // 16: rpc.OSPFThrottle
+	(*OSPFSPF)(nil),                  // 17: rpc.OSPFSPF
+	(*OSPFSPFRun)(nil),               // 18: rpc.OSPFSPFRun
+	(*OSPFSPFTrigger)(nil),           // 19: rpc.OSPFSPFTrigger
+	(*SetOSPFMaxMetricRequest)(nil),  // 20: rpc.SetOSPFMaxMetricRequest
+	(*SetOSPFMaxMetricReply)(nil),    // 21: rpc.SetOSPFMaxMetricReply
+	(*GetOSPFInterfacesRequest)(nil), // 22: rpc.GetOSPFInterfacesRequest
+	(*GetOSPFInterfacesReply)(nil),   // 23: rpc.GetOSPFInterfacesReply
+	(*OSPFInterface)(nil),            // 24: rpc.OSPFInterface
+	(*OSPFRouter)(nil),               // 25: rpc.OSPFRouter
+	(*OSPFInterfaceStats)(nil),       // 26: rpc.OSPFInterfaceStats
+	(*GetOSPFNeighborsRequest)(nil),  // 27: rpc.GetOSPFNeighborsRequest
+	(*GetOSPFNeighborsReply)(nil),    // 28: rpc.GetOSPFNeighborsReply
+	(*OSPFNeighbor)(nil),             // 29: rpc.OSPFNeighbor
+	(*OSPFNeighborStats)(nil),        // 30: rpc.OSPFNeighborStats
+	(*OSPFNeighborTransition)(nil),   // 31: rpc.OSPFNeighborTransition
+	(*GetOSPFRoutesRequest)(nil),     // 32: rpc.GetOSPFRoutesRequest
+	(*GetOSPFRoutesReply)(nil),       // 33: rpc.GetOSPFRoutesReply
+	(*OSPFRoute)(nil),                // 34: rpc.OSPFRoute
+	(*OSPFNextHop)(nil),              // 35: rpc.OSPFNextHop
+	(*GetOSPFAreasRequest)(nil),      // 36: rpc.GetOSPFAreasRequest
+	(*GetOSPFAreasReply)(nil),        // 37: rpc.GetOSPFAreasReply
+	(*OSPFArea)(nil),                 // 38: rpc.OSPFArea
 }
 var file_rpc_proto_depIdxs = []int32{
 	6,  // 0: rpc.GetServicesReply.services:type_name -> rpc.Service
 	9,  // 1: rpc.GetInterfacesReply.interfaces:type_name -> rpc.Interface
 	10, // 2: rpc.Interface.addrs:type_name -> rpc.Prefix
-	0,  // 3: rpc.API.GetVersion:input_type -> rpc.GetVersionRequest
-	2,  // 4: rpc.API.Shutdown:input_type -> rpc.ShutdownRequest
-	4,  // 5: rpc.API.GetServices:input_type -> rpc.GetServicesRequest
-	7,  // 6: rpc.API.GetInterfaces:input_type -> rpc.GetInterfacesRequest
-	1,  // 7: rpc.API.GetVersion:output_type -> rpc.GetVersionReply
-	3,  // 8: rpc.API.Shutdown:output_type -> rpc.ShutdownReply
-	5,  // 9: rpc.API.GetServices:output_type -> rpc.GetServicesReply
-	8,  // 10: rpc.API.GetInterfaces:output_type -> rpc.GetInterfacesReply
-	7,  // [7:11] is the sub-list for method output_type
-	3,  // [3:7] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	13, // 3: rpc.GetOSPFInstanceReply.instance:type_name -> rpc.OSPFInstance
+	14, // 4: rpc.OSPFInstance.graceful_restart:type_name -> rpc.OSPFGracefulRestart
+	15, // 5: rpc.OSPFInstance.max_metric:type_name -> rpc.OSPFMaxMetric
+	17, // 6: rpc.OSPFInstance.spf:type_name -> rpc.OSPFSPF
+	16, // 7: rpc.OSPFInstance.lsa_throttle:type_name -> rpc.OSPFThrottle
+	16, // 8: rpc.OSPFSPF.throttle:type_name -> rpc.OSPFThrottle
+	18, // 9: rpc.OSPFSPF.history:type_name -> rpc.OSPFSPFRun
+	19, // 10: rpc.OSPFSPFRun.triggers:type_name -> rpc.OSPFSPFTrigger
+	24, // 11: rpc.GetOSPFInterfacesReply.interfaces:type_name -> rpc.OSPFInterface
+	10, // 12: rpc.OSPFInterface.addr:type_name -> rpc.Prefix
+	25, // 13: rpc.OSPFInterface.designated_router:type_name -> rpc.OSPFRouter
+	25, // 14: rpc.OSPFInterface.backup_designated_router:type_name -> rpc.OSPFRouter
+	26, // 15: rpc.OSPFInterface.stats:type_name -> rpc.OSPFInterfaceStats
+	29, // 16: rpc.GetOSPFNeighborsReply.neighbors:type_name -> rpc.OSPFNeighbor
+	10, // 17: rpc.OSPFNeighbor.interface_addr:type_name -> rpc.Prefix
+	31, // 18: rpc.OSPFNeighbor.history:type_name -> rpc.OSPFNeighborTransition
+	30, // 19: rpc.OSPFNeighbor.stats:type_name -> rpc.OSPFNeighborStats
+	34, // 20: rpc.GetOSPFRoutesReply.routes:type_name -> rpc.OSPFRoute
+	10, // 21: rpc.OSPFRoute.prefix:type_name -> rpc.Prefix
+	35, // 22: rpc.OSPFRoute.next_hops:type_name -> rpc.OSPFNextHop
+	10, // 23: rpc.OSPFNextHop.interface_addr:type_name -> rpc.Prefix
+	38, // 24: rpc.GetOSPFAreasReply.areas:type_name -> rpc.OSPFArea
+	0,  // 25: rpc.API.GetVersion:input_type -> rpc.GetVersionRequest
+	2,  // 26: rpc.API.Shutdown:input_type -> rpc.ShutdownRequest
+	4,  // 27: rpc.API.GetServices:input_type -> rpc.GetServicesRequest
+	7,  // 28: rpc.API.GetInterfaces:input_type -> rpc.GetInterfacesRequest
+	11, // 29: rpc.API.GetOSPFInstance:input_type -> rpc.GetOSPFInstanceRequest
+	22, // 30: rpc.API.GetOSPFInterfaces:input_type -> rpc.GetOSPFInterfacesRequest
+	27, // 31: rpc.API.GetOSPFNeighbors:input_type -> rpc.GetOSPFNeighborsRequest
+	32, // 32: rpc.API.GetOSPFRoutes:input_type -> rpc.GetOSPFRoutesRequest
+	36, // 33: rpc.API.GetOSPFAreas:input_type -> rpc.GetOSPFAreasRequest
+	20, // 34: rpc.API.SetOSPFMaxMetric:input_type -> rpc.SetOSPFMaxMetricRequest
+	1,  // 35: rpc.API.GetVersion:output_type -> rpc.GetVersionReply
+	3,  // 36: rpc.API.Shutdown:output_type -> rpc.ShutdownReply
+	5,  // 37: rpc.API.GetServices:output_type -> rpc.GetServicesReply
+	8,  // 38: rpc.API.GetInterfaces:output_type -> rpc.GetInterfacesReply
+	12, // 39: rpc.API.GetOSPFInstance:output_type -> rpc.GetOSPFInstanceReply
+	23, // 40: rpc.API.GetOSPFInterfaces:output_type -> rpc.GetOSPFInterfacesReply
+	28, // 41: rpc.API.GetOSPFNeighbors:output_type -> rpc.GetOSPFNeighborsReply
+	33, // 42: rpc.API.GetOSPFRoutes:output_type -> rpc.GetOSPFRoutesReply
+	37, // 43: rpc.API.GetOSPFAreas:output_type -> rpc.GetOSPFAreasReply
+	21, // 44: rpc.API.SetOSPFMaxMetric:output_type -> rpc.SetOSPFMaxMetricReply
+	35, // [35:45] is the sub-list for method output_type
+	25, // [25:35] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
 }
 
 func init() { file_rpc_proto_init() }
@@ -672,8 +3025,80 @@ func file_rpc_proto_init() {
 				return nil
 			}
 		}
-		file_rpc_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetVersionReply); i {
+		file_rpc_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetVersionReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShutdownRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShutdownReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetServicesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetServicesReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Service); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetInterfacesRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -684,8 +3109,8 @@ func file_rpc_proto_init() {
 				return nil
 			}
 		}
-		file_rpc_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ShutdownRequest); i {
+		file_rpc_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetInterfacesReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -696,8 +3121,8 @@ func file_rpc_proto_init() {
 				return nil
 			}
 		}
-		file_rpc_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ShutdownReply); i {
+		file_rpc_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Interface); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -708,8 +3133,8 @@ func file_rpc_proto_init() {
 				return nil
 			}
 		}
-		file_rpc_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetServicesRequest); i {
+		file_rpc_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Prefix); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -720,8 +3145,8 @@ func file_rpc_proto_init() {
 				return nil
 			}
 		}
-		file_rpc_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetServicesReply); i {
+		file_rpc_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOSPFInstanceRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -732,8 +3157,8 @@ func file_rpc_proto_init() {
 				return nil
 			}
 		}
-		file_rpc_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Service); i {
+		file_rpc_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOSPFInstanceReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -744,8 +3169,8 @@ func file_rpc_proto_init() {
 				return nil
 			}
 		}
-		file_rpc_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetInterfacesRequest); i {
+		file_rpc_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFInstance); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -756,8 +3181,8 @@ func file_rpc_proto_init() {
 				return nil
 			}
 		}
-		file_rpc_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetInterfacesReply); i {
+		file_rpc_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFGracefulRestart); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -768,8 +3193,8 @@ func file_rpc_proto_init() {
 				return nil
 			}
 		}
-		file_rpc_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Interface); i {
+		file_rpc_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFMaxMetric); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -780,8 +3205,272 @@ func file_rpc_proto_init() {
 				return nil
 			}
 		}
-		file_rpc_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Prefix); i {
+		file_rpc_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFThrottle); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFSPF); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFSPFRun); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFSPFTrigger); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetOSPFMaxMetricRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetOSPFMaxMetricReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOSPFInterfacesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOSPFInterfacesReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFInterface); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFRouter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFInterfaceStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOSPFNeighborsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOSPFNeighborsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFNeighbor); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFNeighborStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFNeighborTransition); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOSPFRoutesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOSPFRoutesReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFRoute); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFNextHop); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOSPFAreasRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOSPFAreasReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OSPFArea); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -799,7 +3488,7 @@ func file_rpc_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_rpc_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   39,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -26,6 +26,12 @@ type APIClient interface {
 	Shutdown(ctx context.Context, in *ShutdownRequest, opts ...grpc.CallOption) (*ShutdownReply, error)
 	GetServices(ctx context.Context, in *GetServicesRequest, opts ...grpc.CallOption) (*GetServicesReply, error)
 	GetInterfaces(ctx context.Context, in *GetInterfacesRequest, opts ...grpc.CallOption) (*GetInterfacesReply, error)
+	GetOSPFInstance(ctx context.Context, in *GetOSPFInstanceRequest, opts ...grpc.CallOption) (*GetOSPFInstanceReply, error)
+	GetOSPFInterfaces(ctx context.Context, in *GetOSPFInterfacesRequest, opts ...grpc.CallOption) (*GetOSPFInterfacesReply, error)
+	GetOSPFNeighbors(ctx context.Context, in *GetOSPFNeighborsRequest, opts ...grpc.CallOption) (*GetOSPFNeighborsReply, error)
+	GetOSPFRoutes(ctx context.Context, in *GetOSPFRoutesRequest, opts ...grpc.CallOption) (*GetOSPFRoutesReply, error)
+	GetOSPFAreas(ctx context.Context, in *GetOSPFAreasRequest, opts ...grpc.CallOption) (*GetOSPFAreasReply, error)
+	SetOSPFMaxMetric(ctx context.Context, in *SetOSPFMaxMetricRequest, opts ...grpc.CallOption) (*SetOSPFMaxMetricReply, error)
 }
 
 type aPIClient struct {
@@ -72,6 +78,60 @@ func (c *aPIClient) GetInterfaces(ctx context.Context, in *GetInterfacesRequest,
 	return out, nil
 }
 
+func (c *aPIClient) GetOSPFInstance(ctx context.Context, in *GetOSPFInstanceRequest, opts ...grpc.CallOption) (*GetOSPFInstanceReply, error) {
+	out := new(GetOSPFInstanceReply)
+	err := c.cc.Invoke(ctx, "/rpc.API/GetOSPFInstance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetOSPFInterfaces(ctx context.Context, in *GetOSPFInterfacesRequest, opts ...grpc.CallOption) (*GetOSPFInterfacesReply, error) {
+	out := new(GetOSPFInterfacesReply)
+	err := c.cc.Invoke(ctx, "/rpc.API/GetOSPFInterfaces", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetOSPFNeighbors(ctx context.Context, in *GetOSPFNeighborsRequest, opts ...grpc.CallOption) (*GetOSPFNeighborsReply, error) {
+	out := new(GetOSPFNeighborsReply)
+	err := c.cc.Invoke(ctx, "/rpc.API/GetOSPFNeighbors", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetOSPFRoutes(ctx context.Context, in *GetOSPFRoutesRequest, opts ...grpc.CallOption) (*GetOSPFRoutesReply, error) {
+	out := new(GetOSPFRoutesReply)
+	err := c.cc.Invoke(ctx, "/rpc.API/GetOSPFRoutes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetOSPFAreas(ctx context.Context, in *GetOSPFAreasRequest, opts ...grpc.CallOption) (*GetOSPFAreasReply, error) {
+	out := new(GetOSPFAreasReply)
+	err := c.cc.Invoke(ctx, "/rpc.API/GetOSPFAreas", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) SetOSPFMaxMetric(ctx context.Context, in *SetOSPFMaxMetricRequest, opts ...grpc.CallOption) (*SetOSPFMaxMetricReply, error) {
+	out := new(SetOSPFMaxMetricReply)
+	err := c.cc.Invoke(ctx, "/rpc.API/SetOSPFMaxMetric", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // APIServer is the server API for API service.
 // All implementations must embed UnimplementedAPIServer
 // for forward compatibility
@@ -80,6 +140,12 @@ type APIServer interface {
 	Shutdown(context.Context, *ShutdownRequest) (*ShutdownReply, error)
 	GetServices(context.Context, *GetServicesRequest) (*GetServicesReply, error)
 	GetInterfaces(context.Context, *GetInterfacesRequest) (*GetInterfacesReply, error)
+	GetOSPFInstance(context.Context, *GetOSPFInstanceRequest) (*GetOSPFInstanceReply, error)
+	GetOSPFInterfaces(context.Context, *GetOSPFInterfacesRequest) (*GetOSPFInterfacesReply, error)
+	GetOSPFNeighbors(context.Context, *GetOSPFNeighborsRequest) (*GetOSPFNeighborsReply, error)
+	GetOSPFRoutes(context.Context, *GetOSPFRoutesRequest) (*GetOSPFRoutesReply, error)
+	GetOSPFAreas(context.Context, *GetOSPFAreasRequest) (*GetOSPFAreasReply, error)
+	SetOSPFMaxMetric(context.Context, *SetOSPFMaxMetricRequest) (*SetOSPFMaxMetricReply, error)
 	mustEmbedUnimplementedAPIServer()
 }
 
@@ -99,6 +165,24 @@ func (UnimplementedAPIServer) GetServices(context.Context, *GetServicesRequest)
 func (UnimplementedAPIServer) GetInterfaces(context.Context, *GetInterfacesRequest) (*GetInterfacesReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetInterfaces not implemented")
 }
+func (UnimplementedAPIServer) GetOSPFInstance(context.Context, *GetOSPFInstanceRequest) (*GetOSPFInstanceReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOSPFInstance not implemented")
+}
+func (UnimplementedAPIServer) GetOSPFInterfaces(context.Context, *GetOSPFInterfacesRequest) (*GetOSPFInterfacesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOSPFInterfaces not implemented")
+}
+func (UnimplementedAPIServer) GetOSPFNeighbors(context.Context, *GetOSPFNeighborsRequest) (*GetOSPFNeighborsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOSPFNeighbors not implemented")
+}
+func (UnimplementedAPIServer) GetOSPFRoutes(context.Context, *GetOSPFRoutesRequest) (*GetOSPFRoutesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOSPFRoutes not implemented")
+}
+func (UnimplementedAPIServer) GetOSPFAreas(context.Context, *GetOSPFAreasRequest) (*GetOSPFAreasReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOSPFAreas not implemented")
+}
+func (UnimplementedAPIServer) SetOSPFMaxMetric(context.Context, *SetOSPFMaxMetricRequest) (*SetOSPFMaxMetricReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetOSPFMaxMetric not implemented")
+}
 func (UnimplementedAPIServer) mustEmbedUnimplementedAPIServer() {}
 
 // UnsafeAPIServer may be embedded to opt out of forward compatibility for this service.
@@ -184,6 +268,114 @@ func _API_GetInterfaces_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_GetOSPFInstance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOSPFInstanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetOSPFInstance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.API/GetOSPFInstance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetOSPFInstance(ctx, req.(*GetOSPFInstanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetOSPFInterfaces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOSPFInterfacesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetOSPFInterfaces(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.API/GetOSPFInterfaces",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetOSPFInterfaces(ctx, req.(*GetOSPFInterfacesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetOSPFNeighbors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOSPFNeighborsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetOSPFNeighbors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.API/GetOSPFNeighbors",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetOSPFNeighbors(ctx, req.(*GetOSPFNeighborsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetOSPFRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOSPFRoutesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetOSPFRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.API/GetOSPFRoutes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetOSPFRoutes(ctx, req.(*GetOSPFRoutesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetOSPFAreas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOSPFAreasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetOSPFAreas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.API/GetOSPFAreas",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetOSPFAreas(ctx, req.(*GetOSPFAreasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_SetOSPFMaxMetric_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetOSPFMaxMetricRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).SetOSPFMaxMetric(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.API/SetOSPFMaxMetric",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).SetOSPFMaxMetric(ctx, req.(*SetOSPFMaxMetricRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // API_ServiceDesc is the grpc.ServiceDesc for API service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -207,6 +399,30 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetInterfaces",
 			Handler:    _API_GetInterfaces_Handler,
 		},
+		{
+			MethodName: "GetOSPFInstance",
+			Handler:    _API_GetOSPFInstance_Handler,
+		},
+		{
+			MethodName: "GetOSPFInterfaces",
+			Handler:    _API_GetOSPFInterfaces_Handler,
+		},
+		{
+			MethodName: "GetOSPFNeighbors",
+			Handler:    _API_GetOSPFNeighbors_Handler,
+		},
+		{
+			MethodName: "GetOSPFRoutes",
+			Handler:    _API_GetOSPFRoutes_Handler,
+		},
+		{
+			MethodName: "GetOSPFAreas",
+			Handler:    _API_GetOSPFAreas_Handler,
+		},
+		{
+			MethodName: "SetOSPFMaxMetric",
+			Handler:    _API_SetOSPFMaxMetric_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "rpc.proto",
@@ -28,12 +28,17 @@ func (q *queue[T]) Put(item T) {
 	q.items <- items
 }
 
-func (q *queue[T]) Get(ctx context.Context) T {
+// Get blocks until there's an item to return. It returns false if ctx is
+// canceled or done is closed first.
+func (q *queue[T]) Get(ctx context.Context, done <-chan struct{}) (T, bool) {
 	var items []T
 	select {
 	case <-ctx.Done():
 		var zero T
-		return zero
+		return zero, false
+	case <-done:
+		var zero T
+		return zero, false
 	case items = <-q.items:
 	}
 
@@ -45,5 +50,5 @@ func (q *queue[T]) Get(ctx context.Context) T {
 		q.items <- items
 	}
 
-	return item
+	return item, true
 }
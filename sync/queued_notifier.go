@@ -16,7 +16,8 @@ type Token struct {
 // for the guarantee that you won't miss any messages and no goroutine can slow another down.
 //
 // The channel is wrapped in a Token struct to hide its implementation details. No values are
-// ever sent on the channel, it's just used a unique value.
+// ever sent on the channel, it's just used a unique value. It's closed when the listener is
+// unregistered, which wakes up anyone waiting in AwaitChange.
 //
 // One thing this is missing right now: when you register a listener, it's sometimes useful to
 // immediately receive the most recent value. Right now, you can't do that, but it would be
@@ -47,7 +48,20 @@ func (n *QueuedNotifier[T]) Register() Token {
 
 func (n *QueuedNotifier[T]) Unregister(t Token) {
 	st := <-n.st
-	delete(st, t.t)
+	if _, ok := st[t.t]; ok {
+		delete(st, t.t)
+		close(t.t)
+	}
+	n.st <- st
+}
+
+// UnregisterAll unregisters every listener.
+func (n *QueuedNotifier[T]) UnregisterAll() {
+	st := <-n.st
+	for t := range st {
+		delete(st, t)
+		close(t)
+	}
 	n.st <- st
 }
 
@@ -59,6 +73,8 @@ func (n *QueuedNotifier[T]) NotifyChange(v T) {
 	n.st <- st
 }
 
+// AwaitChange blocks until the next change is available. It returns false if ctx is canceled
+// or t is unregistered, including while it's waiting.
 func (n *QueuedNotifier[T]) AwaitChange(ctx context.Context, t Token) (T, bool) {
 	st := <-n.st
 	q := st[t.t]
@@ -69,5 +85,5 @@ func (n *QueuedNotifier[T]) AwaitChange(ctx context.Context, t Token) (T, bool)
 		return zero, false
 	}
 
-	return q.Get(ctx), true
+	return q.Get(ctx, t.t)
 }